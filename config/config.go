@@ -1,52 +1,244 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strconv"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"family_finance_back/internal/money"
 )
 
+// Config содержит настройки приложения
+// Значения собираются из нескольких источников в порядке возрастания приоритета:
+// значения по умолчанию (тег default), файл конфигурации (YAML или TOML),
+// переменные окружения (тег env, а также вариант <ENV>_FILE для Docker secrets)
+// и флаги командной строки (--db-host и т.п.)
+// Поля с тегом secret скрываются при выводе конфигурации
 type Config struct {
-	DBHost       string
-	DBPort       string
-	DBUser       string
-	DBPassword   string
-	DBName       string
-	RedisAddr    string
-	RedisPass    string
-	SMTPHost     string
-	SMTPPort     int
-	SMTPUsername string
-	SMTPPassword string
-	JWTSecret    string
-}
-
-func LoadConfig() Config {
-	// Загружаем файл .env
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, using system environment variables")
-	}
-
-	smtpPort, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
-	if err != nil {
-		log.Fatalf("Invalid SMTP_PORT: %v", err)
-	}
-
-	return Config{
-		DBHost:       os.Getenv("DB_HOST"),
-		DBPort:       os.Getenv("DB_PORT"),
-		DBUser:       os.Getenv("DB_USER"),
-		DBPassword:   os.Getenv("DB_PASSWORD"),
-		DBName:       os.Getenv("DB_NAME"),
-		RedisAddr:    os.Getenv("REDIS_ADDR"),
-		RedisPass:    os.Getenv("REDIS_PASSWORD"),
-		SMTPHost:     os.Getenv("SMTP_HOST"),
-		SMTPPort:     smtpPort,
-		SMTPUsername: os.Getenv("SMTP_USERNAME"),
-		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
-		JWTSecret:    os.Getenv("JWT_SECRET"),
+	// HTTPAddr адрес, на котором слушает HTTP-сервер
+	HTTPAddr string `env:"HTTP_ADDR" default:":8080"`
+
+	// AppURL публичный адрес приложения, используемый в ссылках из писем
+	AppURL string `env:"APP_URL" default:"http://localhost:8080"`
+
+	DBHost     string `env:"DB_HOST" required:"true"`
+	DBPort     int    `env:"DB_PORT" default:"5432"`
+	DBUser     string `env:"DB_USER" required:"true"`
+	DBPassword string `env:"DB_PASSWORD" secret:"true"`
+	DBName     string `env:"DB_NAME" required:"true"`
+
+	RedisAddr string `env:"REDIS_ADDR" required:"true"`
+	RedisPass string `env:"REDIS_PASSWORD" secret:"true"`
+
+	// EmailTransport способ доставки писем: smtp, file, log или capture
+	EmailTransport string `env:"EMAIL_TRANSPORT" default:"smtp"`
+	// EmailFrom адрес отправителя; по умолчанию SMTP_USERNAME
+	EmailFrom string `env:"EMAIL_FROM"`
+	// EmailLocale язык писем (ru или en)
+	EmailLocale string `env:"EMAIL_LOCALE" default:"ru"`
+	// EmailFileDir каталог для транспорта file
+	EmailFileDir string `env:"EMAIL_FILE_DIR" default:"var/mail"`
+
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT" default:"465"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD" secret:"true"`
+	// SMTPSecurity режим защиты соединения: tls (неявный TLS), starttls или plain
+	SMTPSecurity string `env:"SMTP_SECURITY" default:"tls"`
+
+	JWTSecret string `env:"JWT_SECRET" required:"true" secret:"true"`
+
+	// InviteTTL срок действия приглашения в семью
+	InviteTTL time.Duration `env:"INVITE_TTL" default:"168h"`
+
+	// AdminEmails адреса администраторов, которым доступны эндпоинты /admin/*
+	AdminEmails []string `env:"ADMIN_EMAILS"`
+
+	// Параметры очереди исходящих писем
+	OutboxWorkers      int           `env:"OUTBOX_WORKERS" default:"4"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" default:"20"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" default:"2s"`
+	OutboxMaxAttempts  int           `env:"OUTBOX_MAX_ATTEMPTS" default:"8"`
+	OutboxBaseBackoff  time.Duration `env:"OUTBOX_BASE_BACKOFF" default:"30s"`
+	OutboxMaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" default:"1h"`
+
+	// RecurringPollInterval как часто планировщик проверяет наступившие регулярные операции
+	RecurringPollInterval time.Duration `env:"RECURRING_POLL_INTERVAL" default:"1m"`
+
+	// BillReminderInterval как часто проверяются сроки оплаты счетов для напоминаний
+	BillReminderInterval time.Duration `env:"BILL_REMINDER_INTERVAL" default:"1h"`
+
+	// ImportPollInterval как часто фоновая задача проверяет выписки, ждущие разбора или проведения
+	ImportPollInterval time.Duration `env:"IMPORT_POLL_INTERVAL" default:"5s"`
+
+	// RatesPivotCurrency валюта, через которую вычисляются кросс-курсы
+	// для пар без прямой котировки (курсы Банка России котируются к рублю)
+	RatesPivotCurrency string `env:"RATES_PIVOT_CURRENCY" default:"RUB"`
+	// RatesMaxAgeDays сколько дней после даты котировки курс считается действующим;
+	// покрывает выходные и праздники, когда курсы не публикуются
+	RatesMaxAgeDays int `env:"RATES_MAX_AGE_DAYS" default:"10"`
+	// RatesFile файл курсов по умолчанию для команды rates import
+	RatesFile string `env:"RATES_FILE"`
+
+	// EmailProviderRules включает нормализацию адресов по правилам
+	// почтовых провайдеров (точки и "+метки" в Gmail и т.п.)
+	EmailProviderRules bool `env:"EMAIL_PROVIDER_RULES" default:"false"`
+
+	// DevEndpoints включает отладочные эндпоинты /dev/*; не включайте в production
+	DevEndpoints bool `env:"DEV_ENDPOINTS" default:"false"`
+}
+
+// defaultSender адрес отправителя для локальных транспортов, если он не задан явно
+const defaultSender = "no-reply@family-finance.local"
+
+// Sender возвращает адрес отправителя писем
+func (c Config) Sender() string {
+	if c.EmailFrom != "" {
+		return c.EmailFrom
+	}
+	if c.SMTPUsername != "" {
+		return c.SMTPUsername
+	}
+	if c.EmailTransport != "smtp" {
+		return defaultSender
+	}
+	return ""
+}
+
+// minJWTSecretLength минимальная длина секрета для подписи JWT
+const minJWTSecretLength = 16
+
+// ValidationError содержит все найденные проблемы конфигурации
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "некорректная конфигурация:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate проверяет конфигурацию и возвращает ValidationError
+// со списком всех найденных проблем сразу
+func (c Config) Validate() error {
+	problems := c.dbProblems()
+	for _, f := range configFields() {
+		if f.required && !isDBField(f) && f.value(&c).IsZero() {
+			problems = append(problems, fmt.Sprintf("%s: значение обязательно", f.env))
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(c.HTTPAddr); err != nil {
+		problems = append(problems, fmt.Sprintf("HTTP_ADDR: некорректный адрес %q", c.HTTPAddr))
+	}
+	if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+		problems = append(problems, fmt.Sprintf("SMTP_PORT: порт должен быть в диапазоне 1-65535, получено %d", c.SMTPPort))
+	}
+	if c.JWTSecret != "" && len(c.JWTSecret) < minJWTSecretLength {
+		problems = append(problems, fmt.Sprintf("JWT_SECRET: длина должна быть не меньше %d символов", minJWTSecretLength))
+	}
+	if u, err := url.Parse(c.AppURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("APP_URL: ожидается абсолютный http(s) адрес, получено %q", c.AppURL))
+	}
+	if c.InviteTTL <= 0 {
+		problems = append(problems, "INVITE_TTL: ожидается положительная длительность")
+	}
+	if c.OutboxWorkers < 1 {
+		problems = append(problems, "OUTBOX_WORKERS: должно быть не меньше 1")
+	}
+	if c.OutboxBatchSize < 1 {
+		problems = append(problems, "OUTBOX_BATCH_SIZE: должно быть не меньше 1")
+	}
+	if c.OutboxMaxAttempts < 1 {
+		problems = append(problems, "OUTBOX_MAX_ATTEMPTS: должно быть не меньше 1")
+	}
+	if c.OutboxPollInterval <= 0 || c.OutboxBaseBackoff <= 0 || c.OutboxMaxBackoff < c.OutboxBaseBackoff {
+		problems = append(problems, "OUTBOX_POLL_INTERVAL, OUTBOX_BASE_BACKOFF, OUTBOX_MAX_BACKOFF: ожидаются положительные длительности, OUTBOX_MAX_BACKOFF >= OUTBOX_BASE_BACKOFF")
+	}
+	if c.RecurringPollInterval <= 0 {
+		problems = append(problems, "RECURRING_POLL_INTERVAL: ожидается положительная длительность")
+	}
+	if c.BillReminderInterval <= 0 {
+		problems = append(problems, "BILL_REMINDER_INTERVAL: ожидается положительная длительность")
+	}
+	if c.ImportPollInterval <= 0 {
+		problems = append(problems, "IMPORT_POLL_INTERVAL: ожидается положительная длительность")
+	}
+	if cur, ok := money.LookupCurrency(c.RatesPivotCurrency); !ok || cur.Code != c.RatesPivotCurrency {
+		problems = append(problems, fmt.Sprintf("RATES_PIVOT_CURRENCY: неизвестный код валюты %q", c.RatesPivotCurrency))
+	}
+	if c.RatesMaxAgeDays < 1 || c.RatesMaxAgeDays > 366 {
+		problems = append(problems, fmt.Sprintf("RATES_MAX_AGE_DAYS: ожидается число от 1 до 366, получено %d", c.RatesMaxAgeDays))
+	}
+	if c.EmailLocale != "ru" && c.EmailLocale != "en" {
+		problems = append(problems, fmt.Sprintf("EMAIL_LOCALE: ожидается ru или en, получено %q", c.EmailLocale))
 	}
+	switch c.EmailTransport {
+	case "smtp":
+		if c.SMTPHost == "" {
+			problems = append(problems, "SMTP_HOST: значение обязательно для EMAIL_TRANSPORT=smtp")
+		}
+		if c.SMTPUsername != "" && c.SMTPPassword == "" {
+			problems = append(problems, "SMTP_PASSWORD: обязателен, если задан SMTP_USERNAME")
+		}
+		if c.SMTPSecurity != "tls" && c.SMTPSecurity != "starttls" && c.SMTPSecurity != "plain" {
+			problems = append(problems, fmt.Sprintf("SMTP_SECURITY: ожидается tls, starttls или plain, получено %q", c.SMTPSecurity))
+		}
+		// net/smtp отказывается передавать пароль по незашифрованному соединению с удалённым сервером,
+		// поэтому такая конфигурация упала бы только на первой отправке
+		if c.SMTPSecurity == "plain" && c.SMTPUsername != "" && !isLocalHost(c.SMTPHost) {
+			problems = append(problems, "SMTP_SECURITY: plain несовместим с SMTP_USERNAME для нелокального SMTP_HOST — "+
+				"пароль нельзя передавать без шифрования; используйте tls или starttls либо уберите SMTP_USERNAME")
+		}
+		if c.Sender() == "" {
+			problems = append(problems, "EMAIL_FROM: значение обязательно, если не задан SMTP_USERNAME")
+		}
+	case "file":
+		if c.EmailFileDir == "" {
+			problems = append(problems, "EMAIL_FILE_DIR: значение обязательно для EMAIL_TRANSPORT=file")
+		}
+	case "log", "capture":
+	default:
+		problems = append(problems, fmt.Sprintf("EMAIL_TRANSPORT: ожидается smtp, file, log или capture, получено %q", c.EmailTransport))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidateDB проверяет только параметры подключения к базе данных (DB_*)
+// Используется командами, которым не нужны Redis, почта и JWT, например migrate
+func (c Config) ValidateDB() error {
+	if problems := c.dbProblems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// dbProblems возвращает проблемы параметров подключения к базе данных
+func (c Config) dbProblems() []string {
+	var problems []string
+	for _, f := range configFields() {
+		if f.required && isDBField(f) && f.value(&c).IsZero() {
+			problems = append(problems, fmt.Sprintf("%s: значение обязательно", f.env))
+		}
+	}
+	if c.DBPort < 1 || c.DBPort > 65535 {
+		problems = append(problems, fmt.Sprintf("DB_PORT: порт должен быть в диапазоне 1-65535, получено %d", c.DBPort))
+	}
+	return problems
+}
+
+// isDBField сообщает, относится ли параметр к подключению к базе данных
+func isDBField(f field) bool {
+	return strings.HasPrefix(f.env, "DB_")
+}
+
+// isLocalHost сообщает, указывает ли host на локальную машину так же, как это
+// определяет net/smtp.PlainAuth при проверке незашифрованного соединения
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
 }
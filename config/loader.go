@@ -0,0 +1,300 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Source описывает, откуда было взято значение параметра
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceEnvFile Source = "env-file"
+	SourceFlag    Source = "flag"
+)
+
+// field описывает параметр конфигурации, построенный по тегам поля Config
+type field struct {
+	index    int
+	env      string
+	key      string
+	flag     string
+	def      string
+	required bool
+	secret   bool
+}
+
+// value возвращает reflect.Value поля в указанной конфигурации
+func (f field) value(c *Config) reflect.Value {
+	return reflect.ValueOf(c).Elem().Field(f.index)
+}
+
+// configFields перечисляет параметры конфигурации по тегам структуры Config
+// Ключ в файле конфигурации — имя переменной окружения в нижнем регистре (db_host),
+// флаг — то же имя через дефис (--db-host)
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		env := sf.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		key := strings.ToLower(env)
+		fields = append(fields, field{
+			index:    i,
+			env:      env,
+			key:      key,
+			flag:     strings.ReplaceAll(key, "_", "-"),
+			def:      sf.Tag.Get("default"),
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+		})
+	}
+	return fields
+}
+
+// setValue разбирает строковое значение в соответствии с типом поля
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("ожидается целое число")
+		}
+		v.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("ожидается true или false")
+		}
+		v.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("ожидается длительность, например 30s или 5m")
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", v.Type())
+	}
+	return nil
+}
+
+// Loader собирает конфигурацию из всех источников
+type Loader struct {
+	fs         *flag.FlagSet
+	configFile *string
+	flags      map[string]*string
+	sources    map[string]Source
+}
+
+// NewLoader создает новый экземпляр Loader и регистрирует флаги
+// конфигурации (--config и по одному флагу на параметр) в указанном FlagSet
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		fs:         fs,
+		configFile: fs.String("config", "", "путь к файлу конфигурации (.yaml, .yml или .toml); по умолчанию CONFIG_FILE"),
+		flags:      make(map[string]*string),
+		sources:    make(map[string]Source),
+	}
+	for _, f := range configFields() {
+		l.flags[f.key] = fs.String(f.flag, "", "переопределяет "+f.env)
+	}
+	return l
+}
+
+// Load собирает конфигурацию после разбора флагов и проверяет её
+// Все ошибки разбора и валидации возвращаются одним ValidationError
+func (l *Loader) Load() (Config, error) {
+	return l.load(Config.Validate)
+}
+
+// LoadDB собирает конфигурацию так же, как Load, но проверяет только параметры
+// подключения к базе данных
+func (l *Loader) LoadDB() (Config, error) {
+	return l.load(Config.ValidateDB)
+}
+
+// load собирает конфигурацию из всех источников и проверяет её функцией validate
+func (l *Loader) load(validate func(Config) error) (Config, error) {
+	var cfg Config
+	var problems []string
+	fields := configFields()
+	byKey := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	set := func(f field, raw string, src Source) {
+		if err := setValue(f.value(&cfg), raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: некорректное значение %q (%s): %v", f.env, raw, src, err))
+			return
+		}
+		l.sources[f.key] = src
+	}
+
+	// 1. Значения по умолчанию
+	for _, f := range fields {
+		if f.def != "" {
+			set(f, f.def, SourceDefault)
+		}
+	}
+
+	// Файл .env дополняет переменные окружения, не перезаписывая уже заданные
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, fmt.Sprintf(".env: %v", err))
+	}
+
+	// 2. Файл конфигурации
+	path := *l.configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: неизвестный параметр %q", path, key))
+				continue
+			}
+			set(f, values[key], SourceFile)
+		}
+	}
+
+	// 3. Переменные окружения и их варианты <ENV>_FILE
+	for _, f := range fields {
+		raw, hasEnv := os.LookupEnv(f.env)
+		secretPath, hasFile := os.LookupEnv(f.env + "_FILE")
+		switch {
+		case hasEnv && hasFile:
+			problems = append(problems, fmt.Sprintf("%s: заданы одновременно %s и %s_FILE", f.env, f.env, f.env))
+		case hasFile:
+			data, err := os.ReadFile(secretPath)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: не удалось прочитать файл: %v", f.env, err))
+				continue
+			}
+			set(f, strings.TrimRight(string(data), "\r\n"), SourceEnvFile)
+		case hasEnv:
+			set(f, raw, SourceEnv)
+		}
+	}
+
+	// 4. Флаги командной строки
+	l.fs.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.flag == fl.Name {
+				set(f, *l.flags[f.key], SourceFlag)
+			}
+		}
+	})
+
+	if err := validate(cfg); err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			problems = append(problems, verr.Problems...)
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Sources возвращает источник значения каждого параметра по ключу
+func (l *Loader) Sources() map[string]Source {
+	return l.sources
+}
+
+// Load разбирает флаги из args и собирает конфигурацию
+// Используется командами, которым не нужны собственные флаги
+func Load(name string, args []string) (Config, error) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	loader := NewLoader(fs)
+	fs.Parse(args)
+	return loader.Load()
+}
+
+// readConfigFile читает YAML или TOML файл и приводит вложенные секции
+// к плоским ключам: секция db с ключом host превращается в db_host
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, errors.New("неподдерживаемый формат, ожидается .yaml, .yml или .toml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+// flatten раскладывает вложенные секции в плоский набор ключей
+func flatten(prefix string, in map[string]interface{}, out map[string]string) {
+	for k, v := range in {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flatten(key, val, out)
+		case []interface{}:
+			items := make([]string, 0, len(val))
+			for _, item := range val {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(items, ",")
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// redacted заменяет значения секретных параметров при выводе
+const redacted = "******"
+
+// Print выводит итоговую конфигурацию в формате файла конфигурации
+// с указанием источника каждого значения; секреты скрываются
+func Print(w io.Writer, cfg Config, sources map[string]Source) {
+	for _, f := range configFields() {
+		v := f.value(&cfg).Interface()
+		value := fmt.Sprint(v)
+		if items, ok := v.([]string); ok {
+			value = strings.Join(items, ",")
+		}
+		if f.secret && value != "" {
+			value = redacted
+		}
+
+		src := sources[f.key]
+		if src == "" {
+			src = "unset"
+		}
+		fmt.Fprintf(w, "%s = %q  # %s\n", f.key, value, src)
+	}
+}
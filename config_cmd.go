@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"family_finance_back/config"
+)
+
+// runConfig выполняет подкоманду config: print
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: family_finance_back config print [флаги конфигурации]")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "print" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+
+	cfg, err := loader.Load()
+	config.Print(os.Stdout, cfg, loader.Sources())
+
+	var verr *config.ValidationError
+	if errors.As(err, &verr) {
+		fmt.Fprintln(os.Stderr, verr.Error())
+		os.Exit(1)
+	}
+}
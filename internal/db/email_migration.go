@@ -0,0 +1,170 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/util"
+
+	"gorm.io/gorm"
+)
+
+// EmailCollision описывает группу пользователей, чьи адреса совпадают
+// после нормализации, то есть имеют одинаковый ключ
+type EmailCollision struct {
+	Canonical string
+	UserIDs   []uint
+	Emails    []string
+}
+
+// EmailCollisionError возвращается миграцией, если в базе есть пользователи
+// с адресами, которые совпадают после нормализации
+type EmailCollisionError struct {
+	Collisions []EmailCollision
+}
+
+func (e *EmailCollisionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "найдено %d конфликтующих адресов, требуется ручное объединение пользователей:", len(e.Collisions))
+	for _, c := range e.Collisions {
+		fmt.Fprintf(&b, "\n  %s: id=%v, адреса=%v", c.Canonical, c.UserIDs, c.Emails)
+	}
+	return b.String()
+}
+
+// NormalizeEmailKeys пересчитывает ключи адресов (email_key) пользователей и приглашений
+// по правилам normalizer, в том числе правилам провайдеров, которые нельзя
+// выразить в SQL-миграции. Сами адреса не меняются: на них отправляются письма
+// Выполняется командой "migrate up" после SQL-миграций
+// Если ключи нескольких пользователей совпадают, миграция ничего не меняет
+// и возвращает EmailCollisionError со списком конфликтов
+// Из ожидающих приглашений в одну семью с одинаковым ключом остаётся самое новое,
+// остальные отзываются, как при повторном приглашении на тот же адрес
+func NormalizeEmailKeys(db *gorm.DB, normalizer util.EmailNormalizer) error {
+	if !db.Migrator().HasColumn("users", "email_key") {
+		return nil
+	}
+
+	var users []emailKeyRow
+	if err := db.Table("users").Select("id", "email", "email_key").Order("id").Scan(&users).Error; err != nil {
+		return err
+	}
+	userKeys, err := computeEmailKeys(users, normalizer, "пользователь")
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*EmailCollision)
+	for _, row := range users {
+		key := userKeys[row.ID]
+		g, ok := groups[key]
+		if !ok {
+			g = &EmailCollision{Canonical: key}
+			groups[key] = g
+		}
+		g.UserIDs = append(g.UserIDs, row.ID)
+		g.Emails = append(g.Emails, row.Email)
+	}
+	var collisions []EmailCollision
+	for _, g := range groups {
+		if len(g.UserIDs) > 1 {
+			collisions = append(collisions, *g)
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Slice(collisions, func(i, j int) bool { return collisions[i].Canonical < collisions[j].Canonical })
+		return &EmailCollisionError{Collisions: collisions}
+	}
+
+	var invites []struct {
+		emailKeyRow
+		FamilyID uint
+		Status   string
+	}
+	if err := db.Table("family_invites").Select("id", "email", "email_key", "family_id", "status").
+		Order("id DESC").Scan(&invites).Error; err != nil {
+		return err
+	}
+	inviteRows := make([]emailKeyRow, len(invites))
+	for i := range invites {
+		inviteRows[i] = invites[i].emailKeyRow
+	}
+	inviteKeys, err := computeEmailKeys(inviteRows, normalizer, "приглашение")
+	if err != nil {
+		return err
+	}
+	// Приглашения отсортированы от новых к старым, поэтому первое по ключу остаётся
+	var revoke []uint
+	pending := make(map[string]bool)
+	for _, invite := range invites {
+		if invite.Status != "pending" {
+			continue
+		}
+		slot := fmt.Sprintf("%d/%s", invite.FamilyID, inviteKeys[invite.ID])
+		if pending[slot] {
+			revoke = append(revoke, invite.ID)
+		}
+		pending[slot] = true
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(revoke) > 0 {
+			if err := tx.Table("family_invites").Where("id IN ?", revoke).
+				Updates(map[string]interface{}{"status": "revoked", "responded_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		if err := updateEmailKeys(tx, "users", users, userKeys); err != nil {
+			return err
+		}
+		return updateEmailKeys(tx, "family_invites", inviteRows, inviteKeys)
+	})
+}
+
+// emailKeyRow адрес и текущий ключ записи
+type emailKeyRow struct {
+	ID       uint
+	Email    string
+	EmailKey string
+}
+
+// computeEmailKeys вычисляет ключи адресов записей по правилам normalizer
+func computeEmailKeys(rows []emailKeyRow, normalizer util.EmailNormalizer, kind string) (map[uint]string, error) {
+	keys := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		key, err := normalizer.Normalize(row.Email)
+		if err != nil {
+			return nil, fmt.Errorf("%s id=%d: не удалось нормализовать адрес %q: %w", kind, row.ID, row.Email, err)
+		}
+		keys[row.ID] = key
+	}
+	return keys, nil
+}
+
+// updateEmailKeys сохраняет изменившиеся ключи в таблицу table
+// Уникальный индекс по ключу проверяется для каждой строки, поэтому сначала
+// изменившимся записям присваиваются временные уникальные ключи, а затем итоговые:
+// иначе обмен ключами между записями прервал бы миграцию
+func updateEmailKeys(tx *gorm.DB, table string, rows []emailKeyRow, keys map[uint]string) error {
+	var changed []uint
+	for _, row := range rows {
+		if keys[row.ID] != row.EmailKey {
+			changed = append(changed, row.ID)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := tx.Table(table).Where("id IN ?", changed).
+		Update("email_key", gorm.Expr("'migrating:' || id")).Error; err != nil {
+		return err
+	}
+	for _, id := range changed {
+		if err := tx.Table(table).Where("id = ?", id).Update("email_key", keys[id]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockKey ключ advisory-блокировки PostgreSQL, под которой выполняются миграции
+// Гарантирует, что одновременно запущенные реплики не применят миграции дважды
+const migrationLockKey int64 = 7_302_154_001
+
+// migrationFileRe описывает имя файла миграции: 0001_create_users.up.sql
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration представляет одну версионированную миграцию схемы
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus описывает состояние миграции в базе данных
+type MigrationStatus struct {
+	Migration
+	// AppliedAt время применения; nil, если миграция ещё не применена
+	AppliedAt *time.Time
+}
+
+// ErrSchemaOutdated возвращается, если в базе есть неприменённые миграции
+var ErrSchemaOutdated = errors.New("схема базы данных устарела")
+
+// schemaMigration запись о применённой миграции
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrator применяет и откатывает встроенные в бинарник SQL-миграции
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator создает новый экземпляр Migrator со встроенными миграциями
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations читает пары up/down файлов из каталога и сортирует их по версии
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		m := migrationFileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("миграция %d объявлена с разными именами: %s и %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("миграция %04d_%s не содержит up-скрипта", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// withLock выполняет fn на выделенном соединении под advisory-блокировкой
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("не удалось получить блокировку миграций: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return fmt.Errorf("не удалось создать таблицу schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+// hasMigrationsTable сообщает, есть ли таблица schema_migrations в текущей схеме
+func (m *Migrator) hasMigrationsTable() (bool, error) {
+	var exists bool
+	err := m.db.Raw("SELECT to_regclass(?) IS NOT NULL", schemaMigration{}.TableName()).Scan(&exists).Error
+	return exists, err
+}
+
+// applied возвращает применённые миграции по версиям
+func applied(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Up применяет все неприменённые миграции по возрастанию версии
+// Каждая миграция выполняется в отдельной транзакции
+func (m *Migrator) Up() ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(func(conn *gorm.DB) error {
+		appliedSet, err := applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := appliedSet[mig.Version]; ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(mig.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("миграция %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает последние steps применённых миграций
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	var done []Migration
+	err := m.withLock(func(conn *gorm.DB) error {
+		var rows []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			mig, ok := known[row.Version]
+			if !ok {
+				return fmt.Errorf("миграция %04d_%s отсутствует в этой версии приложения", row.Version, row.Name)
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("миграция %04d_%s не поддерживает откат", mig.Version, mig.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(mig.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, mig.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("откат миграции %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status возвращает состояние всех известных миграций
+// Миграции, применённые в базе, но отсутствующие в бинарнике, тоже попадают в список
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(func(conn *gorm.DB) error {
+		appliedSet, err := applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := MigrationStatus{Migration: mig}
+			if row, ok := appliedSet[mig.Version]; ok {
+				appliedAt := row.AppliedAt
+				st.AppliedAt = &appliedAt
+				delete(appliedSet, mig.Version)
+			}
+			statuses = append(statuses, st)
+		}
+		for _, row := range appliedSet {
+			appliedAt := row.AppliedAt
+			statuses = append(statuses, MigrationStatus{
+				Migration: Migration{Version: row.Version, Name: row.Name},
+				AppliedAt: &appliedAt,
+			})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// CheckSchema проверяет, что все встроенные миграции применены
+// Проверка только читает schema_migrations: не берёт блокировку миграций и не создаёт таблицу,
+// поэтому одновременно запускаемые реплики не ждут друг друга
+// Возвращает ErrSchemaOutdated со списком неприменённых миграций или с сообщением,
+// что таблицы schema_migrations нет и миграции ещё не применялись
+func (m *Migrator) CheckSchema() error {
+	exists, err := m.hasMigrationsTable()
+	if err != nil {
+		return fmt.Errorf("не удалось проверить таблицу schema_migrations: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: таблица schema_migrations не найдена, миграции не применялись", ErrSchemaOutdated)
+	}
+	appliedSet, err := applied(m.db)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать schema_migrations: %w", err)
+	}
+	var pending []string
+	for _, mig := range m.migrations {
+		if _, ok := appliedSet[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", mig.Version, mig.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: не применены миграции %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CreateMigration создает пару пустых up/down файлов со следующим номером версии
+// Возвращает пути к созданным файлам
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "", "", errors.New("имя миграции не может быть пустым")
+	}
+
+	existing, err := loadMigrations(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+	next := int64(1)
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte("-- "+base+": up\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- "+base+": down\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
@@ -0,0 +1,13 @@
+-- Таблица пользователей
+-- IF NOT EXISTS позволяет принять под управление базы, созданные AutoMigrate
+CREATE TABLE IF NOT EXISTS users (
+    id         bigserial PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    surname    varchar(100) NOT NULL,
+    nickname   varchar(100) NOT NULL,
+    email      varchar(100) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
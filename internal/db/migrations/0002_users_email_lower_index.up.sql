@@ -0,0 +1,25 @@
+-- Регистронезависимая уникальность email
+-- Если после приведения к нижнему регистру адреса нескольких пользователей
+-- совпадают, миграция прерывается со списком конфликтов
+DO $$
+DECLARE
+    conflicts text;
+BEGIN
+    SELECT string_agg(format('%s (id: %s)', canonical, ids), '; ')
+    INTO conflicts
+    FROM (
+        SELECT lower(trim(email)) AS canonical, string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM users
+        GROUP BY lower(trim(email))
+        HAVING count(*) > 1
+    ) c;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'конфликтующие адреса email, требуется ручное объединение пользователей: %', conflicts;
+    END IF;
+END $$;
+
+UPDATE users SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+DROP INDEX IF EXISTS idx_users_email;
@@ -0,0 +1,23 @@
+-- Очередь исходящих писем (transactional outbox)
+CREATE TABLE email_outbox (
+    id              bigserial PRIMARY KEY,
+    message_id      varchar(64)  NOT NULL,
+    template        varchar(64)  NOT NULL,
+    sender          varchar(255) NOT NULL,
+    recipient       varchar(255) NOT NULL,
+    subject         varchar(255) NOT NULL,
+    text_body       text         NOT NULL,
+    html_body       text,
+    status          varchar(16)  NOT NULL,
+    attempts        integer      NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz  NOT NULL,
+    locked_until    timestamptz,
+    last_error      text,
+    sent_at         timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+
+CREATE UNIQUE INDEX idx_email_outbox_message_id ON email_outbox (message_id);
+CREATE INDEX idx_email_outbox_status ON email_outbox (status);
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at) WHERE status IN ('pending', 'sending');
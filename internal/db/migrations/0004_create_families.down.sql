@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS family_members;
+DROP TABLE IF EXISTS families;
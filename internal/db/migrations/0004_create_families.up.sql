@@ -0,0 +1,21 @@
+-- Семьи (домохозяйства) и членство пользователей в них
+CREATE TABLE families (
+    id         bigserial PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    currency   varchar(3)   NOT NULL,
+    timezone   varchar(64)  NOT NULL,
+    created_by bigint       NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE family_members (
+    id        bigserial PRIMARY KEY,
+    family_id bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    user_id   bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role      varchar(16) NOT NULL CHECK (role IN ('owner', 'adult', 'child', 'viewer')),
+    joined_at timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_family_members_family_user ON family_members (family_id, user_id);
+CREATE INDEX idx_family_members_user_id ON family_members (user_id);
@@ -0,0 +1,21 @@
+-- Приглашения в семью по email
+CREATE TABLE family_invites (
+    id           bigserial PRIMARY KEY,
+    family_id    bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    email        varchar(100) NOT NULL,
+    role         varchar(16)  NOT NULL CHECK (role IN ('owner', 'adult', 'child', 'viewer')),
+    token_hash   varchar(64)  NOT NULL,
+    status       varchar(16)  NOT NULL CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    invited_by   bigint       NOT NULL REFERENCES users (id),
+    expires_at   timestamptz  NOT NULL,
+    responded_at timestamptz,
+    accepted_by  bigint REFERENCES users (id),
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+
+CREATE UNIQUE INDEX idx_family_invites_token_hash ON family_invites (token_hash);
+CREATE INDEX idx_family_invites_family_id ON family_invites (family_id);
+CREATE INDEX idx_family_invites_email ON family_invites (lower(email));
+-- Не более одного активного приглашения на адрес в каждой семье
+CREATE UNIQUE INDEX idx_family_invites_pending ON family_invites (family_id, lower(email)) WHERE status = 'pending';
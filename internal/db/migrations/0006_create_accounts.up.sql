@@ -0,0 +1,18 @@
+-- Счета семьи: общие и личные счета участников
+CREATE TABLE accounts (
+    id              bigserial PRIMARY KEY,
+    family_id       bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    owner_id        bigint REFERENCES users (id) ON DELETE SET NULL,
+    name            varchar(100) NOT NULL,
+    type            varchar(16)  NOT NULL CHECK (type IN ('cash', 'debit_card', 'credit_card', 'savings', 'deposit')),
+    currency        varchar(3)   NOT NULL,
+    opening_balance bigint       NOT NULL DEFAULT 0,
+    archived        boolean      NOT NULL DEFAULT false,
+    sort_order      integer      NOT NULL DEFAULT 0,
+    created_by      bigint       NOT NULL REFERENCES users (id),
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+
+CREATE INDEX idx_accounts_family_id ON accounts (family_id);
+CREATE INDEX idx_accounts_owner_id ON accounts (owner_id);
@@ -0,0 +1,22 @@
+-- Операции доходов и расходов по счетам
+CREATE TABLE transactions (
+    id          bigserial PRIMARY KEY,
+    family_id   bigint        NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    account_id  bigint        NOT NULL REFERENCES accounts (id),
+    direction   varchar(16)   NOT NULL CHECK (direction IN ('income', 'expense')),
+    amount      bigint        NOT NULL CHECK (amount > 0),
+    currency    varchar(3)    NOT NULL,
+    category_id bigint,
+    date        date          NOT NULL,
+    payee       varchar(200)  NOT NULL DEFAULT '',
+    note        varchar(1000) NOT NULL DEFAULT '',
+    created_by  bigint        NOT NULL REFERENCES users (id),
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+-- Порядок выборки совпадает с курсорной пагинацией: date DESC, id DESC
+CREATE INDEX idx_transactions_family_date ON transactions (family_id, date DESC, id DESC);
+CREATE INDEX idx_transactions_account_id ON transactions (account_id);
+CREATE INDEX idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX idx_transactions_created_by ON transactions (created_by);
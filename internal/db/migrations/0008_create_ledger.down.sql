@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS postings;
+
+-- Переводы не представимы без главной книги
+DELETE FROM transactions WHERE direction = 'transfer';
+
+ALTER TABLE transactions
+    DROP CONSTRAINT transactions_transfer_check,
+    DROP CONSTRAINT transactions_direction_check,
+    DROP COLUMN counter_account_id,
+    DROP COLUMN counter_amount,
+    DROP COLUMN counter_currency,
+    DROP COLUMN rate,
+    ADD CONSTRAINT transactions_direction_check CHECK (direction IN ('income', 'expense'));
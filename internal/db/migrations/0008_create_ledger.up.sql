@@ -0,0 +1,40 @@
+-- Главная книга: операции становятся записями журнала, а остатки счетов
+-- вычисляются из проводок. Сумма проводок операции в каждой валюте равна нулю
+
+-- Переводы между счетами, в том числе в разных валютах
+ALTER TABLE transactions DROP CONSTRAINT transactions_direction_check;
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_direction_check CHECK (direction IN ('income', 'expense', 'transfer')),
+    ADD COLUMN counter_account_id bigint REFERENCES accounts (id),
+    ADD COLUMN counter_amount     bigint CHECK (counter_amount > 0),
+    ADD COLUMN counter_currency   varchar(3),
+    ADD COLUMN rate               numeric(24, 10) CHECK (rate > 0),
+    ADD CONSTRAINT transactions_transfer_check CHECK (
+        (direction = 'transfer') = (counter_account_id IS NOT NULL AND counter_amount IS NOT NULL AND counter_currency IS NOT NULL)
+    );
+
+CREATE INDEX idx_transactions_counter_account_id ON transactions (counter_account_id);
+
+CREATE TABLE postings (
+    id             bigserial PRIMARY KEY,
+    transaction_id bigint      NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    account_id     bigint REFERENCES accounts (id),
+    nominal        varchar(16) NOT NULL DEFAULT '' CHECK (nominal IN ('', 'income', 'expense', 'exchange')),
+    amount         bigint      NOT NULL CHECK (amount <> 0),
+    currency       varchar(3)  NOT NULL,
+    created_at     timestamptz,
+    -- Проводка относится либо к реальному счёту, либо к служебному
+    CHECK ((account_id IS NULL) <> (nominal = ''))
+);
+
+CREATE INDEX idx_postings_transaction_id ON postings (transaction_id);
+CREATE INDEX idx_postings_account_id ON postings (account_id);
+
+-- Проводки для уже существующих доходов и расходов
+INSERT INTO postings (transaction_id, account_id, nominal, amount, currency, created_at)
+SELECT id, account_id, '', CASE WHEN direction = 'income' THEN amount ELSE -amount END, currency, now()
+FROM transactions;
+
+INSERT INTO postings (transaction_id, account_id, nominal, amount, currency, created_at)
+SELECT id, NULL, direction, CASE WHEN direction = 'income' THEN -amount ELSE amount END, currency, now()
+FROM transactions;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_category_id_fkey;
+DROP TABLE IF EXISTS categories;
+ALTER TABLE families DROP COLUMN IF EXISTS locale;
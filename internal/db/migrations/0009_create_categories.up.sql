@@ -0,0 +1,27 @@
+-- Язык семьи определяет язык категорий по умолчанию
+ALTER TABLE families ADD COLUMN locale varchar(2) NOT NULL DEFAULT 'ru' CHECK (locale IN ('ru', 'en'));
+
+-- Дерево категорий доходов и расходов семьи
+CREATE TABLE categories (
+    id         bigserial PRIMARY KEY,
+    family_id  bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    parent_id  bigint REFERENCES categories (id) ON DELETE CASCADE,
+    kind       varchar(16)  NOT NULL CHECK (kind IN ('income', 'expense')),
+    name       varchar(100) NOT NULL,
+    icon       varchar(32)  NOT NULL DEFAULT '',
+    color      varchar(7)   NOT NULL DEFAULT '',
+    archived   boolean      NOT NULL DEFAULT false,
+    sort_order integer      NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE INDEX idx_categories_family_id ON categories (family_id);
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- До появления категорий идентификаторы не проверялись
+UPDATE transactions SET category_id = NULL WHERE category_id IS NOT NULL;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE SET NULL;
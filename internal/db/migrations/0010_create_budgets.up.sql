@@ -0,0 +1,26 @@
+-- Бюджеты семьи на период
+CREATE TABLE budgets (
+    id         bigserial PRIMARY KEY,
+    family_id  bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    period     varchar(16) NOT NULL CHECK (period IN ('month', 'week', 'custom')),
+    start_date date        NOT NULL,
+    end_date   date        NOT NULL,
+    currency   varchar(3)  NOT NULL,
+    created_by bigint      NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    updated_at timestamptz,
+    CHECK (end_date >= start_date),
+    UNIQUE (family_id, period, start_date)
+);
+
+-- Плановые суммы бюджета по категориям
+CREATE TABLE budget_lines (
+    id          bigserial PRIMARY KEY,
+    budget_id   bigint  NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    category_id bigint  NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    amount      bigint  NOT NULL CHECK (amount > 0),
+    rollover    boolean NOT NULL DEFAULT false,
+    UNIQUE (budget_id, category_id)
+);
+
+CREATE INDEX idx_budget_lines_category_id ON budget_lines (category_id);
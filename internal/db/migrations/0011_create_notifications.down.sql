@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS budget_alerts;
+ALTER TABLE budgets DROP COLUMN IF EXISTS alert_thresholds;
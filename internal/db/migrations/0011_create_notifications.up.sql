@@ -0,0 +1,38 @@
+-- Пороги предупреждений бюджета в процентах через запятую
+ALTER TABLE budgets ADD COLUMN alert_thresholds varchar(64) NOT NULL DEFAULT '80,100';
+
+-- Сработавшие пороги: каждый порог категории срабатывает один раз за период бюджета
+CREATE TABLE budget_alerts (
+    id          bigserial PRIMARY KEY,
+    budget_id   bigint  NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    category_id bigint  NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    threshold   integer NOT NULL,
+    created_at  timestamptz,
+    UNIQUE (budget_id, category_id, threshold)
+);
+
+-- Лента уведомлений пользователя
+CREATE TABLE notifications (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id   bigint REFERENCES families (id) ON DELETE CASCADE,
+    type        varchar(32)   NOT NULL,
+    title       varchar(200)  NOT NULL,
+    body        varchar(1000) NOT NULL DEFAULT '',
+    object_type varchar(32)   NOT NULL DEFAULT '',
+    object_id   bigint,
+    read_at     timestamptz,
+    created_at  timestamptz
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Настройки доставки уведомлений; отсутствие записи означает доставку всеми способами
+CREATE TABLE notification_preferences (
+    user_id bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type    varchar(32) NOT NULL,
+    email   boolean     NOT NULL DEFAULT true,
+    in_app  boolean     NOT NULL DEFAULT true,
+    PRIMARY KEY (user_id, type)
+);
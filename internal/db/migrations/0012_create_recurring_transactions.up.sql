@@ -0,0 +1,48 @@
+-- Шаблоны регулярных операций с правилом повторения
+CREATE TABLE recurring_transactions (
+    id             bigserial PRIMARY KEY,
+    family_id      bigint        NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    account_id     bigint        NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    direction      varchar(16)   NOT NULL CHECK (direction IN ('income', 'expense')),
+    amount         bigint        NOT NULL CHECK (amount > 0),
+    currency       varchar(3)    NOT NULL,
+    category_id    bigint REFERENCES categories (id) ON DELETE SET NULL,
+    payee          varchar(200)  NOT NULL DEFAULT '',
+    note           varchar(1000) NOT NULL DEFAULT '',
+    frequency      varchar(16)   NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval_count integer       NOT NULL DEFAULT 1 CHECK (interval_count BETWEEN 1 AND 999),
+    month_day      smallint      NOT NULL DEFAULT 0 CHECK (month_day BETWEEN -1 AND 31),
+    business_day   boolean       NOT NULL DEFAULT false,
+    start_date     date          NOT NULL,
+    end_date       date,
+    next_date      date,
+    paused         boolean       NOT NULL DEFAULT false,
+    created_by     bigint        NOT NULL REFERENCES users (id),
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_recurring_transactions_family_id ON recurring_transactions (family_id);
+CREATE INDEX idx_recurring_transactions_account_id ON recurring_transactions (account_id);
+-- Планировщик выбирает активные шаблоны с наступившей датой повторения
+CREATE INDEX idx_recurring_transactions_due ON recurring_transactions (next_date) WHERE NOT paused;
+
+-- Повторения, отличающиеся от шаблона: изменённые, пропущенные и проведённые
+-- Уникальность даты не даёт провести одно повторение дважды
+CREATE TABLE recurring_occurrences (
+    id             bigserial PRIMARY KEY,
+    recurring_id   bigint      NOT NULL REFERENCES recurring_transactions (id) ON DELETE CASCADE,
+    date           date        NOT NULL,
+    status         varchar(16) NOT NULL CHECK (status IN ('scheduled', 'skipped', 'posted')),
+    transaction_id bigint REFERENCES transactions (id) ON DELETE SET NULL,
+    amount         bigint CHECK (amount > 0),
+    category_id    bigint REFERENCES categories (id) ON DELETE SET NULL,
+    payee          varchar(200),
+    note           varchar(1000),
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    UNIQUE (recurring_id, date)
+);
+
+CREATE INDEX idx_recurring_occurrences_transaction_id ON recurring_occurrences (transaction_id);
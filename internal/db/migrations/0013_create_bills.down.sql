@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bill_instances;
+DROP TABLE IF EXISTS bills;
@@ -0,0 +1,42 @@
+-- Счета к оплате: разовые и повторяющиеся
+CREATE TABLE bills (
+    id             bigserial PRIMARY KEY,
+    family_id      bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    name           varchar(200) NOT NULL,
+    amount         bigint       NOT NULL CHECK (amount > 0),
+    currency       varchar(3)   NOT NULL,
+    account_id     bigint REFERENCES accounts (id) ON DELETE SET NULL,
+    category_id    bigint REFERENCES categories (id) ON DELETE SET NULL,
+    frequency      varchar(16)  NOT NULL DEFAULT '' CHECK (frequency IN ('', 'daily', 'weekly', 'monthly', 'yearly')),
+    interval_count integer      NOT NULL DEFAULT 1 CHECK (interval_count BETWEEN 1 AND 999),
+    month_day      smallint     NOT NULL DEFAULT 0 CHECK (month_day BETWEEN -1 AND 31),
+    business_day   boolean      NOT NULL DEFAULT false,
+    start_date     date         NOT NULL,
+    end_date       date,
+    remind_days    smallint     NOT NULL DEFAULT 3 CHECK (remind_days BETWEEN 0 AND 30),
+    created_by     bigint       NOT NULL REFERENCES users (id),
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_bills_family_id ON bills (family_id);
+
+-- Оплаты и напоминания по срокам счетов
+-- Уникальность срока не даёт отправить напоминание дважды; при удалении операции оплаты
+-- запись удаляется и срок снова считается неоплаченным
+CREATE TABLE bill_instances (
+    id             bigserial PRIMARY KEY,
+    bill_id        bigint      NOT NULL REFERENCES bills (id) ON DELETE CASCADE,
+    due_date       date        NOT NULL,
+    status         varchar(16) NOT NULL CHECK (status IN ('unpaid', 'paid')),
+    transaction_id bigint REFERENCES transactions (id) ON DELETE CASCADE,
+    paid_at        timestamptz,
+    reminded_at    timestamptz,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    UNIQUE (bill_id, due_date)
+);
+
+-- Одной операцией оплачивается не больше одного срока
+CREATE UNIQUE INDEX idx_bill_instances_transaction_id ON bill_instances (transaction_id) WHERE transaction_id IS NOT NULL;
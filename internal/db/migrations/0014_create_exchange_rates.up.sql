@@ -0,0 +1,13 @@
+-- Курсы валют по датам: одна единица base стоит rate единиц quote
+CREATE TABLE exchange_rates (
+    id         bigserial PRIMARY KEY,
+    date       date           NOT NULL,
+    base       varchar(3)     NOT NULL,
+    quote      varchar(3)     NOT NULL,
+    rate       numeric(24,10) NOT NULL CHECK (rate > 0),
+    source     varchar(100)   NOT NULL DEFAULT '',
+    created_at timestamptz,
+    updated_at timestamptz,
+    CHECK (base <> quote),
+    UNIQUE (base, quote, date)
+);
@@ -0,0 +1,31 @@
+-- Разделение расходов между участниками семьи
+-- Каждое изменение разделения — новая версия; действующая версия операции одна
+CREATE TABLE transaction_splits (
+    id             bigserial PRIMARY KEY,
+    transaction_id bigint      NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    family_id      bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    version        integer     NOT NULL CHECK (version > 0),
+    method         varchar(16) NOT NULL CHECK (method IN ('equal', 'exact', 'percent')),
+    amount         bigint      NOT NULL CHECK (amount > 0),
+    currency       varchar(3)  NOT NULL,
+    paid_by        bigint      NOT NULL REFERENCES users (id),
+    created_by     bigint      NOT NULL REFERENCES users (id),
+    created_at     timestamptz,
+    superseded_at  timestamptz,
+    UNIQUE (transaction_id, version)
+);
+
+CREATE UNIQUE INDEX idx_transaction_splits_current ON transaction_splits (transaction_id) WHERE superseded_at IS NULL;
+CREATE INDEX idx_transaction_splits_family_id ON transaction_splits (family_id) WHERE superseded_at IS NULL;
+
+-- Доли участников в версии разделения
+CREATE TABLE transaction_split_shares (
+    id       bigserial PRIMARY KEY,
+    split_id bigint NOT NULL REFERENCES transaction_splits (id) ON DELETE CASCADE,
+    user_id  bigint NOT NULL REFERENCES users (id),
+    amount   bigint NOT NULL CHECK (amount >= 0),
+    percent  numeric(5, 2) CHECK (percent >= 0 AND percent <= 100),
+    UNIQUE (split_id, user_id)
+);
+
+CREATE INDEX idx_transaction_split_shares_user_id ON transaction_split_shares (user_id);
@@ -0,0 +1,17 @@
+-- Займы и погашения долгов между участниками семьи
+CREATE TABLE debts (
+    id          bigserial PRIMARY KEY,
+    family_id   bigint        NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    kind        varchar(16)   NOT NULL CHECK (kind IN ('loan', 'settlement')),
+    lender_id   bigint        NOT NULL REFERENCES users (id),
+    borrower_id bigint        NOT NULL REFERENCES users (id),
+    amount      bigint        NOT NULL CHECK (amount > 0),
+    currency    varchar(3)    NOT NULL,
+    date        date          NOT NULL,
+    note        varchar(1000) NOT NULL DEFAULT '',
+    created_by  bigint        NOT NULL REFERENCES users (id),
+    created_at  timestamptz,
+    CHECK (lender_id <> borrower_id)
+);
+
+CREATE INDEX idx_debts_family_id ON debts (family_id, date DESC, id DESC);
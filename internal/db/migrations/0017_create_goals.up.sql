@@ -0,0 +1,28 @@
+-- Цели накоплений
+-- Счёт без операций можно удалить; его цели удаляются вместе с ним, взносов у них быть не может
+CREATE TABLE goals (
+    id            bigserial PRIMARY KEY,
+    family_id     bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    name          varchar(200) NOT NULL,
+    target_amount bigint       NOT NULL CHECK (target_amount > 0),
+    currency      varchar(3)   NOT NULL,
+    deadline      date,
+    account_id    bigint       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    created_by    bigint       NOT NULL REFERENCES users (id),
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE INDEX idx_goals_family_id ON goals (family_id);
+CREATE INDEX idx_goals_account_id ON goals (account_id);
+
+-- Взносы в цели: переводы на счёт накоплений
+-- Перевод может быть взносом только в одну цель; при удалении перевода взнос удаляется
+CREATE TABLE goal_contributions (
+    id             bigserial PRIMARY KEY,
+    goal_id        bigint NOT NULL REFERENCES goals (id) ON DELETE CASCADE,
+    transaction_id bigint NOT NULL UNIQUE REFERENCES transactions (id) ON DELETE CASCADE,
+    created_at     timestamptz
+);
+
+CREATE INDEX idx_goal_contributions_goal_id ON goal_contributions (goal_id);
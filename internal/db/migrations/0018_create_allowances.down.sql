@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS spending_requests;
+DROP TABLE IF EXISTS allowance_payments;
+DROP TABLE IF EXISTS allowance_bonuses;
+DROP TABLE IF EXISTS allowances;
+ALTER TABLE accounts DROP COLUMN IF EXISTS supervised;
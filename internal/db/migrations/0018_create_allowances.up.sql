@@ -0,0 +1,78 @@
+-- Счёт ребёнка под присмотром: ребёнок видит остаток, операции проводят родители
+ALTER TABLE accounts ADD COLUMN supervised boolean NOT NULL DEFAULT false;
+
+-- Карманные деньги: регулярный перевод со счёта родителей на счёт ребёнка
+CREATE TABLE allowances (
+    id              bigserial PRIMARY KEY,
+    family_id       bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    child_id        bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_account_id bigint      NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    to_account_id   bigint      NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    amount          bigint      NOT NULL CHECK (amount > 0),
+    currency        varchar(3)  NOT NULL,
+    frequency       varchar(16) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval_count  integer     NOT NULL DEFAULT 1 CHECK (interval_count BETWEEN 1 AND 999),
+    month_day       smallint    NOT NULL DEFAULT 0 CHECK (month_day BETWEEN -1 AND 31),
+    business_day    boolean     NOT NULL DEFAULT false,
+    start_date      date        NOT NULL,
+    end_date        date,
+    next_date       date,
+    paused          boolean     NOT NULL DEFAULT false,
+    created_by      bigint      NOT NULL REFERENCES users (id),
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CHECK (from_account_id <> to_account_id),
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_allowances_family_id ON allowances (family_id);
+CREATE INDEX idx_allowances_to_account_id ON allowances (to_account_id);
+-- Планировщик выбирает активные карманные деньги с наступившей датой выплаты
+CREATE INDEX idx_allowances_due ON allowances (next_date) WHERE NOT paused;
+
+-- Премии за домашние дела; выплачиваются вместе с ближайшими карманными деньгами
+CREATE TABLE allowance_bonuses (
+    id             bigserial PRIMARY KEY,
+    allowance_id   bigint       NOT NULL REFERENCES allowances (id) ON DELETE CASCADE,
+    chore          varchar(200) NOT NULL,
+    amount         bigint       NOT NULL CHECK (amount > 0),
+    transaction_id bigint REFERENCES transactions (id) ON DELETE SET NULL,
+    created_by     bigint       NOT NULL REFERENCES users (id),
+    created_at     timestamptz
+);
+
+CREATE INDEX idx_allowance_bonuses_allowance_id ON allowance_bonuses (allowance_id);
+
+-- Выплаты карманных денег; уникальность даты не даёт провести выплату дважды
+CREATE TABLE allowance_payments (
+    id             bigserial PRIMARY KEY,
+    allowance_id   bigint NOT NULL REFERENCES allowances (id) ON DELETE CASCADE,
+    date           date   NOT NULL,
+    amount         bigint NOT NULL CHECK (amount > 0),
+    bonus          bigint NOT NULL DEFAULT 0 CHECK (bonus >= 0),
+    transaction_id bigint REFERENCES transactions (id) ON DELETE SET NULL,
+    created_at     timestamptz,
+    UNIQUE (allowance_id, date)
+);
+
+-- Запросы ребёнка на расход со счёта под присмотром
+CREATE TABLE spending_requests (
+    id             bigserial PRIMARY KEY,
+    family_id      bigint        NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    account_id     bigint        NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    requested_by   bigint        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount         bigint        NOT NULL CHECK (amount > 0),
+    currency       varchar(3)    NOT NULL,
+    category_id    bigint REFERENCES categories (id) ON DELETE SET NULL,
+    payee          varchar(200)  NOT NULL DEFAULT '',
+    note           varchar(1000) NOT NULL DEFAULT '',
+    status         varchar(16)   NOT NULL CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    reviewed_by    bigint REFERENCES users (id),
+    reviewed_at    timestamptz,
+    review_note    varchar(1000) NOT NULL DEFAULT '',
+    transaction_id bigint REFERENCES transactions (id) ON DELETE SET NULL,
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+
+CREATE INDEX idx_spending_requests_family_id ON spending_requests (family_id, status, created_at DESC);
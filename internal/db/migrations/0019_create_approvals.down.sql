@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS approvals;
+DROP TABLE IF EXISTS approval_rules;
+DROP INDEX IF EXISTS idx_transactions_pending;
+ALTER TABLE transactions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,39 @@
+-- Состояние операции: непроведённые операции (ожидающие одобрения и отклонённые) не имеют проводок
+ALTER TABLE transactions
+    ADD COLUMN status varchar(16) NOT NULL DEFAULT 'posted'
+    CHECK (status IN ('posted', 'pending', 'rejected'));
+
+CREATE INDEX idx_transactions_pending ON transactions (family_id) WHERE status <> 'posted';
+
+-- Правила семьи, по которым расход ждёт одобрения второго взрослого
+CREATE TABLE approval_rules (
+    id          bigserial PRIMARY KEY,
+    family_id   bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    min_amount  bigint      NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    currency    varchar(3)  NOT NULL,
+    category_id bigint REFERENCES categories (id) ON DELETE CASCADE,
+    role        varchar(16) NOT NULL DEFAULT '' CHECK (role IN ('', 'owner', 'adult', 'child')),
+    created_by  bigint      NOT NULL REFERENCES users (id),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    CHECK (min_amount > 0 OR category_id IS NOT NULL OR role <> '')
+);
+
+CREATE INDEX idx_approval_rules_family_id ON approval_rules (family_id);
+
+-- Запросы на одобрение расходов и решения по ним
+CREATE TABLE approvals (
+    id             bigserial PRIMARY KEY,
+    transaction_id bigint        NOT NULL UNIQUE REFERENCES transactions (id) ON DELETE CASCADE,
+    family_id      bigint        NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    rule_id        bigint REFERENCES approval_rules (id) ON DELETE SET NULL,
+    requested_by   bigint        NOT NULL REFERENCES users (id),
+    status         varchar(16)   NOT NULL CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by     bigint REFERENCES users (id),
+    decided_at     timestamptz,
+    note           varchar(1000) NOT NULL DEFAULT '',
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+
+CREATE INDEX idx_approvals_family_id ON approvals (family_id, status, created_at DESC);
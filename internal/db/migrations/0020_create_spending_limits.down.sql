@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_member_date;
+DROP TABLE IF EXISTS spending_limits;
+ALTER TABLE transactions DROP COLUMN IF EXISTS flagged;
@@ -0,0 +1,25 @@
+-- Отметка о расходе сверх лимита участника
+ALTER TABLE transactions ADD COLUMN flagged boolean NOT NULL DEFAULT false;
+
+-- Лимиты расходов участников по счёту или по всем счетам семьи
+CREATE TABLE spending_limits (
+    id         bigserial PRIMARY KEY,
+    family_id  bigint      NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    user_id    bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    account_id bigint REFERENCES accounts (id) ON DELETE CASCADE,
+    amount     bigint      NOT NULL CHECK (amount > 0),
+    currency   varchar(3)  NOT NULL,
+    period     varchar(16) NOT NULL CHECK (period IN ('day', 'week', 'month')),
+    action     varchar(16) NOT NULL CHECK (action IN ('reject', 'flag')),
+    created_by bigint      NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+-- Не больше одного лимита участника на счёт (или на все счета) и период
+CREATE UNIQUE INDEX idx_spending_limits_scope
+    ON spending_limits (family_id, user_id, COALESCE(account_id, 0), period);
+
+-- Расходы участника за период считаются при каждой новой операции
+CREATE INDEX idx_transactions_member_date ON transactions (family_id, created_by, date)
+    WHERE direction = 'expense';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS statement_fingerprints;
+DROP TABLE IF EXISTS statement_import_rows;
+DROP TABLE IF EXISTS statement_imports;
+DROP TABLE IF EXISTS statement_profiles;
@@ -0,0 +1,91 @@
+-- Профили сопоставления столбцов банковских выписок; принадлежат пользователю
+CREATE TABLE statement_profiles (
+    id                bigserial PRIMARY KEY,
+    user_id           bigint       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name              varchar(100) NOT NULL,
+    encoding          varchar(16)  NOT NULL,
+    delimiter         varchar(1)   NOT NULL,
+    date_format       varchar(16)  NOT NULL,
+    decimal_separator varchar(1)   NOT NULL,
+    date_column       varchar(100) NOT NULL,
+    amount_column     varchar(100) NOT NULL,
+    debit_column      varchar(100) NOT NULL,
+    credit_column     varchar(100) NOT NULL,
+    payee_column      varchar(100) NOT NULL,
+    note_column       varchar(100) NOT NULL,
+    category_column   varchar(100) NOT NULL,
+    created_at        timestamptz,
+    updated_at        timestamptz
+);
+
+CREATE INDEX idx_statement_profiles_user_id ON statement_profiles (user_id);
+
+-- Загруженные выписки по счетам
+CREATE TABLE statement_imports (
+    id                bigserial PRIMARY KEY,
+    user_id           bigint       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id         bigint       NOT NULL REFERENCES families (id) ON DELETE CASCADE,
+    account_id        bigint       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    currency          varchar(3)   NOT NULL,
+    profile_id        bigint REFERENCES statement_profiles (id) ON DELETE SET NULL,
+    file_name         varchar(255) NOT NULL,
+    file              bytea        NOT NULL,
+    file_hash         varchar(64)  NOT NULL,
+    status            varchar(16)  NOT NULL
+        CHECK (status IN ('parsing', 'ready', 'committing', 'completed', 'failed')),
+    error             varchar(1000) NOT NULL,
+    encoding          varchar(16)  NOT NULL,
+    delimiter         varchar(1)   NOT NULL,
+    date_format       varchar(16)  NOT NULL,
+    decimal_separator varchar(1)   NOT NULL,
+    date_column       varchar(100) NOT NULL,
+    amount_column     varchar(100) NOT NULL,
+    debit_column      varchar(100) NOT NULL,
+    credit_column     varchar(100) NOT NULL,
+    payee_column      varchar(100) NOT NULL,
+    note_column       varchar(100) NOT NULL,
+    category_column   varchar(100) NOT NULL,
+    total_rows        integer      NOT NULL DEFAULT 0,
+    error_rows        integer      NOT NULL DEFAULT 0,
+    duplicate_rows    integer      NOT NULL DEFAULT 0,
+    imported_rows     integer      NOT NULL DEFAULT 0,
+    completed_at      timestamptz,
+    created_at        timestamptz,
+    updated_at        timestamptz
+);
+
+CREATE INDEX idx_statement_imports_user_id ON statement_imports (user_id, id DESC);
+
+-- Фоновая задача выбирает выписки, ждущие разбора или проведения
+CREATE INDEX idx_statement_imports_queued ON statement_imports (id)
+    WHERE status IN ('parsing', 'committing');
+
+-- Строки выписок
+CREATE TABLE statement_import_rows (
+    id             bigserial PRIMARY KEY,
+    import_id      bigint        NOT NULL REFERENCES statement_imports (id) ON DELETE CASCADE,
+    line           integer       NOT NULL,
+    date           date,
+    direction      varchar(16)   NOT NULL,
+    amount         bigint        NOT NULL,
+    payee          varchar(200)  NOT NULL,
+    note           varchar(1000) NOT NULL,
+    bank_category  varchar(200)  NOT NULL,
+    category_id    bigint REFERENCES categories (id) ON DELETE SET NULL,
+    fingerprint    varchar(64)   NOT NULL,
+    duplicate      boolean       NOT NULL DEFAULT false,
+    skip           boolean       NOT NULL DEFAULT false,
+    error          varchar(500)  NOT NULL,
+    transaction_id bigint REFERENCES transactions (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_statement_import_rows_import_id ON statement_import_rows (import_id, line);
+
+-- Импортированные на счёт строки выписок: повторная загрузка не создаёт операции дважды,
+-- даже если импорт удалён или операция удалена вручную
+CREATE TABLE statement_fingerprints (
+    account_id  bigint      NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    fingerprint varchar(64) NOT NULL,
+    created_at  timestamptz,
+    PRIMARY KEY (account_id, fingerprint)
+);
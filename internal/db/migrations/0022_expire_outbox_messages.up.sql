@@ -0,0 +1,12 @@
+-- Срок действия письма: письмо с кодом подтверждения после истечения кода не доставляется
+ALTER TABLE email_outbox ADD COLUMN expires_at timestamptz;
+
+-- Коды подтверждения живут 90 секунд
+UPDATE email_outbox SET expires_at = created_at + interval '90 seconds'
+WHERE template = 'verification_code';
+
+-- Тела доставленных и просроченных писем больше не нужны и могут содержать коды
+UPDATE email_outbox SET status = 'dead', last_error = 'срок действия письма истёк', locked_until = NULL
+WHERE expires_at <= now() AND status IN ('pending', 'sending');
+UPDATE email_outbox SET text_body = '', html_body = NULL
+WHERE status = 'sent' OR expires_at <= now();
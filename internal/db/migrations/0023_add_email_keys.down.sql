@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_family_invites_pending;
+DROP INDEX IF EXISTS idx_family_invites_email_key;
+ALTER TABLE family_invites DROP COLUMN IF EXISTS email_key;
+CREATE INDEX idx_family_invites_email ON family_invites (lower(email));
+CREATE UNIQUE INDEX idx_family_invites_pending ON family_invites (family_id, lower(email)) WHERE status = 'pending';
+
+DROP INDEX IF EXISTS idx_users_email_key;
+ALTER TABLE users DROP COLUMN IF EXISTS email_key;
@@ -0,0 +1,17 @@
+-- Ключ адреса для поиска и уникальности хранится отдельно от адреса доставки
+-- Здесь ключ равен адресу в нижнем регистре; правила провайдеров применяются
+-- командой "migrate up" после SQL-миграций (db.NormalizeEmailKeys)
+ALTER TABLE users ADD COLUMN email_key varchar(100);
+UPDATE users SET email_key = lower(email);
+ALTER TABLE users ALTER COLUMN email_key SET NOT NULL;
+CREATE UNIQUE INDEX idx_users_email_key ON users (email_key);
+
+ALTER TABLE family_invites ADD COLUMN email_key varchar(100);
+UPDATE family_invites SET email_key = lower(email);
+ALTER TABLE family_invites ALTER COLUMN email_key SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_family_invites_pending;
+DROP INDEX IF EXISTS idx_family_invites_email;
+CREATE INDEX idx_family_invites_email_key ON family_invites (email_key);
+-- Не более одного активного приглашения на адрес в каждой семье
+CREATE UNIQUE INDEX idx_family_invites_pending ON family_invites (family_id, email_key) WHERE status = 'pending';
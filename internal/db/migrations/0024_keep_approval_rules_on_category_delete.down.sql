@@ -0,0 +1,4 @@
+ALTER TABLE approval_rules
+    DROP CONSTRAINT approval_rules_category_id_fkey,
+    ADD CONSTRAINT approval_rules_category_id_fkey
+        FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+-- Удаление категории больше не удаляет правила одобрения: объединение категорий переносит
+-- правила в целевую категорию, а удаление категории, на которую ссылается правило, отклоняется
+-- NO ACTION проверяется в конце запроса, поэтому удаление семьи по-прежнему удаляет её правила
+ALTER TABLE approval_rules
+    DROP CONSTRAINT approval_rules_category_id_fkey,
+    ADD CONSTRAINT approval_rules_category_id_fkey
+        FOREIGN KEY (category_id) REFERENCES categories (id);
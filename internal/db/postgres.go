@@ -5,27 +5,34 @@ import (
 	"log"
 
 	"family_finance_back/config"
-	"family_finance_back/internal/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// InitPostgres инициализирует подключение к PostgreSQL
+// Connect открывает подключение к PostgreSQL
 // Использует параметры подключения из конфигурации
+func Connect(cfg config.Config) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
+
+// InitPostgres инициализирует подключение к PostgreSQL
+// Проверяет, что все миграции применены, и отказывается запускаться, если схема устарела
 // Возвращает экземпляр *gorm.DB для работы с базой данных
 func InitPostgres(cfg config.Config) *gorm.DB {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := Connect(cfg)
 	if err != nil {
 		log.Fatalf("error connecting to database: %v", err)
 	}
 
-	// Автоматическая миграция (создание таблиц, если их нет)
-	err = db.AutoMigrate(&models.User{})
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		log.Fatalf("error during migration: %v", err)
+		log.Fatalf("error loading migrations: %v", err)
+	}
+	if err = migrator.CheckSchema(); err != nil {
+		log.Fatalf("%v; выполните \"family_finance_back migrate up\"", err)
 	}
 
 	return db
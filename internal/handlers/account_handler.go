@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxAccountNameLength соответствует размеру колонки name в models.Account
+	maxAccountNameLength = 100
+
+	// maxReorderAccounts ограничение количества счетов в запросе на сортировку
+	maxReorderAccounts = 500
+)
+
+// AccountHandler обрабатывает HTTP запросы, связанные со счетами
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountHandler создает новый экземпляр AccountHandler
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// validateAccountType проверяет вид счёта
+func validateAccountType(errs *ValidationErrors, field string, value models.AccountType, required bool) {
+	if value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if !value.Valid() {
+		errs.Add(field, "ожидается cash, debit_card, credit_card, savings или deposit")
+	}
+}
+
+// CreateAccountRequest представляет запрос на создание счёта
+type CreateAccountRequest struct {
+	FamilyID       uint               `json:"family_id"`
+	Name           string             `json:"name"`
+	Type           models.AccountType `json:"type"`
+	Currency       string             `json:"currency"`
+	OpeningBalance int64              `json:"opening_balance"`
+	OwnerID        *uint              `json:"owner_id"`
+}
+
+// Validate проверяет запрос на создание счёта
+func (req *CreateAccountRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateTitle(&errs, "name", &req.Name, true, maxAccountNameLength)
+	validateAccountType(&errs, "type", req.Type, true)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	validateAmount(&errs, "opening_balance", req.OpeningBalance, false)
+	if req.OwnerID != nil {
+		validateID(&errs, "owner_id", *req.OwnerID)
+	}
+	return errs
+}
+
+// UpdateAccountRequest представляет запрос на изменение счёта
+// Все поля, кроме account_id, необязательны; валюту счёта изменить нельзя
+type UpdateAccountRequest struct {
+	AccountID      uint               `json:"account_id"`
+	Name           string             `json:"name"`
+	Type           models.AccountType `json:"type"`
+	OpeningBalance *int64             `json:"opening_balance"`
+	Archived       *bool              `json:"archived"`
+	SortOrder      *int               `json:"sort_order"`
+}
+
+// Validate проверяет запрос на изменение счёта
+func (req *UpdateAccountRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	validateTitle(&errs, "name", &req.Name, false, maxAccountNameLength)
+	validateAccountType(&errs, "type", req.Type, false)
+	if req.OpeningBalance != nil {
+		validateAmount(&errs, "opening_balance", *req.OpeningBalance, false)
+	}
+	if req.SortOrder != nil && *req.SortOrder < 0 {
+		errs.Add("sort_order", "значение не может быть отрицательным")
+	}
+	return errs
+}
+
+// DeleteAccountRequest представляет запрос на удаление счёта
+type DeleteAccountRequest struct {
+	AccountID uint `json:"account_id"`
+}
+
+// Validate проверяет запрос на удаление счёта
+func (req *DeleteAccountRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	return errs
+}
+
+// ReorderAccountsRequest представляет запрос на изменение порядка счетов семьи
+type ReorderAccountsRequest struct {
+	FamilyID   uint   `json:"family_id"`
+	AccountIDs []uint `json:"account_ids"`
+}
+
+// Validate проверяет запрос на изменение порядка счетов
+func (req *ReorderAccountsRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	switch {
+	case len(req.AccountIDs) == 0:
+		errs.Add("account_ids", "поле обязательно для заполнения")
+	case len(req.AccountIDs) > maxReorderAccounts:
+		errs.Add("account_ids", "слишком много счетов в одном запросе")
+	}
+	return errs
+}
+
+// ListAccountsHandler возвращает счета семьи с текущими остатками
+func (h *AccountHandler) ListAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	includeArchived := queryBool(r, &errs, "include_archived")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	accounts, err := h.accountService.List(user, familyID, includeArchived)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка счетов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// AccountTotalsHandler возвращает остатки счетов семьи по валютам и итог в основной валюте семьи
+func (h *AccountHandler) AccountTotalsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	totals, err := h.accountService.Totals(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка вычисления итогов по счетам", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(totals)
+}
+
+// GetAccountHandler возвращает счёт с текущим остатком
+func (h *AccountHandler) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	accountID := queryID(r, &errs, "account_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	account, err := h.accountService.Get(user, accountID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения счёта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// CreateAccountHandler обрабатывает запрос на создание счёта
+func (h *AccountHandler) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateAccountRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	account, err := h.accountService.Create(user, req.FamilyID, service.AccountInput{
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+		OwnerID:        req.OwnerID,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания счёта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(account)
+}
+
+// UpdateAccountHandler обрабатывает запрос на изменение счёта
+func (h *AccountHandler) UpdateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateAccountRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	account, err := h.accountService.Update(user, req.AccountID, service.AccountChanges{
+		Name:           req.Name,
+		Type:           req.Type,
+		OpeningBalance: req.OpeningBalance,
+		Archived:       req.Archived,
+		SortOrder:      req.SortOrder,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения счёта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// DeleteAccountHandler обрабатывает запрос на удаление счёта
+func (h *AccountHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteAccountRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.accountService.Delete(user, req.AccountID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления счёта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Счёт удалён"})
+}
+
+// ReorderAccountsHandler обрабатывает запрос на изменение порядка счетов
+func (h *AccountHandler) ReorderAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ReorderAccountsRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.accountService.Reorder(user, req.FamilyID, req.AccountIDs); err != nil {
+		respondWithServiceError(w, "Ошибка изменения порядка счетов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Порядок счетов изменён"})
+}
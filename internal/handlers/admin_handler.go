@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// AdminHandler обрабатывает административные HTTP запросы
+type AdminHandler struct {
+	outboxService service.OutboxService
+	ledgerService service.LedgerService
+}
+
+// NewAdminHandler создает новый экземпляр AdminHandler
+func NewAdminHandler(outboxService service.OutboxService, ledgerService service.LedgerService) *AdminHandler {
+	return &AdminHandler{outboxService: outboxService, ledgerService: ledgerService}
+}
+
+// ReplayOutboxRequest представляет запрос на повторную отправку письма из dead-letter
+type ReplayOutboxRequest struct {
+	ID uint `json:"id"`
+}
+
+// Validate проверяет запрос на повторную отправку письма
+func (req *ReplayOutboxRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if req.ID == 0 {
+		errs.Add("id", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// parsePagination читает параметры limit и offset из строки запроса
+// По умолчанию limit=50, максимум 500
+func parsePagination(r *http.Request, errs *ValidationErrors) (int, int) {
+	limit, offset := queryLimit(r, errs, 50, 500), 0
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs.Add("offset", "ожидается неотрицательное число")
+		}
+		offset = n
+	}
+	return limit, offset
+}
+
+// ListOutboxHandler возвращает письма из очереди отправки без тел писем
+// Параметр status фильтрует по состоянию: pending, sending, sent или dead
+func (h *AdminHandler) ListOutboxHandler(w http.ResponseWriter, r *http.Request) {
+	var errs ValidationErrors
+	status := models.OutboxStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.OutboxPending, models.OutboxSending, models.OutboxSent, models.OutboxDead:
+	default:
+		errs.Add("status", "ожидается pending, sending, sent или dead")
+	}
+	limit, offset := parsePagination(r, &errs)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	msgs, err := h.outboxService.List(status, limit, offset)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Ошибка получения очереди писем", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msgs)
+}
+
+// ReplayOutboxHandler возвращает письмо из dead-letter в очередь отправки
+func (h *AdminHandler) ReplayOutboxHandler(w http.ResponseWriter, r *http.Request) {
+	var req ReplayOutboxRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	err := h.outboxService.Replay(req.ID)
+	switch {
+	case errors.Is(err, service.ErrOutboxMessageNotFound):
+		respondWithError(w, http.StatusNotFound, "Письмо не найдено", err.Error())
+		return
+	case errors.Is(err, service.ErrOutboxNotReplayable), errors.Is(err, service.ErrOutboxExpiring):
+		respondWithError(w, http.StatusConflict, "Повтор невозможен", err.Error())
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, "Ошибка повтора письма", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Письмо возвращено в очередь отправки"})
+}
+
+// CheckLedgerHandler проверяет целостность главной книги и возвращает найденные нарушения
+func (h *AdminHandler) CheckLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.ledgerService.Check()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Ошибка проверки главной книги", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
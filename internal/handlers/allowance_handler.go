@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxChoreLength соответствует размеру колонки chore в models.AllowanceBonus
+	maxChoreLength = 200
+	// maxPaymentsPage максимальное количество выплат карманных денег в ответе
+	maxPaymentsPage = 200
+)
+
+// AllowanceHandler обрабатывает HTTP запросы, связанные с карманными деньгами детей
+type AllowanceHandler struct {
+	allowanceService service.AllowanceService
+}
+
+// NewAllowanceHandler создает новый экземпляр AllowanceHandler
+func NewAllowanceHandler(allowanceService service.AllowanceService) *AllowanceHandler {
+	return &AllowanceHandler{allowanceService: allowanceService}
+}
+
+// CreateAllowanceRequest представляет запрос на назначение карманных денег
+type CreateAllowanceRequest struct {
+	ChildID       uint                  `json:"child_id"`
+	FromAccountID uint                  `json:"from_account_id"`
+	ToAccountID   uint                  `json:"to_account_id"`
+	Amount        int64                 `json:"amount"`
+	Rule          RecurrenceRuleRequest `json:"rule"`
+
+	rule models.RecurrenceRule
+}
+
+// Validate проверяет запрос на назначение карманных денег
+func (req *CreateAllowanceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "child_id", req.ChildID)
+	validateID(&errs, "from_account_id", req.FromAccountID)
+	validateID(&errs, "to_account_id", req.ToAccountID)
+	validateAmount(&errs, "amount", req.Amount, true)
+	req.rule = validateRecurrenceRule(&errs, "rule", &req.Rule)
+	return errs
+}
+
+// UpdateAllowanceRequest представляет запрос на изменение карманных денег
+type UpdateAllowanceRequest struct {
+	AllowanceID   uint                   `json:"allowance_id"`
+	FromAccountID uint                   `json:"from_account_id"`
+	Amount        int64                  `json:"amount"`
+	Rule          *RecurrenceRuleRequest `json:"rule"`
+
+	rule *models.RecurrenceRule
+}
+
+// Validate проверяет запрос на изменение карманных денег
+func (req *UpdateAllowanceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "allowance_id", req.AllowanceID)
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	if req.Rule != nil {
+		rule := validateRecurrenceRule(&errs, "rule", req.Rule)
+		req.rule = &rule
+	}
+	return errs
+}
+
+// PauseAllowanceRequest представляет запрос на приостановку или возобновление выплат
+type PauseAllowanceRequest struct {
+	AllowanceID uint  `json:"allowance_id"`
+	Paused      *bool `json:"paused"`
+}
+
+// Validate проверяет запрос на приостановку выплат
+func (req *PauseAllowanceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "allowance_id", req.AllowanceID)
+	if req.Paused == nil {
+		errs.Add("paused", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// DeleteAllowanceRequest представляет запрос на отмену карманных денег
+type DeleteAllowanceRequest struct {
+	AllowanceID uint `json:"allowance_id"`
+}
+
+// Validate проверяет запрос на отмену карманных денег
+func (req *DeleteAllowanceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "allowance_id", req.AllowanceID)
+	return errs
+}
+
+// CreateBonusRequest представляет запрос на премию за домашнее дело
+type CreateBonusRequest struct {
+	AllowanceID uint   `json:"allowance_id"`
+	Chore       string `json:"chore"`
+	Amount      int64  `json:"amount"`
+}
+
+// Validate проверяет запрос на премию
+func (req *CreateBonusRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "allowance_id", req.AllowanceID)
+	validateTitle(&errs, "chore", &req.Chore, true, maxChoreLength)
+	validateAmount(&errs, "amount", req.Amount, true)
+	return errs
+}
+
+// DeleteBonusRequest представляет запрос на удаление премии
+type DeleteBonusRequest struct {
+	BonusID uint `json:"bonus_id"`
+}
+
+// Validate проверяет запрос на удаление премии
+func (req *DeleteBonusRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "bonus_id", req.BonusID)
+	return errs
+}
+
+// ListAllowancesHandler возвращает карманные деньги семьи
+func (h *AllowanceHandler) ListAllowancesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	list, err := h.allowanceService.List(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetAllowanceHandler возвращает карманные деньги
+func (h *AllowanceHandler) GetAllowanceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "allowance_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	allowance, err := h.allowanceService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allowance)
+}
+
+// ListPaymentsHandler возвращает выплаты карманных денег
+func (h *AllowanceHandler) ListPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "allowance_id")
+	limit := queryLimit(r, &errs, 50, maxPaymentsPage)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	payments, err := h.allowanceService.Payments(user, id, limit)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения выплат карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payments)
+}
+
+// CreateAllowanceHandler обрабатывает запрос на назначение карманных денег
+func (h *AllowanceHandler) CreateAllowanceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateAllowanceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	allowance, err := h.allowanceService.Create(user, service.AllowanceInput{
+		ChildID:       req.ChildID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Rule:          req.rule,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка назначения карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(allowance)
+}
+
+// UpdateAllowanceHandler обрабатывает запрос на изменение карманных денег
+func (h *AllowanceHandler) UpdateAllowanceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateAllowanceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	allowance, err := h.allowanceService.Update(user, req.AllowanceID, service.AllowanceChanges{
+		FromAccountID: req.FromAccountID,
+		Amount:        req.Amount,
+		Rule:          req.rule,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allowance)
+}
+
+// PauseAllowanceHandler обрабатывает запрос на приостановку или возобновление выплат
+func (h *AllowanceHandler) PauseAllowanceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req PauseAllowanceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	allowance, err := h.allowanceService.Pause(user, req.AllowanceID, *req.Paused)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка приостановки карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allowance)
+}
+
+// DeleteAllowanceHandler обрабатывает запрос на отмену карманных денег
+func (h *AllowanceHandler) DeleteAllowanceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteAllowanceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.allowanceService.Delete(user, req.AllowanceID); err != nil {
+		respondWithServiceError(w, "Ошибка отмены карманных денег", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Карманные деньги отменены"})
+}
+
+// ListBonusesHandler возвращает премии за домашние дела
+func (h *AllowanceHandler) ListBonusesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "allowance_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	bonuses, err := h.allowanceService.Bonuses(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка премий", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bonuses)
+}
+
+// CreateBonusHandler обрабатывает запрос на премию за домашнее дело
+func (h *AllowanceHandler) CreateBonusHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateBonusRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	bonus, err := h.allowanceService.AddBonus(user, req.AllowanceID, service.BonusInput{
+		Chore:  req.Chore,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка назначения премии", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bonus)
+}
+
+// DeleteBonusHandler обрабатывает запрос на удаление премии
+func (h *AllowanceHandler) DeleteBonusHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteBonusRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.allowanceService.DeleteBonus(user, req.BonusID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления премии", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Премия удалена"})
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// maxApprovalsPage максимальное количество запросов на одобрение в ответе
+const maxApprovalsPage = 200
+
+// ApprovalHandler обрабатывает HTTP запросы, связанные с одобрением расходов
+type ApprovalHandler struct {
+	approvalService service.ApprovalService
+}
+
+// NewApprovalHandler создает новый экземпляр ApprovalHandler
+func NewApprovalHandler(approvalService service.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{approvalService: approvalService}
+}
+
+// validateApprovalRule проверяет условия правила одобрения; нулевые условия не проверяются
+func validateApprovalRule(errs *ValidationErrors, minAmount int64, currency *string, categoryID *uint, role models.FamilyRole) {
+	if minAmount != 0 {
+		validateAmount(errs, "min_amount", minAmount, true)
+	}
+	validateCurrency(errs, "currency", currency, false)
+	if categoryID != nil {
+		validateID(errs, "category_id", *categoryID)
+	}
+	if role != "" && (!role.Valid() || role == models.RoleViewer) {
+		errs.Add("role", "ожидается owner, adult или child")
+	}
+}
+
+// CreateApprovalRuleRequest представляет запрос на создание правила одобрения
+// Заданные условия должны выполняться одновременно; нужно хотя бы одно
+type CreateApprovalRuleRequest struct {
+	FamilyID   uint              `json:"family_id"`
+	MinAmount  int64             `json:"min_amount"`
+	Currency   string            `json:"currency"`
+	CategoryID *uint             `json:"category_id"`
+	Role       models.FamilyRole `json:"role"`
+}
+
+// Validate проверяет запрос на создание правила
+func (req *CreateApprovalRuleRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateApprovalRule(&errs, req.MinAmount, &req.Currency, req.CategoryID, req.Role)
+	return errs
+}
+
+// UpdateApprovalRuleRequest представляет запрос на замену условий правила одобрения
+type UpdateApprovalRuleRequest struct {
+	RuleID     uint              `json:"rule_id"`
+	MinAmount  int64             `json:"min_amount"`
+	Currency   string            `json:"currency"`
+	CategoryID *uint             `json:"category_id"`
+	Role       models.FamilyRole `json:"role"`
+}
+
+// Validate проверяет запрос на изменение правила
+func (req *UpdateApprovalRuleRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "rule_id", req.RuleID)
+	validateApprovalRule(&errs, req.MinAmount, &req.Currency, req.CategoryID, req.Role)
+	return errs
+}
+
+// DeleteApprovalRuleRequest представляет запрос на удаление правила одобрения
+type DeleteApprovalRuleRequest struct {
+	RuleID uint `json:"rule_id"`
+}
+
+// Validate проверяет запрос на удаление правила
+func (req *DeleteApprovalRuleRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "rule_id", req.RuleID)
+	return errs
+}
+
+// DecideApprovalRequest представляет решение по расходу, ожидающему одобрения
+type DecideApprovalRequest struct {
+	TransactionID uint   `json:"transaction_id"`
+	Note          string `json:"note"`
+}
+
+// Validate проверяет решение по расходу
+func (req *DecideApprovalRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "transaction_id", req.TransactionID)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// ListApprovalsHandler возвращает запросы на одобрение расходов
+// Параметр status фильтрует по состоянию: pending, approved или rejected
+func (h *ApprovalHandler) ListApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	status := models.ApprovalStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.Valid() {
+		errs.Add("status", "ожидается pending, approved или rejected")
+	}
+	limit := queryLimit(r, &errs, 50, maxApprovalsPage)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	list, err := h.approvalService.List(user, familyID, status, limit)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка запросов на одобрение", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// ApproveHandler обрабатывает одобрение расхода
+func (h *ApprovalHandler) ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DecideApprovalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	approval, err := h.approvalService.Approve(user, req.TransactionID, req.Note)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка одобрения расхода", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(approval)
+}
+
+// RejectHandler обрабатывает отклонение расхода
+func (h *ApprovalHandler) RejectHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DecideApprovalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	approval, err := h.approvalService.Reject(user, req.TransactionID, req.Note)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка отклонения расхода", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(approval)
+}
+
+// ListRulesHandler возвращает правила одобрения семьи
+func (h *ApprovalHandler) ListRulesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	rules, err := h.approvalService.Rules(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения правил одобрения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreateRuleHandler обрабатывает запрос на создание правила одобрения
+func (h *ApprovalHandler) CreateRuleHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateApprovalRuleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	rule, err := h.approvalService.CreateRule(user, req.FamilyID, service.ApprovalRuleInput{
+		MinAmount:  req.MinAmount,
+		Currency:   req.Currency,
+		CategoryID: req.CategoryID,
+		Role:       req.Role,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания правила одобрения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateRuleHandler обрабатывает запрос на изменение правила одобрения
+func (h *ApprovalHandler) UpdateRuleHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateApprovalRuleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	rule, err := h.approvalService.UpdateRule(user, req.RuleID, service.ApprovalRuleInput{
+		MinAmount:  req.MinAmount,
+		Currency:   req.Currency,
+		CategoryID: req.CategoryID,
+		Role:       req.Role,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения правила одобрения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteRuleHandler обрабатывает запрос на удаление правила одобрения
+func (h *ApprovalHandler) DeleteRuleHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteApprovalRuleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.approvalService.DeleteRule(user, req.RuleID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления правила одобрения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Правило одобрения удалено"})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -28,6 +29,15 @@ func respondWithError(w http.ResponseWriter, statusCode int, errMsg, details str
 	})
 }
 
+// codeRequestStatus выбирает HTTP-статус для ошибки запроса кода:
+// недоступность очереди писем — временная ошибка сервера, остальное — ошибка запроса
+func codeRequestStatus(err error) int {
+	if errors.Is(err, service.ErrCodeEmailNotQueued) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
 // LoginRequest представляет запрос на получение кода для входа
 type LoginRequest struct {
 	Email string `json:"email"`
@@ -53,16 +63,48 @@ type VerifyRegistrationRequest struct {
 	Nickname string `json:"nickname"`
 }
 
+// Validate проверяет запрос на получение кода для входа
+func (req *LoginRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateEmail(&errs, "email", &req.Email, true)
+	return errs
+}
+
+// Validate проверяет запрос на проверку кода входа
+func (req *VerifyLoginRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateTempID(&errs, "temp_id", &req.TempID)
+	validateCode(&errs, "code", &req.Code)
+	return errs
+}
+
+// Validate проверяет запрос на получение кода для регистрации
+func (req *RegistrationRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateEmail(&errs, "email", &req.Email, true)
+	return errs
+}
+
+// Validate проверяет запрос на проверку кода регистрации
+func (req *VerifyRegistrationRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateTempID(&errs, "temp_id", &req.TempID)
+	validateCode(&errs, "code", &req.Code)
+	validatePersonName(&errs, "name", &req.Name, true)
+	validatePersonName(&errs, "surname", &req.Surname, true)
+	validateNickname(&errs, "nickname", &req.Nickname)
+	return errs
+}
+
 // RequestLoginCodeHandler обрабатывает запрос на получение кода для входа
 func (h *AuthHandler) RequestLoginCodeHandler(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Поле 'email' обязательно для заполнения")
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 	tempID, err := h.authService.RequestLoginCode(req.Email)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Ошибка запроса кода авторизации", err.Error())
+		respondWithError(w, codeRequestStatus(err), "Ошибка запроса кода авторизации", err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -72,8 +114,7 @@ func (h *AuthHandler) RequestLoginCodeHandler(w http.ResponseWriter, r *http.Req
 // VerifyLoginCodeHandler обрабатывает запрос на проверку кода входа
 func (h *AuthHandler) VerifyLoginCodeHandler(w http.ResponseWriter, r *http.Request) {
 	var req VerifyLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TempID == "" || req.Code == "" {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Поля 'temp_id' и 'code' обязательны для заполнения")
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 	token, err := h.authService.VerifyLoginCode(req.TempID, req.Code)
@@ -88,14 +129,13 @@ func (h *AuthHandler) VerifyLoginCodeHandler(w http.ResponseWriter, r *http.Requ
 // RequestRegistrationCodeHandler обрабатывает запрос на получение кода для регистрации
 func (h *AuthHandler) RequestRegistrationCodeHandler(w http.ResponseWriter, r *http.Request) {
 	var req RegistrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Поле 'email' обязательно для заполнения")
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 
 	tempID, err := h.authService.RequestRegistrationCode(req.Email)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Ошибка запроса кода регистрации", err.Error())
+		respondWithError(w, codeRequestStatus(err), "Ошибка запроса кода регистрации", err.Error())
 		return
 	}
 
@@ -106,9 +146,7 @@ func (h *AuthHandler) RequestRegistrationCodeHandler(w http.ResponseWriter, r *h
 // VerifyRegistrationCodeHandler обрабатывает запрос на проверку кода регистрации
 func (h *AuthHandler) VerifyRegistrationCodeHandler(w http.ResponseWriter, r *http.Request) {
 	var req VerifyRegistrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
-		req.TempID == "" || req.Code == "" || req.Name == "" || req.Surname == "" {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Поля 'temp_id', 'code', 'name' и 'surname' обязательны")
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 	// Получаем токен после успешной регистрации
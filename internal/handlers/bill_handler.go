@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxBillNameLength максимальная длина названия счёта к оплате
+	maxBillNameLength = 200
+
+	// maxBillRemindDays наибольшее число дней, за которое отправляется напоминание
+	maxBillRemindDays = 30
+
+	// defaultBillRemindDays за сколько дней до срока по умолчанию отправляется напоминание
+	defaultBillRemindDays = 3
+
+	// defaultUpcomingDays период, за который по умолчанию показываются предстоящие счета
+	defaultUpcomingDays = 30
+
+	// maxUpcomingDays наибольший период списка предстоящих счетов
+	maxUpcomingDays = 366
+)
+
+// BillHandler обрабатывает HTTP запросы, связанные со счетами к оплате
+type BillHandler struct {
+	billService service.BillService
+}
+
+// NewBillHandler создает новый экземпляр BillHandler
+func NewBillHandler(billService service.BillService) *BillHandler {
+	return &BillHandler{billService: billService}
+}
+
+// validateBillRule проверяет сроки оплаты счёта
+// Без frequency счёт разовый и оплачивается один раз в день start_date
+func validateBillRule(errs *ValidationErrors, field string, req *RecurrenceRuleRequest) models.RecurrenceRule {
+	if req.Frequency != "" {
+		return validateRecurrenceRule(errs, field, req)
+	}
+	var rule models.RecurrenceRule
+	if start := validateDate(errs, field+".start_date", req.StartDate); start != nil {
+		rule.StartDate = *start
+	} else if req.StartDate == "" {
+		errs.Add(field+".start_date", "поле обязательно для заполнения")
+	}
+	if req.Interval != 0 || req.MonthDay != 0 || req.BusinessDay || req.EndDate != "" {
+		errs.Add(field+".frequency", "укажите частоту повторения или уберите параметры повторения")
+	}
+	return rule
+}
+
+// validateRemindDays проверяет, за сколько дней до срока отправляется напоминание
+func validateRemindDays(errs *ValidationErrors, field string, value int) {
+	if value < 0 || value > maxBillRemindDays {
+		errs.Add(field, "ожидается число от 0 до 30")
+	}
+}
+
+// CreateBillRequest представляет запрос на создание счёта к оплате
+// Валюта берётся из account_id, если он указан; иначе currency обязательна
+type CreateBillRequest struct {
+	FamilyID   uint                  `json:"family_id"`
+	Name       string                `json:"name"`
+	Amount     int64                 `json:"amount"`
+	Currency   string                `json:"currency"`
+	AccountID  *uint                 `json:"account_id"`
+	CategoryID *uint                 `json:"category_id"`
+	Rule       RecurrenceRuleRequest `json:"rule"`
+	// RemindDays за сколько дней до срока отправить напоминание; по умолчанию 3
+	RemindDays *int `json:"remind_days"`
+
+	rule models.RecurrenceRule
+}
+
+// Validate проверяет запрос на создание счёта к оплате
+func (req *CreateBillRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateTitle(&errs, "name", &req.Name, true, maxBillNameLength)
+	validateAmount(&errs, "amount", req.Amount, true)
+	validateCurrency(&errs, "currency", &req.Currency, req.AccountID == nil)
+	if req.AccountID != nil {
+		validateID(&errs, "account_id", *req.AccountID)
+	}
+	if req.CategoryID != nil {
+		validateID(&errs, "category_id", *req.CategoryID)
+	}
+	req.rule = validateBillRule(&errs, "rule", &req.Rule)
+	if req.RemindDays != nil {
+		validateRemindDays(&errs, "remind_days", *req.RemindDays)
+	}
+	return errs
+}
+
+// UpdateBillRequest представляет запрос на изменение счёта к оплате
+// Значение 0 в account_id и category_id снимает счёт оплаты и категорию
+type UpdateBillRequest struct {
+	BillID     uint                   `json:"bill_id"`
+	Name       *string                `json:"name"`
+	Amount     int64                  `json:"amount"`
+	AccountID  *uint                  `json:"account_id"`
+	CategoryID *uint                  `json:"category_id"`
+	Rule       *RecurrenceRuleRequest `json:"rule"`
+	RemindDays *int                   `json:"remind_days"`
+
+	rule *models.RecurrenceRule
+}
+
+// Validate проверяет запрос на изменение счёта к оплате
+func (req *UpdateBillRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "bill_id", req.BillID)
+	if req.Name != nil {
+		validateTitle(&errs, "name", req.Name, true, maxBillNameLength)
+	}
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	if req.Rule != nil {
+		rule := validateBillRule(&errs, "rule", req.Rule)
+		req.rule = &rule
+	}
+	if req.RemindDays != nil {
+		validateRemindDays(&errs, "remind_days", *req.RemindDays)
+	}
+	return errs
+}
+
+// PayBillRequest представляет запрос на отметку об оплате срока счёта
+// transaction_id связывает срок с уже проведённым расходом; record = true проводит
+// новый расход со счёта account_id или счёта оплаты; без них срок просто отмечается оплаченным
+type PayBillRequest struct {
+	BillID        uint   `json:"bill_id"`
+	DueDate       string `json:"due_date"`
+	TransactionID uint   `json:"transaction_id"`
+	Record        bool   `json:"record"`
+	AccountID     uint   `json:"account_id"`
+	Amount        int64  `json:"amount"`
+	Date          string `json:"date"`
+
+	dueDate *time.Time
+	date    *time.Time
+}
+
+// Validate проверяет запрос на отметку об оплате
+func (req *PayBillRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "bill_id", req.BillID)
+	req.dueDate = validateDate(&errs, "due_date", req.DueDate)
+	if req.dueDate == nil && req.DueDate == "" {
+		errs.Add("due_date", "поле обязательно для заполнения")
+	}
+	if req.TransactionID != 0 && req.Record {
+		errs.Add("transaction_id", "укажите существующую операцию или record, но не оба сразу")
+	}
+	if !req.Record && (req.AccountID != 0 || req.Amount != 0 || req.Date != "") {
+		errs.Add("record", "счёт, сумма и дата указываются только при проведении нового расхода")
+	}
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	req.date = validateDate(&errs, "date", req.Date)
+	return errs
+}
+
+// UnpayBillRequest представляет запрос на снятие отметки об оплате срока счёта
+type UnpayBillRequest struct {
+	BillID  uint   `json:"bill_id"`
+	DueDate string `json:"due_date"`
+
+	dueDate *time.Time
+}
+
+// Validate проверяет запрос на снятие отметки об оплате
+func (req *UnpayBillRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "bill_id", req.BillID)
+	req.dueDate = validateDate(&errs, "due_date", req.DueDate)
+	if req.dueDate == nil && req.DueDate == "" {
+		errs.Add("due_date", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// DeleteBillRequest представляет запрос на удаление счёта к оплате
+type DeleteBillRequest struct {
+	BillID uint `json:"bill_id"`
+}
+
+// Validate проверяет запрос на удаление счёта к оплате
+func (req *DeleteBillRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "bill_id", req.BillID)
+	return errs
+}
+
+// ListBillsHandler возвращает счета к оплате семьи
+func (h *BillHandler) ListBillsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	bills, err := h.billService.List(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка счетов к оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bills)
+}
+
+// GetBillHandler возвращает счёт к оплате
+func (h *BillHandler) GetBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "bill_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	bill, err := h.billService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения счёта к оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bill)
+}
+
+// UpcomingBillsHandler возвращает сроки оплаты счетов семьи на ближайшие days дней
+// (по умолчанию 30) вместе с просроченными неоплаченными сроками
+func (h *BillHandler) UpcomingBillsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	days := defaultUpcomingDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUpcomingDays {
+			errs.Add("days", "ожидается число от 1 до 366")
+		}
+		days = n
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	upcoming, err := h.billService.Upcoming(user, familyID, days)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения предстоящих счетов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upcoming)
+}
+
+// CreateBillHandler обрабатывает запрос на создание счёта к оплате
+func (h *BillHandler) CreateBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateBillRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	remindDays := defaultBillRemindDays
+	if req.RemindDays != nil {
+		remindDays = *req.RemindDays
+	}
+	bill, err := h.billService.Create(user, req.FamilyID, service.BillInput{
+		Name:       req.Name,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		AccountID:  req.AccountID,
+		CategoryID: req.CategoryID,
+		Rule:       req.rule,
+		RemindDays: remindDays,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания счёта к оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bill)
+}
+
+// UpdateBillHandler обрабатывает запрос на изменение счёта к оплате
+func (h *BillHandler) UpdateBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateBillRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	bill, err := h.billService.Update(user, req.BillID, service.BillChanges{
+		Name:       req.Name,
+		Amount:     req.Amount,
+		AccountID:  req.AccountID,
+		CategoryID: req.CategoryID,
+		Rule:       req.rule,
+		RemindDays: req.RemindDays,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения счёта к оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bill)
+}
+
+// DeleteBillHandler обрабатывает запрос на удаление счёта к оплате
+func (h *BillHandler) DeleteBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteBillRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.billService.Delete(user, req.BillID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления счёта к оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Счёт к оплате удалён"})
+}
+
+// PayBillHandler обрабатывает запрос на отметку об оплате срока счёта
+func (h *BillHandler) PayBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req PayBillRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	result, err := h.billService.Pay(user, req.BillID, service.BillPayment{
+		DueDate:       *req.dueDate,
+		TransactionID: req.TransactionID,
+		Record:        req.Record,
+		AccountID:     req.AccountID,
+		Amount:        req.Amount,
+		Date:          req.date,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка отметки об оплате счёта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// UnpayBillHandler обрабатывает запрос на снятие отметки об оплате срока счёта
+func (h *BillHandler) UnpayBillHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UnpayBillRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	result, err := h.billService.Unpay(user, req.BillID, *req.dueDate)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка снятия отметки об оплате", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxBudgetLines ограничение количества строк в одном бюджете
+	maxBudgetLines = 500
+
+	// maxAlertThresholds ограничение количества порогов предупреждений бюджета
+	maxAlertThresholds = 5
+
+	// maxAlertThreshold наибольший порог предупреждения в процентах
+	maxAlertThreshold = 1000
+)
+
+// BudgetHandler обрабатывает HTTP запросы, связанные с бюджетами
+type BudgetHandler struct {
+	budgetService service.BudgetService
+}
+
+// NewBudgetHandler создает новый экземпляр BudgetHandler
+func NewBudgetHandler(budgetService service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{budgetService: budgetService}
+}
+
+// BudgetLineRequest плановая сумма по категории в запросе
+type BudgetLineRequest struct {
+	CategoryID uint  `json:"category_id"`
+	Amount     int64 `json:"amount"`
+	Rollover   bool  `json:"rollover"`
+}
+
+// validateBudgetLines проверяет строки бюджета и преобразует их в данные сервиса
+func validateBudgetLines(errs *ValidationErrors, field string, lines []BudgetLineRequest) []service.BudgetLineInput {
+	if len(lines) > maxBudgetLines {
+		errs.Add(field, "слишком много категорий в одном бюджете")
+		return nil
+	}
+	result := make([]service.BudgetLineInput, 0, len(lines))
+	for i, line := range lines {
+		validateID(errs, fmt.Sprintf("%s[%d].category_id", field, i), line.CategoryID)
+		validateAmount(errs, fmt.Sprintf("%s[%d].amount", field, i), line.Amount, true)
+		result = append(result, service.BudgetLineInput{
+			CategoryID: line.CategoryID,
+			Amount:     line.Amount,
+			Rollover:   line.Rollover,
+		})
+	}
+	return result
+}
+
+// validateThresholds проверяет пороги предупреждений и упорядочивает их по возрастанию
+func validateThresholds(errs *ValidationErrors, field string, values []int) models.Thresholds {
+	if len(values) > maxAlertThresholds {
+		errs.Add(field, fmt.Sprintf("не больше %d порогов", maxAlertThresholds))
+		return nil
+	}
+	result := make(models.Thresholds, 0, len(values))
+	seen := make(map[int]bool, len(values))
+	for _, v := range values {
+		if v < 1 || v > maxAlertThreshold {
+			errs.Add(field, fmt.Sprintf("порог должен быть от 1 до %d процентов", maxAlertThreshold))
+			return nil
+		}
+		if seen[v] {
+			errs.Add(field, "пороги не должны повторяться")
+			return nil
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// CreateBudgetRequest представляет запрос на создание бюджета
+type CreateBudgetRequest struct {
+	FamilyID  uint                `json:"family_id"`
+	Period    models.BudgetPeriod `json:"period"`
+	StartDate string              `json:"start_date"`
+	EndDate   string              `json:"end_date"`
+	Currency  string              `json:"currency"`
+	Lines     []BudgetLineRequest `json:"lines"`
+	// AlertThresholds пороги предупреждений в процентах; по умолчанию 80 и 100
+	AlertThresholds []int `json:"alert_thresholds"`
+
+	start      *time.Time
+	end        *time.Time
+	lines      []service.BudgetLineInput
+	thresholds models.Thresholds
+}
+
+// Validate проверяет запрос на создание бюджета
+func (req *CreateBudgetRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	if !req.Period.Valid() {
+		errs.Add("period", "ожидается month, week или custom")
+	}
+	req.start = validateDate(&errs, "start_date", req.StartDate)
+	if req.start == nil && req.StartDate == "" {
+		errs.Add("start_date", "поле обязательно для заполнения")
+	}
+	req.end = validateDate(&errs, "end_date", req.EndDate)
+	if req.Period == models.BudgetCustom && req.end == nil && req.EndDate == "" {
+		errs.Add("end_date", "поле обязательно для произвольного периода")
+	}
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	req.lines = validateBudgetLines(&errs, "lines", req.Lines)
+	if req.AlertThresholds != nil {
+		req.thresholds = validateThresholds(&errs, "alert_thresholds", req.AlertThresholds)
+	}
+	return errs
+}
+
+// UpdateBudgetRequest представляет запрос на изменение бюджета
+// Переданные строки бюджета заменяют текущие целиком; пустой alert_thresholds отключает предупреждения
+type UpdateBudgetRequest struct {
+	BudgetID        uint                 `json:"budget_id"`
+	Lines           *[]BudgetLineRequest `json:"lines"`
+	AlertThresholds *[]int               `json:"alert_thresholds"`
+
+	changes service.BudgetChanges
+}
+
+// Validate проверяет запрос на изменение бюджета
+func (req *UpdateBudgetRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "budget_id", req.BudgetID)
+	if req.Lines != nil {
+		lines := validateBudgetLines(&errs, "lines", *req.Lines)
+		req.changes.Lines = &lines
+	}
+	if req.AlertThresholds != nil {
+		thresholds := validateThresholds(&errs, "alert_thresholds", *req.AlertThresholds)
+		req.changes.AlertThresholds = &thresholds
+	}
+	return errs
+}
+
+// DeleteBudgetRequest представляет запрос на удаление бюджета
+type DeleteBudgetRequest struct {
+	BudgetID uint `json:"budget_id"`
+}
+
+// Validate проверяет запрос на удаление бюджета
+func (req *DeleteBudgetRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "budget_id", req.BudgetID)
+	return errs
+}
+
+// CopyBudgetRequest представляет запрос на копирование бюджета на следующий период
+type CopyBudgetRequest struct {
+	BudgetID  uint   `json:"budget_id"`
+	StartDate string `json:"start_date"`
+
+	start *time.Time
+}
+
+// Validate проверяет запрос на копирование бюджета
+func (req *CopyBudgetRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "budget_id", req.BudgetID)
+	req.start = validateDate(&errs, "start_date", req.StartDate)
+	return errs
+}
+
+// ListBudgetsHandler возвращает бюджеты семьи, пересекающиеся с периодом date_from — date_to
+func (h *BudgetHandler) ListBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	from := queryDate(r, &errs, "date_from")
+	to := queryDate(r, &errs, "date_to")
+	if from != nil && to != nil && to.Before(*from) {
+		errs.Add("date_to", "дата окончания не может быть раньше даты начала")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	budgets, err := h.budgetService.List(user, familyID, from, to)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка бюджетов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budgets)
+}
+
+// GetBudgetHandler возвращает бюджет
+func (h *BudgetHandler) GetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	budgetID := queryID(r, &errs, "budget_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	budget, err := h.budgetService.Get(user, budgetID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// BudgetProgressHandler возвращает план, факт и остаток бюджета по каждой категории
+func (h *BudgetHandler) BudgetProgressHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	budgetID := queryID(r, &errs, "budget_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	progress, err := h.budgetService.Progress(user, budgetID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка расчёта исполнения бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// CreateBudgetHandler обрабатывает запрос на создание бюджета
+func (h *BudgetHandler) CreateBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateBudgetRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	budget, err := h.budgetService.Create(user, req.FamilyID, service.BudgetInput{
+		Period:          req.Period,
+		StartDate:       *req.start,
+		EndDate:         req.end,
+		Currency:        req.Currency,
+		AlertThresholds: req.thresholds,
+		Lines:           req.lines,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget)
+}
+
+// UpdateBudgetHandler обрабатывает запрос на изменение плановых сумм и порогов бюджета
+func (h *BudgetHandler) UpdateBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateBudgetRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	budget, err := h.budgetService.Update(user, req.BudgetID, req.changes)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// DeleteBudgetHandler обрабатывает запрос на удаление бюджета
+func (h *BudgetHandler) DeleteBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteBudgetRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.budgetService.Delete(user, req.BudgetID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Бюджет удалён"})
+}
+
+// CopyBudgetHandler обрабатывает запрос на копирование бюджета на следующий период
+func (h *BudgetHandler) CopyBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CopyBudgetRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	budget, err := h.budgetService.CopyForward(user, req.BudgetID, req.start)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка копирования бюджета", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget)
+}
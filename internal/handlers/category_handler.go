@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxCategoryNameLength соответствует размеру колонки name в models.Category
+	maxCategoryNameLength = 100
+
+	// maxReorderCategories ограничение количества категорий в запросе на сортировку
+	maxReorderCategories = 500
+)
+
+var (
+	// categoryIconPattern идентификатор иконки: строчные латинские буквы, цифры, дефис и подчёркивание
+	categoryIconPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+	// categoryColorPattern цвет в формате #RRGGBB
+	categoryColorPattern = regexp.MustCompile(`^#[0-9A-F]{6}$`)
+)
+
+// CategoryHandler обрабатывает HTTP запросы, связанные с категориями
+type CategoryHandler struct {
+	categoryService service.CategoryService
+}
+
+// NewCategoryHandler создает новый экземпляр CategoryHandler
+func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// validateCategoryKind проверяет вид категории: доходы или расходы
+func validateCategoryKind(errs *ValidationErrors, field string, value models.Direction) {
+	if value != "" && value != models.DirectionIncome && value != models.DirectionExpense {
+		errs.Add(field, "ожидается income или expense")
+	}
+}
+
+// validateCategoryIcon нормализует и проверяет необязательный идентификатор иконки
+func validateCategoryIcon(errs *ValidationErrors, field string, value *string) {
+	*value = strings.ToLower(strings.TrimSpace(*value))
+	if *value != "" && !categoryIconPattern.MatchString(*value) {
+		errs.Add(field, "ожидается идентификатор из латинских букв, цифр, дефиса и подчёркивания длиной до 32 символов")
+	}
+}
+
+// validateCategoryColor нормализует и проверяет необязательный цвет в формате #RRGGBB
+func validateCategoryColor(errs *ValidationErrors, field string, value *string) {
+	*value = strings.ToUpper(strings.TrimSpace(*value))
+	if *value != "" && !categoryColorPattern.MatchString(*value) {
+		errs.Add(field, "ожидается цвет в формате #RRGGBB")
+	}
+}
+
+// CreateCategoryRequest представляет запрос на создание категории
+// Для подкатегории вид, иконка и цвет по умолчанию берутся у родительской категории
+type CreateCategoryRequest struct {
+	FamilyID uint             `json:"family_id"`
+	ParentID *uint            `json:"parent_id"`
+	Kind     models.Direction `json:"kind"`
+	Name     string           `json:"name"`
+	Icon     string           `json:"icon"`
+	Color    string           `json:"color"`
+}
+
+// Validate проверяет запрос на создание категории
+func (req *CreateCategoryRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	if req.ParentID != nil {
+		validateID(&errs, "parent_id", *req.ParentID)
+	} else if req.Kind == "" {
+		errs.Add("kind", "поле обязательно для заполнения")
+	}
+	validateCategoryKind(&errs, "kind", req.Kind)
+	validateTitle(&errs, "name", &req.Name, true, maxCategoryNameLength)
+	validateCategoryIcon(&errs, "icon", &req.Icon)
+	validateCategoryColor(&errs, "color", &req.Color)
+	return errs
+}
+
+// UpdateCategoryRequest представляет запрос на изменение категории
+// Все поля, кроме category_id, необязательны
+type UpdateCategoryRequest struct {
+	CategoryID uint    `json:"category_id"`
+	Name       *string `json:"name"`
+	Icon       *string `json:"icon"`
+	Color      *string `json:"color"`
+}
+
+// Validate проверяет запрос на изменение категории
+func (req *UpdateCategoryRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "category_id", req.CategoryID)
+	if req.Name != nil {
+		validateTitle(&errs, "name", req.Name, true, maxCategoryNameLength)
+	}
+	if req.Icon != nil {
+		validateCategoryIcon(&errs, "icon", req.Icon)
+	}
+	if req.Color != nil {
+		validateCategoryColor(&errs, "color", req.Color)
+	}
+	return errs
+}
+
+// ArchiveCategoryRequest представляет запрос на архивирование категории
+// archived = false возвращает категорию из архива
+type ArchiveCategoryRequest struct {
+	CategoryID uint  `json:"category_id"`
+	Archived   *bool `json:"archived"`
+}
+
+// Validate проверяет запрос на архивирование категории
+func (req *ArchiveCategoryRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "category_id", req.CategoryID)
+	if req.Archived == nil {
+		errs.Add("archived", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// ReorderCategoriesRequest представляет запрос на изменение порядка категорий одного уровня
+// Без parent_id упорядочиваются категории верхнего уровня, иначе подкатегории parent_id
+type ReorderCategoriesRequest struct {
+	FamilyID    uint   `json:"family_id"`
+	ParentID    *uint  `json:"parent_id"`
+	CategoryIDs []uint `json:"category_ids"`
+}
+
+// Validate проверяет запрос на изменение порядка категорий
+func (req *ReorderCategoriesRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	if req.ParentID != nil {
+		validateID(&errs, "parent_id", *req.ParentID)
+	}
+	switch {
+	case len(req.CategoryIDs) == 0:
+		errs.Add("category_ids", "поле обязательно для заполнения")
+	case len(req.CategoryIDs) > maxReorderCategories:
+		errs.Add("category_ids", "слишком много категорий в одном запросе")
+	}
+	return errs
+}
+
+// MergeCategoriesRequest представляет запрос на объединение категорий
+type MergeCategoriesRequest struct {
+	SourceID uint `json:"source_id"`
+	TargetID uint `json:"target_id"`
+}
+
+// Validate проверяет запрос на объединение категорий
+func (req *MergeCategoriesRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "source_id", req.SourceID)
+	validateID(&errs, "target_id", req.TargetID)
+	return errs
+}
+
+// ListCategoriesHandler возвращает дерево категорий семьи
+func (h *CategoryHandler) ListCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	includeArchived := queryBool(r, &errs, "include_archived")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	categories, err := h.categoryService.List(user, familyID, includeArchived)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка категорий", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// CreateCategoryHandler обрабатывает запрос на создание категории
+func (h *CategoryHandler) CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateCategoryRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	category, err := h.categoryService.Create(user, req.FamilyID, service.CategoryInput{
+		ParentID: req.ParentID,
+		Kind:     req.Kind,
+		Name:     req.Name,
+		Icon:     req.Icon,
+		Color:    req.Color,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания категории", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+// UpdateCategoryHandler обрабатывает запрос на изменение категории
+func (h *CategoryHandler) UpdateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateCategoryRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	category, err := h.categoryService.Update(user, req.CategoryID, service.CategoryChanges{
+		Name:  req.Name,
+		Icon:  req.Icon,
+		Color: req.Color,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения категории", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// ArchiveCategoryHandler обрабатывает запрос на архивирование категории
+func (h *CategoryHandler) ArchiveCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ArchiveCategoryRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	category, err := h.categoryService.Archive(user, req.CategoryID, *req.Archived)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка архивирования категории", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// ReorderCategoriesHandler обрабатывает запрос на изменение порядка категорий
+func (h *CategoryHandler) ReorderCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ReorderCategoriesRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.categoryService.Reorder(user, req.FamilyID, req.ParentID, req.CategoryIDs); err != nil {
+		respondWithServiceError(w, "Ошибка изменения порядка категорий", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Порядок категорий изменён"})
+}
+
+// MergeCategoriesHandler обрабатывает запрос на объединение категорий
+// Операции и подкатегории source переносятся в target, source удаляется
+func (h *CategoryHandler) MergeCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req MergeCategoriesRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	category, err := h.categoryService.Merge(user, req.SourceID, req.TargetID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка объединения категорий", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"family_finance_back/internal/middleware"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/service"
+)
+
+// currentUser возвращает пользователя, определённого JWTAuthMiddleware
+// Если пользователя нет, отправляет ответ 401 и возвращает nil
+func currentUser(w http.ResponseWriter, r *http.Request) *models.User {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		respondWithError(w, http.StatusUnauthorized, "Ошибка авторизации", "Пользователь не определён")
+	}
+	return user
+}
+
+// respondWithServiceError отправляет ошибку бизнес-логики с HTTP-статусом,
+// соответствующим её виду; неклассифицированные ошибки считаются внутренними
+func respondWithServiceError(w http.ResponseWriter, errMsg string, err error) {
+	status := http.StatusInternalServerError
+	var svcErr *service.Error
+	if errors.As(err, &svcErr) {
+		switch svcErr.Kind {
+		case service.KindInvalid:
+			status = http.StatusBadRequest
+		case service.KindNotFound:
+			status = http.StatusNotFound
+		case service.KindForbidden:
+			status = http.StatusForbidden
+		case service.KindConflict:
+			status = http.StatusConflict
+		}
+	}
+	respondWithError(w, status, errMsg, err.Error())
+}
+
+// queryID читает обязательный идентификатор из строки запроса
+func queryID(r *http.Request, errs *ValidationErrors, field string) uint {
+	v := r.URL.Query().Get(field)
+	if v == "" {
+		errs.Add(field, "параметр обязателен")
+		return 0
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		errs.Add(field, "ожидается положительное целое число")
+		return 0
+	}
+	return uint(id)
+}
+
+// queryBool читает необязательный логический параметр из строки запроса
+// Отсутствующий параметр считается false
+func queryBool(r *http.Request, errs *ValidationErrors, field string) bool {
+	v := r.URL.Query().Get(field)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		errs.Add(field, "ожидается true или false")
+		return false
+	}
+	return b
+}
+
+// queryOptionalID читает необязательный идентификатор из строки запроса
+// Отсутствующий параметр возвращается как 0
+func queryOptionalID(r *http.Request, errs *ValidationErrors, field string) uint {
+	if r.URL.Query().Get(field) == "" {
+		return 0
+	}
+	return queryID(r, errs, field)
+}
+
+// queryDate читает необязательную дату в формате ГГГГ-ММ-ДД из строки запроса
+func queryDate(r *http.Request, errs *ValidationErrors, field string) *time.Time {
+	return validateDate(errs, field, r.URL.Query().Get(field))
+}
+
+// queryAmount читает необязательную сумму в минимальных единицах валюты из строки запроса
+func queryAmount(r *http.Request, errs *ValidationErrors, field string) *int64 {
+	v := r.URL.Query().Get(field)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 || n > money.MaxAmount {
+		errs.Add(field, "ожидается неотрицательное целое число в минимальных единицах валюты")
+		return nil
+	}
+	return &n
+}
+
+// queryLimit читает размер страницы из параметра limit
+func queryLimit(r *http.Request, errs *ValidationErrors, def, max int) int {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > max {
+		errs.Add("limit", fmt.Sprintf("ожидается число от 1 до %d", max))
+		return def
+	}
+	return n
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"family_finance_back/internal/service"
+)
+
+// DebtHandler обрабатывает HTTP запросы, связанные с долгами между участниками семьи
+type DebtHandler struct {
+	debtService service.DebtService
+}
+
+// NewDebtHandler создает новый экземпляр DebtHandler
+func NewDebtHandler(debtService service.DebtService) *DebtHandler {
+	return &DebtHandler{debtService: debtService}
+}
+
+// CreateDebtRequest представляет запрос на запись займа между участниками семьи
+type CreateDebtRequest struct {
+	FamilyID   uint   `json:"family_id"`
+	LenderID   uint   `json:"lender_id"`
+	BorrowerID uint   `json:"borrower_id"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	Date       string `json:"date"`
+	Note       string `json:"note"`
+
+	date *time.Time
+}
+
+// Validate проверяет запрос на запись займа
+func (req *CreateDebtRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateID(&errs, "lender_id", req.LenderID)
+	validateID(&errs, "borrower_id", req.BorrowerID)
+	validateAmount(&errs, "amount", req.Amount, true)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	req.date = validateDate(&errs, "date", req.Date)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// SettleDebtRequest представляет запрос на запись погашения долга
+// Без amount погашается весь долг from_id перед to_id в валюте currency
+type SettleDebtRequest struct {
+	FamilyID uint   `json:"family_id"`
+	FromID   uint   `json:"from_id"`
+	ToID     uint   `json:"to_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Date     string `json:"date"`
+	Note     string `json:"note"`
+
+	date *time.Time
+}
+
+// Validate проверяет запрос на погашение долга
+func (req *SettleDebtRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateID(&errs, "from_id", req.FromID)
+	validateID(&errs, "to_id", req.ToID)
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	req.date = validateDate(&errs, "date", req.Date)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// DeleteDebtRequest представляет запрос на удаление записи о займе или погашении
+type DeleteDebtRequest struct {
+	DebtID uint `json:"debt_id"`
+}
+
+// Validate проверяет запрос на удаление записи о долге
+func (req *DeleteDebtRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "debt_id", req.DebtID)
+	return errs
+}
+
+// DebtSummaryHandler возвращает долги между участниками семьи и предложение, как их погасить
+func (h *DebtHandler) DebtSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	summary, err := h.debtService.Summary(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения долгов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// ListDebtsHandler возвращает записи о займах и погашениях семьи
+func (h *DebtHandler) ListDebtsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	memberID := queryOptionalID(r, &errs, "member_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	debts, err := h.debtService.List(user, familyID, memberID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка долгов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(debts)
+}
+
+// CreateDebtHandler обрабатывает запрос на запись займа
+func (h *DebtHandler) CreateDebtHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateDebtRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	debt, err := h.debtService.Lend(user, req.FamilyID, service.DebtInput{
+		LenderID:   req.LenderID,
+		BorrowerID: req.BorrowerID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Date:       req.date,
+		Note:       req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка записи займа", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(debt)
+}
+
+// SettleDebtHandler обрабатывает запрос на запись погашения долга
+func (h *DebtHandler) SettleDebtHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req SettleDebtRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	debt, err := h.debtService.Settle(user, req.FamilyID, service.SettleInput{
+		FromID:   req.FromID,
+		ToID:     req.ToID,
+		Currency: req.Currency,
+		Amount:   req.Amount,
+		Date:     req.date,
+		Note:     req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка записи погашения долга", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(debt)
+}
+
+// DeleteDebtHandler обрабатывает запрос на удаление записи о долге
+func (h *DebtHandler) DeleteDebtHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteDebtRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.debtService.Delete(user, req.DebtID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления записи о долге", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Запись о долге удалена"})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/mail"
+)
+
+// DevHandler обрабатывает отладочные HTTP запросы
+// Регистрируется только при DEV_ENDPOINTS=true
+type DevHandler struct {
+	capture  *mail.CaptureTransport
+	renderer *mail.Renderer
+}
+
+// NewDevHandler создает новый экземпляр DevHandler
+func NewDevHandler(capture *mail.CaptureTransport, renderer *mail.Renderer) *DevHandler {
+	return &DevHandler{capture: capture, renderer: renderer}
+}
+
+// ListEmailsHandler возвращает письма, перехваченные транспортом capture
+// Параметр to фильтрует письма по получателю, метод DELETE очищает список
+func (h *DevHandler) ListEmailsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.capture == nil {
+		respondWithError(w, http.StatusNotFound, "Перехват писем выключен", "Установите EMAIL_TRANSPORT=capture")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		h.capture.Reset()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var errs ValidationErrors
+	to := r.URL.Query().Get("to")
+	validateEmail(&errs, "to", &to, false)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.capture.Messages(to))
+}
+
+// PreviewEmailHandler отрисовывает шаблон письма на примере данных
+// Без параметра template возвращает список шаблонов и языков
+// Параметр format: html (по умолчанию), text или json
+func (h *DevHandler) PreviewEmailHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("template")
+	if name == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.renderer.Templates())
+		return
+	}
+
+	data, ok := mail.PreviewData[name]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Шаблон не найден", "Нет примера данных для шаблона '"+name+"'")
+		return
+	}
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = mail.DefaultLocale
+	}
+	content, err := h.renderer.Render(name, locale, data)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Ошибка отрисовки шаблона", err.Error())
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("Subject: " + content.Subject + "\n\n" + content.Text))
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(content)
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(content.HTML))
+	}
+}
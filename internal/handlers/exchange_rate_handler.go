@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"family_finance_back/internal/service"
+)
+
+// ExchangeRateHandler обрабатывает HTTP запросы справочника валют и курсов
+type ExchangeRateHandler struct {
+	rateService service.ExchangeRateService
+}
+
+// NewExchangeRateHandler создает новый экземпляр ExchangeRateHandler
+func NewExchangeRateHandler(rateService service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{rateService: rateService}
+}
+
+// ListCurrenciesHandler возвращает справочник валют с количеством знаков после запятой
+func (h *ExchangeRateHandler) ListCurrenciesHandler(w http.ResponseWriter, r *http.Request) {
+	if currentUser(w, r) == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.rateService.Currencies())
+}
+
+// ListRatesHandler возвращает курсы, действующие на дату (по умолчанию сегодня)
+func (h *ExchangeRateHandler) ListRatesHandler(w http.ResponseWriter, r *http.Request) {
+	if currentUser(w, r) == nil {
+		return
+	}
+	var errs ValidationErrors
+	date := queryDate(r, &errs, "date")
+	currency := r.URL.Query().Get("currency")
+	validateCurrency(&errs, "currency", &currency, false)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	if date == nil {
+		today := time.Now().UTC()
+		date = &today
+	}
+	rates, err := h.rateService.Effective(*date, currency)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения курсов валют", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// defaultFamilyCurrency валюта семьи по умолчанию
+	defaultFamilyCurrency = "RUB"
+
+	// defaultFamilyTimezone часовой пояс семьи по умолчанию
+	defaultFamilyTimezone = "Europe/Moscow"
+
+	// defaultFamilyLocale язык семьи по умолчанию
+	defaultFamilyLocale = "ru"
+
+	// maxFamilyNameLength соответствует размеру колонки name в models.Family
+	maxFamilyNameLength = 100
+)
+
+// FamilyHandler обрабатывает HTTP запросы, связанные с семьями
+type FamilyHandler struct {
+	familyService service.FamilyService
+}
+
+// NewFamilyHandler создает новый экземпляр FamilyHandler
+func NewFamilyHandler(familyService service.FamilyService) *FamilyHandler {
+	return &FamilyHandler{familyService: familyService}
+}
+
+// CreateFamilyRequest представляет запрос на создание семьи
+type CreateFamilyRequest struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Timezone string `json:"timezone"`
+	// Locale язык категорий по умолчанию: ru или en
+	Locale string `json:"locale"`
+}
+
+// Validate проверяет запрос на создание семьи и подставляет значения по умолчанию
+func (req *CreateFamilyRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateTitle(&errs, "name", &req.Name, true, maxFamilyNameLength)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	validateTimezone(&errs, "timezone", &req.Timezone)
+	req.Locale = strings.ToLower(strings.TrimSpace(req.Locale))
+	if req.Locale != "" && req.Locale != "ru" && req.Locale != "en" {
+		errs.Add("locale", "ожидается ru или en")
+	}
+	if req.Currency == "" {
+		req.Currency = defaultFamilyCurrency
+	}
+	if req.Timezone == "" {
+		req.Timezone = defaultFamilyTimezone
+	}
+	if req.Locale == "" {
+		req.Locale = defaultFamilyLocale
+	}
+	return errs
+}
+
+// RenameFamilyRequest представляет запрос на переименование семьи
+type RenameFamilyRequest struct {
+	FamilyID uint   `json:"family_id"`
+	Name     string `json:"name"`
+}
+
+// Validate проверяет запрос на переименование семьи
+func (req *RenameFamilyRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateTitle(&errs, "name", &req.Name, true, maxFamilyNameLength)
+	return errs
+}
+
+// SetFamilyCurrencyRequest представляет запрос на изменение основной валюты семьи
+type SetFamilyCurrencyRequest struct {
+	FamilyID uint   `json:"family_id"`
+	Currency string `json:"currency"`
+}
+
+// Validate проверяет запрос на изменение валюты семьи
+func (req *SetFamilyCurrencyRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateCurrency(&errs, "currency", &req.Currency, true)
+	return errs
+}
+
+// LeaveFamilyRequest представляет запрос на выход из семьи
+type LeaveFamilyRequest struct {
+	FamilyID uint `json:"family_id"`
+}
+
+// Validate проверяет запрос на выход из семьи
+func (req *LeaveFamilyRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	return errs
+}
+
+// ChangeRoleRequest представляет запрос на изменение роли участника семьи
+type ChangeRoleRequest struct {
+	FamilyID uint              `json:"family_id"`
+	UserID   uint              `json:"user_id"`
+	Role     models.FamilyRole `json:"role"`
+}
+
+// Validate проверяет запрос на изменение роли участника
+func (req *ChangeRoleRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateID(&errs, "user_id", req.UserID)
+	if !req.Role.Valid() {
+		errs.Add("role", "ожидается owner, adult, child или viewer")
+	}
+	return errs
+}
+
+// ListFamiliesHandler возвращает семьи текущего пользователя вместе с его ролью
+func (h *FamilyHandler) ListFamiliesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	families, err := h.familyService.ListMine(user)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка семей", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(families)
+}
+
+// CreateFamilyHandler обрабатывает запрос на создание семьи
+func (h *FamilyHandler) CreateFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateFamilyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	family, err := h.familyService.Create(user, req.Name, req.Currency, req.Timezone, req.Locale)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания семьи", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(family)
+}
+
+// RenameFamilyHandler обрабатывает запрос на переименование семьи
+func (h *FamilyHandler) RenameFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req RenameFamilyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	family, err := h.familyService.Rename(user, req.FamilyID, req.Name)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка переименования семьи", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(family)
+}
+
+// SetCurrencyHandler обрабатывает запрос на изменение основной валюты семьи
+func (h *FamilyHandler) SetCurrencyHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req SetFamilyCurrencyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	family, err := h.familyService.SetCurrency(user, req.FamilyID, req.Currency)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения валюты семьи", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(family)
+}
+
+// ListMembersHandler возвращает участников семьи
+func (h *FamilyHandler) ListMembersHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	members, err := h.familyService.Members(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения участников семьи", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// ChangeRoleHandler обрабатывает запрос на изменение роли участника семьи
+func (h *FamilyHandler) ChangeRoleHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ChangeRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.familyService.ChangeRole(user, req.FamilyID, req.UserID, req.Role); err != nil {
+		respondWithServiceError(w, "Ошибка изменения роли", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Роль участника изменена"})
+}
+
+// LeaveFamilyHandler обрабатывает запрос на выход из семьи
+func (h *FamilyHandler) LeaveFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req LeaveFamilyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.familyService.Leave(user, req.FamilyID); err != nil {
+		respondWithServiceError(w, "Ошибка выхода из семьи", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Вы вышли из семьи"})
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"family_finance_back/internal/service"
+)
+
+// maxGoalNameLength соответствует размеру колонки name в models.Goal
+const maxGoalNameLength = 200
+
+// GoalHandler обрабатывает HTTP запросы, связанные с целями накоплений
+type GoalHandler struct {
+	goalService service.GoalService
+}
+
+// NewGoalHandler создает новый экземпляр GoalHandler
+func NewGoalHandler(goalService service.GoalService) *GoalHandler {
+	return &GoalHandler{goalService: goalService}
+}
+
+// CreateGoalRequest представляет запрос на создание цели накоплений
+type CreateGoalRequest struct {
+	FamilyID     uint   `json:"family_id"`
+	Name         string `json:"name"`
+	TargetAmount int64  `json:"target_amount"`
+	Currency     string `json:"currency"`
+	Deadline     string `json:"deadline"`
+	AccountID    uint   `json:"account_id"`
+
+	deadline *time.Time
+}
+
+// Validate проверяет запрос на создание цели
+func (req *CreateGoalRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateTitle(&errs, "name", &req.Name, true, maxGoalNameLength)
+	validateAmount(&errs, "target_amount", req.TargetAmount, true)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	req.deadline = validateDate(&errs, "deadline", req.Deadline)
+	validateID(&errs, "account_id", req.AccountID)
+	return errs
+}
+
+// UpdateGoalRequest представляет запрос на изменение цели
+// Пустая строка в deadline снимает срок
+type UpdateGoalRequest struct {
+	GoalID       uint    `json:"goal_id"`
+	Name         *string `json:"name"`
+	TargetAmount int64   `json:"target_amount"`
+	Deadline     *string `json:"deadline"`
+
+	deadline *time.Time
+}
+
+// Validate проверяет запрос на изменение цели
+func (req *UpdateGoalRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "goal_id", req.GoalID)
+	if req.Name != nil {
+		validateTitle(&errs, "name", req.Name, true, maxGoalNameLength)
+	}
+	if req.TargetAmount != 0 {
+		validateAmount(&errs, "target_amount", req.TargetAmount, true)
+	}
+	if req.Deadline != nil {
+		req.deadline = validateDate(&errs, "deadline", *req.Deadline)
+	}
+	return errs
+}
+
+// DeleteGoalRequest представляет запрос на удаление цели
+type DeleteGoalRequest struct {
+	GoalID uint `json:"goal_id"`
+}
+
+// Validate проверяет запрос на удаление цели
+func (req *DeleteGoalRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "goal_id", req.GoalID)
+	return errs
+}
+
+// ContributeGoalRequest представляет запрос на взнос в цель
+type ContributeGoalRequest struct {
+	GoalID        uint   `json:"goal_id"`
+	FromAccountID uint   `json:"from_account_id"`
+	Amount        int64  `json:"amount"`
+	Rate          string `json:"rate"`
+	Date          string `json:"date"`
+	Note          string `json:"note"`
+
+	rate *big.Rat
+	date *time.Time
+}
+
+// Validate проверяет запрос на взнос в цель
+func (req *ContributeGoalRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "goal_id", req.GoalID)
+	validateID(&errs, "from_account_id", req.FromAccountID)
+	validateAmount(&errs, "amount", req.Amount, true)
+	req.rate = validateRate(&errs, "rate", req.Rate)
+	req.date = validateDate(&errs, "date", req.Date)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// ListGoalsHandler возвращает цели семьи с прогнозами
+func (h *GoalHandler) ListGoalsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	goals, err := h.goalService.List(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка целей", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goals)
+}
+
+// GetGoalHandler возвращает цель с прогнозом
+func (h *GoalHandler) GetGoalHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "goal_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	goal, err := h.goalService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения цели", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goal)
+}
+
+// ListContributionsHandler возвращает взносы в цель
+func (h *GoalHandler) ListContributionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "goal_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	contributions, err := h.goalService.Contributions(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения взносов в цель", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contributions)
+}
+
+// CreateGoalHandler обрабатывает запрос на создание цели
+func (h *GoalHandler) CreateGoalHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateGoalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	goal, err := h.goalService.Create(user, req.FamilyID, service.GoalInput{
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		Currency:     req.Currency,
+		Deadline:     req.deadline,
+		AccountID:    req.AccountID,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания цели", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(goal)
+}
+
+// UpdateGoalHandler обрабатывает запрос на изменение цели
+func (h *GoalHandler) UpdateGoalHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateGoalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	goal, err := h.goalService.Update(user, req.GoalID, service.GoalChanges{
+		Name:          req.Name,
+		TargetAmount:  req.TargetAmount,
+		Deadline:      req.deadline,
+		ClearDeadline: req.Deadline != nil && req.deadline == nil,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения цели", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goal)
+}
+
+// DeleteGoalHandler обрабатывает запрос на удаление цели
+func (h *GoalHandler) DeleteGoalHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteGoalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.goalService.Delete(user, req.GoalID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления цели", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Цель удалена"})
+}
+
+// ContributeGoalHandler обрабатывает запрос на взнос в цель
+func (h *GoalHandler) ContributeGoalHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ContributeGoalRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	contribution, err := h.goalService.Contribute(user, req.GoalID, service.ContributionInput{
+		FromAccountID: req.FromAccountID,
+		Amount:        req.Amount,
+		Rate:          req.rate,
+		Date:          req.date,
+		Note:          req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка взноса в цель", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contribution)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// maxInviteTokenLength ограничение длины токена приглашения
+const maxInviteTokenLength = 256
+
+// InviteHandler обрабатывает HTTP запросы, связанные с приглашениями в семью
+type InviteHandler struct {
+	inviteService service.InviteService
+}
+
+// NewInviteHandler создает новый экземпляр InviteHandler
+func NewInviteHandler(inviteService service.InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+// CreateInviteRequest представляет запрос на приглашение в семью
+type CreateInviteRequest struct {
+	FamilyID uint              `json:"family_id"`
+	Email    string            `json:"email"`
+	Role     models.FamilyRole `json:"role"`
+}
+
+// Validate проверяет запрос на приглашение в семью
+func (req *CreateInviteRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateEmail(&errs, "email", &req.Email, true)
+	if !req.Role.Valid() {
+		errs.Add("role", "ожидается owner, adult, child или viewer")
+	}
+	return errs
+}
+
+// RevokeInviteRequest представляет запрос на отзыв приглашения
+type RevokeInviteRequest struct {
+	InviteID uint `json:"invite_id"`
+}
+
+// Validate проверяет запрос на отзыв приглашения
+func (req *RevokeInviteRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "invite_id", req.InviteID)
+	return errs
+}
+
+// RespondInviteRequest представляет запрос на принятие или отклонение приглашения
+// Приглашение указывается либо токеном из письма, либо идентификатором
+type RespondInviteRequest struct {
+	Token    string `json:"token"`
+	InviteID uint   `json:"invite_id"`
+}
+
+// Validate проверяет, что указан ровно один способ выбрать приглашение
+func (req *RespondInviteRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	req.Token = strings.TrimSpace(req.Token)
+	switch {
+	case req.Token == "" && req.InviteID == 0:
+		errs.Add("token", "укажите token или invite_id")
+	case req.Token != "" && req.InviteID != 0:
+		errs.Add("token", "укажите только одно из полей token и invite_id")
+	case len(req.Token) > maxInviteTokenLength:
+		errs.Add("token", "некорректный токен")
+	}
+	return errs
+}
+
+// CreateInviteHandler обрабатывает запрос на приглашение в семью
+func (h *InviteHandler) CreateInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	invite, err := h.inviteService.Create(user, req.FamilyID, req.Email, req.Role)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания приглашения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invite)
+}
+
+// ListFamilyInvitesHandler возвращает действующие приглашения семьи
+func (h *InviteHandler) ListFamilyInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	invites, err := h.inviteService.ListForFamily(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка приглашений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invites)
+}
+
+// RevokeInviteHandler обрабатывает запрос на отзыв приглашения
+func (h *InviteHandler) RevokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req RevokeInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.inviteService.Revoke(user, req.InviteID); err != nil {
+		respondWithServiceError(w, "Ошибка отзыва приглашения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Приглашение отозвано"})
+}
+
+// ListMyInvitesHandler возвращает действующие приглашения текущего пользователя
+func (h *InviteHandler) ListMyInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	invites, err := h.inviteService.ListMine(user)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка приглашений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invites)
+}
+
+// AcceptInviteHandler обрабатывает запрос на принятие приглашения
+func (h *InviteHandler) AcceptInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req RespondInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	member, err := h.inviteService.Accept(user, req.Token, req.InviteID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка принятия приглашения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// DeclineInviteHandler обрабатывает запрос на отклонение приглашения
+func (h *InviteHandler) DeclineInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req RespondInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.inviteService.Decline(user, req.Token, req.InviteID); err != nil {
+		respondWithServiceError(w, "Ошибка отклонения приглашения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Приглашение отклонено"})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxNotificationsPage наибольший размер страницы ленты уведомлений
+	maxNotificationsPage = 100
+
+	// maxMarkReadIDs ограничение количества уведомлений в одном запросе на прочтение
+	maxMarkReadIDs = 500
+)
+
+// NotificationHandler обрабатывает HTTP запросы, связанные с уведомлениями пользователя
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler создает новый экземпляр NotificationHandler
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// MarkReadRequest представляет запрос на отметку уведомлений прочитанными
+// Пустой ids отмечает прочитанными все уведомления пользователя
+type MarkReadRequest struct {
+	IDs []uint `json:"ids"`
+}
+
+// Validate проверяет запрос на отметку уведомлений прочитанными
+func (req *MarkReadRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if len(req.IDs) > maxMarkReadIDs {
+		errs.Add("ids", fmt.Sprintf("не больше %d уведомлений за раз", maxMarkReadIDs))
+	}
+	for i, id := range req.IDs {
+		validateID(&errs, fmt.Sprintf("ids[%d]", i), id)
+	}
+	return errs
+}
+
+// UpdatePreferenceRequest представляет запрос на изменение настроек доставки уведомлений
+// Не переданные способы доставки не изменяются
+type UpdatePreferenceRequest struct {
+	Type  models.NotificationType `json:"type"`
+	Email *bool                   `json:"email"`
+	InApp *bool                   `json:"in_app"`
+}
+
+// Validate проверяет запрос на изменение настроек уведомлений
+func (req *UpdatePreferenceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if !req.Type.Valid() {
+		errs.Add("type", "неизвестный вид уведомлений")
+	}
+	if req.Email == nil && req.InApp == nil {
+		errs.Add("email", "укажите email или in_app")
+	}
+	return errs
+}
+
+// ListNotificationsHandler возвращает ленту уведомлений текущего пользователя
+func (h *NotificationHandler) ListNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	unreadOnly := queryBool(r, &errs, "unread")
+	before := queryOptionalID(r, &errs, "before")
+	limit := queryLimit(r, &errs, 20, maxNotificationsPage)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	page, err := h.notificationService.List(user, unreadOnly, before, limit)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения уведомлений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// MarkReadHandler отмечает уведомления текущего пользователя прочитанными
+func (h *NotificationHandler) MarkReadHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req MarkReadRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.notificationService.MarkRead(user, req.IDs); err != nil {
+		respondWithServiceError(w, "Ошибка отметки уведомлений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Уведомления отмечены прочитанными"})
+}
+
+// ListPreferencesHandler возвращает настройки доставки уведомлений текущего пользователя
+func (h *NotificationHandler) ListPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	preferences, err := h.notificationService.Preferences(user)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения настроек уведомлений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// UpdatePreferenceHandler изменяет настройки доставки уведомлений одного вида
+func (h *NotificationHandler) UpdatePreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdatePreferenceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	preference, err := h.notificationService.UpdatePreference(user, req.Type, req.Email, req.InApp)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения настроек уведомлений", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preference)
+}
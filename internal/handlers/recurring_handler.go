@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxOccurrencesPage наибольшее количество повторений в одном ответе
+	maxOccurrencesPage = 366
+
+	// defaultOccurrencesHorizon период, за который по умолчанию показываются предстоящие повторения
+	defaultOccurrencesHorizon = 90 * 24 * time.Hour
+)
+
+// RecurringHandler обрабатывает HTTP запросы, связанные с регулярными операциями
+type RecurringHandler struct {
+	recurringService service.RecurringService
+}
+
+// NewRecurringHandler создает новый экземпляр RecurringHandler
+func NewRecurringHandler(recurringService service.RecurringService) *RecurringHandler {
+	return &RecurringHandler{recurringService: recurringService}
+}
+
+// RecurrenceRuleRequest правило повторения в запросе
+type RecurrenceRuleRequest struct {
+	Frequency   models.RecurrenceFrequency `json:"frequency"`
+	Interval    int                        `json:"interval"`
+	MonthDay    int                        `json:"month_day"`
+	BusinessDay bool                       `json:"business_day"`
+	StartDate   string                     `json:"start_date"`
+	EndDate     string                     `json:"end_date"`
+}
+
+// validateRecurrenceRule проверяет правило повторения и преобразует его в модель
+// Интервал по умолчанию равен 1
+func validateRecurrenceRule(errs *ValidationErrors, field string, req *RecurrenceRuleRequest) models.RecurrenceRule {
+	rule := models.RecurrenceRule{
+		Frequency:   req.Frequency,
+		Interval:    req.Interval,
+		MonthDay:    req.MonthDay,
+		BusinessDay: req.BusinessDay,
+	}
+	if !req.Frequency.Valid() {
+		errs.Add(field+".frequency", "ожидается daily, weekly, monthly или yearly")
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	if rule.Interval < 1 || rule.Interval > 999 {
+		errs.Add(field+".interval", "ожидается число от 1 до 999")
+	}
+	if rule.MonthDay < -1 || rule.MonthDay > 31 {
+		errs.Add(field+".month_day", "ожидается день месяца от 1 до 31 или -1 для последнего дня")
+	}
+	if start := validateDate(errs, field+".start_date", req.StartDate); start != nil {
+		rule.StartDate = *start
+	} else if req.StartDate == "" {
+		errs.Add(field+".start_date", "поле обязательно для заполнения")
+	}
+	rule.EndDate = validateDate(errs, field+".end_date", req.EndDate)
+	return rule
+}
+
+// CreateRecurringRequest представляет запрос на создание регулярной операции
+type CreateRecurringRequest struct {
+	AccountID  uint                  `json:"account_id"`
+	Direction  models.Direction      `json:"direction"`
+	Amount     int64                 `json:"amount"`
+	CategoryID *uint                 `json:"category_id"`
+	Payee      string                `json:"payee"`
+	Note       string                `json:"note"`
+	Rule       RecurrenceRuleRequest `json:"rule"`
+
+	rule models.RecurrenceRule
+}
+
+// Validate проверяет запрос на создание регулярной операции
+func (req *CreateRecurringRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	validateDirection(&errs, "direction", req.Direction, true)
+	validateAmount(&errs, "amount", req.Amount, true)
+	if req.CategoryID != nil {
+		validateID(&errs, "category_id", *req.CategoryID)
+	}
+	validateTitle(&errs, "payee", &req.Payee, false, maxPayeeLength)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	req.rule = validateRecurrenceRule(&errs, "rule", &req.Rule)
+	return errs
+}
+
+// UpdateRecurringRequest представляет запрос на изменение регулярной операции
+// scope = all (по умолчанию) изменяет все будущие повторения; scope = this изменяет
+// только повторение в дату date: сумму, категорию, получателя и комментарий
+type UpdateRecurringRequest struct {
+	RecurringID uint                   `json:"recurring_id"`
+	Scope       string                 `json:"scope"`
+	Date        string                 `json:"date"`
+	AccountID   uint                   `json:"account_id"`
+	Direction   models.Direction       `json:"direction"`
+	Amount      int64                  `json:"amount"`
+	CategoryID  *uint                  `json:"category_id"`
+	Payee       *string                `json:"payee"`
+	Note        *string                `json:"note"`
+	Rule        *RecurrenceRuleRequest `json:"rule"`
+
+	date *time.Time
+	rule *models.RecurrenceRule
+}
+
+// Validate проверяет запрос на изменение регулярной операции
+func (req *UpdateRecurringRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "recurring_id", req.RecurringID)
+	switch req.Scope {
+	case "", "all":
+		validateDirection(&errs, "direction", req.Direction, false)
+		if req.Rule != nil {
+			rule := validateRecurrenceRule(&errs, "rule", req.Rule)
+			req.rule = &rule
+		}
+	case "this":
+		req.date = validateDate(&errs, "date", req.Date)
+		if req.date == nil && req.Date == "" {
+			errs.Add("date", "поле обязательно для изменения одного повторения")
+		}
+		if req.AccountID != 0 || req.Direction != "" || req.Rule != nil {
+			errs.Add("scope", "у одного повторения можно изменить только сумму, категорию, получателя и комментарий")
+		}
+		if req.CategoryID != nil && *req.CategoryID == 0 {
+			errs.Add("category_id", "у одного повторения категорию можно только заменить")
+		}
+	default:
+		errs.Add("scope", "ожидается all или this")
+	}
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	if req.Payee != nil {
+		validateTitle(&errs, "payee", req.Payee, false, maxPayeeLength)
+	}
+	if req.Note != nil {
+		validateNote(&errs, "note", req.Note, maxNoteLength)
+	}
+	return errs
+}
+
+// SkipOccurrenceRequest представляет запрос на пропуск повторения регулярной операции
+// skip = false возвращает ранее пропущенное повторение
+type SkipOccurrenceRequest struct {
+	RecurringID uint   `json:"recurring_id"`
+	Date        string `json:"date"`
+	Skip        *bool  `json:"skip"`
+
+	date *time.Time
+}
+
+// Validate проверяет запрос на пропуск повторения
+func (req *SkipOccurrenceRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "recurring_id", req.RecurringID)
+	req.date = validateDate(&errs, "date", req.Date)
+	if req.date == nil && req.Date == "" {
+		errs.Add("date", "поле обязательно для заполнения")
+	}
+	if req.Skip == nil {
+		errs.Add("skip", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// PauseRecurringRequest представляет запрос на приостановку или возобновление регулярной операции
+type PauseRecurringRequest struct {
+	RecurringID uint  `json:"recurring_id"`
+	Paused      *bool `json:"paused"`
+}
+
+// Validate проверяет запрос на приостановку регулярной операции
+func (req *PauseRecurringRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "recurring_id", req.RecurringID)
+	if req.Paused == nil {
+		errs.Add("paused", "поле обязательно для заполнения")
+	}
+	return errs
+}
+
+// DeleteRecurringRequest представляет запрос на удаление регулярной операции
+type DeleteRecurringRequest struct {
+	RecurringID uint `json:"recurring_id"`
+}
+
+// Validate проверяет запрос на удаление регулярной операции
+func (req *DeleteRecurringRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "recurring_id", req.RecurringID)
+	return errs
+}
+
+// ListRecurringHandler возвращает регулярные операции семьи
+func (h *RecurringHandler) ListRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	list, err := h.recurringService.List(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка регулярных операций", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetRecurringHandler возвращает регулярную операцию
+func (h *RecurringHandler) GetRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "recurring_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	recurring, err := h.recurringService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recurring)
+}
+
+// ListOccurrencesHandler возвращает повторения регулярной операции за период date_from — date_to
+// По умолчанию показываются повторения на 90 дней вперёд от сегодняшнего дня
+func (h *RecurringHandler) ListOccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "recurring_id")
+	from := queryDate(r, &errs, "date_from")
+	to := queryDate(r, &errs, "date_to")
+	limit := queryLimit(r, &errs, 50, maxOccurrencesPage)
+	if from == nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		from = &today
+	}
+	if to == nil {
+		end := from.Add(defaultOccurrencesHorizon)
+		to = &end
+	}
+	if to.Before(*from) {
+		errs.Add("date_to", "дата окончания не может быть раньше даты начала")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	occurrences, err := h.recurringService.Occurrences(user, id, *from, *to, limit)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения повторений регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrences)
+}
+
+// CreateRecurringHandler обрабатывает запрос на создание регулярной операции
+func (h *RecurringHandler) CreateRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateRecurringRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	recurring, err := h.recurringService.Create(user, service.RecurringInput{
+		AccountID:  req.AccountID,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		CategoryID: req.CategoryID,
+		Payee:      req.Payee,
+		Note:       req.Note,
+		Rule:       req.rule,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recurring)
+}
+
+// UpdateRecurringHandler обрабатывает запрос на изменение регулярной операции или одного её повторения
+func (h *RecurringHandler) UpdateRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateRecurringRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	var result interface{}
+	var err error
+	if req.Scope == "this" {
+		result, err = h.recurringService.UpdateOccurrence(user, req.RecurringID, *req.date, service.OccurrenceChanges{
+			Amount:     req.Amount,
+			CategoryID: req.CategoryID,
+			Payee:      req.Payee,
+			Note:       req.Note,
+		})
+	} else {
+		result, err = h.recurringService.Update(user, req.RecurringID, service.RecurringChanges{
+			AccountID:  req.AccountID,
+			Direction:  req.Direction,
+			Amount:     req.Amount,
+			CategoryID: req.CategoryID,
+			Payee:      req.Payee,
+			Note:       req.Note,
+			Rule:       req.rule,
+		})
+	}
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// SkipOccurrenceHandler обрабатывает запрос на пропуск повторения регулярной операции
+func (h *RecurringHandler) SkipOccurrenceHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req SkipOccurrenceRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	occurrence, err := h.recurringService.Skip(user, req.RecurringID, *req.date, *req.Skip)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка пропуска повторения", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrence)
+}
+
+// PauseRecurringHandler обрабатывает запрос на приостановку или возобновление регулярной операции
+func (h *RecurringHandler) PauseRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req PauseRecurringRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	recurring, err := h.recurringService.Pause(user, req.RecurringID, *req.Paused)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка приостановки регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recurring)
+}
+
+// DeleteRecurringHandler обрабатывает запрос на удаление регулярной операции
+func (h *RecurringHandler) DeleteRecurringHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteRecurringRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.recurringService.Delete(user, req.RecurringID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления регулярной операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Регулярная операция удалена"})
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// SpendingLimitHandler обрабатывает HTTP запросы, связанные с лимитами расходов участников
+type SpendingLimitHandler struct {
+	limitService service.SpendingLimitService
+}
+
+// NewSpendingLimitHandler создает новый экземпляр SpendingLimitHandler
+func NewSpendingLimitHandler(limitService service.SpendingLimitService) *SpendingLimitHandler {
+	return &SpendingLimitHandler{limitService: limitService}
+}
+
+// validateLimitPeriod проверяет период лимита
+func validateLimitPeriod(errs *ValidationErrors, field string, value models.LimitPeriod, required bool) {
+	if value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if !value.Valid() {
+		errs.Add(field, "ожидается day, week или month")
+	}
+}
+
+// validateLimitAction проверяет действие при превышении лимита
+func validateLimitAction(errs *ValidationErrors, field string, value models.LimitAction, required bool) {
+	if value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if !value.Valid() {
+		errs.Add(field, "ожидается reject или flag")
+	}
+}
+
+// CreateSpendingLimitRequest представляет запрос на назначение лимита расходов
+type CreateSpendingLimitRequest struct {
+	FamilyID  uint               `json:"family_id"`
+	UserID    uint               `json:"user_id"`
+	AccountID *uint              `json:"account_id"`
+	Amount    int64              `json:"amount"`
+	Currency  string             `json:"currency"`
+	Period    models.LimitPeriod `json:"period"`
+	Action    models.LimitAction `json:"action"`
+}
+
+// Validate проверяет запрос на назначение лимита
+func (req *CreateSpendingLimitRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "family_id", req.FamilyID)
+	validateID(&errs, "user_id", req.UserID)
+	if req.AccountID != nil {
+		validateID(&errs, "account_id", *req.AccountID)
+	}
+	validateAmount(&errs, "amount", req.Amount, true)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	validateLimitPeriod(&errs, "period", req.Period, true)
+	validateLimitAction(&errs, "action", req.Action, false)
+	return errs
+}
+
+// UpdateSpendingLimitRequest представляет запрос на изменение лимита расходов
+type UpdateSpendingLimitRequest struct {
+	LimitID uint               `json:"limit_id"`
+	Amount  int64              `json:"amount"`
+	Period  models.LimitPeriod `json:"period"`
+	Action  models.LimitAction `json:"action"`
+}
+
+// Validate проверяет запрос на изменение лимита
+func (req *UpdateSpendingLimitRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "limit_id", req.LimitID)
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	validateLimitPeriod(&errs, "period", req.Period, false)
+	validateLimitAction(&errs, "action", req.Action, false)
+	return errs
+}
+
+// DeleteSpendingLimitRequest представляет запрос на удаление лимита расходов
+type DeleteSpendingLimitRequest struct {
+	LimitID uint `json:"limit_id"`
+}
+
+// Validate проверяет запрос на удаление лимита
+func (req *DeleteSpendingLimitRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "limit_id", req.LimitID)
+	return errs
+}
+
+// ListLimitsHandler возвращает лимиты расходов семьи
+// Параметр member_id оставляет лимиты одного участника
+func (h *SpendingLimitHandler) ListLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	memberID := queryOptionalID(r, &errs, "member_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	limits, err := h.limitService.List(user, familyID, memberID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка лимитов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limits)
+}
+
+// RemainingHandler возвращает остатки по лимитам участника за текущий период
+// Без member_id — по лимитам самого пользователя
+func (h *SpendingLimitHandler) RemainingHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	memberID := queryOptionalID(r, &errs, "member_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	usage, err := h.limitService.Remaining(user, familyID, memberID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения остатков по лимитам", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// CreateLimitHandler обрабатывает запрос на назначение лимита расходов
+func (h *SpendingLimitHandler) CreateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateSpendingLimitRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	limit, err := h.limitService.Create(user, req.FamilyID, service.SpendingLimitInput{
+		UserID:    req.UserID,
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Period:    req.Period,
+		Action:    req.Action,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка назначения лимита", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(limit)
+}
+
+// UpdateLimitHandler обрабатывает запрос на изменение лимита расходов
+func (h *SpendingLimitHandler) UpdateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateSpendingLimitRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	limit, err := h.limitService.Update(user, req.LimitID, service.SpendingLimitChanges{
+		Amount: req.Amount,
+		Period: req.Period,
+		Action: req.Action,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения лимита", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limit)
+}
+
+// DeleteLimitHandler обрабатывает запрос на удаление лимита расходов
+func (h *SpendingLimitHandler) DeleteLimitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteSpendingLimitRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.limitService.Delete(user, req.LimitID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления лимита", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Лимит удалён"})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// maxSpendingRequestsPage максимальное количество запросов на расход в ответе
+const maxSpendingRequestsPage = 200
+
+// SpendingRequestHandler обрабатывает HTTP запросы, связанные с запросами детей на расход
+type SpendingRequestHandler struct {
+	requestService service.SpendingRequestService
+}
+
+// NewSpendingRequestHandler создает новый экземпляр SpendingRequestHandler
+func NewSpendingRequestHandler(requestService service.SpendingRequestService) *SpendingRequestHandler {
+	return &SpendingRequestHandler{requestService: requestService}
+}
+
+// CreateSpendingRequest представляет запрос ребёнка на расход
+type CreateSpendingRequest struct {
+	AccountID  uint   `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	CategoryID *uint  `json:"category_id"`
+	Payee      string `json:"payee"`
+	Note       string `json:"note"`
+}
+
+// Validate проверяет запрос на расход
+func (req *CreateSpendingRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	validateAmount(&errs, "amount", req.Amount, true)
+	if req.CategoryID != nil {
+		validateID(&errs, "category_id", *req.CategoryID)
+	}
+	validateTitle(&errs, "payee", &req.Payee, false, maxPayeeLength)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// ReviewSpendingRequest представляет решение родителя по запросу на расход
+type ReviewSpendingRequest struct {
+	RequestID uint   `json:"request_id"`
+	Note      string `json:"note"`
+}
+
+// Validate проверяет решение по запросу
+func (req *ReviewSpendingRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "request_id", req.RequestID)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// CancelSpendingRequest представляет отзыв запроса на расход
+type CancelSpendingRequest struct {
+	RequestID uint `json:"request_id"`
+}
+
+// Validate проверяет отзыв запроса
+func (req *CancelSpendingRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "request_id", req.RequestID)
+	return errs
+}
+
+// ListSpendingRequestsHandler возвращает запросы на расход
+// Параметр status фильтрует по состоянию: pending, approved, rejected или cancelled
+func (h *SpendingRequestHandler) ListSpendingRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryID(r, &errs, "family_id")
+	status := models.SpendingStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.Valid() {
+		errs.Add("status", "ожидается pending, approved, rejected или cancelled")
+	}
+	limit := queryLimit(r, &errs, 50, maxSpendingRequestsPage)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	list, err := h.requestService.List(user, familyID, status, limit)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка запросов на расход", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// CreateSpendingRequestHandler обрабатывает запрос ребёнка на расход
+func (h *SpendingRequestHandler) CreateSpendingRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateSpendingRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	request, err := h.requestService.Create(user, service.SpendingRequestInput{
+		AccountID:  req.AccountID,
+		Amount:     req.Amount,
+		CategoryID: req.CategoryID,
+		Payee:      req.Payee,
+		Note:       req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания запроса на расход", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// ApproveSpendingRequestHandler обрабатывает одобрение запроса на расход
+func (h *SpendingRequestHandler) ApproveSpendingRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ReviewSpendingRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	request, err := h.requestService.Approve(user, req.RequestID, req.Note)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка одобрения запроса на расход", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// RejectSpendingRequestHandler обрабатывает отклонение запроса на расход
+func (h *SpendingRequestHandler) RejectSpendingRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ReviewSpendingRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	request, err := h.requestService.Reject(user, req.RequestID, req.Note)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка отклонения запроса на расход", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// CancelSpendingRequestHandler обрабатывает отзыв запроса на расход
+func (h *SpendingRequestHandler) CancelSpendingRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CancelSpendingRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	request, err := h.requestService.Cancel(user, req.RequestID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка отзыва запроса на расход", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
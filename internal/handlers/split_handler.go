@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+// maxSplitShares ограничение количества участников одного разделения
+const maxSplitShares = 50
+
+// SplitHandler обрабатывает HTTP запросы, связанные с разделением расходов
+type SplitHandler struct {
+	splitService service.SplitService
+}
+
+// NewSplitHandler создает новый экземпляр SplitHandler
+func NewSplitHandler(splitService service.SplitService) *SplitHandler {
+	return &SplitHandler{splitService: splitService}
+}
+
+// SplitShareRequest доля участника в запросе
+type SplitShareRequest struct {
+	UserID uint `json:"user_id"`
+	// Amount доля в минимальных единицах валюты для способа exact
+	Amount int64 `json:"amount"`
+	// Percent доля в процентах с точностью до сотых для способа percent, например "33.33"
+	Percent string `json:"percent"`
+}
+
+// validatePercent разбирает процент от 0 до 100 с точностью до сотых
+func validatePercent(errs *ValidationErrors, field, value string) *big.Rat {
+	value = strings.Replace(strings.TrimSpace(value), ",", ".", 1)
+	percent, ok := new(big.Rat).SetString(value)
+	if value == "" || !ok || strings.ContainsAny(value, "/eE") ||
+		percent.Sign() < 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+		errs.Add(field, "ожидается число от 0 до 100, например 33.33")
+		return nil
+	}
+	if !new(big.Rat).Mul(percent, big.NewRat(100, 1)).IsInt() {
+		errs.Add(field, "процент указывается с точностью до сотых")
+		return nil
+	}
+	return percent
+}
+
+// SetSplitRequest представляет запрос на разделение расхода
+type SetSplitRequest struct {
+	TransactionID uint                `json:"transaction_id"`
+	Method        models.SplitMethod  `json:"method"`
+	PaidBy        uint                `json:"paid_by"`
+	Shares        []SplitShareRequest `json:"shares"`
+
+	shares []service.SplitShareInput
+}
+
+// Validate проверяет запрос на разделение расхода
+func (req *SetSplitRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "transaction_id", req.TransactionID)
+	if !req.Method.Valid() {
+		errs.Add("method", "ожидается equal, exact или percent")
+	}
+	switch {
+	case len(req.Shares) == 0:
+		errs.Add("shares", "укажите хотя бы одного участника")
+	case len(req.Shares) > maxSplitShares:
+		errs.Add("shares", fmt.Sprintf("не больше %d участников", maxSplitShares))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	req.shares = make([]service.SplitShareInput, 0, len(req.Shares))
+	for i, share := range req.Shares {
+		prefix := fmt.Sprintf("shares[%d]", i)
+		validateID(&errs, prefix+".user_id", share.UserID)
+		input := service.SplitShareInput{UserID: share.UserID}
+		switch req.Method {
+		case models.SplitExact:
+			validateAmount(&errs, prefix+".amount", share.Amount, false)
+			if share.Amount < 0 {
+				errs.Add(prefix+".amount", "сумма не может быть отрицательной")
+			}
+			input.Amount = share.Amount
+		case models.SplitPercent:
+			input.Percent = validatePercent(&errs, prefix+".percent", share.Percent)
+		}
+		req.shares = append(req.shares, input)
+	}
+	return errs
+}
+
+// CancelSplitRequest представляет запрос на отмену разделения расхода
+type CancelSplitRequest struct {
+	TransactionID uint `json:"transaction_id"`
+}
+
+// Validate проверяет запрос на отмену разделения
+func (req *CancelSplitRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "transaction_id", req.TransactionID)
+	return errs
+}
+
+// GetSplitHandler возвращает действующее разделение операции
+func (h *SplitHandler) GetSplitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "transaction_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	split, err := h.splitService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения разделения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(split)
+}
+
+// SplitHistoryHandler возвращает все версии разделения операции
+func (h *SplitHandler) SplitHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "transaction_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	splits, err := h.splitService.History(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения истории разделения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(splits)
+}
+
+// SetSplitHandler обрабатывает запрос на разделение расхода между участниками
+func (h *SplitHandler) SetSplitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req SetSplitRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	split, err := h.splitService.Set(user, req.TransactionID, service.SplitInput{
+		Method: req.Method,
+		PaidBy: req.PaidBy,
+		Shares: req.shares,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка разделения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(split)
+}
+
+// CancelSplitHandler обрабатывает запрос на отмену разделения расхода
+func (h *SplitHandler) CancelSplitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CancelSplitRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.splitService.Cancel(user, req.TransactionID); err != nil {
+		respondWithServiceError(w, "Ошибка отмены разделения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Разделение операции отменено"})
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+	"family_finance_back/internal/statement"
+)
+
+// maxStatementFileSize ограничивает размер загружаемой выписки (5 МБ)
+const maxStatementFileSize = 5 << 20
+
+// StatementImportHandler обрабатывает HTTP запросы импорта банковских выписок
+type StatementImportHandler struct {
+	importService service.StatementImportService
+}
+
+// NewStatementImportHandler создает новый экземпляр StatementImportHandler
+func NewStatementImportHandler(importService service.StatementImportService) *StatementImportHandler {
+	return &StatementImportHandler{importService: importService}
+}
+
+// validateStatementMapping нормализует и проверяет сопоставление столбцов выписки
+// Кодировку можно указать как cp1251 или utf8, табуляцию-разделитель — как tab
+func validateStatementMapping(errs *ValidationErrors, prefix string, m *models.StatementMapping) {
+	m.Encoding = strings.ToLower(strings.TrimSpace(m.Encoding))
+	switch m.Encoding {
+	case "cp1251":
+		m.Encoding = statement.EncodingWindows1251
+	case "utf8":
+		m.Encoding = statement.EncodingUTF8
+	}
+	if m.Encoding != "" && !statement.ValidEncoding(m.Encoding) {
+		errs.Add(prefix+"encoding", "ожидается utf-8 или windows-1251")
+	}
+
+	if m.Delimiter == "tab" || m.Delimiter == `\t` {
+		m.Delimiter = "\t"
+	}
+	if m.Delimiter != "" && !statement.ValidDelimiter(m.Delimiter) {
+		errs.Add(prefix+"delimiter", "ожидается «;», «,», «|» или tab")
+	}
+
+	m.DateFormat = strings.ToUpper(strings.TrimSpace(m.DateFormat))
+	if m.DateFormat != "" && !statement.ValidDateFormat(m.DateFormat) {
+		errs.Add(prefix+"date_format", "ожидается один из форматов "+strings.Join(statement.DateFormats(), ", "))
+	}
+
+	m.DecimalSeparator = strings.TrimSpace(m.DecimalSeparator)
+	if m.DecimalSeparator != "" && !statement.ValidDecimalSeparator(m.DecimalSeparator) {
+		errs.Add(prefix+"decimal_separator", "ожидается «,» или «.»")
+	}
+
+	validateTitle(errs, prefix+"date_column", &m.DateColumn, false, 100)
+	validateTitle(errs, prefix+"amount_column", &m.AmountColumn, false, 100)
+	validateTitle(errs, prefix+"debit_column", &m.DebitColumn, false, 100)
+	validateTitle(errs, prefix+"credit_column", &m.CreditColumn, false, 100)
+	validateTitle(errs, prefix+"payee_column", &m.PayeeColumn, false, 100)
+	validateTitle(errs, prefix+"note_column", &m.NoteColumn, false, 100)
+	validateTitle(errs, prefix+"category_column", &m.CategoryColumn, false, 100)
+	if m.AmountColumn != "" && (m.DebitColumn != "" || m.CreditColumn != "") {
+		errs.Add(prefix+"amount_column", "задайте либо столбец суммы, либо столбцы списания и зачисления")
+	}
+}
+
+// UploadStatementRequest представляет запрос на загрузку выписки; поля передаются в multipart/form-data
+type UploadStatementRequest struct {
+	AccountID uint
+	ProfileID uint
+	FileName  string
+	Data      []byte
+	Mapping   models.StatementMapping
+}
+
+// Validate проверяет запрос на загрузку выписки
+func (req *UploadStatementRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	validateTitle(&errs, "file", &req.FileName, false, 255)
+	if len(req.Data) == 0 {
+		errs.Add("file", "ожидается непустой файл выписки")
+	}
+	validateStatementMapping(&errs, "", &req.Mapping)
+	return errs
+}
+
+// RemapImportRequest представляет запрос на повторный разбор выписки с другим сопоставлением
+type RemapImportRequest struct {
+	ImportID  uint                    `json:"import_id"`
+	ProfileID uint                    `json:"profile_id"`
+	Mapping   models.StatementMapping `json:"mapping"`
+}
+
+// Validate проверяет запрос на повторный разбор выписки
+func (req *RemapImportRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "import_id", req.ImportID)
+	validateStatementMapping(&errs, "mapping.", &req.Mapping)
+	return errs
+}
+
+// UpdateImportRowRequest представляет запрос на изменение строки выписки
+type UpdateImportRowRequest struct {
+	RowID      uint  `json:"row_id"`
+	CategoryID *uint `json:"category_id"`
+	Skip       *bool `json:"skip"`
+}
+
+// Validate проверяет запрос на изменение строки выписки
+func (req *UpdateImportRowRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "row_id", req.RowID)
+	if req.CategoryID == nil && req.Skip == nil {
+		errs.Add("category_id", "укажите category_id или skip")
+	}
+	return errs
+}
+
+// ImportIDRequest представляет запрос с идентификатором импорта: проведение или удаление
+type ImportIDRequest struct {
+	ImportID uint `json:"import_id"`
+}
+
+// Validate проверяет запрос с идентификатором импорта
+func (req *ImportIDRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "import_id", req.ImportID)
+	return errs
+}
+
+// CreateStatementProfileRequest представляет запрос на сохранение профиля сопоставления
+type CreateStatementProfileRequest struct {
+	Name    string                  `json:"name"`
+	Mapping models.StatementMapping `json:"mapping"`
+}
+
+// Validate проверяет запрос на сохранение профиля сопоставления
+func (req *CreateStatementProfileRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateTitle(&errs, "name", &req.Name, true, 100)
+	validateStatementMapping(&errs, "mapping.", &req.Mapping)
+	return errs
+}
+
+// UpdateStatementProfileRequest представляет запрос на изменение профиля сопоставления
+// Переданное сопоставление заменяет прежнее целиком
+type UpdateStatementProfileRequest struct {
+	ProfileID uint                     `json:"profile_id"`
+	Name      string                   `json:"name"`
+	Mapping   *models.StatementMapping `json:"mapping"`
+}
+
+// Validate проверяет запрос на изменение профиля сопоставления
+func (req *UpdateStatementProfileRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "profile_id", req.ProfileID)
+	validateTitle(&errs, "name", &req.Name, false, 100)
+	if req.Mapping != nil {
+		validateStatementMapping(&errs, "mapping.", req.Mapping)
+	}
+	return errs
+}
+
+// DeleteStatementProfileRequest представляет запрос на удаление профиля сопоставления
+type DeleteStatementProfileRequest struct {
+	ProfileID uint `json:"profile_id"`
+}
+
+// Validate проверяет запрос на удаление профиля сопоставления
+func (req *DeleteStatementProfileRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "profile_id", req.ProfileID)
+	return errs
+}
+
+// formID читает идентификатор из поля формы; отсутствующее поле возвращается как 0
+func formID(r *http.Request, errs *ValidationErrors, field string) uint {
+	v := strings.TrimSpace(r.FormValue(field))
+	if v == "" {
+		return 0
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		errs.Add(field, "ожидается положительное целое число")
+		return 0
+	}
+	return uint(id)
+}
+
+// readUploadRequest читает запрос на загрузку выписки из multipart/form-data
+// При ошибке сам отправляет ответ клиенту и возвращает false
+func readUploadRequest(w http.ResponseWriter, r *http.Request, req *UploadStatementRequest) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementFileSize+maxRequestBodySize)
+	if err := r.ParseMultipartForm(maxRequestBodySize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Слишком большой запрос",
+				fmt.Sprintf("Размер файла выписки не должен превышать %d байт", maxStatementFileSize))
+			return false
+		}
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос",
+			"Ожидается multipart/form-data с файлом выписки в поле file")
+		return false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var errs ValidationErrors
+	req.AccountID = formID(r, &errs, "account_id")
+	req.ProfileID = formID(r, &errs, "profile_id")
+	req.Mapping = models.StatementMapping{
+		Encoding:         r.FormValue("encoding"),
+		Delimiter:        r.FormValue("delimiter"),
+		DateFormat:       r.FormValue("date_format"),
+		DecimalSeparator: r.FormValue("decimal_separator"),
+		DateColumn:       r.FormValue("date_column"),
+		AmountColumn:     r.FormValue("amount_column"),
+		DebitColumn:      r.FormValue("debit_column"),
+		CreditColumn:     r.FormValue("credit_column"),
+		PayeeColumn:      r.FormValue("payee_column"),
+		NoteColumn:       r.FormValue("note_column"),
+		CategoryColumn:   r.FormValue("category_column"),
+	}
+	file, header, err := r.FormFile("file")
+	if err == nil {
+		defer file.Close()
+		req.FileName = header.Filename
+		if req.Data, err = io.ReadAll(io.LimitReader(file, maxStatementFileSize+1)); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Не удалось прочитать файл выписки")
+			return false
+		}
+		if len(req.Data) > maxStatementFileSize {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Слишком большой запрос",
+				fmt.Sprintf("Размер файла выписки не должен превышать %d байт", maxStatementFileSize))
+			return false
+		}
+	}
+	errs = append(errs, req.Validate()...)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return false
+	}
+	return true
+}
+
+// ListImportsHandler возвращает последние импорты выписок пользователя
+// Параметр family_id оставляет импорты по счетам одной семьи
+func (h *StatementImportHandler) ListImportsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	familyID := queryOptionalID(r, &errs, "family_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	imports, err := h.importService.List(user, familyID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка импортов", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(imports)
+}
+
+// GetImportHandler возвращает импорт выписки со строками и предложенными категориями
+func (h *StatementImportHandler) GetImportHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	importID := queryID(r, &errs, "import_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	imp, err := h.importService.Get(user, importID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения импорта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(imp)
+}
+
+// UploadHandler обрабатывает загрузку выписки в CSV
+func (h *StatementImportHandler) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UploadStatementRequest
+	if !readUploadRequest(w, r, &req) {
+		return
+	}
+	imp, err := h.importService.Upload(user, service.StatementUpload{
+		AccountID: req.AccountID,
+		ProfileID: req.ProfileID,
+		FileName:  req.FileName,
+		Data:      req.Data,
+		Mapping:   req.Mapping,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка загрузки выписки", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(imp)
+}
+
+// RemapHandler обрабатывает запрос на повторный разбор выписки с другим сопоставлением
+func (h *StatementImportHandler) RemapHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req RemapImportRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	imp, err := h.importService.Remap(user, req.ImportID, req.ProfileID, req.Mapping)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка разбора выписки", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(imp)
+}
+
+// UpdateRowHandler обрабатывает запрос на изменение категории строки или исключение её из импорта
+func (h *StatementImportHandler) UpdateRowHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateImportRowRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	row, err := h.importService.UpdateRow(user, req.RowID, service.ImportRowChanges{
+		CategoryID: req.CategoryID,
+		Skip:       req.Skip,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения строки выписки", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(row)
+}
+
+// CommitHandler обрабатывает запрос на проведение операций из выписки
+// Если операции проводятся в фоне, отвечает 202 Accepted
+func (h *StatementImportHandler) CommitHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ImportIDRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	imp, err := h.importService.Commit(user, req.ImportID)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка проведения выписки", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if imp.Status == models.ImportCommitting {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(imp)
+}
+
+// DeleteImportHandler обрабатывает запрос на удаление импорта
+func (h *StatementImportHandler) DeleteImportHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req ImportIDRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.importService.Delete(user, req.ImportID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления импорта", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Импорт удалён"})
+}
+
+// ListProfilesHandler возвращает профили сопоставления пользователя
+func (h *StatementImportHandler) ListProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	profiles, err := h.importService.ListProfiles(user)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка профилей", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+// CreateProfileHandler обрабатывает запрос на сохранение профиля сопоставления
+func (h *StatementImportHandler) CreateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateStatementProfileRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	profile, err := h.importService.CreateProfile(user, req.Name, req.Mapping)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка сохранения профиля", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// UpdateProfileHandler обрабатывает запрос на изменение профиля сопоставления
+func (h *StatementImportHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateStatementProfileRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	profile, err := h.importService.UpdateProfile(user, req.ProfileID, req.Name, req.Mapping)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения профиля", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// DeleteProfileHandler обрабатывает запрос на удаление профиля сопоставления
+func (h *StatementImportHandler) DeleteProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteStatementProfileRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.importService.DeleteProfile(user, req.ProfileID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления профиля", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Профиль удалён"})
+}
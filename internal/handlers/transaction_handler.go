@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/service"
+)
+
+const (
+	// maxPayeeLength соответствует размеру колонки payee в models.Transaction
+	maxPayeeLength = 200
+
+	// maxNoteLength соответствует размеру колонки note в models.Transaction
+	maxNoteLength = 1000
+
+	// maxTransactionsPage наибольший размер страницы списка операций
+	maxTransactionsPage = 200
+)
+
+// TransactionHandler обрабатывает HTTP запросы, связанные с операциями
+type TransactionHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewTransactionHandler создает новый экземпляр TransactionHandler
+func NewTransactionHandler(transactionService service.TransactionService) *TransactionHandler {
+	return &TransactionHandler{transactionService: transactionService}
+}
+
+// validateDirection проверяет направление дохода или расхода
+// Переводы создаются отдельным запросом, поэтому transfer здесь не допускается
+func validateDirection(errs *ValidationErrors, field string, value models.Direction, required bool) {
+	if value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if value != models.DirectionIncome && value != models.DirectionExpense {
+		errs.Add(field, "ожидается income или expense")
+	}
+}
+
+// validateRate разбирает необязательный курс обмена валют: положительное десятичное число
+func validateRate(errs *ValidationErrors, field, value string) *big.Rat {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	rate, ok := new(big.Rat).SetString(strings.Replace(value, ",", ".", 1))
+	if !ok || rate.Sign() <= 0 || strings.ContainsAny(value, "/eE") {
+		errs.Add(field, "ожидается положительное десятичное число, например 92.35")
+		return nil
+	}
+	return rate
+}
+
+// CreateTransactionRequest представляет запрос на создание операции
+type CreateTransactionRequest struct {
+	AccountID  uint             `json:"account_id"`
+	Direction  models.Direction `json:"direction"`
+	Amount     int64            `json:"amount"`
+	Currency   string           `json:"currency"`
+	CategoryID *uint            `json:"category_id"`
+	Date       string           `json:"date"`
+	Payee      string           `json:"payee"`
+	Note       string           `json:"note"`
+
+	date *time.Time
+}
+
+// Validate проверяет запрос на создание операции
+func (req *CreateTransactionRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "account_id", req.AccountID)
+	validateDirection(&errs, "direction", req.Direction, true)
+	validateAmount(&errs, "amount", req.Amount, true)
+	validateCurrency(&errs, "currency", &req.Currency, false)
+	if req.CategoryID != nil {
+		validateID(&errs, "category_id", *req.CategoryID)
+	}
+	req.date = validateDate(&errs, "date", req.Date)
+	validateTitle(&errs, "payee", &req.Payee, false, maxPayeeLength)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// UpdateTransactionRequest представляет запрос на изменение операции
+// Все поля, кроме transaction_id, необязательны; category_id = 0 снимает категорию
+type UpdateTransactionRequest struct {
+	TransactionID uint             `json:"transaction_id"`
+	AccountID     uint             `json:"account_id"`
+	Direction     models.Direction `json:"direction"`
+	Amount        int64            `json:"amount"`
+	CategoryID    *uint            `json:"category_id"`
+	Date          string           `json:"date"`
+	Payee         *string          `json:"payee"`
+	Note          *string          `json:"note"`
+
+	date *time.Time
+}
+
+// Validate проверяет запрос на изменение операции
+func (req *UpdateTransactionRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "transaction_id", req.TransactionID)
+	validateDirection(&errs, "direction", req.Direction, false)
+	if req.Amount != 0 {
+		validateAmount(&errs, "amount", req.Amount, true)
+	}
+	req.date = validateDate(&errs, "date", req.Date)
+	if req.Payee != nil {
+		validateTitle(&errs, "payee", req.Payee, false, maxPayeeLength)
+	}
+	if req.Note != nil {
+		validateNote(&errs, "note", req.Note, maxNoteLength)
+	}
+	return errs
+}
+
+// CreateTransferRequest представляет запрос на перевод между счетами
+type CreateTransferRequest struct {
+	FromAccountID uint   `json:"from_account_id"`
+	ToAccountID   uint   `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Rate          string `json:"rate"`
+	Date          string `json:"date"`
+	Payee         string `json:"payee"`
+	Note          string `json:"note"`
+
+	rate *big.Rat
+	date *time.Time
+}
+
+// Validate проверяет запрос на перевод между счетами
+func (req *CreateTransferRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "from_account_id", req.FromAccountID)
+	validateID(&errs, "to_account_id", req.ToAccountID)
+	validateAmount(&errs, "amount", req.Amount, true)
+	req.rate = validateRate(&errs, "rate", req.Rate)
+	req.date = validateDate(&errs, "date", req.Date)
+	validateTitle(&errs, "payee", &req.Payee, false, maxPayeeLength)
+	validateNote(&errs, "note", &req.Note, maxNoteLength)
+	return errs
+}
+
+// DeleteTransactionRequest представляет запрос на удаление операции
+type DeleteTransactionRequest struct {
+	TransactionID uint `json:"transaction_id"`
+}
+
+// Validate проверяет запрос на удаление операции
+func (req *DeleteTransactionRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validateID(&errs, "transaction_id", req.TransactionID)
+	return errs
+}
+
+// ListTransactionsHandler возвращает страницу операций семьи
+// Фильтры: date_from, date_to, account_id, category_id, member_id, direction,
+// amount_min, amount_max; пагинация: cursor, limit
+func (h *TransactionHandler) ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	query := service.TransactionQuery{
+		FamilyID:   queryID(r, &errs, "family_id"),
+		DateFrom:   queryDate(r, &errs, "date_from"),
+		DateTo:     queryDate(r, &errs, "date_to"),
+		AccountID:  queryOptionalID(r, &errs, "account_id"),
+		CategoryID: queryOptionalID(r, &errs, "category_id"),
+		MemberID:   queryOptionalID(r, &errs, "member_id"),
+		Direction:  models.Direction(r.URL.Query().Get("direction")),
+		AmountMin:  queryAmount(r, &errs, "amount_min"),
+		AmountMax:  queryAmount(r, &errs, "amount_max"),
+		Cursor:     r.URL.Query().Get("cursor"),
+		Limit:      queryLimit(r, &errs, 50, maxTransactionsPage),
+	}
+	if query.Direction != "" && !query.Direction.Valid() {
+		errs.Add("direction", "ожидается income, expense или transfer")
+	}
+	if query.DateFrom != nil && query.DateTo != nil && query.DateTo.Before(*query.DateFrom) {
+		errs.Add("date_to", "дата окончания не может быть раньше даты начала")
+	}
+	if query.AmountMin != nil && query.AmountMax != nil && *query.AmountMax < *query.AmountMin {
+		errs.Add("amount_max", "максимальная сумма не может быть меньше минимальной")
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	page, err := h.transactionService.List(user, query)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения списка операций", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetTransactionHandler возвращает операцию
+func (h *TransactionHandler) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var errs ValidationErrors
+	id := queryID(r, &errs, "transaction_id")
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	tx, err := h.transactionService.Get(user, id)
+	if err != nil {
+		respondWithServiceError(w, "Ошибка получения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tx)
+}
+
+// CreateTransactionHandler обрабатывает запрос на создание операции
+func (h *TransactionHandler) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateTransactionRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	tx, err := h.transactionService.Create(user, service.TransactionInput{
+		AccountID:  req.AccountID,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		CategoryID: req.CategoryID,
+		Date:       req.date,
+		Payee:      req.Payee,
+		Note:       req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка создания операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tx)
+}
+
+// CreateTransferHandler обрабатывает запрос на перевод между счетами
+func (h *TransactionHandler) CreateTransferHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req CreateTransferRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	tx, err := h.transactionService.Transfer(user, service.TransferInput{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Rate:          req.rate,
+		Date:          req.date,
+		Payee:         req.Payee,
+		Note:          req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка перевода между счетами", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tx)
+}
+
+// UpdateTransactionHandler обрабатывает запрос на изменение операции
+func (h *TransactionHandler) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req UpdateTransactionRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	tx, err := h.transactionService.Update(user, req.TransactionID, service.TransactionChanges{
+		AccountID:  req.AccountID,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		CategoryID: req.CategoryID,
+		Date:       req.date,
+		Payee:      req.Payee,
+		Note:       req.Note,
+	})
+	if err != nil {
+		respondWithServiceError(w, "Ошибка изменения операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tx)
+}
+
+// DeleteTransactionHandler обрабатывает запрос на удаление операции
+func (h *TransactionHandler) DeleteTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(w, r)
+	if user == nil {
+		return
+	}
+	var req DeleteTransactionRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	if err := h.transactionService.Delete(user, req.TransactionID); err != nil {
+		respondWithServiceError(w, "Ошибка удаления операции", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Операция удалена"})
+}
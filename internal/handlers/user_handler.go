@@ -24,6 +24,16 @@ type UpdateUserRequest struct {
 	Nickname string `json:"nickname"`
 }
 
+// Validate проверяет запрос на обновление данных пользователя
+// Все поля необязательны, но переданные значения должны быть корректными
+func (req *UpdateUserRequest) Validate() ValidationErrors {
+	var errs ValidationErrors
+	validatePersonName(&errs, "name", &req.Name, false)
+	validatePersonName(&errs, "surname", &req.Surname, false)
+	validateNickname(&errs, "nickname", &req.Nickname)
+	return errs
+}
+
 // GetUserHandler обрабатывает запрос на получение данных пользователя
 func (h *UserHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 	// Получаем токен из заголовка Authorization
@@ -73,8 +83,7 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 
 	// Парсим данные для обновления
 	var req UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Не удалось прочитать данные запроса")
+	if !decodeAndValidate(w, r, &req) {
 		return
 	}
 
@@ -104,8 +113,10 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 func (h *UserHandler) SearchUserByEmailHandler(w http.ResponseWriter, r *http.Request) {
 	// Получаем email из query параметра
 	email := r.URL.Query().Get("email")
-	if email == "" {
-		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Параметр 'email' обязателен")
+	var errs ValidationErrors
+	validateEmail(&errs, "email", &email, true)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
 		return
 	}
 
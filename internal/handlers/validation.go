@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRequestBodySize ограничивает размер тела JSON-запроса (1 МБ)
+	maxRequestBodySize = 1 << 20
+
+	// maxEmailLength соответствует размеру колонки email в models.User
+	maxEmailLength = 100
+
+	// maxNameLength соответствует размеру колонок name, surname и nickname в models.User
+	maxNameLength = 100
+)
+
+// FieldError описывает ошибку валидации отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors накапливает ошибки валидации по всем полям запроса,
+// чтобы клиент получил их все в одном ответе
+type ValidationErrors []FieldError
+
+// Add добавляет ошибку для указанного поля
+func (v *ValidationErrors) Add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+// Error объединяет все ошибки в одну строку
+func (v ValidationErrors) Error() string {
+	parts := make([]string, 0, len(v))
+	for _, fe := range v {
+		parts = append(parts, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// validatable реализуется структурами запросов, которые умеют
+// нормализовать и проверять собственные поля
+type validatable interface {
+	Validate() ValidationErrors
+}
+
+// respondWithValidationErrors отправляет ответ со списком ошибок по полям
+func respondWithValidationErrors(w http.ResponseWriter, errs ValidationErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "Ошибка валидации",
+		"details": errs.Error(),
+		"fields":  errs,
+	})
+}
+
+// decodeAndValidate читает JSON из тела запроса с ограничением размера,
+// отклоняет неизвестные поля и лишние данные, после чего валидирует запрос.
+// При ошибке сам отправляет ответ клиенту и возвращает false
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, dst validatable) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		respondWithDecodeError(w, err)
+		return false
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Тело запроса должно содержать ровно один JSON-объект")
+		return false
+	}
+
+	if errs := dst.Validate(); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return false
+	}
+	return true
+}
+
+// respondWithDecodeError переводит ошибку разбора JSON в понятный клиенту ответ
+func respondWithDecodeError(w http.ResponseWriter, err error) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Слишком большой запрос",
+			fmt.Sprintf("Размер тела запроса не должен превышать %d байт", maxBytesErr.Limit))
+	case errors.As(err, &syntaxErr):
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос",
+			fmt.Sprintf("Некорректный JSON (позиция %d)", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Некорректный JSON")
+	case errors.As(err, &typeErr):
+		respondWithValidationErrors(w, ValidationErrors{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("ожидается значение типа %s", typeErr.Type),
+		}})
+	case errors.Is(err, io.EOF):
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Тело запроса пустое")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		respondWithValidationErrors(w, ValidationErrors{{Field: field, Message: "неизвестное поле"}})
+	default:
+		respondWithError(w, http.StatusBadRequest, "Неверный запрос", "Не удалось прочитать данные запроса")
+	}
+}
+
+// validateEmail нормализует email (обрезает пробелы, приводит к нижнему регистру,
+// переводит IDN-домен в punycode) и проверяет его синтаксис
+// Длина проверяется после перевода в punycode: в таком виде адрес хранится, а домен
+// в punycode длиннее введённого
+func validateEmail(errs *ValidationErrors, field string, value *string, required bool) {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	canonical, err := util.CanonicalEmail(*value)
+	if err != nil {
+		errs.Add(field, "некорректный адрес электронной почты")
+		return
+	}
+	*value = canonical
+	if utf8.RuneCountInString(*value) > maxEmailLength {
+		errs.Add(field, fmt.Sprintf("длина не должна превышать %d символов", maxEmailLength))
+		return
+	}
+	addr, err := mail.ParseAddress(*value)
+	if err != nil || addr.Name != "" || addr.Address != *value {
+		errs.Add(field, "некорректный адрес электронной почты")
+		return
+	}
+	at := strings.LastIndex(*value, "@")
+	local, domain := (*value)[:at], (*value)[at+1:]
+	if len(local) > 64 {
+		errs.Add(field, "локальная часть адреса не должна превышать 64 символа")
+		return
+	}
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		errs.Add(field, "некорректный домен адреса электронной почты")
+	}
+}
+
+// validatePersonName нормализует и проверяет имя или фамилию:
+// допускаются буквы, пробел, дефис и апостроф
+func validatePersonName(errs *ValidationErrors, field string, value *string, required bool) {
+	*value = strings.Join(strings.Fields(*value), " ")
+	if *value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if utf8.RuneCountInString(*value) > maxNameLength {
+		errs.Add(field, fmt.Sprintf("длина не должна превышать %d символов", maxNameLength))
+		return
+	}
+	for _, r := range *value {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '\'' {
+			errs.Add(field, "допускаются только буквы, пробел, дефис и апостроф")
+			return
+		}
+	}
+}
+
+// validateNickname нормализует и проверяет псевдоним:
+// допускаются буквы, цифры, точка, дефис и подчёркивание
+func validateNickname(errs *ValidationErrors, field string, value *string) {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		return
+	}
+	if utf8.RuneCountInString(*value) > maxNameLength {
+		errs.Add(field, fmt.Sprintf("длина не должна превышать %d символов", maxNameLength))
+		return
+	}
+	for _, r := range *value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			errs.Add(field, "допускаются только буквы, цифры, точка, дефис и подчёркивание")
+			return
+		}
+	}
+}
+
+// validateCode проверяет код подтверждения: ровно util.CodeLength цифр
+func validateCode(errs *ValidationErrors, field string, value *string) {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		errs.Add(field, "поле обязательно для заполнения")
+		return
+	}
+	if len(*value) != util.CodeLength {
+		errs.Add(field, fmt.Sprintf("код должен состоять из %d цифр", util.CodeLength))
+		return
+	}
+	for _, r := range *value {
+		if r < '0' || r > '9' {
+			errs.Add(field, "код должен содержать только цифры")
+			return
+		}
+	}
+}
+
+// validateTempID проверяет, что временный идентификатор является UUID
+func validateTempID(errs *ValidationErrors, field string, value *string) {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		errs.Add(field, "поле обязательно для заполнения")
+		return
+	}
+	if _, err := uuid.Parse(*value); err != nil {
+		errs.Add(field, "некорректный идентификатор")
+	}
+}
+
+// validateTitle нормализует и проверяет название (семьи, счёта и т.п.):
+// пробелы по краям обрезаются, управляющие символы запрещены
+func validateTitle(errs *ValidationErrors, field string, value *string, required bool, maxLen int) {
+	*value = strings.Join(strings.Fields(*value), " ")
+	if *value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if utf8.RuneCountInString(*value) > maxLen {
+		errs.Add(field, fmt.Sprintf("длина не должна превышать %d символов", maxLen))
+		return
+	}
+	for _, r := range *value {
+		if !unicode.IsPrint(r) {
+			errs.Add(field, "содержит недопустимые символы")
+			return
+		}
+	}
+}
+
+// validateCurrency нормализует код валюты к верхнему регистру и проверяет, что валюта
+// есть в справочнике ISO 4217
+func validateCurrency(errs *ValidationErrors, field string, value *string, required bool) {
+	*value = strings.ToUpper(strings.TrimSpace(*value))
+	if *value == "" {
+		if required {
+			errs.Add(field, "поле обязательно для заполнения")
+		}
+		return
+	}
+	if len(*value) != 3 {
+		errs.Add(field, "ожидается трёхбуквенный код валюты ISO 4217")
+		return
+	}
+	for _, r := range *value {
+		if r < 'A' || r > 'Z' {
+			errs.Add(field, "ожидается трёхбуквенный код валюты ISO 4217")
+			return
+		}
+	}
+	if _, ok := money.LookupCurrency(*value); !ok {
+		errs.Add(field, "неизвестная валюта; список валют — GET /currencies")
+	}
+}
+
+// validateTimezone проверяет, что значение является часовым поясом из базы IANA
+func validateTimezone(errs *ValidationErrors, field string, value *string) {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		return
+	}
+	if _, err := time.LoadLocation(*value); err != nil {
+		errs.Add(field, "неизвестный часовой пояс, ожидается имя из базы IANA, например Europe/Moscow")
+	}
+}
+
+// validateID проверяет, что обязательный идентификатор задан
+func validateID(errs *ValidationErrors, field string, value uint) {
+	if value == 0 {
+		errs.Add(field, "поле обязательно для заполнения")
+	}
+}
+
+// validateAmount проверяет сумму в минимальных единицах валюты
+// Если positive, сумма должна быть больше нуля
+func validateAmount(errs *ValidationErrors, field string, value int64, positive bool) {
+	switch {
+	case positive && value <= 0:
+		errs.Add(field, "сумма должна быть больше нуля")
+	case value > money.MaxAmount || value < -money.MaxAmount:
+		errs.Add(field, "сумма слишком велика")
+	}
+}
+
+// validateDate разбирает необязательную дату в формате ГГГГ-ММ-ДД
+func validateDate(errs *ValidationErrors, field, value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	d, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		errs.Add(field, "ожидается дата в формате ГГГГ-ММ-ДД")
+		return nil
+	}
+	return &d
+}
+
+// validateNote обрезает пробелы по краям многострочного комментария и проверяет его длину
+// Переводы строк допускаются, остальные управляющие символы — нет
+func validateNote(errs *ValidationErrors, field string, value *string, maxLen int) {
+	*value = strings.TrimSpace(*value)
+	if utf8.RuneCountInString(*value) > maxLen {
+		errs.Add(field, fmt.Sprintf("длина не должна превышать %d символов", maxLen))
+		return
+	}
+	for _, r := range *value {
+		if r != '\n' && r != '\r' && r != '\t' && !unicode.IsPrint(r) {
+			errs.Add(field, "содержит недопустимые символы")
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEmail(t *testing.T) {
+	// «почта.рф» в punycode — «xn--80a1acny.xn--p1ai»: 20 символов вместо 8
+	idnLocal := strings.Repeat("a", 80)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		err   string
+	}{
+		{name: "регистр и пробелы", value: "  Ivan@Example.COM ", want: "ivan@example.com"},
+		{name: "IDN-домен в punycode", value: "Ivan@Почта.РФ", want: "ivan@xn--80a1acny.xn--p1ai"},
+		{name: "ровно 100 символов", value: strings.Repeat("a", 64) + "@" + strings.Repeat("b", 31) + ".com",
+			want: strings.Repeat("a", 64) + "@" + strings.Repeat("b", 31) + ".com"},
+		{name: "101 символ", value: strings.Repeat("a", 64) + "@" + strings.Repeat("b", 32) + ".com",
+			err: "длина не должна превышать 100 символов"},
+		{name: "короткий IDN-адрес длиннее 100 символов в punycode", value: idnLocal + "@почта.рф",
+			err: "длина не должна превышать 100 символов"},
+		{name: "без домена", value: "ivan@", err: "некорректный адрес электронной почты"},
+		{name: "домен без точки", value: "ivan@localhost", err: "некорректный домен адреса электронной почты"},
+		{name: "длинная локальная часть", value: strings.Repeat("a", 65) + "@example.com",
+			err: "локальная часть адреса не должна превышать 64 символа"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ValidationErrors
+			value := tt.value
+			validateEmail(&errs, "email", &value, true)
+			if tt.err != "" {
+				if len(errs) != 1 || errs[0].Message != tt.err {
+					t.Errorf("errors = %v, want %q", errs, tt.err)
+				}
+				return
+			}
+			if len(errs) != 0 {
+				t.Fatalf("errors = %v, want none", errs)
+			}
+			if value != tt.want {
+				t.Errorf("value = %q, want %q", value, tt.want)
+			}
+		})
+	}
+}
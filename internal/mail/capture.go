@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"sync"
+)
+
+// defaultCaptureLimit количество последних писем, которые хранит CaptureTransport
+const defaultCaptureLimit = 200
+
+// CaptureTransport сохраняет письма в памяти процесса
+// Используется при локальной разработке и в интеграционных тестах,
+// чтобы прочитать отправленные коды через dev-эндпоинт
+type CaptureTransport struct {
+	mu       sync.RWMutex
+	limit    int
+	messages []*Message
+}
+
+// NewCaptureTransport создает новый экземпляр CaptureTransport,
+// хранящий не более limit последних писем
+func NewCaptureTransport(limit int) *CaptureTransport {
+	return &CaptureTransport{limit: limit}
+}
+
+// Send сохраняет письмо, вытесняя самое старое при превышении лимита
+func (t *CaptureTransport) Send(msg *Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	if len(t.messages) > t.limit {
+		t.messages = t.messages[len(t.messages)-t.limit:]
+	}
+	return nil
+}
+
+// Messages возвращает сохранённые письма от новых к старым
+// Если to не пустой, возвращаются только письма этому получателю
+func (t *CaptureTransport) Messages(to string) []*Message {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]*Message, 0, len(t.messages))
+	for i := len(t.messages) - 1; i >= 0; i-- {
+		msg := t.messages[i]
+		if to == "" || containsAddress(msg.To, to) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Reset удаляет все сохранённые письма
+func (t *CaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
+
+func containsAddress(list []string, addr string) bool {
+	for _, a := range list {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
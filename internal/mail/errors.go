@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"errors"
+	"net/textproto"
+)
+
+// PermanentError помечает ошибку, при которой повторная отправка письма бессмысленна
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent оборачивает err в PermanentError
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent сообщает, является ли ошибка доставки постоянной
+// Ответы SMTP-сервера с кодом 5xx (неверный адрес, отказ в приёме и т.п.)
+// и ошибки формирования письма считаются постоянными; коды 4xx, сетевые
+// ошибки и таймауты — временными
+func IsPermanent(err error) bool {
+	var permErr *PermanentError
+	if errors.As(err, &permErr) {
+		return true
+	}
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 500
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileTransport сохраняет письма в каталог в виде .eml файлов
+// Файлы можно открыть любым почтовым клиентом
+type FileTransport struct {
+	dir string
+}
+
+// NewFileTransport создает новый экземпляр FileTransport
+// Создает каталог, если он не существует
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог для писем: %w", err)
+	}
+	return &FileTransport{dir: dir}, nil
+}
+
+// Send записывает письмо в файл <время>-<id>.eml
+func (t *FileTransport) Send(msg *Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return Permanent(err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", msg.CreatedAt.Format("20060102T150405.000"), msg.ID)
+	return os.WriteFile(filepath.Join(t.dir, name), body, 0o644)
+}
@@ -0,0 +1,20 @@
+package mail
+
+import (
+	"log"
+	"strings"
+)
+
+// LogTransport выводит письма в журнал приложения вместо отправки
+type LogTransport struct{}
+
+// NewLogTransport создает новый экземпляр LogTransport
+func NewLogTransport() *LogTransport {
+	return &LogTransport{}
+}
+
+// Send записывает получателей, тему и текст письма в журнал
+func (t *LogTransport) Send(msg *Message) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", strings.Join(msg.To, ","), msg.Subject, msg.Text)
+	return nil
+}
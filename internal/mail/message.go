@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordan-wright/email"
+)
+
+// Message представляет исходящее письмо независимо от способа доставки
+type Message struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        []string  `json:"to"`
+	Subject   string    `json:"subject"`
+	Text      string    `json:"text"`
+	HTML      string    `json:"html,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt время, после которого письмо теряет смысл и не доставляется,
+	// например письмо с кодом подтверждения; nil — без срока
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewMessage создает новое письмо с уникальным идентификатором
+func NewMessage(from string, to []string, subject, text, html string) *Message {
+	return &Message{
+		ID:        uuid.New().String(),
+		From:      from,
+		To:        to,
+		Subject:   subject,
+		Text:      text,
+		HTML:      html,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Bytes формирует MIME-представление письма (RFC 5322)
+func (m *Message) Bytes() ([]byte, error) {
+	e := email.NewEmail()
+	e.From = m.From
+	e.To = m.To
+	e.Subject = m.Subject
+	e.Text = []byte(m.Text)
+	if m.HTML != "" {
+		e.HTML = []byte(m.HTML)
+	}
+	e.Headers.Set("Message-Id", "<"+m.ID+"@family-finance>")
+	return e.Bytes()
+}
@@ -0,0 +1,197 @@
+package mail
+
+import "time"
+
+// Типы писем; имя совпадает с каталогом шаблона в templates/
+const (
+	// TemplateVerificationCode письмо с кодом подтверждения входа или регистрации
+	TemplateVerificationCode = "verification_code"
+
+	// TemplateFamilyInvite приглашение в семью
+	TemplateFamilyInvite = "family_invite"
+
+	// TemplateBudgetAlert предупреждение о достижении порога бюджета
+	TemplateBudgetAlert = "budget_alert"
+
+	// TemplateBillReminder напоминание о сроке оплаты счёта
+	TemplateBillReminder = "bill_reminder"
+
+	// TemplateDebtRecorded запись о займе или погашении долга между участниками семьи
+	TemplateDebtRecorded = "debt_recorded"
+
+	// TemplateApprovalRequested расход ждёт одобрения по правилу семьи
+	TemplateApprovalRequested = "approval_requested"
+
+	// TemplateApprovalDecided по расходу принято решение
+	TemplateApprovalDecided = "approval_decided"
+)
+
+// VerificationCodeData данные письма с кодом подтверждения
+type VerificationCodeData struct {
+	Code       string
+	TTLSeconds int
+}
+
+// FamilyInviteData данные письма с приглашением в семью
+// Role — код роли (owner, adult, child, viewer); шаблон выводит её название
+type FamilyInviteData struct {
+	FamilyName  string
+	InviterName string
+	Role        string
+	AcceptURL   string
+	ExpiresAt   time.Time
+}
+
+// BudgetAlertData данные предупреждения о достижении порога бюджета
+// Суммы в минимальных единицах валюты Currency
+type BudgetAlertData struct {
+	FamilyName   string
+	CategoryName string
+	Threshold    int
+	Percent      int
+	Planned      int64
+	Actual       int64
+	Remaining    int64
+	Currency     string
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+}
+
+// BillReminderData данные напоминания о сроке оплаты счёта
+// Суммы в минимальных единицах валюты Currency
+type BillReminderData struct {
+	FamilyName string
+	BillName   string
+	Amount     int64
+	Currency   string
+	DueDate    time.Time
+	// DaysLeft сколько дней осталось до срока; 0 — срок сегодня
+	DaysLeft int
+	// AccountName счёт, с которого планируется оплата; пустой, если счёт не указан
+	AccountName string
+	// Balance текущий остаток на счёте AccountName
+	Balance int64
+	// Shortfall сколько не хватает на счёте AccountName до суммы счёта; 0 — хватает
+	Shortfall int64
+}
+
+// DebtRecordedData данные письма о записанном займе или погашении долга
+// Суммы в минимальных единицах валюты Currency
+type DebtRecordedData struct {
+	FamilyName string
+	// AuthorName кто сделал запись
+	AuthorName string
+	// Kind вид записи: loan (заём) или settlement (погашение)
+	Kind         string
+	LenderName   string
+	BorrowerName string
+	Amount       int64
+	Currency     string
+	Date         time.Time
+	Note         string
+	// DebtorName и CreditorName итог после записи: DebtorName должен CreditorName сумму Balance
+	// в валюте Currency; Balance 0 — взаимных долгов в этой валюте нет
+	DebtorName   string
+	CreditorName string
+	Balance      int64
+}
+
+// ApprovalRequestedData данные письма о расходе, ожидающем одобрения
+// Суммы в минимальных единицах валюты Currency
+type ApprovalRequestedData struct {
+	FamilyName string
+	// AuthorName кто записал расход
+	AuthorName  string
+	Amount      int64
+	Currency    string
+	Date        time.Time
+	AccountName string
+	// CategoryName пустое, если категория не указана
+	CategoryName string
+	Payee        string
+	Note         string
+}
+
+// ApprovalDecidedData данные письма автору расхода о решении по нему
+// Суммы в минимальных единицах валюты Currency
+type ApprovalDecidedData struct {
+	FamilyName  string
+	DeciderName string
+	Approved    bool
+	Amount      int64
+	Currency    string
+	Date        time.Time
+	Payee       string
+	// Note комментарий к решению
+	Note string
+}
+
+// PreviewData примеры данных для предпросмотра шаблонов через dev-эндпоинт
+var PreviewData = map[string]interface{}{
+	TemplateVerificationCode: VerificationCodeData{Code: "12345", TTLSeconds: 90},
+	TemplateFamilyInvite: FamilyInviteData{
+		FamilyName:  "Ивановы",
+		InviterName: "Иван Иванов",
+		Role:        "adult",
+		AcceptURL:   "http://localhost:8080/invites/accept?token=example",
+		ExpiresAt:   time.Date(2030, 1, 7, 12, 0, 0, 0, time.UTC),
+	},
+	TemplateBudgetAlert: BudgetAlertData{
+		FamilyName:   "Ивановы",
+		CategoryName: "Продукты",
+		Threshold:    80,
+		Percent:      84,
+		Planned:      3000000,
+		Actual:       2520000,
+		Remaining:    480000,
+		Currency:     "RUB",
+		PeriodStart:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:    time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+	},
+	TemplateBillReminder: BillReminderData{
+		FamilyName:  "Ивановы",
+		BillName:    "Аренда",
+		Amount:      4500000,
+		Currency:    "RUB",
+		DueDate:     time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+		DaysLeft:    3,
+		AccountName: "Зарплатная карта",
+		Balance:     3800000,
+		Shortfall:   700000,
+	},
+	TemplateDebtRecorded: DebtRecordedData{
+		FamilyName:   "Ивановы",
+		AuthorName:   "Иван Иванов",
+		Kind:         "loan",
+		LenderName:   "Иван Иванов",
+		BorrowerName: "Пётр Иванов",
+		Amount:       500000,
+		Currency:     "RUB",
+		Date:         time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC),
+		Note:         "На ремонт машины",
+		DebtorName:   "Пётр Иванов",
+		CreditorName: "Иван Иванов",
+		Balance:      750000,
+	},
+	TemplateApprovalRequested: ApprovalRequestedData{
+		FamilyName:   "Ивановы",
+		AuthorName:   "Пётр Иванов",
+		Amount:       8990000,
+		Currency:     "RUB",
+		Date:         time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC),
+		AccountName:  "Общая карта",
+		CategoryName: "Электроника",
+		Payee:        "Магазин техники",
+		Note:         "Новый ноутбук",
+	},
+	TemplateApprovalDecided: ApprovalDecidedData{
+		FamilyName:  "Ивановы",
+		DeciderName: "Иван Иванов",
+		Approved:    true,
+		Amount:      8990000,
+		Currency:    "RUB",
+		Date:        time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC),
+		Payee:       "Магазин техники",
+		Note:        "Согласовано",
+	},
+}
@@ -0,0 +1,164 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"family_finance_back/internal/money"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// DefaultLocale язык писем, если запрошенный язык не поддерживается шаблоном
+const DefaultLocale = "ru"
+
+// Content результат отрисовки шаблона письма
+type Content struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// view данные, которые получают шаблоны: общие поля макета и данные конкретного письма
+type view struct {
+	Locale string
+	Year   int
+	Data   interface{}
+}
+
+// localized пара шаблонов письма для одного языка
+type localized struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Renderer отрисовывает письма из встроенных шаблонов
+// Каждый тип письма хранится в каталоге templates/<тип>/ в виде файлов
+// <язык>.html и <язык>.txt, которые оборачиваются общим макетом layout.html/layout.txt
+// Название приложения и подвал берутся из footer.<язык>.tmpl
+type Renderer struct {
+	templates map[string]map[string]localized
+	// now возвращает текущее время для года в подвале письма
+	now func() time.Time
+}
+
+// NewRenderer разбирает все встроенные шаблоны
+// Ошибка в любом шаблоне возвращается сразу при запуске приложения
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]map[string]localized), now: time.Now}
+
+	dirs, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		name := dir.Name()
+		files, err := fs.ReadDir(templatesFS, path.Join("templates", name))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if path.Ext(file.Name()) != ".html" {
+				continue
+			}
+			locale := strings.TrimSuffix(file.Name(), ".html")
+			tpl, err := parseLocalized(name, locale)
+			if err != nil {
+				return nil, fmt.Errorf("шаблон %s/%s: %w", name, locale, err)
+			}
+			if r.templates[name] == nil {
+				r.templates[name] = make(map[string]localized)
+			}
+			r.templates[name][locale] = tpl
+		}
+	}
+	return r, nil
+}
+
+// funcs функции, доступные в шаблонах писем
+// money форматирует сумму в минимальных единицах валюты для языка письма:
+// {{money .Locale .Data.Amount .Data.Currency}}; neg меняет знак суммы
+var funcs = map[string]interface{}{
+	"money": func(locale string, amount int64, currency string) string {
+		return money.Display(amount, currency, locale)
+	},
+	"neg": func(amount int64) int64 {
+		return -amount
+	},
+}
+
+// parseLocalized разбирает HTML и текстовый шаблон письма вместе с макетом и подвалом
+func parseLocalized(name, locale string) (localized, error) {
+	footer := fmt.Sprintf("templates/footer.%s.tmpl", locale)
+	base := path.Join("templates", name, locale)
+
+	html, err := htmltemplate.New("layout.html").Funcs(funcs).
+		ParseFS(templatesFS, "templates/layout.html", footer, base+".html")
+	if err != nil {
+		return localized{}, err
+	}
+	text, err := texttemplate.New("layout.txt").Funcs(funcs).
+		ParseFS(templatesFS, "templates/layout.txt", footer, base+".txt")
+	if err != nil {
+		return localized{}, err
+	}
+	return localized{html: html, text: text}, nil
+}
+
+// Render отрисовывает письмо name на языке locale
+// Если язык не поддерживается, используется DefaultLocale
+func (r *Renderer) Render(name, locale string, data interface{}) (*Content, error) {
+	locales, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный шаблон письма %q", name)
+	}
+	tpl, ok := locales[locale]
+	if !ok {
+		locale = DefaultLocale
+		if tpl, ok = locales[locale]; !ok {
+			return nil, fmt.Errorf("шаблон письма %q не поддерживает язык %q", name, DefaultLocale)
+		}
+	}
+
+	v := view{Locale: locale, Year: r.now().Year(), Data: data}
+
+	var subject, text, html bytes.Buffer
+	if err := tpl.text.ExecuteTemplate(&subject, "subject", v); err != nil {
+		return nil, err
+	}
+	if err := tpl.text.ExecuteTemplate(&text, "layout", v); err != nil {
+		return nil, err
+	}
+	if err := tpl.html.ExecuteTemplate(&html, "layout", v); err != nil {
+		return nil, err
+	}
+	return &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Templates возвращает список шаблонов и поддерживаемых ими языков
+func (r *Renderer) Templates() map[string][]string {
+	result := make(map[string][]string, len(r.templates))
+	for name, locales := range r.templates {
+		for locale := range locales {
+			result[name] = append(result[name], locale)
+		}
+		sort.Strings(result[name])
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// update перезаписывает эталонные файлы в testdata: go test ./internal/mail -update
+var update = flag.Bool("update", false, "перезаписать эталонные файлы в testdata")
+
+// goldenLocales языки, на которых проверяется каждый шаблон
+var goldenLocales = []string{"ru", "en"}
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	r.now = func() time.Time { return time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return r
+}
+
+// TestRenderGolden сравнивает каждое письмо на каждом языке с эталоном
+// testdata/<тип>.<язык>.txt содержит тему и текстовую версию, testdata/<тип>.<язык>.html — HTML-версию
+func TestRenderGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	names := make([]string, 0, len(PreviewData))
+	for name := range PreviewData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, locale := range goldenLocales {
+			t.Run(name+"/"+locale, func(t *testing.T) {
+				content, err := r.Render(name, locale, PreviewData[name])
+				if err != nil {
+					t.Fatalf("Render: %v", err)
+				}
+				base := filepath.Join("testdata", name+"."+locale)
+				checkGolden(t, base+".txt", []byte("Subject: "+content.Subject+"\n\n"+content.Text))
+				checkGolden(t, base+".html", []byte(content.HTML))
+			})
+		}
+	}
+}
+
+// TestPreviewDataCoversTemplates не даёт добавить шаблон без примера данных,
+// а значит, и без эталона, и требует, чтобы каждый шаблон поддерживал все языки
+func TestPreviewDataCoversTemplates(t *testing.T) {
+	r := newTestRenderer(t)
+	for name, locales := range r.Templates() {
+		if _, ok := PreviewData[name]; !ok {
+			t.Errorf("для шаблона %s нет примера в PreviewData", name)
+		}
+		for _, want := range goldenLocales {
+			found := false
+			for _, locale := range locales {
+				found = found || locale == want
+			}
+			if !found {
+				t.Errorf("шаблон %s не поддерживает язык %s", name, want)
+			}
+		}
+	}
+	for name := range PreviewData {
+		if _, ok := r.Templates()[name]; !ok {
+			t.Errorf("пример %s в PreviewData не соответствует ни одному шаблону", name)
+		}
+	}
+}
+
+// checkGolden сравнивает got с содержимым эталонного файла или перезаписывает его при -update
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("запись %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("чтение %s: %v; запустите go test ./internal/mail -update", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s не совпадает с эталоном\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package mail
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// Режимы защиты SMTP-соединения, допустимые в SMTP_SECURITY
+const (
+	// SecurityTLS неявный TLS (обычно порт 465)
+	SecurityTLS = "tls"
+	// SecurityStartTLS обычное соединение с переходом на TLS командой STARTTLS (обычно порт 587)
+	SecurityStartTLS = "starttls"
+	// SecurityPlain соединение без шифрования (локальные релеи и отладочные серверы)
+	SecurityPlain = "plain"
+)
+
+// smtpDialTimeout ограничивает время установки соединения с SMTP-сервером
+const smtpDialTimeout = 10 * time.Second
+
+// smtpSendTimeout ограничивает весь обмен с сервером после подключения. Он короче аренды
+// письма в очереди (5 минут), поэтому зависшая отправка завершается ошибкой раньше, чем
+// письмо заберёт другой воркер
+const smtpSendTimeout = 2 * time.Minute
+
+// SMTPOptions параметры подключения к SMTP-серверу
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string
+}
+
+// SMTPTransport доставляет письма через SMTP-сервер
+type SMTPTransport struct {
+	opts SMTPOptions
+}
+
+// NewSMTPTransport создает новый экземпляр SMTPTransport
+func NewSMTPTransport(opts SMTPOptions) *SMTPTransport {
+	return &SMTPTransport{opts: opts}
+}
+
+// Send открывает одно соединение с сервером, при необходимости выполняет
+// STARTTLS и авторизацию, после чего передаёт письмо
+func (t *SMTPTransport) Send(msg *Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return Permanent(err)
+	}
+
+	addr := net.JoinHostPort(t.opts.Host, fmt.Sprint(t.opts.Port))
+	tlsConfig := &tls.Config{ServerName: t.opts.Host}
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	if t.opts.Security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(smtpSendTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, t.opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if t.opts.Security == SecurityStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP-сервер не поддерживает STARTTLS")
+		}
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if t.opts.Username != "" {
+		auth := smtp.PlainAuth("", t.opts.Username, t.opts.Password, t.opts.Host)
+		if err = c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(msg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range msg.To {
+		if err = c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(body); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
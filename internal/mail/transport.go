@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"fmt"
+
+	"family_finance_back/config"
+)
+
+// Названия транспортов, допустимые в EMAIL_TRANSPORT
+const (
+	TransportSMTP    = "smtp"
+	TransportFile    = "file"
+	TransportLog     = "log"
+	TransportCapture = "capture"
+)
+
+// Transport определяет способ доставки писем
+type Transport interface {
+	// Send доставляет письмо
+	// Возвращает ошибку, если письмо не удалось передать получателю или серверу
+	Send(msg *Message) error
+}
+
+// NewTransport создает транспорт, выбранный в конфигурации
+func NewTransport(cfg config.Config) (Transport, error) {
+	switch cfg.EmailTransport {
+	case TransportSMTP:
+		return NewSMTPTransport(SMTPOptions{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Security: cfg.SMTPSecurity,
+		}), nil
+	case TransportFile:
+		return NewFileTransport(cfg.EmailFileDir)
+	case TransportLog:
+		return NewLogTransport(), nil
+	case TransportCapture:
+		return NewCaptureTransport(defaultCaptureLimit), nil
+	default:
+		return nil, fmt.Errorf("неизвестный транспорт писем %q", cfg.EmailTransport)
+	}
+}
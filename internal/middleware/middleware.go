@@ -5,9 +5,29 @@ import (
 	"net/http"
 	"strings"
 
+	"family_finance_back/internal/models"
+
 	"github.com/go-redis/redis/v8"
 )
 
+// contextKey тип ключей, которые middleware кладёт в контекст запроса
+type contextKey string
+
+// userContextKey ключ, под которым в контексте хранится авторизованный пользователь
+const userContextKey contextKey = "user"
+
+// UserResolver получает пользователя по JWT токену
+type UserResolver interface {
+	GetUserByToken(token string) (*models.User, error)
+}
+
+// UserFromContext возвращает пользователя, которого JWTAuthMiddleware
+// определил по токену запроса; nil, если запрос не прошёл через middleware
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userContextKey).(*models.User)
+	return user
+}
+
 // LoggerMiddleware создает middleware для логирования HTTP запросов
 // Логирует метод, путь, статус ответа и время выполнения запроса
 func LoggerMiddleware(next http.Handler) http.Handler {
@@ -32,7 +52,8 @@ func AuthMiddleware(next http.Handler) http.Handler {
 // JWTAuthMiddleware создает middleware для проверки JWT токена
 // Проверяет наличие и валидность токена в заголовке Authorization
 // Также проверяет, не находится ли токен в черном списке
-func JWTAuthMiddleware(redisClient *redis.Client) func(http.HandlerFunc) http.HandlerFunc {
+// Найденный по токену пользователь доступен обработчикам через UserFromContext
+func JWTAuthMiddleware(redisClient *redis.Client, users UserResolver) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -55,7 +76,32 @@ func JWTAuthMiddleware(redisClient *redis.Client) func(http.HandlerFunc) http.Ha
 				return
 			}
 
-			// Если всё ок, передаем дальше
+			user, err := users.GetUserByToken(token)
+			if err != nil {
+				http.Error(w, "Недействительный токен", http.StatusUnauthorized)
+				return
+			}
+
+			// Если всё ок, передаем дальше вместе с пользователем
+			next(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+		}
+	}
+}
+
+// AdminMiddleware создает middleware, пропускающий только администраторов
+// Должен применяться после JWTAuthMiddleware; администраторы задаются списком email
+func AdminMiddleware(adminEmails []string) func(http.HandlerFunc) http.HandlerFunc {
+	admins := make(map[string]bool, len(adminEmails))
+	for _, email := range adminEmails {
+		admins[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user := UserFromContext(r.Context())
+			if user == nil || !admins[strings.ToLower(user.Email)] {
+				http.Error(w, "Доступ запрещён", http.StatusForbidden)
+				return
+			}
 			next(w, r)
 		}
 	}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// AccountType вид счёта
+type AccountType string
+
+const (
+	// AccountCash наличные
+	AccountCash AccountType = "cash"
+	// AccountDebitCard дебетовая карта
+	AccountDebitCard AccountType = "debit_card"
+	// AccountCreditCard кредитная карта
+	AccountCreditCard AccountType = "credit_card"
+	// AccountSavings накопительный счёт
+	AccountSavings AccountType = "savings"
+	// AccountDeposit вклад
+	AccountDeposit AccountType = "deposit"
+)
+
+// Valid сообщает, является ли вид счёта допустимым
+func (t AccountType) Valid() bool {
+	switch t {
+	case AccountCash, AccountDebitCard, AccountCreditCard, AccountSavings, AccountDeposit:
+		return true
+	}
+	return false
+}
+
+// Account представляет счёт, на котором хранятся деньги: кошелёк, карту, вклад и т.п.
+// Счёт всегда принадлежит семье; если задан OwnerID, это личный счёт участника,
+// иначе — общий счёт семьи
+// Остаток не хранится, а вычисляется из начального остатка и операций по счёту
+type Account struct {
+	// ID уникальный идентификатор счёта
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// OwnerID идентификатор участника-владельца личного счёта; nil для общего счёта
+	OwnerID *uint `gorm:"index" json:"owner_id"`
+
+	// Name название счёта
+	Name string `gorm:"size:100;not null" json:"name"`
+
+	// Type вид счёта
+	Type AccountType `gorm:"size:16;not null" json:"type"`
+
+	// Currency валюта счёта (код ISO 4217); после создания не меняется
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// OpeningBalance начальный остаток в минимальных единицах валюты (копейках, центах)
+	OpeningBalance int64 `gorm:"not null" json:"opening_balance"`
+
+	// Archived архивный счёт скрыт из списков, но его история сохраняется
+	Archived bool `gorm:"not null" json:"archived"`
+
+	// Supervised счёт ребёнка под присмотром родителей: на него приходят карманные деньги,
+	// ребёнок видит остаток, но не изменяет счёт и не проводит по нему операции —
+	// расходы проводятся через запросы, которые одобряют родители
+	Supervised bool `gorm:"not null" json:"supervised"`
+
+	// SortOrder порядок отображения счёта в списке
+	SortOrder int `gorm:"not null" json:"sort_order"`
+
+	// CreatedBy идентификатор пользователя, создавшего счёт
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Balance текущий остаток в минимальных единицах валюты; вычисляется при выборке
+	Balance int64 `gorm:"-" json:"balance"`
+}
+
+// Shared сообщает, является ли счёт общим счётом семьи
+func (a *Account) Shared() bool {
+	return a.OwnerID == nil
+}
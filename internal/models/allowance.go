@@ -0,0 +1,171 @@
+package models
+
+import "time"
+
+// Allowance карманные деньги ребёнка: регулярный перевод со счёта родителей
+// на счёт ребёнка под присмотром (Account.Supervised)
+type Allowance struct {
+	// ID уникальный идентификатор
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// ChildID ребёнок, которому выплачиваются карманные деньги
+	ChildID uint `gorm:"not null" json:"child_id"`
+
+	// FromAccountID счёт, с которого переводятся деньги
+	FromAccountID uint `gorm:"not null" json:"from_account_id"`
+
+	// ToAccountID личный счёт ребёнка
+	ToAccountID uint `gorm:"not null" json:"to_account_id"`
+
+	// Amount сумма выплаты без премий, в минимальных единицах валюты
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта обоих счетов
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// Rule расписание выплат
+	Rule RecurrenceRule `gorm:"embedded" json:"rule"`
+
+	// NextDate дата следующей выплаты; nil, если выплаты закончились
+	NextDate *time.Time `gorm:"type:date" json:"next_date"`
+
+	// Paused приостановленные карманные деньги не выплачиваются
+	Paused bool `gorm:"not null" json:"paused"`
+
+	// CreatedBy идентификатор родителя, назначившего карманные деньги; он же автор переводов
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AllowanceBonus премия за выполненное домашнее дело
+// Невыплаченные премии прибавляются к ближайшей выплате карманных денег
+type AllowanceBonus struct {
+	// ID уникальный идентификатор
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// AllowanceID идентификатор карманных денег
+	AllowanceID uint `gorm:"not null" json:"allowance_id"`
+
+	// Chore за что премия, например «Убрал в комнате»
+	Chore string `gorm:"size:200;not null" json:"chore"`
+
+	// Amount сумма премии в минимальных единицах валюты карманных денег
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// TransactionID перевод, с которым выплачена премия; nil, пока премия не выплачена
+	TransactionID *uint `json:"transaction_id"`
+
+	// CreatedBy идентификатор родителя, назначившего премию
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AllowancePayment выплата карманных денег в дату расписания
+// Дата уникальна в пределах карманных денег, поэтому одна выплата не проводится дважды
+type AllowancePayment struct {
+	// ID уникальный идентификатор
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// AllowanceID идентификатор карманных денег
+	AllowanceID uint `gorm:"not null" json:"allowance_id"`
+
+	// Date дата выплаты по расписанию
+	Date time.Time `gorm:"type:date;not null" json:"date"`
+
+	// Amount сумма выплаты вместе с премиями
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Bonus сумма премий в выплате
+	Bonus int64 `gorm:"not null" json:"bonus"`
+
+	// TransactionID проведённый перевод
+	TransactionID *uint `json:"transaction_id"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SpendingStatus состояние запроса ребёнка на расход
+type SpendingStatus string
+
+const (
+	// SpendingPending запрос ждёт решения родителей
+	SpendingPending SpendingStatus = "pending"
+	// SpendingApproved запрос одобрен, расход проведён
+	SpendingApproved SpendingStatus = "approved"
+	// SpendingRejected запрос отклонён
+	SpendingRejected SpendingStatus = "rejected"
+	// SpendingCancelled запрос отозван ребёнком
+	SpendingCancelled SpendingStatus = "cancelled"
+)
+
+// Valid сообщает, является ли состояние допустимым
+func (s SpendingStatus) Valid() bool {
+	switch s {
+	case SpendingPending, SpendingApproved, SpendingRejected, SpendingCancelled:
+		return true
+	}
+	return false
+}
+
+// SpendingRequest запрос ребёнка на расход со своего счёта под присмотром
+// После одобрения расход проводится от имени одобрившего родителя
+type SpendingRequest struct {
+	// ID уникальный идентификатор
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// AccountID счёт ребёнка, с которого будет проведён расход
+	AccountID uint `gorm:"not null" json:"account_id"`
+
+	// RequestedBy ребёнок, который просит разрешения на расход
+	RequestedBy uint `gorm:"not null" json:"requested_by"`
+
+	// Amount сумма в минимальных единицах валюты счёта
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта счёта
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// CategoryID категория расхода
+	CategoryID *uint `json:"category_id"`
+
+	// Payee на что или кому, например «Книжный магазин»
+	Payee string `gorm:"size:200;not null" json:"payee"`
+
+	// Note комментарий ребёнка
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// Status состояние запроса
+	Status SpendingStatus `gorm:"size:16;not null" json:"status"`
+
+	// ReviewedBy родитель, принявший решение
+	ReviewedBy *uint `json:"reviewed_by"`
+
+	// ReviewedAt время решения
+	ReviewedAt *time.Time `json:"reviewed_at"`
+
+	// ReviewNote комментарий родителя к решению
+	ReviewNote string `gorm:"size:1000;not null" json:"review_note"`
+
+	// TransactionID проведённый расход одобренного запроса
+	TransactionID *uint `json:"transaction_id"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
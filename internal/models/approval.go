@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// ApprovalRule правило семьи, по которому расход ждёт одобрения второго взрослого
+// Заданные условия должны выполняться одновременно; незаданные не проверяются
+type ApprovalRule struct {
+	// ID уникальный идентификатор правила
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// MinAmount расход от этой суммы в минимальных единицах валюты Currency; 0 — любая сумма
+	MinAmount int64 `gorm:"not null" json:"min_amount"`
+
+	// Currency валюта порога MinAmount; расходы в других валютах пересчитываются по курсу на дату операции
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// CategoryID категория расхода, включая её подкатегории; nil — любая категория
+	CategoryID *uint `json:"category_id"`
+
+	// Role роль автора расхода; пустая — любая роль
+	Role FamilyRole `gorm:"size:16;not null" json:"role"`
+
+	// CreatedBy идентификатор автора правила
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ApprovalStatus состояние одобрения расхода
+type ApprovalStatus string
+
+const (
+	// ApprovalPending расход ждёт решения
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalApproved расход одобрен и проведён
+	ApprovalApproved ApprovalStatus = "approved"
+	// ApprovalRejected расход отклонён
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// Valid сообщает, является ли состояние допустимым
+func (s ApprovalStatus) Valid() bool {
+	return s == ApprovalPending || s == ApprovalApproved || s == ApprovalRejected
+}
+
+// Approval запрос на одобрение расхода и решение по нему
+// У операции не больше одного запроса; при повторной отправке на одобрение запрос переиспользуется
+type Approval struct {
+	// ID уникальный идентификатор
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// TransactionID расход, ожидающий одобрения
+	TransactionID uint `gorm:"not null" json:"transaction_id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null" json:"family_id"`
+
+	// RuleID правило, по которому расходу нужно одобрение; nil, если правило удалено
+	RuleID *uint `json:"rule_id"`
+
+	// RequestedBy участник, создавший или изменивший расход
+	RequestedBy uint `gorm:"not null" json:"requested_by"`
+
+	// Status состояние
+	Status ApprovalStatus `gorm:"size:16;not null" json:"status"`
+
+	// DecidedBy кто принял решение
+	DecidedBy *uint `json:"decided_by"`
+
+	// DecidedAt когда принято решение
+	DecidedAt *time.Time `json:"decided_at"`
+
+	// Note комментарий к решению
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Transaction расход
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Bill счёт к оплате: аренда, коммунальные платежи, кредит
+// Разовый счёт оплачивается один раз в день Rule.StartDate; у повторяющегося
+// сроки оплаты задаются правилом повторения
+type Bill struct {
+	// ID уникальный идентификатор счёта к оплате
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// Name название, например «Аренда»
+	Name string `gorm:"size:200;not null" json:"name"`
+
+	// Amount сумма к оплате в минимальных единицах валюты
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта суммы
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// AccountID счёт семьи, с которого планируется оплата
+	AccountID *uint `json:"account_id"`
+
+	// CategoryID категория расхода, в которую попадает оплата
+	CategoryID *uint `json:"category_id"`
+
+	// Rule сроки оплаты: StartDate — первый срок; пустой Frequency означает разовый счёт
+	Rule RecurrenceRule `gorm:"embedded" json:"rule"`
+
+	// RemindDays за сколько дней до срока оплаты отправляется напоминание; 0 — в день оплаты
+	RemindDays int `gorm:"not null" json:"remind_days"`
+
+	// CreatedBy идентификатор автора
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Recurring сообщает, повторяется ли счёт к оплате
+func (b *Bill) Recurring() bool {
+	return b.Rule.Frequency != ""
+}
+
+// BillStatus состояние оплаты счёта в конкретный срок
+type BillStatus string
+
+const (
+	// BillUnpaid срок ещё не наступил, счёт не оплачен
+	BillUnpaid BillStatus = "unpaid"
+	// BillPaid счёт оплачен
+	BillPaid BillStatus = "paid"
+	// BillOverdue срок прошёл, счёт не оплачен; в базе не хранится
+	BillOverdue BillStatus = "overdue"
+)
+
+// BillInstance оплата счёта в конкретный срок
+// Запись появляется, когда по сроку отправлено напоминание или счёт оплачен
+type BillInstance struct {
+	// ID уникальный идентификатор записи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// BillID идентификатор счёта к оплате
+	BillID uint `gorm:"not null" json:"bill_id"`
+
+	// DueDate срок оплаты
+	DueDate time.Time `gorm:"type:date;not null" json:"due_date"`
+
+	// Status состояние оплаты: unpaid или paid
+	Status BillStatus `gorm:"size:16;not null" json:"status"`
+
+	// TransactionID операция, которой оплачен счёт
+	TransactionID *uint `json:"transaction_id"`
+
+	// PaidAt время отметки об оплате
+	PaidAt *time.Time `json:"paid_at"`
+
+	// RemindedAt время отправки напоминания
+	RemindedAt *time.Time `json:"reminded_at"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BudgetPeriod вид периода бюджета
+type BudgetPeriod string
+
+const (
+	// BudgetMonthly бюджет на календарный месяц
+	BudgetMonthly BudgetPeriod = "month"
+	// BudgetWeekly бюджет на неделю с понедельника по воскресенье
+	BudgetWeekly BudgetPeriod = "week"
+	// BudgetCustom бюджет на произвольный период
+	BudgetCustom BudgetPeriod = "custom"
+)
+
+// Valid сообщает, является ли вид периода допустимым
+func (p BudgetPeriod) Valid() bool {
+	return p == BudgetMonthly || p == BudgetWeekly || p == BudgetCustom
+}
+
+// Budget представляет план доходов и расходов семьи на период
+// Суммы плана по категориям хранятся в строках бюджета (BudgetLine)
+type Budget struct {
+	// ID уникальный идентификатор бюджета
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// Period вид периода
+	Period BudgetPeriod `gorm:"size:16;not null" json:"period"`
+
+	// StartDate первый день периода
+	StartDate time.Time `gorm:"type:date;not null" json:"start_date"`
+
+	// EndDate последний день периода включительно
+	EndDate time.Time `gorm:"type:date;not null" json:"end_date"`
+
+	// Currency валюта бюджета; учитываются операции только в этой валюте
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// AlertThresholds пороги исполнения плана категории в процентах,
+	// при достижении которых участники получают предупреждение
+	AlertThresholds Thresholds `gorm:"type:varchar(64);not null" json:"alert_thresholds"`
+
+	// CreatedBy идентификатор автора бюджета
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Lines плановые суммы по категориям
+	Lines []BudgetLine `gorm:"foreignKey:BudgetID" json:"lines"`
+}
+
+// DefaultAlertThresholds пороги предупреждений нового бюджета
+var DefaultAlertThresholds = Thresholds{80, 100}
+
+// Thresholds пороги в процентах по возрастанию
+// В базе данных хранятся строкой через запятую, например "80,100"
+type Thresholds []int
+
+// Value реализует driver.Valuer
+func (t Thresholds) Value() (driver.Value, error) {
+	parts := make([]string, len(t))
+	for i, v := range t {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Scan реализует sql.Scanner
+func (t *Thresholds) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("неподдерживаемый тип порогов %T", src)
+	}
+	result := Thresholds{}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return fmt.Errorf("некорректный порог %q: %w", part, err)
+		}
+		result = append(result, v)
+	}
+	*t = result
+	return nil
+}
+
+// BudgetAlert отметка о сработавшем пороге по категории бюджета
+// Каждый порог срабатывает не более одного раза за период бюджета
+type BudgetAlert struct {
+	// ID уникальный идентификатор отметки
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// BudgetID идентификатор бюджета
+	BudgetID uint `gorm:"not null" json:"budget_id"`
+
+	// CategoryID идентификатор категории строки бюджета
+	CategoryID uint `gorm:"not null" json:"category_id"`
+
+	// Threshold сработавший порог в процентах
+	Threshold int `gorm:"not null" json:"threshold"`
+
+	// CreatedAt время срабатывания
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BudgetLine плановая сумма бюджета по категории
+type BudgetLine struct {
+	// ID уникальный идентификатор строки
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// BudgetID идентификатор бюджета
+	BudgetID uint `gorm:"not null;index" json:"budget_id"`
+
+	// CategoryID идентификатор категории; план учитывает и её подкатегории
+	CategoryID uint `gorm:"not null" json:"category_id"`
+
+	// Amount плановая сумма в минимальных единицах валюты бюджета
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Rollover переносит неизрасходованный остаток предыдущего периода в этот
+	Rollover bool `gorm:"not null" json:"rollover"`
+}
+
+// HasRollover сообщает, переносится ли в бюджет остаток предыдущего периода хотя бы по одной категории
+func (b *Budget) HasRollover() bool {
+	for _, line := range b.Lines {
+		if line.Rollover {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Category представляет категорию доходов или расходов семьи
+// Категории образуют дерево из двух уровней: категория верхнего уровня
+// и её подкатегории
+type Category struct {
+	// ID уникальный идентификатор категории
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// ParentID идентификатор родительской категории; nil для категории верхнего уровня
+	ParentID *uint `gorm:"index" json:"parent_id"`
+
+	// Kind вид категории: income или expense; совпадает с направлением операций категории
+	Kind Direction `gorm:"size:16;not null" json:"kind"`
+
+	// Name название категории
+	Name string `gorm:"size:100;not null" json:"name"`
+
+	// Icon идентификатор иконки в клиентском приложении
+	Icon string `gorm:"size:32;not null" json:"icon"`
+
+	// Color цвет категории в формате #RRGGBB
+	Color string `gorm:"size:7;not null" json:"color"`
+
+	// Archived архивная категория недоступна для новых операций, но сохраняется в истории
+	Archived bool `gorm:"not null" json:"archived"`
+
+	// SortOrder порядок отображения среди категорий с тем же родителем
+	SortOrder int `gorm:"not null" json:"sort_order"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Children подкатегории; заполняется при выдаче дерева категорий
+	Children []Category `gorm:"-" json:"children,omitempty"`
+}
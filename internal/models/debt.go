@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// DebtKind вид записи о долге между участниками семьи
+type DebtKind string
+
+const (
+	// DebtLoan заём: LenderID дал BorrowerID деньги в долг
+	DebtLoan DebtKind = "loan"
+	// DebtSettlement погашение: BorrowerID вернул LenderID часть долга или весь долг
+	DebtSettlement DebtKind = "settlement"
+)
+
+// Debt запись о долге между участниками семьи: заём или погашение
+// Долги из разделённых расходов сюда не пишутся — они вычисляются из разделений
+type Debt struct {
+	// ID уникальный идентификатор записи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null" json:"family_id"`
+
+	// Kind вид записи: loan или settlement
+	Kind DebtKind `gorm:"size:16;not null" json:"kind"`
+
+	// LenderID кредитор: кто дал в долг или кому вернули долг
+	LenderID uint `gorm:"not null" json:"lender_id"`
+
+	// BorrowerID должник: кто взял в долг или кто вернул долг
+	BorrowerID uint `gorm:"not null" json:"borrower_id"`
+
+	// Amount сумма в минимальных единицах валюты; всегда положительна
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта долга
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// Date дата займа или погашения
+	Date time.Time `gorm:"type:date;not null" json:"date"`
+
+	// Note комментарий
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// CreatedBy автор записи
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// OutboxStatus состояние письма в очереди отправки
+type OutboxStatus string
+
+const (
+	// OutboxPending письмо ожидает отправки (в том числе повторной)
+	OutboxPending OutboxStatus = "pending"
+	// OutboxSending письмо взято воркером в работу
+	OutboxSending OutboxStatus = "sending"
+	// OutboxSent письмо успешно доставлено
+	OutboxSent OutboxStatus = "sent"
+	// OutboxDead письмо не удалось доставить или истёк его срок; письмо без срока можно повторить вручную
+	OutboxDead OutboxStatus = "dead"
+)
+
+// EmailOutbox представляет исходящее письмо в очереди отправки
+// Письмо сохраняется уже отрисованным, чтобы повторная отправка не зависела от шаблонов
+type EmailOutbox struct {
+	// ID уникальный идентификатор записи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// MessageID идентификатор письма (заголовок Message-Id)
+	MessageID string `gorm:"size:64;uniqueIndex;not null" json:"message_id"`
+
+	// Template тип письма (имя шаблона)
+	Template string `gorm:"size:64;not null" json:"template"`
+
+	// Sender адрес отправителя
+	Sender string `gorm:"size:255;not null" json:"sender"`
+
+	// Recipient адрес получателя
+	Recipient string `gorm:"size:255;not null" json:"recipient"`
+
+	// Subject тема письма
+	Subject string `gorm:"size:255;not null" json:"subject"`
+
+	// TextBody текстовая версия письма; очищается после доставки или истечения срока
+	// Письмо может содержать код подтверждения, поэтому в API тело не отдаётся
+	TextBody string `gorm:"type:text;not null" json:"-"`
+
+	// HTMLBody HTML-версия письма; очищается вместе с TextBody
+	HTMLBody string `gorm:"type:text" json:"-"`
+
+	// Status состояние письма в очереди
+	Status OutboxStatus `gorm:"size:16;not null;index" json:"status"`
+
+	// Attempts количество выполненных попыток отправки
+	Attempts int `gorm:"not null" json:"attempts"`
+
+	// NextAttemptAt время следующей попытки отправки
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+
+	// LockedUntil время, до которого письмо закреплено за воркером
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+
+	// LastError текст последней ошибки доставки
+	LastError string `gorm:"type:text" json:"last_error,omitempty"`
+
+	// ExpiresAt время, после которого письмо не доставляется и не повторяется; nil — без срока
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// SentAt время успешной доставки
+	SentAt *time.Time `json:"sent_at,omitempty"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName возвращает имя таблицы очереди писем
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
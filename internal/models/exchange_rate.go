@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ExchangeRate курс валюты на дату: одна единица Base стоит Rate единиц Quote
+// Курс действует с даты Date до даты следующего курса той же пары
+type ExchangeRate struct {
+	// ID уникальный идентификатор записи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// Date дата, с которой действует курс
+	Date time.Time `gorm:"type:date;not null" json:"date"`
+
+	// Base валюта, курс которой указан (код ISO 4217)
+	Base string `gorm:"size:3;not null" json:"base"`
+
+	// Quote валюта, в которой выражен курс (код ISO 4217)
+	Quote string `gorm:"size:3;not null" json:"quote"`
+
+	// Rate стоимость одной единицы Base в единицах Quote, десятичное число
+	Rate string `gorm:"type:numeric(24,10);not null" json:"rate"`
+
+	// Source источник курса: имя загруженного файла или формат, например cbr
+	Source string `gorm:"size:100;not null" json:"source"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
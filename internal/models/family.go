@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// FamilyRole роль участника в семье
+type FamilyRole string
+
+const (
+	// RoleOwner владелец: управляет семьёй, участниками и всеми данными
+	RoleOwner FamilyRole = "owner"
+	// RoleAdult взрослый участник: ведёт счета, операции и бюджеты
+	RoleAdult FamilyRole = "adult"
+	// RoleChild ребёнок: видит общие данные и ведёт собственные операции
+	RoleChild FamilyRole = "child"
+	// RoleViewer наблюдатель: только просмотр
+	RoleViewer FamilyRole = "viewer"
+)
+
+// Valid сообщает, является ли роль допустимой
+func (r FamilyRole) Valid() bool {
+	switch r {
+	case RoleOwner, RoleAdult, RoleChild, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanManage сообщает, может ли роль изменять общие данные семьи
+func (r FamilyRole) CanManage() bool {
+	return r == RoleOwner || r == RoleAdult
+}
+
+// Family представляет семью (домохозяйство) с общим бюджетом
+type Family struct {
+	// ID уникальный идентификатор семьи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// Name название семьи
+	Name string `gorm:"size:100;not null" json:"name"`
+
+	// Currency основная валюта семьи (код ISO 4217)
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// Timezone часовой пояс семьи (имя из базы IANA, например Europe/Moscow)
+	Timezone string `gorm:"size:64;not null" json:"timezone"`
+
+	// Locale язык семьи (ru или en): на нём создаются категории по умолчанию
+	Locale string `gorm:"size:2;not null" json:"locale"`
+
+	// CreatedBy идентификатор пользователя, создавшего семью
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FamilyMember представляет членство пользователя в семье
+// Пользователь может состоять в нескольких семьях
+type FamilyMember struct {
+	// ID уникальный идентификатор членства
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;uniqueIndex:idx_family_members_family_user" json:"family_id"`
+
+	// UserID идентификатор пользователя
+	UserID uint `gorm:"not null;uniqueIndex:idx_family_members_family_user;index" json:"user_id"`
+
+	// Role роль участника в семье
+	Role FamilyRole `gorm:"size:16;not null" json:"role"`
+
+	// JoinedAt время вступления в семью
+	JoinedAt time.Time `gorm:"not null" json:"joined_at"`
+
+	// Family семья (заполняется при выборке семей пользователя)
+	Family *Family `gorm:"foreignKey:FamilyID" json:"family,omitempty"`
+
+	// User пользователь (заполняется при выборке участников семьи)
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
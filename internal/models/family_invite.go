@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// InviteStatus состояние приглашения в семью
+type InviteStatus string
+
+const (
+	// InvitePending приглашение ожидает ответа
+	InvitePending InviteStatus = "pending"
+	// InviteAccepted приглашение принято
+	InviteAccepted InviteStatus = "accepted"
+	// InviteDeclined приглашение отклонено
+	InviteDeclined InviteStatus = "declined"
+	// InviteRevoked приглашение отозвано владельцем семьи
+	InviteRevoked InviteStatus = "revoked"
+)
+
+// FamilyInvite представляет приглашение в семью по email
+// Приглашение может быть адресовано как зарегистрированному пользователю,
+// так и человеку, который ещё не зарегистрировался
+type FamilyInvite struct {
+	// ID уникальный идентификатор приглашения
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// Email адрес приглашённого в виде util.CanonicalEmail; на него отправляется приглашение
+	Email string `gorm:"size:100;not null" json:"email"`
+
+	// EmailKey ключ адреса, по которому приглашение сопоставляется с пользователем (см. User.EmailKey)
+	EmailKey string `gorm:"size:100;not null;index" json:"-"`
+
+	// Role роль, которую получит приглашённый
+	Role FamilyRole `gorm:"size:16;not null" json:"role"`
+
+	// TokenHash SHA-256 хеш подписанного токена приглашения
+	TokenHash string `gorm:"size:64;not null;uniqueIndex" json:"-"`
+
+	// Status состояние приглашения
+	Status InviteStatus `gorm:"size:16;not null" json:"status"`
+
+	// InvitedBy идентификатор пригласившего пользователя
+	InvitedBy uint `gorm:"not null" json:"invited_by"`
+
+	// ExpiresAt время истечения приглашения
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+
+	// RespondedAt время принятия, отклонения или отзыва
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	// AcceptedBy идентификатор пользователя, принявшего приглашение
+	AcceptedBy *uint `json:"accepted_by,omitempty"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Family семья (заполняется при выборке приглашений пользователя)
+	Family *Family `gorm:"foreignKey:FamilyID" json:"family,omitempty"`
+}
+
+// Expired сообщает, истёк ли срок действия приглашения
+func (i *FamilyInvite) Expired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
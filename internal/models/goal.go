@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Goal цель накоплений: отпуск, машина, обучение ребёнка
+// Деньги копятся на накопительном счёте AccountID; взносы — переводы на этот счёт
+// Цель на общем счёте семьи общая, на личном счёте — личная цель владельца счёта
+type Goal struct {
+	// ID уникальный идентификатор цели
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// Name название, например «Отпуск на море»
+	Name string `gorm:"size:200;not null" json:"name"`
+
+	// TargetAmount сколько нужно накопить, в минимальных единицах валюты
+	TargetAmount int64 `gorm:"not null" json:"target_amount"`
+
+	// Currency валюта цели; совпадает с валютой счёта накоплений
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// Deadline к какой дате нужно накопить; nil — срок не задан
+	Deadline *time.Time `gorm:"type:date" json:"deadline"`
+
+	// AccountID накопительный счёт или вклад, на котором копятся деньги
+	AccountID uint `gorm:"not null" json:"account_id"`
+
+	// CreatedBy идентификатор автора
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GoalContribution взнос в цель — перевод на счёт накоплений цели
+// При удалении перевода взнос удаляется вместе с ним
+type GoalContribution struct {
+	// ID уникальный идентификатор взноса
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// GoalID идентификатор цели
+	GoalID uint `gorm:"not null" json:"goal_id"`
+
+	// TransactionID перевод, которым сделан взнос
+	TransactionID uint `gorm:"not null" json:"transaction_id"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// Transaction перевод взноса; сумма взноса — сумма зачисления перевода
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// NotificationType вид уведомления; по нему пользователь настраивает доставку
+type NotificationType string
+
+const (
+	// NotificationBudgetAlert расходы по категории бюджета достигли порога
+	NotificationBudgetAlert NotificationType = "budget_alert"
+
+	// NotificationBillReminder приближается срок оплаты счёта
+	NotificationBillReminder NotificationType = "bill_reminder"
+
+	// NotificationDebtRecorded участник записал заём или погашение долга с получателем
+	NotificationDebtRecorded NotificationType = "debt_recorded"
+
+	// NotificationApprovalRequested расход ждёт одобрения по правилу семьи
+	NotificationApprovalRequested NotificationType = "approval_requested"
+
+	// NotificationApprovalDecided по расходу автора принято решение
+	NotificationApprovalDecided NotificationType = "approval_decided"
+)
+
+// NotificationTypes все виды уведомлений в порядке отображения настроек
+var NotificationTypes = []NotificationType{
+	NotificationBudgetAlert, NotificationBillReminder, NotificationDebtRecorded,
+	NotificationApprovalRequested, NotificationApprovalDecided,
+}
+
+// Valid сообщает, является ли вид уведомления допустимым
+func (t NotificationType) Valid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification уведомление в ленте пользователя внутри приложения
+type Notification struct {
+	// ID уникальный идентификатор уведомления
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// UserID получатель уведомления
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	// FamilyID семья, к которой относится уведомление
+	FamilyID *uint `json:"family_id"`
+
+	// Type вид уведомления
+	Type NotificationType `gorm:"size:32;not null" json:"type"`
+
+	// Title заголовок на языке семьи
+	Title string `gorm:"size:200;not null" json:"title"`
+
+	// Body текст уведомления на языке семьи
+	Body string `gorm:"size:1000;not null" json:"body"`
+
+	// ObjectType вид объекта, к которому относится уведомление, например budget
+	ObjectType string `gorm:"size:32;not null" json:"object_type"`
+
+	// ObjectID идентификатор объекта
+	ObjectID *uint `json:"object_id"`
+
+	// ReadAt время прочтения; nil для непрочитанного уведомления
+	ReadAt *time.Time `json:"read_at"`
+
+	// CreatedAt время создания уведомления
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationPreference настройки доставки уведомлений одного вида
+// Отсутствие записи означает, что уведомления доставляются всеми способами
+type NotificationPreference struct {
+	// UserID пользователь
+	UserID uint `gorm:"primaryKey" json:"-"`
+
+	// Type вид уведомления
+	Type NotificationType `gorm:"primaryKey;size:32" json:"type"`
+
+	// Email отправлять уведомления на почту
+	Email bool `gorm:"not null" json:"email"`
+
+	// InApp показывать уведомления в ленте приложения
+	InApp bool `gorm:"not null" json:"in_app"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// NominalAccount служебный счёт главной книги, который не хранит денег семьи,
+// а уравновешивает проводки по реальным счетам
+type NominalAccount string
+
+const (
+	// NominalIncome источник доходов
+	NominalIncome NominalAccount = "income"
+	// NominalExpense получатель расходов
+	NominalExpense NominalAccount = "expense"
+	// NominalExchange обмен валют: уравновешивает переводы между счетами в разных валютах
+	NominalExchange NominalAccount = "exchange"
+)
+
+// Posting представляет проводку главной книги — изменение одного счёта в рамках операции
+// Операция (Transaction) является записью журнала: сумма её проводок
+// в каждой валюте равна нулю
+type Posting struct {
+	// ID уникальный идентификатор проводки
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// TransactionID идентификатор операции (записи журнала)
+	TransactionID uint `gorm:"not null;index" json:"transaction_id"`
+
+	// AccountID идентификатор реального счёта; nil для служебного счёта
+	AccountID *uint `gorm:"index" json:"account_id"`
+
+	// Nominal служебный счёт; пустой для реального счёта
+	Nominal NominalAccount `gorm:"size:16;not null" json:"nominal,omitempty"`
+
+	// Amount изменение счёта в минимальных единицах валюты: положительное — поступление,
+	// отрицательное — списание
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта проводки
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+}
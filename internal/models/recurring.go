@@ -0,0 +1,147 @@
+package models
+
+import "time"
+
+// RecurrenceFrequency единица периода повторения операции
+type RecurrenceFrequency string
+
+const (
+	// RecurDaily повтор каждые Interval дней
+	RecurDaily RecurrenceFrequency = "daily"
+	// RecurWeekly повтор каждые Interval недель в день недели даты начала
+	RecurWeekly RecurrenceFrequency = "weekly"
+	// RecurMonthly повтор каждые Interval месяцев в день MonthDay
+	RecurMonthly RecurrenceFrequency = "monthly"
+	// RecurYearly повтор каждые Interval лет в день и месяц даты начала
+	RecurYearly RecurrenceFrequency = "yearly"
+)
+
+// Valid сообщает, является ли период повторения допустимым
+func (f RecurrenceFrequency) Valid() bool {
+	return f == RecurDaily || f == RecurWeekly || f == RecurMonthly || f == RecurYearly
+}
+
+// RecurrenceRule правило повторения в духе RRULE (RFC 5545): FREQ, INTERVAL, BYMONTHDAY, UNTIL
+type RecurrenceRule struct {
+	// Frequency единица периода повторения
+	Frequency RecurrenceFrequency `gorm:"size:16;not null" json:"frequency"`
+
+	// Interval повтор каждые Interval единиц периода
+	Interval int `gorm:"column:interval_count;not null" json:"interval"`
+
+	// MonthDay день месяца для ежемесячного повтора: 1-31 или -1 для последнего дня;
+	// 0 — день даты начала. Если в месяце меньше дней, берётся последний день
+	MonthDay int `gorm:"not null" json:"month_day"`
+
+	// BusinessDay переносит дату, выпавшую на субботу или воскресенье, на предшествующую пятницу,
+	// а если пятница раньше StartDate — на следующий понедельник
+	// Вместе с MonthDay = -1 задаёт последний рабочий день месяца
+	BusinessDay bool `gorm:"not null" json:"business_day"`
+
+	// StartDate дата первого повторения
+	StartDate time.Time `gorm:"type:date;not null" json:"start_date"`
+
+	// EndDate последняя дата, до которой включительно создаются повторения; nil — без окончания
+	EndDate *time.Time `gorm:"type:date" json:"end_date"`
+}
+
+// RecurringTransaction шаблон регулярной операции: аренда, коммунальные платежи, подписки
+// Планировщик проводит операции по шаблону в даты, заданные правилом повторения
+type RecurringTransaction struct {
+	// ID уникальный идентификатор шаблона
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// AccountID счёт, по которому проводятся операции
+	AccountID uint `gorm:"not null" json:"account_id"`
+
+	// Direction направление операций: доход или расход
+	Direction Direction `gorm:"size:16;not null" json:"direction"`
+
+	// Amount сумма в минимальных единицах валюты счёта
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта счёта
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// CategoryID идентификатор категории
+	CategoryID *uint `json:"category_id"`
+
+	// Payee получатель или плательщик
+	Payee string `gorm:"size:200;not null" json:"payee"`
+
+	// Note комментарий
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// Rule правило повторения
+	Rule RecurrenceRule `gorm:"embedded" json:"rule"`
+
+	// NextDate дата следующего повторения, которое ещё не проведено; nil, если повторения закончились
+	NextDate *time.Time `gorm:"type:date" json:"next_date"`
+
+	// Paused приостановленный шаблон не проводит операции
+	Paused bool `gorm:"not null" json:"paused"`
+
+	// CreatedBy идентификатор автора; он же автор проведённых операций
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OccurrenceStatus состояние отдельного повторения регулярной операции
+type OccurrenceStatus string
+
+const (
+	// OccurrenceScheduled повторение ещё не проведено и изменено отдельно от шаблона
+	OccurrenceScheduled OccurrenceStatus = "scheduled"
+	// OccurrenceSkipped повторение пропущено
+	OccurrenceSkipped OccurrenceStatus = "skipped"
+	// OccurrencePosted операция по повторению проведена
+	OccurrencePosted OccurrenceStatus = "posted"
+	// OccurrencePlanned повторение ещё не проведено и совпадает с шаблоном; в базе не хранится
+	OccurrencePlanned OccurrenceStatus = "planned"
+)
+
+// RecurringOccurrence повторение регулярной операции, отличающееся от шаблона:
+// изменённое, пропущенное или уже проведённое. Дата повторения уникальна в пределах шаблона,
+// поэтому одно повторение не может быть проведено дважды
+type RecurringOccurrence struct {
+	// ID уникальный идентификатор записи
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// RecurringID идентификатор шаблона
+	RecurringID uint `gorm:"not null" json:"recurring_id"`
+
+	// Date дата повторения по правилу
+	Date time.Time `gorm:"type:date;not null" json:"date"`
+
+	// Status состояние повторения
+	Status OccurrenceStatus `gorm:"size:16;not null" json:"status"`
+
+	// TransactionID проведённая операция
+	TransactionID *uint `json:"transaction_id"`
+
+	// Amount сумма этого повторения вместо суммы шаблона
+	Amount *int64 `json:"amount"`
+
+	// CategoryID категория этого повторения вместо категории шаблона
+	CategoryID *uint `json:"category_id"`
+
+	// Payee получатель этого повторения вместо получателя шаблона
+	Payee *string `gorm:"size:200" json:"payee"`
+
+	// Note комментарий этого повторения вместо комментария шаблона
+	Note *string `gorm:"size:1000" json:"note"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
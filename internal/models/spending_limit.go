@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// LimitPeriod период, за который считается лимит расходов участника
+type LimitPeriod string
+
+const (
+	// LimitDaily лимит на календарный день
+	LimitDaily LimitPeriod = "day"
+	// LimitWeekly лимит на неделю с понедельника по воскресенье
+	LimitWeekly LimitPeriod = "week"
+	// LimitMonthly лимит на календарный месяц
+	LimitMonthly LimitPeriod = "month"
+)
+
+// Valid сообщает, является ли период допустимым
+func (p LimitPeriod) Valid() bool {
+	return p == LimitDaily || p == LimitWeekly || p == LimitMonthly
+}
+
+// LimitAction что делать с расходом, превышающим лимит
+type LimitAction string
+
+const (
+	// LimitReject расход не сохраняется
+	LimitReject LimitAction = "reject"
+	// LimitFlag расход сохраняется с отметкой о превышении
+	LimitFlag LimitAction = "flag"
+)
+
+// Valid сообщает, является ли действие допустимым
+func (a LimitAction) Valid() bool {
+	return a == LimitReject || a == LimitFlag
+}
+
+// SpendingLimit лимит расходов участника семьи за период по одному счёту или по всем счетам семьи
+// Учитываются расходы, которые участник записал сам
+type SpendingLimit struct {
+	// ID уникальный идентификатор лимита
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// UserID участник, на расходы которого действует лимит
+	UserID uint `gorm:"not null" json:"user_id"`
+
+	// AccountID счёт; nil — расходы по всем счетам семьи
+	AccountID *uint `json:"account_id"`
+
+	// Amount лимит в минимальных единицах валюты Currency
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта лимита; для лимита по счёту совпадает с валютой счёта
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// Period период лимита
+	Period LimitPeriod `gorm:"size:16;not null" json:"period"`
+
+	// Action действие при превышении
+	Action LimitAction `gorm:"size:16;not null" json:"action"`
+
+	// CreatedBy идентификатор автора лимита
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
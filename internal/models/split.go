@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// SplitMethod способ разделения суммы операции между участниками
+type SplitMethod string
+
+const (
+	// SplitEqual поровну
+	SplitEqual SplitMethod = "equal"
+	// SplitExact точные суммы для каждого участника
+	SplitExact SplitMethod = "exact"
+	// SplitPercent доли в процентах
+	SplitPercent SplitMethod = "percent"
+)
+
+// Valid сообщает, является ли способ разделения допустимым
+func (m SplitMethod) Valid() bool {
+	return m == SplitEqual || m == SplitExact || m == SplitPercent
+}
+
+// Split разделение расхода между участниками семьи
+// Изменение разделения создаёт новую версию, а предыдущая помечается заменённой,
+// поэтому история разделений операции сохраняется
+type Split struct {
+	// ID уникальный идентификатор версии разделения
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// TransactionID разделяемая операция
+	TransactionID uint `gorm:"not null" json:"transaction_id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null" json:"family_id"`
+
+	// Version номер версии разделения операции, начиная с 1
+	Version int `gorm:"not null" json:"version"`
+
+	// Method способ разделения
+	Method SplitMethod `gorm:"size:16;not null" json:"method"`
+
+	// Amount сумма операции на момент разделения в минимальных единицах валюты
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта операции
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// PaidBy участник, который заплатил
+	PaidBy uint `gorm:"not null" json:"paid_by"`
+
+	// CreatedBy автор версии
+	CreatedBy uint `gorm:"not null" json:"created_by"`
+
+	// CreatedAt время создания версии
+	CreatedAt time.Time `json:"created_at"`
+
+	// SupersededAt время замены версии новой или отмены разделения; nil у действующей версии
+	SupersededAt *time.Time `json:"superseded_at"`
+
+	// Shares доли участников
+	Shares []SplitShare `gorm:"foreignKey:SplitID" json:"shares"`
+}
+
+// SplitShare доля участника в разделении
+type SplitShare struct {
+	// ID уникальный идентификатор доли
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// SplitID идентификатор версии разделения
+	SplitID uint `gorm:"not null" json:"-"`
+
+	// UserID участник семьи
+	UserID uint `gorm:"not null" json:"user_id"`
+
+	// Amount доля в минимальных единицах валюты операции
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Percent доля в процентах для способа percent
+	Percent *string `gorm:"type:numeric(5,2)" json:"percent,omitempty"`
+
+	// User участник семьи
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName возвращает имя таблицы версий разделения
+func (Split) TableName() string {
+	return "transaction_splits"
+}
+
+// TableName возвращает имя таблицы долей разделения
+func (SplitShare) TableName() string {
+	return "transaction_split_shares"
+}
@@ -0,0 +1,206 @@
+package models
+
+import "time"
+
+// StatementMapping сопоставление столбцов банковской выписки полям операции и параметры разбора
+// Пустые поля определяются по содержимому файла. Столбец задаётся именем из строки заголовка
+// (без учёта регистра) или номером, начиная с 1
+type StatementMapping struct {
+	// Encoding кодировка файла: utf-8 или windows-1251
+	Encoding string `gorm:"size:16;not null" json:"encoding"`
+
+	// Delimiter разделитель столбцов: «;», «,», табуляция или «|»
+	Delimiter string `gorm:"size:1;not null" json:"delimiter"`
+
+	// DateFormat формат даты, например DD.MM.YYYY
+	DateFormat string `gorm:"size:16;not null" json:"date_format"`
+
+	// DecimalSeparator десятичный разделитель сумм: «,» или «.»
+	DecimalSeparator string `gorm:"size:1;not null" json:"decimal_separator"`
+
+	// DateColumn столбец даты операции
+	DateColumn string `gorm:"size:100;not null" json:"date_column"`
+
+	// AmountColumn столбец суммы со знаком: отрицательная сумма — списание
+	AmountColumn string `gorm:"size:100;not null" json:"amount_column"`
+
+	// DebitColumn столбец суммы списания; вместе с CreditColumn заменяет AmountColumn
+	DebitColumn string `gorm:"size:100;not null" json:"debit_column"`
+
+	// CreditColumn столбец суммы зачисления
+	CreditColumn string `gorm:"size:100;not null" json:"credit_column"`
+
+	// PayeeColumn столбец получателя или описания операции
+	PayeeColumn string `gorm:"size:100;not null" json:"payee_column"`
+
+	// NoteColumn столбец комментария
+	NoteColumn string `gorm:"size:100;not null" json:"note_column"`
+
+	// CategoryColumn столбец категории банка
+	CategoryColumn string `gorm:"size:100;not null" json:"category_column"`
+}
+
+// StatementProfile сохранённое сопоставление столбцов для выписок одного банка
+// Профиль принадлежит пользователю, который его создал
+type StatementProfile struct {
+	// ID уникальный идентификатор профиля
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// UserID владелец профиля
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	// Name название профиля, обычно название банка
+	Name string `gorm:"size:100;not null" json:"name"`
+
+	// Mapping сопоставление столбцов
+	Mapping StatementMapping `gorm:"embedded" json:"mapping"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImportStatus состояние импорта выписки
+type ImportStatus string
+
+const (
+	// ImportParsing файл ждёт разбора фоновой задачей
+	ImportParsing ImportStatus = "parsing"
+	// ImportReady файл разобран, строки доступны для просмотра и правки
+	ImportReady ImportStatus = "ready"
+	// ImportCommitting строки ждут проведения фоновой задачей
+	ImportCommitting ImportStatus = "committing"
+	// ImportCompleted операции проведены
+	ImportCompleted ImportStatus = "completed"
+	// ImportFailed файл не удалось разобрать; причина в Error, файл можно разобрать заново
+	// с другим сопоставлением
+	ImportFailed ImportStatus = "failed"
+)
+
+// StatementImport загруженная банковская выписка по счёту семьи
+// Строки выписки проводятся как операции счёта после просмотра и подтверждения
+type StatementImport struct {
+	// ID уникальный идентификатор импорта
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// UserID пользователь, загрузивший выписку
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	// FamilyID идентификатор семьи счёта
+	FamilyID uint `gorm:"not null" json:"family_id"`
+
+	// AccountID счёт, операции по которому содержит выписка
+	AccountID uint `gorm:"not null" json:"account_id"`
+
+	// Currency валюта счёта; суммы строк указаны в ней
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// ProfileID профиль сопоставления, с которым загружена выписка
+	ProfileID *uint `json:"profile_id"`
+
+	// FileName имя загруженного файла
+	FileName string `gorm:"size:255;not null" json:"file_name"`
+
+	// File содержимое файла; хранится для разбора в фоне и повторного разбора
+	File []byte `gorm:"type:bytea;not null" json:"-"`
+
+	// FileHash SHA-256 содержимого файла
+	FileHash string `gorm:"size:64;not null" json:"file_hash"`
+
+	// Status состояние импорта
+	Status ImportStatus `gorm:"size:16;not null" json:"status"`
+
+	// Error причина, по которой файл не удалось разобрать или провести
+	Error string `gorm:"size:1000;not null" json:"error"`
+
+	// Mapping сопоставление, с которым разобран файл: заданное и определённое автоматически
+	Mapping StatementMapping `gorm:"embedded" json:"mapping"`
+
+	// TotalRows количество операций в выписке
+	TotalRows int `gorm:"not null" json:"total_rows"`
+
+	// ErrorRows количество строк, которые не удалось разобрать или провести
+	ErrorRows int `gorm:"not null" json:"error_rows"`
+
+	// DuplicateRows количество строк, уже импортированных на этот счёт
+	DuplicateRows int `gorm:"not null" json:"duplicate_rows"`
+
+	// ImportedRows количество проведённых строк
+	ImportedRows int `gorm:"not null" json:"imported_rows"`
+
+	// CompletedAt время проведения операций
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Rows строки выписки
+	Rows []StatementImportRow `gorm:"foreignKey:ImportID" json:"rows,omitempty"`
+}
+
+// StatementImportRow строка выписки, из которой при подтверждении импорта создаётся операция
+type StatementImportRow struct {
+	// ID уникальный идентификатор строки
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// ImportID идентификатор импорта
+	ImportID uint `gorm:"not null;index" json:"import_id"`
+
+	// Line номер строки в файле
+	Line int `gorm:"not null" json:"line"`
+
+	// Date дата операции; nil, если строку не удалось разобрать
+	Date *time.Time `gorm:"type:date" json:"date"`
+
+	// Direction направление операции: доход или расход
+	Direction Direction `gorm:"size:16;not null" json:"direction"`
+
+	// Amount сумма в минимальных единицах валюты счёта; всегда положительна
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Payee получатель или описание операции
+	Payee string `gorm:"size:200;not null" json:"payee"`
+
+	// Note комментарий
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// BankCategory категория, присвоенная банком
+	BankCategory string `gorm:"size:200;not null" json:"bank_category"`
+
+	// CategoryID категория операции: предложенная по истории операций семьи или выбранная пользователем
+	CategoryID *uint `json:"category_id"`
+
+	// Fingerprint отпечаток строки, по которому распознаются повторно загруженные операции
+	Fingerprint string `gorm:"size:64;not null" json:"-"`
+
+	// Duplicate операция уже импортирована на этот счёт из другой выписки
+	Duplicate bool `gorm:"not null" json:"duplicate"`
+
+	// Skip строка не проводится
+	Skip bool `gorm:"not null" json:"skip"`
+
+	// Error причина, по которой строку не удалось разобрать или провести (превышен лимит расходов)
+	Error string `gorm:"size:500;not null" json:"error"`
+
+	// TransactionID операция, созданная из строки
+	TransactionID *uint `json:"transaction_id"`
+}
+
+// StatementFingerprint отметка об операции, импортированной на счёт из выписки
+// Уникальна для счёта и отпечатка строки, поэтому повторная загрузка выписки
+// не создаёт операции дважды
+type StatementFingerprint struct {
+	// AccountID счёт
+	AccountID uint `gorm:"primaryKey;autoIncrement:false" json:"account_id"`
+
+	// Fingerprint отпечаток строки выписки
+	Fingerprint string `gorm:"primaryKey;size:64" json:"fingerprint"`
+
+	// CreatedAt время импорта
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Direction направление движения денег в операции
+type Direction string
+
+const (
+	// DirectionIncome доход: деньги поступают на счёт
+	DirectionIncome Direction = "income"
+	// DirectionExpense расход: деньги списываются со счёта
+	DirectionExpense Direction = "expense"
+	// DirectionTransfer перевод: деньги переходят со счёта AccountID на счёт CounterAccountID
+	DirectionTransfer Direction = "transfer"
+)
+
+// Valid сообщает, является ли направление допустимым
+func (d Direction) Valid() bool {
+	return d == DirectionIncome || d == DirectionExpense || d == DirectionTransfer
+}
+
+// TransactionStatus состояние операции в главной книге
+type TransactionStatus string
+
+const (
+	// TransactionPosted проведённая операция: у неё есть проводки, она влияет на остатки
+	TransactionPosted TransactionStatus = "posted"
+	// TransactionPending расход ждёт одобрения по правилу семьи; проводок у него нет
+	TransactionPending TransactionStatus = "pending"
+	// TransactionRejected расход отклонён; проводок у него нет
+	TransactionRejected TransactionStatus = "rejected"
+)
+
+// Transaction представляет операцию по счёту: доход, расход или перевод между счетами
+// Операция является записью журнала главной книги; её проводки (Posting)
+// определяют остатки счетов
+type Transaction struct {
+	// ID уникальный идентификатор операции
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// FamilyID идентификатор семьи
+	FamilyID uint `gorm:"not null;index" json:"family_id"`
+
+	// AccountID идентификатор счёта
+	AccountID uint `gorm:"not null;index" json:"account_id"`
+
+	// Direction направление операции
+	Direction Direction `gorm:"size:16;not null" json:"direction"`
+
+	// Amount сумма в минимальных единицах валюты; всегда положительна
+	// Для перевода — сумма списания со счёта AccountID
+	Amount int64 `gorm:"not null" json:"amount"`
+
+	// Currency валюта операции; совпадает с валютой счёта
+	Currency string `gorm:"size:3;not null" json:"currency"`
+
+	// CounterAccountID счёт зачисления перевода
+	CounterAccountID *uint `gorm:"index" json:"counter_account_id,omitempty"`
+
+	// CounterAmount сумма зачисления перевода в валюте счёта зачисления
+	CounterAmount *int64 `json:"counter_amount,omitempty"`
+
+	// CounterCurrency валюта счёта зачисления перевода
+	CounterCurrency *string `gorm:"size:3" json:"counter_currency,omitempty"`
+
+	// Rate курс перевода между валютами: сколько единиц CounterCurrency стоит единица Currency
+	Rate *string `gorm:"type:numeric(24,10)" json:"rate,omitempty"`
+
+	// CategoryID идентификатор категории
+	CategoryID *uint `gorm:"index" json:"category_id"`
+
+	// Date дата операции в часовом поясе семьи
+	Date time.Time `gorm:"type:date;not null" json:"date"`
+
+	// Payee получатель или плательщик
+	Payee string `gorm:"size:200;not null" json:"payee"`
+
+	// Note комментарий
+	Note string `gorm:"size:1000;not null" json:"note"`
+
+	// Status состояние операции; на остатки влияют только проведённые операции
+	Status TransactionStatus `gorm:"size:16;not null" json:"status"`
+
+	// Flagged расход превысил лимит автора, при превышении которого расход только отмечается
+	Flagged bool `gorm:"not null" json:"flagged"`
+
+	// CreatedBy идентификатор автора операции
+	CreatedBy uint `gorm:"not null;index" json:"created_by"`
+
+	// CreatedAt время создания записи
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt время последнего обновления записи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Author автор операции
+	Author *User `gorm:"foreignKey:CreatedBy" json:"author,omitempty"`
+}
+
+// IsTransfer сообщает, является ли операция переводом между счетами
+func (t *Transaction) IsTransfer() bool {
+	return t.Direction == DirectionTransfer
+}
+
+// Posted сообщает, проведена ли операция
+func (t *Transaction) Posted() bool {
+	return t.Status == TransactionPosted
+}
@@ -16,8 +16,13 @@ type User struct {
 	// Nickname псевдоним пользователя
 	Nickname string `gorm:"size:100;not null" json:"nickname"`
 
-	// Email электронная почта пользователя (уникальная)
-	Email string `gorm:"size:100;uniqueIndex;not null" json:"email"`
+	// Email электронная почта пользователя в виде util.CanonicalEmail; на неё отправляются письма
+	// Уникальность проверяется без учёта регистра (индекс по lower(email))
+	Email string `gorm:"size:100;not null;uniqueIndex:idx_users_email_lower,expression:lower(email)" json:"email"`
+
+	// EmailKey ключ адреса для поиска и проверки уникальности: адрес по правилам util.EmailNormalizer,
+	// например без точек и «+меток» в Gmail. Для доставки писем не используется
+	EmailKey string `gorm:"size:100;not null;uniqueIndex:idx_users_email_key" json:"-"`
 
 	// CreatedAt время создания записи
 	CreatedAt time.Time `json:"created_at"`
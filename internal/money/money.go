@@ -0,0 +1,197 @@
+// Package money содержит операции с денежными суммами
+// Суммы хранятся целым числом минимальных единиц валюты (копеек, центов),
+// чтобы исключить ошибки округления чисел с плавающей точкой
+// Количество знаков после запятой зависит от валюты (ISO 4217)
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxAmount наибольшая допустимая сумма в минимальных единицах
+// Запас до предела int64 позволяет суммировать миллионы операций без переполнения
+const MaxAmount int64 = 1_000_000_000_000_000
+
+var (
+	// ErrInvalidAmount возвращается, если строку нельзя разобрать как сумму
+	ErrInvalidAmount = errors.New("некорректная сумма")
+
+	// ErrPrecision возвращается, если в сумме больше знаков после запятой, чем допускает валюта
+	ErrPrecision = errors.New("слишком много знаков после запятой для этой валюты")
+
+	// ErrOutOfRange возвращается, если сумма превышает MaxAmount
+	ErrOutOfRange = errors.New("сумма слишком велика")
+)
+
+// Exponent возвращает количество знаков после запятой для валюты по справочнику ISO 4217
+// Для валют, которых нет в справочнике, используются два знака
+func Exponent(currency string) int {
+	if c, ok := byCode[strings.ToUpper(currency)]; ok {
+		return c.MinorUnits
+	}
+	return 2
+}
+
+// scale возвращает 10^exp
+func scale(exp int) int64 {
+	s := int64(1)
+	for i := 0; i < exp; i++ {
+		s *= 10
+	}
+	return s
+}
+
+// Parse разбирает сумму в основных единицах валюты ("1 234,56", "-12.5", "100")
+// и возвращает её в минимальных единицах
+// Пробелы как разделители разрядов допускаются; дробная часть отделяется точкой или запятой
+func Parse(s, currency string) (int64, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	if intPart == "" && fracPart == "" || hasFrac && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	// Второй разделитель или посторонний знак в дробной части — некорректная сумма, а не лишняя точность
+	if strings.Trim(fracPart, "0123456789") != "" {
+		return 0, ErrInvalidAmount
+	}
+	exp := Exponent(currency)
+	// Незначащие нули в конце дробной части не считаются лишней точностью
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > exp {
+		return 0, ErrPrecision
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	var minor int64
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+		minor = minor*10 + int64(r-'0')
+		if minor > MaxAmount {
+			return 0, ErrOutOfRange
+		}
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// Format возвращает сумму в основных единицах валюты без разделителей разрядов: "-1234.56"
+func Format(amount int64, currency string) string {
+	return format(amount, Exponent(currency), ".", "")
+}
+
+// symbols знаки распространённых валют
+var symbols = map[string]string{
+	"RUB": "₽", "USD": "$", "EUR": "€", "GBP": "£", "CNY": "¥", "JPY": "¥",
+	"KZT": "₸", "UAH": "₴", "BYN": "Br", "TRY": "₺", "GEL": "₾", "AMD": "֏",
+}
+
+// Display возвращает сумму для показа пользователю с учётом языка:
+// "1 234,56 ₽" (с неразрывными пробелами) для ru и "₽1,234.56" для en
+// Для валют без известного знака используется код валюты
+func Display(amount int64, currency, locale string) string {
+	currency = strings.ToUpper(currency)
+	exp := Exponent(currency)
+	symbol, ok := symbols[currency]
+	if locale == "en" {
+		number := format(amount, exp, ".", ",")
+		sign := ""
+		if amount < 0 {
+			sign, number = "-", number[1:]
+		}
+		if !ok {
+			return sign + currency + " " + number
+		}
+		return sign + symbol + number
+	}
+	if !ok {
+		symbol = currency
+	}
+	return format(amount, exp, ",", " ") + " " + symbol
+}
+
+// format форматирует сумму с указанным разделителем дробной части и разрядов
+func format(amount int64, exp int, decimalSep, groupSep string) string {
+	negative := amount < 0
+	u := uint64(amount)
+	if negative {
+		u = uint64(-amount)
+	}
+	s := scale(exp)
+	intPart, fracPart := u/uint64(s), u%uint64(s)
+
+	digits := strconv.FormatUint(intPart, 10)
+	if groupSep != "" {
+		var b strings.Builder
+		for i, r := range digits {
+			if i > 0 && (len(digits)-i)%3 == 0 {
+				b.WriteString(groupSep)
+			}
+			b.WriteRune(r)
+		}
+		digits = b.String()
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteString(digits)
+	if exp > 0 {
+		frac := strconv.FormatUint(fracPart, 10)
+		b.WriteString(decimalSep)
+		b.WriteString(strings.Repeat("0", exp-len(frac)))
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Round округляет дробное количество минимальных единиц до целого
+// по правилу «половина — от нуля»
+func Round(r *big.Rat) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// |остаток| * 2 >= знаменатель — округляем от нуля
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo.Int64()
+}
+
+// Convert пересчитывает сумму из одной валюты в другую по курсу
+// (сколько единиц валюты to стоит одна единица валюты from)
+// Результат округляется до минимальной единицы валюты to
+func Convert(amount int64, from, to string, rate *big.Rat) (int64, error) {
+	r := new(big.Rat).SetInt64(amount)
+	r.Mul(r, rate)
+	r.Mul(r, new(big.Rat).SetFrac64(scale(Exponent(to)), scale(Exponent(from))))
+	if new(big.Rat).Abs(r).Cmp(new(big.Rat).SetInt64(MaxAmount)) > 0 {
+		return 0, ErrOutOfRange
+	}
+	return Round(r), nil
+}
@@ -0,0 +1,150 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{name: "целое число", input: "100", currency: "RUB", want: 10000},
+		{name: "запятая и пробелы в разрядах", input: "1 234,56", currency: "RUB", want: 123456},
+		{name: "неразрывные пробелы и апостроф", input: "1\u00a0234\u202f567'00.5", currency: "RUB", want: 12345670050},
+		{name: "точка и одна цифра дроби", input: "12.5", currency: "EUR", want: 1250},
+		{name: "отрицательная сумма", input: "-12.5", currency: "RUB", want: -1250},
+		{name: "явный плюс", input: "+7", currency: "RUB", want: 700},
+		{name: "только дробная часть", input: ".5", currency: "RUB", want: 50},
+		{name: "минус ноль", input: "-0", currency: "RUB", want: 0},
+		{name: "три знака у динара", input: "1.005", currency: "KWD", want: 1005},
+		{name: "иена без дробной части", input: "1500", currency: "JPY", want: 1500},
+		{name: "незначащие нули у иены", input: "1500.00", currency: "JPY", want: 1500},
+		{name: "неизвестная валюта — два знака", input: "1.23", currency: "XXX", want: 123},
+		{name: "код валюты в нижнем регистре", input: "1.005", currency: "kwd", want: 1005},
+		{name: "наибольшая сумма", input: "10000000000000", currency: "RUB", want: MaxAmount},
+		{name: "наибольшая отрицательная сумма", input: "-10000000000000", currency: "RUB", want: -MaxAmount},
+
+		{name: "лишний знак после запятой", input: "1.005", currency: "RUB", wantErr: ErrPrecision},
+		{name: "дробная часть у иены", input: "1.5", currency: "JPY", wantErr: ErrPrecision},
+		{name: "больше наибольшей суммы", input: "10000000000000.01", currency: "RUB", wantErr: ErrOutOfRange},
+		{name: "переполнение int64", input: "99999999999999999999999", currency: "RUB", wantErr: ErrOutOfRange},
+		{name: "пустая строка", input: "", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "только знак", input: "-", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "точка без дроби", input: "12.", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "два разделителя", input: "1,234.56", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "двойной минус", input: "--5", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "буквы", input: "12abc", currency: "RUB", wantErr: ErrInvalidAmount},
+		{name: "экспонента", input: "1e3", currency: "RUB", wantErr: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.currency)
+			if err != tt.wantErr {
+				t.Fatalf("Parse(%q, %s) error = %v, want %v", tt.input, tt.currency, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %s) = %d, want %d", tt.input, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency string
+		want     string
+	}{
+		{name: "ноль", amount: 0, currency: "RUB", want: "0.00"},
+		{name: "копейки", amount: 5, currency: "RUB", want: "0.05"},
+		{name: "без разделителей разрядов", amount: 123456, currency: "RUB", want: "1234.56"},
+		{name: "отрицательная сумма", amount: -123456, currency: "RUB", want: "-1234.56"},
+		{name: "отрицательные копейки", amount: -5, currency: "RUB", want: "-0.05"},
+		{name: "иена", amount: 1500, currency: "JPY", want: "1500"},
+		{name: "три знака", amount: 1005, currency: "KWD", want: "1.005"},
+		{name: "наибольшая сумма", amount: MaxAmount, currency: "RUB", want: "10000000000000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.amount, tt.currency)
+			if got != tt.want {
+				t.Errorf("Format(%d, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+			// Отформатированная сумма разбирается обратно без потерь
+			if back, err := Parse(got, tt.currency); err != nil || back != tt.amount {
+				t.Errorf("Parse(%q) = %d, %v, want %d", got, back, err, tt.amount)
+			}
+		})
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency string
+		locale   string
+		want     string
+	}{
+		{name: "рубли по-русски", amount: 123456, currency: "RUB", locale: "ru", want: "1\u00a0234,56\u00a0₽"},
+		{name: "отрицательная сумма по-русски", amount: -100000000, currency: "RUB", locale: "ru", want: "-1\u00a0000\u00a0000,00\u00a0₽"},
+		{name: "рубли по-английски", amount: 123456, currency: "RUB", locale: "en", want: "₽1,234.56"},
+		{name: "отрицательная сумма по-английски", amount: -123456, currency: "USD", locale: "en", want: "-$1,234.56"},
+		{name: "валюта без знака", amount: 1005, currency: "KWD", locale: "en", want: "KWD\u00a01.005"},
+		{name: "валюта без знака по-русски", amount: 1500, currency: "chf", locale: "ru", want: "15,00\u00a0CHF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Display(tt.amount, tt.currency, tt.locale); got != tt.want {
+				t.Errorf("Display(%d, %s, %s) = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rate := func(s string) *big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			t.Fatalf("bad rate %q", s)
+		}
+		return r
+	}
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to string
+		rate     string
+		want     int64
+		wantErr  error
+	}{
+		{name: "точный пересчёт", amount: 10000, from: "USD", to: "RUB", rate: "92.5", want: 925000},
+		{name: "половина округляется вверх", amount: 1, from: "USD", to: "RUB", rate: "0.5", want: 1},
+		{name: "меньше половины округляется вниз", amount: 1, from: "USD", to: "RUB", rate: "0.49", want: 0},
+		{name: "отрицательная половина — от нуля", amount: -1, from: "USD", to: "RUB", rate: "0.5", want: -1},
+		{name: "отрицательная сумма меньше половины", amount: -1, from: "USD", to: "RUB", rate: "0.49", want: 0},
+		{name: "в валюту без дробной части", amount: 12345, from: "USD", to: "JPY", rate: "150", want: 18518},
+		{name: "из валюты без дробной части", amount: 1500, from: "JPY", to: "USD", rate: "0.0066667", want: 1000},
+		{name: "в валюту с тремя знаками", amount: 100, from: "EUR", to: "KWD", rate: "0.3345", want: 335},
+		{name: "наибольшая сумма", amount: MaxAmount, from: "RUB", to: "RUB", rate: "1", want: MaxAmount},
+		{name: "больше наибольшей суммы", amount: MaxAmount, from: "USD", to: "RUB", rate: "92.5", wantErr: ErrOutOfRange},
+		{name: "отрицательная больше наибольшей", amount: -MaxAmount, from: "USD", to: "RUB", rate: "92.5", wantErr: ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.amount, tt.from, tt.to, rate(tt.rate))
+			if err != tt.wantErr {
+				t.Fatalf("Convert error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%d %s -> %s at %s) = %d, want %d", tt.amount, tt.from, tt.to, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package rates разбирает файлы с курсами валют для загрузки в справочник курсов
+// Поддерживаются ежедневный XML Банка России (XML_daily.asp) и CSV со столбцами
+// date, base, quote, rate и необязательным nominal
+package rates
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Format формат файла с курсами
+type Format string
+
+const (
+	// FormatAuto определить формат по содержимому файла
+	FormatAuto Format = "auto"
+	// FormatCBR ежедневный XML Банка России: курсы валют к рублю
+	FormatCBR Format = "cbr"
+	// FormatCSV CSV со столбцами date, base, quote, rate[, nominal]
+	FormatCSV Format = "csv"
+)
+
+// Valid сообщает, является ли значение допустимым форматом
+func (f Format) Valid() bool {
+	return f == FormatAuto || f == FormatCBR || f == FormatCSV
+}
+
+// rateScale количество знаков после запятой у сохраняемого курса (numeric(24,10))
+const rateScale = 10
+
+// ErrEmpty возвращается, если в файле нет ни одного курса
+var ErrEmpty = errors.New("в файле нет курсов валют")
+
+// Parse читает курсы из r в формате format
+// Курс пересчитывается на одну единицу валюты base с учётом номинала
+func Parse(r io.Reader, format Format) ([]models.ExchangeRate, error) {
+	br := bufio.NewReader(r)
+	if format == FormatAuto {
+		format = detect(br)
+	}
+	var (
+		list []models.ExchangeRate
+		err  error
+	)
+	switch format {
+	case FormatCBR:
+		list, err = parseCBR(br)
+	case FormatCSV:
+		list, err = parseCSV(br)
+	default:
+		return nil, fmt.Errorf("неизвестный формат файла курсов %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrEmpty
+	}
+	return list, nil
+}
+
+// detect определяет формат по первым байтам: XML начинается с «<»
+func detect(br *bufio.Reader) Format {
+	head, _ := br.Peek(512)
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) {
+		return FormatCBR
+	}
+	return FormatCSV
+}
+
+// cbrDocument ежедневный XML Банка России
+type cbrDocument struct {
+	Date    string `xml:"Date,attr"`
+	Valutes []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+}
+
+// parseCBR разбирает ежедневный XML Банка России; файл обычно в кодировке windows-1251
+func parseCBR(r io.Reader) ([]models.ExchangeRate, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "windows-1251", "cp1251":
+			return charmap.Windows1251.NewDecoder().Reader(input), nil
+		case "utf-8", "utf8":
+			return input, nil
+		}
+		return nil, fmt.Errorf("неподдерживаемая кодировка %q", label)
+	}
+	var doc cbrDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("некорректный XML: %w", err)
+	}
+	date, err := parseDate(doc.Date)
+	if err != nil {
+		return nil, fmt.Errorf("атрибут Date: %w", err)
+	}
+	list := make([]models.ExchangeRate, 0, len(doc.Valutes))
+	for _, v := range doc.Valutes {
+		rate, err := perUnit(v.Value, v.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("валюта %s: %w", v.CharCode, err)
+		}
+		list = append(list, models.ExchangeRate{
+			Date:  date,
+			Base:  strings.ToUpper(strings.TrimSpace(v.CharCode)),
+			Quote: "RUB",
+			Rate:  rate,
+		})
+	}
+	return list, nil
+}
+
+// parseCSV разбирает CSV с курсами; разделитель — запятая или точка с запятой
+// Первая строка может быть заголовком с именами столбцов date, base, quote, rate, nominal;
+// без заголовка столбцы идут в этом порядке
+func parseCSV(br *bufio.Reader) ([]models.ExchangeRate, error) {
+	head, _ := br.Peek(512)
+	firstLine, _, _ := bytes.Cut(head, []byte("\n"))
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	columns := map[string]int{"date": 0, "base": 1, "quote": 2, "rate": 3, "nominal": -1}
+	var list []models.ExchangeRate
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if _, err := parseDate(record[0]); err != nil {
+				if err := readHeader(record, columns); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		rate, err := csvRate(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		list = append(list, rate)
+	}
+	return list, nil
+}
+
+// readHeader сопоставляет именам столбцов их позиции по строке заголовка
+func readHeader(record []string, columns map[string]int) error {
+	for name := range columns {
+		columns[name] = -1
+	}
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if columns[name] < 0 {
+			return fmt.Errorf("в заголовке нет столбца %s", name)
+		}
+	}
+	return nil
+}
+
+// csvRate разбирает строку CSV с курсом
+func csvRate(record []string, columns map[string]int) (models.ExchangeRate, error) {
+	field := func(name string) string {
+		i := columns[name]
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	date, err := parseDate(field("date"))
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	nominal := field("nominal")
+	if nominal == "" {
+		nominal = "1"
+	}
+	rate, err := perUnit(field("rate"), nominal)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	return models.ExchangeRate{
+		Date:  date,
+		Base:  strings.ToUpper(field("base")),
+		Quote: strings.ToUpper(field("quote")),
+		Rate:  rate,
+	}, nil
+}
+
+// parseDate разбирает дату в формате ГГГГ-ММ-ДД или ДД.ММ.ГГГГ
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("некорректная дата %q: ожидается ГГГГ-ММ-ДД или ДД.ММ.ГГГГ", value)
+}
+
+// perUnit делит курс за nominal единиц на номинал и возвращает курс за одну единицу
+// Дробная часть курса может отделяться запятой
+func perUnit(value, nominal string) (string, error) {
+	rate, ok := new(big.Rat).SetString(strings.Replace(strings.TrimSpace(value), ",", ".", 1))
+	if !ok || rate.Sign() <= 0 || strings.ContainsAny(value, "/eE") {
+		return "", fmt.Errorf("некорректный курс %q", value)
+	}
+	n, ok := new(big.Rat).SetString(strings.TrimSpace(nominal))
+	if !ok || n.Sign() <= 0 || strings.ContainsAny(nominal, "/eE") {
+		return "", fmt.Errorf("некорректный номинал %q", nominal)
+	}
+	rate.Quo(rate, n)
+	formatted := strings.TrimRight(strings.TrimRight(rate.FloatString(rateScale), "0"), ".")
+	if formatted == "0" {
+		return "", fmt.Errorf("курс %q слишком мал", value)
+	}
+	return formatted, nil
+}
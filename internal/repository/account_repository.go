@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AccountRepository определяет интерфейс для работы со счетами в базе данных
+type AccountRepository interface {
+	// Create создает счёт
+	Create(account *models.Account) error
+
+	// GetByID получает счёт по идентификатору
+	// Возвращает nil, если счёт не найден
+	GetByID(id uint) (*models.Account, error)
+
+	// Update обновляет данные счёта
+	Update(account *models.Account) error
+
+	// Delete удаляет счёт
+	Delete(id uint) error
+
+	// ListByFamily возвращает счета семьи в порядке отображения
+	ListByFamily(familyID uint, includeArchived bool) ([]models.Account, error)
+
+	// MaxSortOrder возвращает наибольший порядковый номер среди счетов семьи
+	MaxSortOrder(familyID uint) (int, error)
+
+	// Reorder в одной транзакции присваивает счетам семьи порядковые номера
+	// в соответствии с позицией идентификатора в списке
+	Reorder(familyID uint, ids []uint) error
+
+	// Balances вычисляет текущие остатки счетов: начальный остаток
+	// плюс сумма проводок главной книги по счёту
+	Balances(ids []uint) (map[uint]int64, error)
+}
+
+// accountRepository реализует интерфейс AccountRepository
+type accountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository создает новый экземпляр AccountRepository
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) Create(account *models.Account) error {
+	return r.db.Create(account).Error
+}
+
+func (r *accountRepository) GetByID(id uint) (*models.Account, error) {
+	var account models.Account
+	result := r.db.First(&account, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &account, result.Error
+}
+
+func (r *accountRepository) Update(account *models.Account) error {
+	return r.db.Save(account).Error
+}
+
+func (r *accountRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Account{}, id).Error
+}
+
+func (r *accountRepository) ListByFamily(familyID uint, includeArchived bool) ([]models.Account, error) {
+	var accounts []models.Account
+	query := r.db.Where("family_id = ?", familyID)
+	if !includeArchived {
+		query = query.Where("archived = ?", false)
+	}
+	err := query.Order("sort_order, id").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *accountRepository) MaxSortOrder(familyID uint) (int, error) {
+	var max int
+	err := r.db.Model(&models.Account{}).
+		Where("family_id = ?", familyID).
+		Select("COALESCE(MAX(sort_order), 0)").
+		Scan(&max).Error
+	return max, err
+}
+
+func (r *accountRepository) Reorder(familyID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.Account{}).
+				Where("id = ? AND family_id = ?", id, familyID).
+				Update("sort_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *accountRepository) Balances(ids []uint) (map[uint]int64, error) {
+	balances := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return balances, nil
+	}
+	var rows []struct {
+		ID      uint
+		Balance int64
+	}
+	err := r.db.Model(&models.Account{}).
+		Select("accounts.id, accounts.opening_balance + COALESCE(SUM(p.amount), 0) AS balance").
+		Joins("LEFT JOIN postings p ON p.account_id = accounts.id").
+		Where("accounts.id IN ?", ids).
+		Group("accounts.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		balances[row.ID] = row.Balance
+	}
+	return balances, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AllowanceRepository определяет интерфейс для работы с карманными деньгами в базе данных
+type AllowanceRepository interface {
+	// Create создает карманные деньги
+	Create(allowance *models.Allowance) error
+
+	// GetByID получает карманные деньги по идентификатору
+	// Возвращает nil, если карманные деньги не найдены
+	GetByID(id uint) (*models.Allowance, error)
+
+	// LockByID получает карманные деньги и блокирует их строку до конца транзакции
+	// Возвращает nil, если карманные деньги не найдены или уже заблокированы другой транзакцией
+	// Вызывайте внутри Transactor.Transaction
+	LockByID(id uint) (*models.Allowance, error)
+
+	// ListByFamily возвращает карманные деньги семьи в порядке даты следующей выплаты
+	// Если childID не 0, возвращаются только карманные деньги этого ребёнка
+	ListByFamily(familyID, childID uint) ([]models.Allowance, error)
+
+	// ListDue возвращает идентификаторы активных карманных денег с датой выплаты не позже until,
+	// начиная с самых давних
+	ListDue(until time.Time, limit int) ([]uint, error)
+
+	// CountByAccount возвращает количество карманных денег, зачисляемых на счёт accountID
+	CountByAccount(accountID uint) (int64, error)
+
+	// Update сохраняет карманные деньги
+	Update(allowance *models.Allowance) error
+
+	// Delete удаляет карманные деньги вместе с премиями и записями о выплатах; переводы остаются
+	Delete(id uint) error
+
+	// CreateBonus создает премию
+	CreateBonus(bonus *models.AllowanceBonus) error
+
+	// GetBonus получает премию по идентификатору
+	// Возвращает nil, если премия не найдена
+	GetBonus(id uint) (*models.AllowanceBonus, error)
+
+	// DeleteBonus удаляет премию
+	DeleteBonus(id uint) error
+
+	// ListBonuses возвращает премии карманных денег, начиная с последних
+	ListBonuses(allowanceID uint) ([]models.AllowanceBonus, error)
+
+	// UnpaidBonuses возвращает невыплаченные премии карманных денег
+	UnpaidBonuses(allowanceID uint) ([]models.AllowanceBonus, error)
+
+	// MarkBonusesPaid отмечает премии ids выплаченными переводом transactionID
+	MarkBonusesPaid(ids []uint, transactionID uint) error
+
+	// CreatePayment сохраняет запись о выплате
+	// Возвращает false, если выплата в эту дату уже записана
+	CreatePayment(payment *models.AllowancePayment) (bool, error)
+
+	// UpdatePayment сохраняет запись о выплате
+	UpdatePayment(payment *models.AllowancePayment) error
+
+	// ListPayments возвращает выплаты карманных денег, начиная с последних, не больше limit
+	ListPayments(allowanceID uint, limit int) ([]models.AllowancePayment, error)
+}
+
+// allowanceRepository реализует интерфейс AllowanceRepository
+type allowanceRepository struct {
+	db *gorm.DB
+}
+
+// NewAllowanceRepository создает новый экземпляр AllowanceRepository
+func NewAllowanceRepository(db *gorm.DB) AllowanceRepository {
+	return &allowanceRepository{db: db}
+}
+
+func (r *allowanceRepository) Create(allowance *models.Allowance) error {
+	return r.db.Create(allowance).Error
+}
+
+func (r *allowanceRepository) GetByID(id uint) (*models.Allowance, error) {
+	var allowance models.Allowance
+	result := r.db.First(&allowance, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &allowance, result.Error
+}
+
+func (r *allowanceRepository) LockByID(id uint) (*models.Allowance, error) {
+	var allowance models.Allowance
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).First(&allowance, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &allowance, result.Error
+}
+
+func (r *allowanceRepository) ListByFamily(familyID, childID uint) ([]models.Allowance, error) {
+	var list []models.Allowance
+	query := r.db.Where("family_id = ?", familyID)
+	if childID != 0 {
+		query = query.Where("child_id = ?", childID)
+	}
+	err := query.Order("next_date NULLS LAST, id").Find(&list).Error
+	return list, err
+}
+
+func (r *allowanceRepository) ListDue(until time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Allowance{}).
+		Where("NOT paused AND next_date <= ?", sqlDate(until)).
+		Order("next_date, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *allowanceRepository) CountByAccount(accountID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Allowance{}).Where("to_account_id = ?", accountID).Count(&count).Error
+	return count, err
+}
+
+func (r *allowanceRepository) Update(allowance *models.Allowance) error {
+	return r.db.Save(allowance).Error
+}
+
+func (r *allowanceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Allowance{}, id).Error
+}
+
+func (r *allowanceRepository) CreateBonus(bonus *models.AllowanceBonus) error {
+	return r.db.Create(bonus).Error
+}
+
+func (r *allowanceRepository) GetBonus(id uint) (*models.AllowanceBonus, error) {
+	var bonus models.AllowanceBonus
+	result := r.db.First(&bonus, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &bonus, result.Error
+}
+
+func (r *allowanceRepository) DeleteBonus(id uint) error {
+	return r.db.Delete(&models.AllowanceBonus{}, id).Error
+}
+
+func (r *allowanceRepository) ListBonuses(allowanceID uint) ([]models.AllowanceBonus, error) {
+	var list []models.AllowanceBonus
+	err := r.db.Where("allowance_id = ?", allowanceID).Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *allowanceRepository) UnpaidBonuses(allowanceID uint) ([]models.AllowanceBonus, error) {
+	var list []models.AllowanceBonus
+	err := r.db.Where("allowance_id = ? AND transaction_id IS NULL", allowanceID).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *allowanceRepository) MarkBonusesPaid(ids []uint, transactionID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.AllowanceBonus{}).
+		Where("id IN ?", ids).
+		Update("transaction_id", transactionID).Error
+}
+
+func (r *allowanceRepository) CreatePayment(payment *models.AllowancePayment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "allowance_id"}, {Name: "date"}},
+		DoNothing: true,
+	}).Create(payment)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *allowanceRepository) UpdatePayment(payment *models.AllowancePayment) error {
+	return r.db.Save(payment).Error
+}
+
+func (r *allowanceRepository) ListPayments(allowanceID uint, limit int) ([]models.AllowancePayment, error) {
+	var list []models.AllowancePayment
+	err := r.db.Where("allowance_id = ?", allowanceID).Order("date DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ApprovalQuery фильтры списка запросов на одобрение; нулевые поля не фильтруют
+type ApprovalQuery struct {
+	FamilyID    uint
+	Status      models.ApprovalStatus
+	RequestedBy uint
+	Limit       int
+}
+
+// ApprovalRepository определяет интерфейс для работы с правилами и запросами на одобрение расходов
+type ApprovalRepository interface {
+	// CreateRule создает правило
+	CreateRule(rule *models.ApprovalRule) error
+
+	// GetRule получает правило по идентификатору
+	// Возвращает nil, если правило не найдено
+	GetRule(id uint) (*models.ApprovalRule, error)
+
+	// ListRules возвращает правила семьи в порядке создания
+	ListRules(familyID uint) ([]models.ApprovalRule, error)
+
+	// UpdateRule сохраняет правило
+	UpdateRule(rule *models.ApprovalRule) error
+
+	// DeleteRule удаляет правило; запросы по нему остаются без ссылки на правило
+	DeleteRule(id uint) error
+
+	// ReassignCategory переносит правила категории from в категорию to
+	ReassignCategory(from, to uint) error
+
+	// GetByTransaction получает запрос на одобрение операции
+	// Возвращает nil, если запроса нет
+	GetByTransaction(transactionID uint) (*models.Approval, error)
+
+	// LockByTransaction получает запрос на одобрение операции и блокирует его строку
+	// до конца транзакции; если строка уже заблокирована, ждёт завершения другой транзакции
+	// Возвращает nil, если запроса нет
+	// Вызывайте внутри Transactor.Transaction
+	LockByTransaction(transactionID uint) (*models.Approval, error)
+
+	// Save создает или обновляет запрос на одобрение операции approval.TransactionID
+	Save(approval *models.Approval) error
+
+	// DeletePending удаляет запрос на одобрение операции, если по нему ещё не принято решение
+	DeletePending(transactionID uint) error
+
+	// List возвращает запросы по фильтрам вместе с операциями, начиная с последних
+	List(query ApprovalQuery) ([]models.Approval, error)
+}
+
+// approvalRepository реализует интерфейс ApprovalRepository
+type approvalRepository struct {
+	db *gorm.DB
+}
+
+// NewApprovalRepository создает новый экземпляр ApprovalRepository
+func NewApprovalRepository(db *gorm.DB) ApprovalRepository {
+	return &approvalRepository{db: db}
+}
+
+func (r *approvalRepository) CreateRule(rule *models.ApprovalRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *approvalRepository) GetRule(id uint) (*models.ApprovalRule, error) {
+	var rule models.ApprovalRule
+	result := r.db.First(&rule, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rule, result.Error
+}
+
+func (r *approvalRepository) ListRules(familyID uint) ([]models.ApprovalRule, error) {
+	var rules []models.ApprovalRule
+	err := r.db.Where("family_id = ?", familyID).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *approvalRepository) UpdateRule(rule *models.ApprovalRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *approvalRepository) DeleteRule(id uint) error {
+	return r.db.Delete(&models.ApprovalRule{}, id).Error
+}
+
+func (r *approvalRepository) ReassignCategory(from, to uint) error {
+	return r.db.Model(&models.ApprovalRule{}).Where("category_id = ?", from).Update("category_id", to).Error
+}
+
+func (r *approvalRepository) GetByTransaction(transactionID uint) (*models.Approval, error) {
+	var approval models.Approval
+	result := r.db.Where("transaction_id = ?", transactionID).First(&approval)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &approval, result.Error
+}
+
+func (r *approvalRepository) LockByTransaction(transactionID uint) (*models.Approval, error) {
+	var approval models.Approval
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).First(&approval)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &approval, result.Error
+}
+
+func (r *approvalRepository) Save(approval *models.Approval) error {
+	if approval.ID != 0 {
+		return r.db.Omit("Transaction").Save(approval).Error
+	}
+	return r.db.Omit("Transaction").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "transaction_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"rule_id", "requested_by", "status", "decided_by", "decided_at", "note", "updated_at",
+		}),
+	}).Create(approval).Error
+}
+
+func (r *approvalRepository) DeletePending(transactionID uint) error {
+	return r.db.Where("transaction_id = ? AND status = ?", transactionID, models.ApprovalPending).
+		Delete(&models.Approval{}).Error
+}
+
+func (r *approvalRepository) List(query ApprovalQuery) ([]models.Approval, error) {
+	var approvals []models.Approval
+	db := r.db.Preload("Transaction").Preload("Transaction.Author").Where("family_id = ?", query.FamilyID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.RequestedBy != 0 {
+		db = db.Where("requested_by = ?", query.RequestedBy)
+	}
+	err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&approvals).Error
+	return approvals, err
+}
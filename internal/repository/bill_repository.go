@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BillRepository определяет интерфейс для работы со счетами к оплате в базе данных
+type BillRepository interface {
+	// Create создает счёт к оплате
+	Create(bill *models.Bill) error
+
+	// GetByID получает счёт к оплате по идентификатору
+	// Возвращает nil, если счёт не найден
+	GetByID(id uint) (*models.Bill, error)
+
+	// ListByFamily возвращает счета к оплате семьи в порядке первого срока
+	ListByFamily(familyID uint) ([]models.Bill, error)
+
+	// ListAfter возвращает счета к оплате всех семей с идентификатором больше afterID
+	// в порядке идентификатора; используется для постраничного обхода
+	ListAfter(afterID uint, limit int) ([]models.Bill, error)
+
+	// Update сохраняет счёт к оплате
+	Update(bill *models.Bill) error
+
+	// Delete удаляет счёт к оплате вместе с записями об оплатах
+	Delete(id uint) error
+
+	// ListInstances возвращает записи об оплатах счетов billIDs со сроком в периоде [from, to]
+	ListInstances(billIDs []uint, from, to time.Time) ([]models.BillInstance, error)
+
+	// GetInstance получает запись об оплате счёта в срок dueDate
+	// Возвращает nil, если записи нет
+	GetInstance(billID uint, dueDate time.Time) (*models.BillInstance, error)
+
+	// FindInstanceByTransaction получает запись об оплате, связанную с операцией
+	// Возвращает nil, если операция не оплачивает ни один счёт
+	FindInstanceByTransaction(transactionID uint) (*models.BillInstance, error)
+
+	// SaveInstance создает или обновляет запись об оплате счёта в срок instance.DueDate
+	SaveInstance(instance *models.BillInstance) error
+
+	// ClaimReminder отмечает отправку напоминания о сроке dueDate
+	// Возвращает false, если напоминание уже отправлялось или счёт уже оплачен
+	ClaimReminder(billID uint, dueDate time.Time) (bool, error)
+
+	// ReassignCategory переносит счета категории from в категорию to
+	ReassignCategory(from, to uint) error
+}
+
+// billRepository реализует интерфейс BillRepository
+type billRepository struct {
+	db *gorm.DB
+}
+
+// NewBillRepository создает новый экземпляр BillRepository
+func NewBillRepository(db *gorm.DB) BillRepository {
+	return &billRepository{db: db}
+}
+
+func (r *billRepository) Create(bill *models.Bill) error {
+	return r.db.Create(bill).Error
+}
+
+func (r *billRepository) GetByID(id uint) (*models.Bill, error) {
+	var bill models.Bill
+	result := r.db.First(&bill, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &bill, result.Error
+}
+
+func (r *billRepository) ListByFamily(familyID uint) ([]models.Bill, error) {
+	var bills []models.Bill
+	err := r.db.Where("family_id = ?", familyID).Order("start_date, id").Find(&bills).Error
+	return bills, err
+}
+
+func (r *billRepository) ListAfter(afterID uint, limit int) ([]models.Bill, error) {
+	var bills []models.Bill
+	err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&bills).Error
+	return bills, err
+}
+
+func (r *billRepository) Update(bill *models.Bill) error {
+	return r.db.Save(bill).Error
+}
+
+func (r *billRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Bill{}, id).Error
+}
+
+func (r *billRepository) ListInstances(billIDs []uint, from, to time.Time) ([]models.BillInstance, error) {
+	var instances []models.BillInstance
+	if len(billIDs) == 0 {
+		return instances, nil
+	}
+	err := r.db.Where("bill_id IN ? AND due_date BETWEEN ? AND ?", billIDs, sqlDate(from), sqlDate(to)).
+		Order("due_date, bill_id").Find(&instances).Error
+	return instances, err
+}
+
+func (r *billRepository) GetInstance(billID uint, dueDate time.Time) (*models.BillInstance, error) {
+	var instance models.BillInstance
+	result := r.db.Where("bill_id = ? AND due_date = ?", billID, sqlDate(dueDate)).First(&instance)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &instance, result.Error
+}
+
+func (r *billRepository) FindInstanceByTransaction(transactionID uint) (*models.BillInstance, error) {
+	var instance models.BillInstance
+	result := r.db.Where("transaction_id = ?", transactionID).First(&instance)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &instance, result.Error
+}
+
+func (r *billRepository) SaveInstance(instance *models.BillInstance) error {
+	if instance.ID != 0 {
+		return r.db.Save(instance).Error
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bill_id"}, {Name: "due_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "transaction_id", "paid_at", "updated_at"}),
+	}).Create(instance).Error
+}
+
+func (r *billRepository) ClaimReminder(billID uint, dueDate time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.Exec(`
+		INSERT INTO bill_instances (bill_id, due_date, status, reminded_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (bill_id, due_date) DO UPDATE SET reminded_at = EXCLUDED.reminded_at, updated_at = EXCLUDED.updated_at
+		WHERE bill_instances.reminded_at IS NULL AND bill_instances.status = ?`,
+		billID, sqlDate(dueDate), models.BillUnpaid, now, now, now, models.BillUnpaid)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *billRepository) ReassignCategory(from, to uint) error {
+	return r.db.Model(&models.Bill{}).Where("category_id = ?", from).Update("category_id", to).Error
+}
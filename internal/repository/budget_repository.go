@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BudgetRepository определяет интерфейс для работы с бюджетами в базе данных
+type BudgetRepository interface {
+	// Create создает бюджет вместе со строками
+	Create(budget *models.Budget) error
+
+	// GetByID получает бюджет по идентификатору вместе со строками
+	// Возвращает nil, если бюджет не найден
+	GetByID(id uint) (*models.Budget, error)
+
+	// FindByStart получает бюджет семьи с указанным видом периода и датой начала
+	// Возвращает nil, если бюджет не найден
+	FindByStart(familyID uint, period models.BudgetPeriod, start time.Time) (*models.Budget, error)
+
+	// FindEndingOn получает бюджет семьи с указанным видом периода, заканчивающийся в день end,
+	// вместе со строками. Возвращает nil, если бюджет не найден
+	FindEndingOn(familyID uint, period models.BudgetPeriod, end time.Time) (*models.Budget, error)
+
+	// ListByFamily возвращает бюджеты семьи со строками, пересекающиеся с периодом [from, to],
+	// от новых к старым; nil границы не ограничивают выборку
+	ListByFamily(familyID uint, from, to *time.Time) ([]models.Budget, error)
+
+	// ReplaceLines в одной транзакции заменяет строки бюджета
+	ReplaceLines(budgetID uint, lines []models.BudgetLine) error
+
+	// Update сохраняет параметры бюджета без строк
+	Update(budget *models.Budget) error
+
+	// Delete удаляет бюджет вместе со строками
+	Delete(id uint) error
+
+	// ReassignCategory переносит строки бюджетов категории from в категорию to
+	// Если в бюджете уже есть строка категории to, плановые суммы складываются
+	ReassignCategory(from, to uint) error
+
+	// RecordAlert отмечает срабатывание порога по категории бюджета
+	// Возвращает false, если порог уже срабатывал
+	RecordAlert(budgetID, categoryID uint, threshold int) (bool, error)
+}
+
+// budgetRepository реализует интерфейс BudgetRepository
+type budgetRepository struct {
+	db *gorm.DB
+}
+
+// NewBudgetRepository создает новый экземпляр BudgetRepository
+func NewBudgetRepository(db *gorm.DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) Create(budget *models.Budget) error {
+	return r.db.Create(budget).Error
+}
+
+func (r *budgetRepository) GetByID(id uint) (*models.Budget, error) {
+	var budget models.Budget
+	result := r.db.Preload("Lines", orderLines).First(&budget, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &budget, result.Error
+}
+
+func (r *budgetRepository) FindByStart(familyID uint, period models.BudgetPeriod, start time.Time) (*models.Budget, error) {
+	var budget models.Budget
+	result := r.db.Where("family_id = ? AND period = ? AND start_date = ?", familyID, period, sqlDate(start)).
+		First(&budget)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &budget, result.Error
+}
+
+func (r *budgetRepository) FindEndingOn(familyID uint, period models.BudgetPeriod, end time.Time) (*models.Budget, error) {
+	var budget models.Budget
+	result := r.db.Preload("Lines", orderLines).
+		Where("family_id = ? AND period = ? AND end_date = ?", familyID, period, sqlDate(end)).
+		Order("start_date DESC").
+		First(&budget)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &budget, result.Error
+}
+
+func (r *budgetRepository) ListByFamily(familyID uint, from, to *time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := r.db.Preload("Lines", orderLines).Where("family_id = ?", familyID)
+	if from != nil {
+		query = query.Where("end_date >= ?", sqlDate(*from))
+	}
+	if to != nil {
+		query = query.Where("start_date <= ?", sqlDate(*to))
+	}
+	err := query.Order("start_date DESC, id DESC").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *budgetRepository) ReplaceLines(budgetID uint, lines []models.BudgetLine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("budget_id = ?", budgetID).Delete(&models.BudgetLine{}).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		for i := range lines {
+			lines[i].ID = 0
+			lines[i].BudgetID = budgetID
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+func (r *budgetRepository) Update(budget *models.Budget) error {
+	return r.db.Omit("Lines").Save(budget).Error
+}
+
+func (r *budgetRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Budget{}, id).Error
+}
+
+func (r *budgetRepository) ReassignCategory(from, to uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Бюджеты, где есть обе категории: сумма переходит в строку категории to
+		err := tx.Exec(`
+			UPDATE budget_lines t SET amount = t.amount + f.amount, rollover = t.rollover OR f.rollover
+			FROM budget_lines f
+			WHERE f.budget_id = t.budget_id AND f.category_id = ? AND t.category_id = ?`, from, to).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`
+			DELETE FROM budget_lines f
+			WHERE f.category_id = ?
+			  AND EXISTS (SELECT 1 FROM budget_lines t WHERE t.budget_id = f.budget_id AND t.category_id = ?)`, from, to).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.BudgetLine{}).Where("category_id = ?", from).Update("category_id", to).Error
+	})
+}
+
+func (r *budgetRepository) RecordAlert(budgetID, categoryID uint, threshold int) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.BudgetAlert{
+		BudgetID:   budgetID,
+		CategoryID: categoryID,
+		Threshold:  threshold,
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// orderLines упорядочивает строки бюджета при загрузке
+func orderLines(db *gorm.DB) *gorm.DB {
+	return db.Order("budget_lines.id")
+}
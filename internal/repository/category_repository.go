@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository определяет интерфейс для работы с категориями в базе данных
+type CategoryRepository interface {
+	// Create создает категорию
+	Create(category *models.Category) error
+
+	// CreateBatch создает несколько категорий одним запросом
+	CreateBatch(categories []models.Category) error
+
+	// GetByID получает категорию по идентификатору
+	// Возвращает nil, если категория не найдена
+	GetByID(id uint) (*models.Category, error)
+
+	// Update обновляет данные категории
+	Update(category *models.Category) error
+
+	// Delete удаляет категорию
+	Delete(id uint) error
+
+	// ListByFamily возвращает категории семьи в порядке отображения:
+	// сначала категории верхнего уровня, затем подкатегории
+	ListByFamily(familyID uint, includeArchived bool) ([]models.Category, error)
+
+	// ListChildren возвращает подкатегории категории
+	ListChildren(parentID uint) ([]models.Category, error)
+
+	// CountByFamily возвращает количество категорий семьи, включая архивные
+	CountByFamily(familyID uint) (int64, error)
+
+	// MaxSortOrder возвращает наибольший порядковый номер среди категорий
+	// с тем же родителем; parentID = nil означает категории верхнего уровня
+	MaxSortOrder(familyID uint, parentID *uint) (int, error)
+
+	// Reorder в одной транзакции присваивает категориям семьи порядковые номера
+	// в соответствии с позицией идентификатора в списке
+	Reorder(familyID uint, ids []uint) error
+
+	// SetArchived архивирует или возвращает из архива категорию вместе с подкатегориями
+	SetArchived(id uint, archived bool) error
+
+	// Reparent переносит подкатегории категории from в категорию to
+	Reparent(from, to uint) error
+}
+
+// categoryRepository реализует интерфейс CategoryRepository
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository создает новый экземпляр CategoryRepository
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *categoryRepository) CreateBatch(categories []models.Category) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	return r.db.Create(&categories).Error
+}
+
+func (r *categoryRepository) GetByID(id uint) (*models.Category, error) {
+	var category models.Category
+	result := r.db.First(&category, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &category, result.Error
+}
+
+func (r *categoryRepository) Update(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+func (r *categoryRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Category{}, id).Error
+}
+
+func (r *categoryRepository) ListByFamily(familyID uint, includeArchived bool) ([]models.Category, error) {
+	var categories []models.Category
+	query := r.db.Where("family_id = ?", familyID)
+	if !includeArchived {
+		query = query.Where("archived = ?", false)
+	}
+	err := query.Order("parent_id NULLS FIRST, sort_order, id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) ListChildren(parentID uint) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Where("parent_id = ?", parentID).Order("sort_order, id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) CountByFamily(familyID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).Where("family_id = ?", familyID).Count(&count).Error
+	return count, err
+}
+
+func (r *categoryRepository) MaxSortOrder(familyID uint, parentID *uint) (int, error) {
+	var max int
+	query := r.db.Model(&models.Category{}).Where("family_id = ?", familyID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.Select("COALESCE(MAX(sort_order), 0)").Scan(&max).Error
+	return max, err
+}
+
+func (r *categoryRepository) Reorder(familyID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.Category{}).
+				Where("id = ? AND family_id = ?", id, familyID).
+				Update("sort_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *categoryRepository) SetArchived(id uint, archived bool) error {
+	return r.db.Model(&models.Category{}).
+		Where("id = ? OR parent_id = ?", id, id).
+		Update("archived", archived).Error
+}
+
+func (r *categoryRepository) Reparent(from, to uint) error {
+	return r.db.Model(&models.Category{}).Where("parent_id = ?", from).Update("parent_id", to).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DebtSum сколько должник BorrowerID должен кредитору LenderID в валюте Currency
+// Сумма отрицательна, если погашено больше, чем занято
+type DebtSum struct {
+	LenderID   uint
+	BorrowerID uint
+	Currency   string
+	Total      int64
+}
+
+// DebtRepository определяет интерфейс для работы с займами и погашениями в базе данных
+type DebtRepository interface {
+	// Create создает запись о займе или погашении
+	Create(debt *models.Debt) error
+
+	// GetByID получает запись по идентификатору
+	// Возвращает nil, если запись не найдена
+	GetByID(id uint) (*models.Debt, error)
+
+	// Delete удаляет запись
+	Delete(id uint) error
+
+	// ListByFamily возвращает записи семьи, начиная с последних;
+	// если memberID не 0 — только записи, где участник кредитор или должник
+	ListByFamily(familyID, memberID uint) ([]models.Debt, error)
+
+	// Sums возвращает долги семьи по парам участников и валютам: займы минус погашения
+	Sums(familyID uint) ([]DebtSum, error)
+}
+
+// debtRepository реализует интерфейс DebtRepository
+type debtRepository struct {
+	db *gorm.DB
+}
+
+// NewDebtRepository создает новый экземпляр DebtRepository
+func NewDebtRepository(db *gorm.DB) DebtRepository {
+	return &debtRepository{db: db}
+}
+
+func (r *debtRepository) Create(debt *models.Debt) error {
+	return r.db.Create(debt).Error
+}
+
+func (r *debtRepository) GetByID(id uint) (*models.Debt, error) {
+	var debt models.Debt
+	result := r.db.First(&debt, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &debt, result.Error
+}
+
+func (r *debtRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Debt{}, id).Error
+}
+
+func (r *debtRepository) ListByFamily(familyID, memberID uint) ([]models.Debt, error) {
+	var debts []models.Debt
+	query := r.db.Where("family_id = ?", familyID)
+	if memberID != 0 {
+		query = query.Where("lender_id = ? OR borrower_id = ?", memberID, memberID)
+	}
+	err := query.Order("date DESC, id DESC").Find(&debts).Error
+	return debts, err
+}
+
+func (r *debtRepository) Sums(familyID uint) ([]DebtSum, error) {
+	var sums []DebtSum
+	err := r.db.Model(&models.Debt{}).
+		Select("lender_id, borrower_id, currency, SUM(CASE WHEN kind = ? THEN amount ELSE -amount END) AS total", models.DebtLoan).
+		Where("family_id = ?", familyID).
+		Group("lender_id, borrower_id, currency").
+		Scan(&sums).Error
+	return sums, err
+}
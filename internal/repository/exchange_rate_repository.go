@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rateUpsertBatchSize количество курсов в одном INSERT при загрузке
+const rateUpsertBatchSize = 500
+
+// ExchangeRateRepository определяет интерфейс для работы с курсами валют в базе данных
+type ExchangeRateRepository interface {
+	// Upsert сохраняет курсы; курс той же пары на ту же дату заменяется
+	Upsert(rates []models.ExchangeRate) error
+
+	// List возвращает курсы пар, обе валюты которых входят в currencies, за период [from, to]
+	// в порядке даты
+	List(currencies []string, from, to time.Time) ([]models.ExchangeRate, error)
+
+	// Effective возвращает для каждой пары последний курс на дату date;
+	// если currency не пуста — только пары с этой валютой
+	Effective(date time.Time, currency string) ([]models.ExchangeRate, error)
+}
+
+// exchangeRateRepository реализует интерфейс ExchangeRateRepository
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository создает новый экземпляр ExchangeRateRepository
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, rateUpsertBatchSize).Error
+}
+
+func (r *exchangeRateRepository) List(currencies []string, from, to time.Time) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	if len(currencies) == 0 {
+		return rates, nil
+	}
+	err := r.db.Where("base IN ? AND quote IN ? AND date BETWEEN ? AND ?", currencies, currencies, sqlDate(from), sqlDate(to)).
+		Order("date, base, quote").Find(&rates).Error
+	return rates, err
+}
+
+func (r *exchangeRateRepository) Effective(date time.Time, currency string) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	query := r.db.Select("DISTINCT ON (base, quote) *").Where("date <= ?", sqlDate(date))
+	if currency != "" {
+		query = query.Where("base = ? OR quote = ?", currency, currency)
+	}
+	err := query.Order("base, quote, date DESC").Find(&rates).Error
+	return rates, err
+}
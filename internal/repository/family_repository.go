@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FamilyRepository определяет интерфейс для работы с семьями и их участниками в базе данных
+type FamilyRepository interface {
+	// Create создает семью и добавляет создателя владельцем в одной транзакции
+	Create(family *models.Family, ownerID uint) error
+
+	// GetByID получает семью по идентификатору
+	// Возвращает nil, если семья не найдена
+	GetByID(id uint) (*models.Family, error)
+
+	// Lock получает семью и блокирует её строку до конца транзакции
+	// Возвращает nil, если семья не найдена
+	// Вызывайте внутри Transactor.Transaction, чтобы сериализовать изменения, зависящие
+	// от данных всей семьи, например погашение долгов
+	Lock(id uint) (*models.Family, error)
+
+	// Update обновляет данные семьи
+	Update(family *models.Family) error
+
+	// Delete удаляет семью вместе с членством участников
+	Delete(id uint) error
+
+	// GetMember получает членство пользователя в семье
+	// Возвращает nil, если пользователь не состоит в семье
+	GetMember(familyID, userID uint) (*models.FamilyMember, error)
+
+	// ListByUser возвращает членства пользователя вместе с данными семей
+	ListByUser(userID uint) ([]models.FamilyMember, error)
+
+	// ListMembers возвращает участников семьи вместе с данными пользователей
+	ListMembers(familyID uint) ([]models.FamilyMember, error)
+
+	// AddMember добавляет пользователя в семью
+	AddMember(member *models.FamilyMember) error
+
+	// UpdateMemberRole изменяет роль участника семьи
+	UpdateMemberRole(familyID, userID uint, role models.FamilyRole) error
+
+	// RemoveMember удаляет пользователя из семьи
+	RemoveMember(familyID, userID uint) error
+
+	// CountMembers возвращает количество участников семьи с указанной ролью
+	// (всех участников, если роль пустая)
+	CountMembers(familyID uint, role models.FamilyRole) (int64, error)
+
+	// LockOwners возвращает владельцев семьи и блокирует их строки до конца транзакции;
+	// если строки уже заблокированы, ждёт завершения другой транзакции
+	// Вызывайте внутри Transactor.Transaction перед изменением роли или выходом владельца
+	LockOwners(familyID uint) ([]models.FamilyMember, error)
+}
+
+// familyRepository реализует интерфейс FamilyRepository
+type familyRepository struct {
+	db *gorm.DB
+}
+
+// NewFamilyRepository создает новый экземпляр FamilyRepository
+func NewFamilyRepository(db *gorm.DB) FamilyRepository {
+	return &familyRepository{db: db}
+}
+
+func (r *familyRepository) Create(family *models.Family, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(family).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.FamilyMember{
+			FamilyID: family.ID,
+			UserID:   ownerID,
+			Role:     models.RoleOwner,
+			JoinedAt: time.Now(),
+		}).Error
+	})
+}
+
+func (r *familyRepository) GetByID(id uint) (*models.Family, error) {
+	var family models.Family
+	result := r.db.First(&family, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &family, result.Error
+}
+
+func (r *familyRepository) Lock(id uint) (*models.Family, error) {
+	var family models.Family
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&family, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &family, result.Error
+}
+
+func (r *familyRepository) Update(family *models.Family) error {
+	return r.db.Save(family).Error
+}
+
+func (r *familyRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Family{}, id).Error
+}
+
+func (r *familyRepository) GetMember(familyID, userID uint) (*models.FamilyMember, error) {
+	var member models.FamilyMember
+	result := r.db.Where("family_id = ? AND user_id = ?", familyID, userID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &member, result.Error
+}
+
+func (r *familyRepository) ListByUser(userID uint) ([]models.FamilyMember, error) {
+	var members []models.FamilyMember
+	err := r.db.Preload("Family").Where("user_id = ?", userID).Order("family_id").Find(&members).Error
+	return members, err
+}
+
+func (r *familyRepository) ListMembers(familyID uint) ([]models.FamilyMember, error) {
+	var members []models.FamilyMember
+	err := r.db.Preload("User").Where("family_id = ?", familyID).Order("joined_at").Find(&members).Error
+	return members, err
+}
+
+func (r *familyRepository) AddMember(member *models.FamilyMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *familyRepository) UpdateMemberRole(familyID, userID uint, role models.FamilyRole) error {
+	return r.db.Model(&models.FamilyMember{}).
+		Where("family_id = ? AND user_id = ?", familyID, userID).
+		Update("role", role).Error
+}
+
+func (r *familyRepository) RemoveMember(familyID, userID uint) error {
+	return r.db.Where("family_id = ? AND user_id = ?", familyID, userID).Delete(&models.FamilyMember{}).Error
+}
+
+func (r *familyRepository) CountMembers(familyID uint, role models.FamilyRole) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.FamilyMember{}).Where("family_id = ?", familyID)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *familyRepository) LockOwners(familyID uint) ([]models.FamilyMember, error) {
+	var owners []models.FamilyMember
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("family_id = ? AND role = ?", familyID, models.RoleOwner).
+		Order("user_id").
+		Find(&owners).Error
+	return owners, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GoalRepository определяет интерфейс для работы с целями накоплений в базе данных
+type GoalRepository interface {
+	// Create создает цель
+	Create(goal *models.Goal) error
+
+	// GetByID получает цель по идентификатору
+	// Возвращает nil, если цель не найдена
+	GetByID(id uint) (*models.Goal, error)
+
+	// ListByFamily возвращает цели семьи в порядке создания
+	ListByFamily(familyID uint) ([]models.Goal, error)
+
+	// Update сохраняет цель
+	Update(goal *models.Goal) error
+
+	// Delete удаляет цель вместе с записями о взносах; переводы взносов остаются
+	Delete(id uint) error
+
+	// AddContribution связывает перевод с целью
+	AddContribution(contribution *models.GoalContribution) error
+
+	// Contributions возвращает взносы в цели goalIDs с переводами, начиная с последних
+	Contributions(goalIDs []uint) ([]models.GoalContribution, error)
+}
+
+// goalRepository реализует интерфейс GoalRepository
+type goalRepository struct {
+	db *gorm.DB
+}
+
+// NewGoalRepository создает новый экземпляр GoalRepository
+func NewGoalRepository(db *gorm.DB) GoalRepository {
+	return &goalRepository{db: db}
+}
+
+func (r *goalRepository) Create(goal *models.Goal) error {
+	return r.db.Create(goal).Error
+}
+
+func (r *goalRepository) GetByID(id uint) (*models.Goal, error) {
+	var goal models.Goal
+	result := r.db.First(&goal, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &goal, result.Error
+}
+
+func (r *goalRepository) ListByFamily(familyID uint) ([]models.Goal, error) {
+	var goals []models.Goal
+	err := r.db.Where("family_id = ?", familyID).Order("id").Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) Update(goal *models.Goal) error {
+	return r.db.Save(goal).Error
+}
+
+func (r *goalRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Goal{}, id).Error
+}
+
+func (r *goalRepository) AddContribution(contribution *models.GoalContribution) error {
+	return r.db.Create(contribution).Error
+}
+
+func (r *goalRepository) Contributions(goalIDs []uint) ([]models.GoalContribution, error) {
+	var contributions []models.GoalContribution
+	if len(goalIDs) == 0 {
+		return contributions, nil
+	}
+	err := r.db.Preload("Transaction").
+		Joins("JOIN transactions AS t ON t.id = goal_contributions.transaction_id").
+		Where("goal_contributions.goal_id IN ?", goalIDs).
+		Order("t.date DESC, goal_contributions.id DESC").
+		Find(&contributions).Error
+	return contributions, err
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InviteRepository определяет интерфейс для работы с приглашениями в семью в базе данных
+type InviteRepository interface {
+	// Create создает приглашение
+	Create(invite *models.FamilyInvite) error
+
+	// GetByID получает приглашение по идентификатору
+	// Возвращает nil, если приглашение не найдено
+	GetByID(id uint) (*models.FamilyInvite, error)
+
+	// GetByTokenHash получает приглашение по хешу токена
+	// Возвращает nil, если приглашение не найдено
+	GetByTokenHash(hash string) (*models.FamilyInvite, error)
+
+	// ListPendingByFamily возвращает действующие приглашения семьи
+	ListPendingByFamily(familyID uint, now time.Time) ([]models.FamilyInvite, error)
+
+	// ListPendingByEmailKey возвращает действующие приглашения на адрес с ключом key вместе с данными семей
+	ListPendingByEmailKey(key string, now time.Time) ([]models.FamilyInvite, error)
+
+	// SetStatus переводит ожидающее приглашение в новый статус
+	// Возвращает false, если приглашение уже не в статусе pending
+	SetStatus(id uint, status models.InviteStatus) (bool, error)
+
+	// RevokePending отзывает ожидающие приглашения в семью на адрес с ключом key
+	RevokePending(familyID uint, key string) error
+
+	// Accept в одной транзакции отмечает приглашение принятым и добавляет
+	// пользователя в семью с ролью из приглашения
+	// Возвращает false, если приглашение уже не в статусе pending
+	Accept(invite *models.FamilyInvite, userID uint) (bool, error)
+}
+
+// inviteRepository реализует интерфейс InviteRepository
+type inviteRepository struct {
+	db *gorm.DB
+}
+
+// NewInviteRepository создает новый экземпляр InviteRepository
+func NewInviteRepository(db *gorm.DB) InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+func (r *inviteRepository) Create(invite *models.FamilyInvite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *inviteRepository) GetByID(id uint) (*models.FamilyInvite, error) {
+	var invite models.FamilyInvite
+	result := r.db.Preload("Family").First(&invite, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &invite, result.Error
+}
+
+func (r *inviteRepository) GetByTokenHash(hash string) (*models.FamilyInvite, error) {
+	var invite models.FamilyInvite
+	result := r.db.Preload("Family").Where("token_hash = ?", hash).First(&invite)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &invite, result.Error
+}
+
+func (r *inviteRepository) ListPendingByFamily(familyID uint, now time.Time) ([]models.FamilyInvite, error) {
+	var invites []models.FamilyInvite
+	err := r.db.Where("family_id = ? AND status = ? AND expires_at > ?", familyID, models.InvitePending, now).
+		Order("created_at DESC").Find(&invites).Error
+	return invites, err
+}
+
+func (r *inviteRepository) ListPendingByEmailKey(key string, now time.Time) ([]models.FamilyInvite, error) {
+	var invites []models.FamilyInvite
+	err := r.db.Preload("Family").
+		Where("email_key = ? AND status = ? AND expires_at > ?", key, models.InvitePending, now).
+		Order("created_at DESC").Find(&invites).Error
+	return invites, err
+}
+
+func (r *inviteRepository) SetStatus(id uint, status models.InviteStatus) (bool, error) {
+	result := r.db.Model(&models.FamilyInvite{}).
+		Where("id = ? AND status = ?", id, models.InvitePending).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *inviteRepository) RevokePending(familyID uint, key string) error {
+	return r.db.Model(&models.FamilyInvite{}).
+		Where("family_id = ? AND email_key = ? AND status = ?", familyID, key, models.InvitePending).
+		Updates(map[string]interface{}{"status": models.InviteRevoked, "responded_at": time.Now()}).Error
+}
+
+func (r *inviteRepository) Accept(invite *models.FamilyInvite, userID uint) (bool, error) {
+	accepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.FamilyInvite{}).
+			Where("id = ? AND status = ?", invite.ID, models.InvitePending).
+			Updates(map[string]interface{}{
+				"status":       models.InviteAccepted,
+				"responded_at": now,
+				"accepted_by":  userID,
+			})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		if err := tx.Create(&models.FamilyMember{
+			FamilyID: invite.FamilyID,
+			UserID:   userID,
+			Role:     invite.Role,
+			JoinedAt: now,
+		}).Error; err != nil {
+			return err
+		}
+		accepted = true
+		return nil
+	})
+	return accepted, err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UnbalancedEntry запись журнала, сумма проводок которой в валюте не равна нулю
+type UnbalancedEntry struct {
+	TransactionID uint   `json:"transaction_id"`
+	Currency      string `json:"currency"`
+	Sum           int64  `json:"sum"`
+}
+
+// MismatchedPosting проводка, валюта которой не совпадает с валютой её счёта
+type MismatchedPosting struct {
+	PostingID       uint   `json:"posting_id"`
+	TransactionID   uint   `json:"transaction_id"`
+	AccountID       uint   `json:"account_id"`
+	Currency        string `json:"currency"`
+	AccountCurrency string `json:"account_currency"`
+}
+
+// LedgerRepository определяет интерфейс для работы с проводками главной книги
+type LedgerRepository interface {
+	// ReplacePostings заменяет проводки операции; пустой postings только удаляет прежние проводки,
+	// как у ожидающего одобрения или отклонённого расхода
+	// Вызывайте внутри Transactor.Transaction вместе с сохранением самой операции
+	ReplacePostings(transactionID uint, postings []models.Posting) error
+
+	// ListByTransaction возвращает проводки операции
+	ListByTransaction(transactionID uint) ([]models.Posting, error)
+
+	// Unbalanced возвращает записи журнала с ненулевой суммой проводок
+	Unbalanced() ([]UnbalancedEntry, error)
+
+	// WithoutPostings возвращает идентификаторы проведённых операций, у которых нет проводок
+	WithoutPostings() ([]uint, error)
+
+	// CurrencyMismatches возвращает проводки в валюте, отличной от валюты счёта
+	CurrencyMismatches() ([]MismatchedPosting, error)
+}
+
+// ledgerRepository реализует интерфейс LedgerRepository
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository создает новый экземпляр LedgerRepository
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) ReplacePostings(transactionID uint, postings []models.Posting) error {
+	if err := r.db.Where("transaction_id = ?", transactionID).Delete(&models.Posting{}).Error; err != nil {
+		return err
+	}
+	if len(postings) == 0 {
+		return nil
+	}
+	for i := range postings {
+		postings[i].ID = 0
+		postings[i].TransactionID = transactionID
+	}
+	return r.db.Create(&postings).Error
+}
+
+func (r *ledgerRepository) ListByTransaction(transactionID uint) ([]models.Posting, error) {
+	var postings []models.Posting
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id").Find(&postings).Error
+	return postings, err
+}
+
+func (r *ledgerRepository) Unbalanced() ([]UnbalancedEntry, error) {
+	var entries []UnbalancedEntry
+	err := r.db.Model(&models.Posting{}).
+		Select("transaction_id, currency, SUM(amount) AS sum").
+		Group("transaction_id, currency").
+		Having("SUM(amount) <> 0").
+		Order("transaction_id").
+		Scan(&entries).Error
+	return entries, err
+}
+
+func (r *ledgerRepository) WithoutPostings() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Transaction{}).
+		Where("status = ?", models.TransactionPosted).
+		Where("NOT EXISTS (SELECT 1 FROM postings p WHERE p.transaction_id = transactions.id)").
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *ledgerRepository) CurrencyMismatches() ([]MismatchedPosting, error) {
+	var mismatches []MismatchedPosting
+	err := r.db.Table("postings p").
+		Select("p.id AS posting_id, p.transaction_id, p.account_id, p.currency, a.currency AS account_currency").
+		Joins("JOIN accounts a ON a.id = p.account_id").
+		Where("p.currency <> a.currency").
+		Order("p.id").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
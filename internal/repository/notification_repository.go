@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository определяет интерфейс для работы с уведомлениями в базе данных
+type NotificationRepository interface {
+	// Create создает уведомление
+	Create(notification *models.Notification) error
+
+	// List возвращает уведомления пользователя от новых к старым
+	// beforeID > 0 возвращает уведомления старше указанного
+	List(userID uint, unreadOnly bool, beforeID uint, limit int) ([]models.Notification, error)
+
+	// CountUnread возвращает количество непрочитанных уведомлений пользователя
+	CountUnread(userID uint) (int64, error)
+
+	// MarkRead отмечает уведомления пользователя прочитанными; пустой ids отмечает все
+	MarkRead(userID uint, ids []uint) error
+
+	// ListPreferences возвращает сохранённые настройки доставки уведомлений пользователя
+	ListPreferences(userID uint) ([]models.NotificationPreference, error)
+
+	// SavePreference создает или обновляет настройку доставки уведомлений
+	SavePreference(preference *models.NotificationPreference) error
+}
+
+// notificationRepository реализует интерфейс NotificationRepository
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository создает новый экземпляр NotificationRepository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepository) List(userID uint, unreadOnly bool, beforeID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(userID uint, ids []uint) error {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) ListPreferences(userID uint) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *notificationRepository) SavePreference(preference *models.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "in_app"}),
+	}).Create(preference).Error
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository определяет интерфейс для работы с очередью исходящих писем
+type OutboxRepository interface {
+	// Create добавляет письма в очередь одним запросом: сохраняются все письма или ни одного
+	Create(msgs []models.EmailOutbox) error
+
+	// ClaimDue атомарно закрепляет за воркером до limit писем, готовых к отправке,
+	// на время lease и увеличивает у них счётчик попыток; LockedUntil писем — конец аренды
+	// Письма, чья аренда истекла (воркер упал или завис), снова становятся доступны
+	ClaimDue(limit int, lease time.Duration) ([]models.EmailOutbox, error)
+
+	// Методы Mark* фиксируют результат отправки, только если письмо всё ещё закреплено
+	// за воркером арендой lockedUntil, полученной от ClaimDue. Возвращают false, если аренда
+	// истекла и письмо забрал другой воркер: его результат не перезаписывается
+
+	// MarkSent отмечает письмо как доставленное и очищает его тело
+	MarkSent(id uint, lockedUntil time.Time) (bool, error)
+
+	// MarkRetry возвращает письмо в очередь для повторной попытки в момент next
+	MarkRetry(id uint, lockedUntil, next time.Time, lastErr string) (bool, error)
+
+	// MarkDead переводит письмо в dead-letter
+	MarkDead(id uint, lockedUntil time.Time, lastErr string) (bool, error)
+
+	// MarkExpired переводит письмо, срок которого истёк, в dead-letter и очищает его тело
+	MarkExpired(id uint, lockedUntil time.Time, lastErr string) (bool, error)
+
+	// List возвращает письма с указанным статусом (все, если статус пустой)
+	// от новых к старым, без тел писем
+	List(status models.OutboxStatus, limit, offset int) ([]models.EmailOutbox, error)
+
+	// GetByID получает письмо по идентификатору
+	// Возвращает nil, если письмо не найдено
+	GetByID(id uint) (*models.EmailOutbox, error)
+
+	// Requeue сбрасывает счётчик попыток и ставит письмо в очередь на немедленную отправку
+	Requeue(id uint) error
+}
+
+// outboxRepository реализует интерфейс OutboxRepository
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository создает новый экземпляр OutboxRepository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(msgs []models.EmailOutbox) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return r.db.Create(&msgs).Error
+}
+
+func (r *outboxRepository) ClaimDue(limit int, lease time.Duration) ([]models.EmailOutbox, error) {
+	var claimed []models.EmailOutbox
+	now := time.Now()
+	err := r.db.Raw(`
+		UPDATE email_outbox
+		SET status = ?, locked_until = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE (status = ? AND next_attempt_at <= ?)
+			   OR (status = ? AND locked_until < ?)
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.OutboxSending, now.Add(lease), now,
+		models.OutboxPending, now,
+		models.OutboxSending, now,
+		limit,
+	).Scan(&claimed).Error
+	return claimed, err
+}
+
+func (r *outboxRepository) MarkSent(id uint, lockedUntil time.Time) (bool, error) {
+	return r.markClaimed(id, lockedUntil, map[string]interface{}{
+		"status":       models.OutboxSent,
+		"sent_at":      time.Now(),
+		"locked_until": nil,
+		"last_error":   "",
+		"text_body":    "",
+		"html_body":    nil,
+	})
+}
+
+func (r *outboxRepository) MarkRetry(id uint, lockedUntil, next time.Time, lastErr string) (bool, error) {
+	return r.markClaimed(id, lockedUntil, map[string]interface{}{
+		"status":          models.OutboxPending,
+		"next_attempt_at": next,
+		"locked_until":    nil,
+		"last_error":      lastErr,
+	})
+}
+
+func (r *outboxRepository) MarkDead(id uint, lockedUntil time.Time, lastErr string) (bool, error) {
+	return r.markClaimed(id, lockedUntil, map[string]interface{}{
+		"status":       models.OutboxDead,
+		"locked_until": nil,
+		"last_error":   lastErr,
+	})
+}
+
+func (r *outboxRepository) MarkExpired(id uint, lockedUntil time.Time, lastErr string) (bool, error) {
+	return r.markClaimed(id, lockedUntil, map[string]interface{}{
+		"status":       models.OutboxDead,
+		"locked_until": nil,
+		"last_error":   lastErr,
+		"text_body":    "",
+		"html_body":    nil,
+	})
+}
+
+// markClaimed обновляет письмо, если оно всё ещё закреплено за воркером арендой lockedUntil
+func (r *outboxRepository) markClaimed(id uint, lockedUntil time.Time, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&models.EmailOutbox{}).
+		Where("id = ? AND status = ? AND locked_until = ?", id, models.OutboxSending, lockedUntil).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) List(status models.OutboxStatus, limit, offset int) ([]models.EmailOutbox, error) {
+	var msgs []models.EmailOutbox
+	query := r.db.Omit("text_body", "html_body").Order("id DESC").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&msgs).Error
+	return msgs, err
+}
+
+func (r *outboxRepository) GetByID(id uint) (*models.EmailOutbox, error) {
+	var msg models.EmailOutbox
+	result := r.db.First(&msg, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &msg, result.Error
+}
+
+func (r *outboxRepository) Requeue(id uint) error {
+	return r.db.Model(&models.EmailOutbox{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.OutboxPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"locked_until":    nil,
+	}).Error
+}
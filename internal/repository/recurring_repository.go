@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecurringRepository определяет интерфейс для работы с регулярными операциями в базе данных
+type RecurringRepository interface {
+	// Create создает шаблон регулярной операции
+	Create(recurring *models.RecurringTransaction) error
+
+	// GetByID получает шаблон по идентификатору
+	// Возвращает nil, если шаблон не найден
+	GetByID(id uint) (*models.RecurringTransaction, error)
+
+	// LockByID получает шаблон и блокирует его строку до конца транзакции
+	// Возвращает nil, если шаблон не найден или уже заблокирован другой транзакцией
+	// Вызывайте внутри Transactor.Transaction
+	LockByID(id uint) (*models.RecurringTransaction, error)
+
+	// ListByFamily возвращает шаблоны семьи в порядке даты следующего повторения
+	ListByFamily(familyID uint) ([]models.RecurringTransaction, error)
+
+	// ListDue возвращает идентификаторы активных шаблонов с датой повторения не позже until,
+	// начиная с самых давних
+	ListDue(until time.Time, limit int) ([]uint, error)
+
+	// Update сохраняет шаблон
+	Update(recurring *models.RecurringTransaction) error
+
+	// Delete удаляет шаблон вместе с записями о повторениях; проведённые операции остаются
+	Delete(id uint) error
+
+	// GetOccurrence получает запись о повторении шаблона в дату date
+	// Возвращает nil, если повторение не отличается от шаблона
+	GetOccurrence(recurringID uint, date time.Time) (*models.RecurringOccurrence, error)
+
+	// ListOccurrences возвращает записи о повторениях шаблона в периоде [from, to]
+	ListOccurrences(recurringID uint, from, to time.Time) ([]models.RecurringOccurrence, error)
+
+	// SaveOccurrence создает или обновляет запись о повторении шаблона в дату occurrence.Date
+	SaveOccurrence(occurrence *models.RecurringOccurrence) error
+
+	// ReassignCategory переносит шаблоны и изменённые повторения категории from в категорию to
+	ReassignCategory(from, to uint) error
+}
+
+// recurringRepository реализует интерфейс RecurringRepository
+type recurringRepository struct {
+	db *gorm.DB
+}
+
+// NewRecurringRepository создает новый экземпляр RecurringRepository
+func NewRecurringRepository(db *gorm.DB) RecurringRepository {
+	return &recurringRepository{db: db}
+}
+
+func (r *recurringRepository) Create(recurring *models.RecurringTransaction) error {
+	return r.db.Create(recurring).Error
+}
+
+func (r *recurringRepository) GetByID(id uint) (*models.RecurringTransaction, error) {
+	var recurring models.RecurringTransaction
+	result := r.db.First(&recurring, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &recurring, result.Error
+}
+
+func (r *recurringRepository) LockByID(id uint) (*models.RecurringTransaction, error) {
+	var recurring models.RecurringTransaction
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).First(&recurring, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &recurring, result.Error
+}
+
+func (r *recurringRepository) ListByFamily(familyID uint) ([]models.RecurringTransaction, error) {
+	var list []models.RecurringTransaction
+	err := r.db.Where("family_id = ?", familyID).Order("next_date NULLS LAST, id").Find(&list).Error
+	return list, err
+}
+
+func (r *recurringRepository) ListDue(until time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.RecurringTransaction{}).
+		Where("NOT paused AND next_date <= ?", sqlDate(until)).
+		Order("next_date, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *recurringRepository) Update(recurring *models.RecurringTransaction) error {
+	return r.db.Save(recurring).Error
+}
+
+func (r *recurringRepository) Delete(id uint) error {
+	return r.db.Delete(&models.RecurringTransaction{}, id).Error
+}
+
+func (r *recurringRepository) GetOccurrence(recurringID uint, date time.Time) (*models.RecurringOccurrence, error) {
+	var occurrence models.RecurringOccurrence
+	result := r.db.Where("recurring_id = ? AND date = ?", recurringID, sqlDate(date)).First(&occurrence)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &occurrence, result.Error
+}
+
+func (r *recurringRepository) ListOccurrences(recurringID uint, from, to time.Time) ([]models.RecurringOccurrence, error) {
+	var list []models.RecurringOccurrence
+	err := r.db.Where("recurring_id = ? AND date BETWEEN ? AND ?", recurringID, sqlDate(from), sqlDate(to)).
+		Order("date").Find(&list).Error
+	return list, err
+}
+
+func (r *recurringRepository) SaveOccurrence(occurrence *models.RecurringOccurrence) error {
+	if occurrence.ID != 0 {
+		return r.db.Save(occurrence).Error
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "recurring_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "transaction_id", "amount", "category_id", "payee", "note", "updated_at",
+		}),
+	}).Create(occurrence).Error
+}
+
+func (r *recurringRepository) ReassignCategory(from, to uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RecurringTransaction{}).Where("category_id = ?", from).Update("category_id", to).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.RecurringOccurrence{}).Where("category_id = ?", from).Update("category_id", to).Error
+	})
+}
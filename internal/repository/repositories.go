@@ -0,0 +1,80 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories набор репозиториев, работающих через одно подключение
+// Внутри Transactor.Transaction все репозитории набора используют одну транзакцию БД
+type Repositories struct {
+	Users         UserRepository
+	Outbox        OutboxRepository
+	Families      FamilyRepository
+	Invites       InviteRepository
+	Accounts      AccountRepository
+	Transactions  TransactionRepository
+	Ledger        LedgerRepository
+	Categories    CategoryRepository
+	Budgets       BudgetRepository
+	Notifications NotificationRepository
+	Recurring     RecurringRepository
+	Bills         BillRepository
+	Rates         ExchangeRateRepository
+	Splits        SplitRepository
+	Debts         DebtRepository
+	Goals         GoalRepository
+	Allowances    AllowanceRepository
+	Spending      SpendingRequestRepository
+	Approvals     ApprovalRepository
+	Limits        SpendingLimitRepository
+	Statements    StatementRepository
+}
+
+// NewRepositories создает набор репозиториев поверх подключения или транзакции db
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db),
+		Outbox:        NewOutboxRepository(db),
+		Families:      NewFamilyRepository(db),
+		Invites:       NewInviteRepository(db),
+		Accounts:      NewAccountRepository(db),
+		Transactions:  NewTransactionRepository(db),
+		Ledger:        NewLedgerRepository(db),
+		Categories:    NewCategoryRepository(db),
+		Budgets:       NewBudgetRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Recurring:     NewRecurringRepository(db),
+		Bills:         NewBillRepository(db),
+		Rates:         NewExchangeRateRepository(db),
+		Splits:        NewSplitRepository(db),
+		Debts:         NewDebtRepository(db),
+		Goals:         NewGoalRepository(db),
+		Allowances:    NewAllowanceRepository(db),
+		Spending:      NewSpendingRequestRepository(db),
+		Approvals:     NewApprovalRepository(db),
+		Limits:        NewSpendingLimitRepository(db),
+		Statements:    NewStatementRepository(db),
+	}
+}
+
+// Transactor выполняет несколько операций с репозиториями атомарно
+type Transactor interface {
+	// Transaction выполняет fn в транзакции БД и передает ей набор репозиториев,
+	// привязанных к этой транзакции
+	// Если fn возвращает ошибку или паникует, транзакция откатывается
+	Transaction(fn func(repos *Repositories) error) error
+}
+
+// transactor реализует интерфейс Transactor
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor создает новый экземпляр Transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) Transaction(fn func(repos *Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
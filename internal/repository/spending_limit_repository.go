@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SpendingLimitRepository определяет интерфейс для работы с лимитами расходов участников
+type SpendingLimitRepository interface {
+	// Create создает лимит
+	Create(limit *models.SpendingLimit) error
+
+	// GetByID получает лимит по идентификатору
+	// Возвращает nil, если лимит не найден
+	GetByID(id uint) (*models.SpendingLimit, error)
+
+	// ListByFamily возвращает лимиты семьи; при userID > 0 — только лимиты этого участника
+	ListByFamily(familyID, userID uint) ([]models.SpendingLimit, error)
+
+	// LockByMember возвращает лимиты участника и блокирует их строки до конца транзакции,
+	// чтобы одновременные расходы участника проверялись по лимиту по очереди
+	// Вызывайте внутри Transactor.Transaction
+	LockByMember(familyID, userID uint) ([]models.SpendingLimit, error)
+
+	// Update сохраняет лимит
+	Update(limit *models.SpendingLimit) error
+
+	// Delete удаляет лимит
+	Delete(id uint) error
+}
+
+// spendingLimitRepository реализует интерфейс SpendingLimitRepository
+type spendingLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewSpendingLimitRepository создает новый экземпляр SpendingLimitRepository
+func NewSpendingLimitRepository(db *gorm.DB) SpendingLimitRepository {
+	return &spendingLimitRepository{db: db}
+}
+
+func (r *spendingLimitRepository) Create(limit *models.SpendingLimit) error {
+	return r.db.Create(limit).Error
+}
+
+func (r *spendingLimitRepository) GetByID(id uint) (*models.SpendingLimit, error) {
+	var limit models.SpendingLimit
+	result := r.db.First(&limit, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &limit, result.Error
+}
+
+func (r *spendingLimitRepository) ListByFamily(familyID, userID uint) ([]models.SpendingLimit, error) {
+	var limits []models.SpendingLimit
+	db := r.db.Where("family_id = ?", familyID)
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	err := db.Order("user_id, id").Find(&limits).Error
+	return limits, err
+}
+
+func (r *spendingLimitRepository) LockByMember(familyID, userID uint) ([]models.SpendingLimit, error) {
+	var limits []models.SpendingLimit
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("family_id = ? AND user_id = ?", familyID, userID).
+		Order("id").Find(&limits).Error
+	return limits, err
+}
+
+func (r *spendingLimitRepository) Update(limit *models.SpendingLimit) error {
+	return r.db.Save(limit).Error
+}
+
+func (r *spendingLimitRepository) Delete(id uint) error {
+	return r.db.Delete(&models.SpendingLimit{}, id).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SpendingRequestQuery фильтры списка запросов на расход; нулевые поля не фильтруют
+type SpendingRequestQuery struct {
+	FamilyID    uint
+	RequestedBy uint
+	Status      models.SpendingStatus
+	Limit       int
+}
+
+// SpendingRequestRepository определяет интерфейс для работы с запросами детей на расход в базе данных
+type SpendingRequestRepository interface {
+	// Create создает запрос на расход
+	Create(request *models.SpendingRequest) error
+
+	// GetByID получает запрос по идентификатору
+	// Возвращает nil, если запрос не найден
+	GetByID(id uint) (*models.SpendingRequest, error)
+
+	// LockByID получает запрос и блокирует его строку до конца транзакции;
+	// если запрос уже заблокирован, ждёт завершения другой транзакции
+	// Возвращает nil, если запрос не найден
+	// Вызывайте внутри Transactor.Transaction
+	LockByID(id uint) (*models.SpendingRequest, error)
+
+	// List возвращает запросы по фильтрам, начиная с последних
+	List(query SpendingRequestQuery) ([]models.SpendingRequest, error)
+
+	// Update сохраняет запрос
+	Update(request *models.SpendingRequest) error
+
+	// ReassignCategory переносит запросы категории from в категорию to
+	ReassignCategory(from, to uint) error
+}
+
+// spendingRequestRepository реализует интерфейс SpendingRequestRepository
+type spendingRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewSpendingRequestRepository создает новый экземпляр SpendingRequestRepository
+func NewSpendingRequestRepository(db *gorm.DB) SpendingRequestRepository {
+	return &spendingRequestRepository{db: db}
+}
+
+func (r *spendingRequestRepository) Create(request *models.SpendingRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *spendingRequestRepository) GetByID(id uint) (*models.SpendingRequest, error) {
+	var request models.SpendingRequest
+	result := r.db.First(&request, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &request, result.Error
+}
+
+func (r *spendingRequestRepository) LockByID(id uint) (*models.SpendingRequest, error) {
+	var request models.SpendingRequest
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &request, result.Error
+}
+
+func (r *spendingRequestRepository) List(query SpendingRequestQuery) ([]models.SpendingRequest, error) {
+	var list []models.SpendingRequest
+	db := r.db.Where("family_id = ?", query.FamilyID)
+	if query.RequestedBy != 0 {
+		db = db.Where("requested_by = ?", query.RequestedBy)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&list).Error
+	return list, err
+}
+
+func (r *spendingRequestRepository) Update(request *models.SpendingRequest) error {
+	return r.db.Save(request).Error
+}
+
+func (r *spendingRequestRepository) ReassignCategory(from, to uint) error {
+	return r.db.Model(&models.SpendingRequest{}).Where("category_id = ?", from).Update("category_id", to).Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SplitRepository определяет интерфейс для работы с разделением расходов в базе данных
+type SplitRepository interface {
+	// Current получает действующую версию разделения операции вместе с долями
+	// Возвращает nil, если операция не разделена
+	Current(transactionID uint) (*models.Split, error)
+
+	// History возвращает все версии разделения операции, начиная с последней
+	History(transactionID uint) ([]models.Split, error)
+
+	// Replace сохраняет новую версию разделения операции split.TransactionID;
+	// действующая версия помечается заменённой. Номер версии назначается автоматически
+	Replace(split *models.Split) error
+
+	// Cancel помечает действующую версию разделения операции заменённой без новой версии
+	// Возвращает false, если операция не разделена
+	Cancel(transactionID uint) (bool, error)
+
+	// Debts возвращает долги участников семьи по действующим разделениям проведённых расходов:
+	// сколько каждый участник должен заплатившему, по валютам
+	Debts(familyID uint) ([]DebtSum, error)
+}
+
+// splitRepository реализует интерфейс SplitRepository
+type splitRepository struct {
+	db *gorm.DB
+}
+
+// NewSplitRepository создает новый экземпляр SplitRepository
+func NewSplitRepository(db *gorm.DB) SplitRepository {
+	return &splitRepository{db: db}
+}
+
+// withShares подгружает доли участников в порядке идентификатора участника
+func withShares(db *gorm.DB) *gorm.DB {
+	return db.Preload("Shares", func(db *gorm.DB) *gorm.DB {
+		return db.Order("user_id")
+	}).Preload("Shares.User")
+}
+
+func (r *splitRepository) Current(transactionID uint) (*models.Split, error) {
+	var split models.Split
+	result := withShares(r.db).Where("transaction_id = ? AND superseded_at IS NULL", transactionID).First(&split)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &split, result.Error
+}
+
+func (r *splitRepository) History(transactionID uint) ([]models.Split, error) {
+	var splits []models.Split
+	err := withShares(r.db).Where("transaction_id = ?", transactionID).Order("version DESC").Find(&splits).Error
+	return splits, err
+}
+
+func (r *splitRepository) Replace(split *models.Split) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var version int
+		err := tx.Model(&models.Split{}).Select("COALESCE(MAX(version), 0)").
+			Where("transaction_id = ?", split.TransactionID).Scan(&version).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Split{}).
+			Where("transaction_id = ? AND superseded_at IS NULL", split.TransactionID).
+			Update("superseded_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		split.ID = 0
+		split.Version = version + 1
+		split.SupersededAt = nil
+		// Одновременная замена упрётся в уникальность (transaction_id, version)
+		return tx.Create(split).Error
+	})
+}
+
+func (r *splitRepository) Cancel(transactionID uint) (bool, error) {
+	result := r.db.Model(&models.Split{}).
+		Where("transaction_id = ? AND superseded_at IS NULL", transactionID).
+		Update("superseded_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *splitRepository) Debts(familyID uint) ([]DebtSum, error) {
+	var sums []DebtSum
+	err := r.db.Table("transaction_splits AS s").
+		Select("s.paid_by AS lender_id, sh.user_id AS borrower_id, s.currency, SUM(sh.amount) AS total").
+		Joins("JOIN transaction_split_shares AS sh ON sh.split_id = s.id").
+		Joins("JOIN transactions AS t ON t.id = s.transaction_id").
+		Where("s.family_id = ? AND s.superseded_at IS NULL AND sh.user_id <> s.paid_by", familyID).
+		Where("t.status = ?", models.TransactionPosted).
+		Group("s.paid_by, sh.user_id, s.currency").
+		Scan(&sums).Error
+	return sums, err
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fingerprintChunk сколько отпечатков проверяется одним запросом
+const fingerprintChunk = 1000
+
+// StatementRepository определяет интерфейс для работы с профилями сопоставления
+// и импортами банковских выписок
+type StatementRepository interface {
+	// CreateProfile создает профиль сопоставления
+	CreateProfile(profile *models.StatementProfile) error
+
+	// GetProfile получает профиль по идентификатору
+	// Возвращает nil, если профиль не найден
+	GetProfile(id uint) (*models.StatementProfile, error)
+
+	// ListProfiles возвращает профили пользователя в порядке названия
+	ListProfiles(userID uint) ([]models.StatementProfile, error)
+
+	// UpdateProfile сохраняет профиль
+	UpdateProfile(profile *models.StatementProfile) error
+
+	// DeleteProfile удаляет профиль; импорты остаются без ссылки на профиль
+	DeleteProfile(id uint) error
+
+	// Create создает импорт вместе с содержимым файла
+	Create(imp *models.StatementImport) error
+
+	// GetByID получает импорт по идентификатору без содержимого файла и строк
+	// Возвращает nil, если импорт не найден
+	GetByID(id uint) (*models.StatementImport, error)
+
+	// Lock получает импорт вместе с содержимым файла и блокирует его строку до конца транзакции;
+	// если строка уже заблокирована, ждёт завершения другой транзакции
+	// Возвращает nil, если импорт не найден
+	// Вызывайте внутри Transactor.Transaction
+	Lock(id uint) (*models.StatementImport, error)
+
+	// LockByID получает импорт вместе с содержимым файла и блокирует его строку до конца транзакции
+	// Возвращает nil, если импорт не найден или уже заблокирован другой транзакцией
+	// Вызывайте внутри Transactor.Transaction
+	LockByID(id uint) (*models.StatementImport, error)
+
+	// FindUnfinished получает незавершённый импорт того же файла на тот же счёт,
+	// загруженный пользователем: ждущий разбора или подтверждения
+	// Возвращает nil, если такого импорта нет
+	FindUnfinished(userID, accountID uint, fileHash string) (*models.StatementImport, error)
+
+	// List возвращает импорты пользователя без содержимого файлов и строк, начиная с последних
+	// familyID, если не 0, отбирает импорты по счетам семьи
+	List(userID, familyID uint, limit int) ([]models.StatementImport, error)
+
+	// ListQueued возвращает идентификаторы импортов, ждущих разбора или проведения,
+	// начиная с самых давних
+	ListQueued(limit int) ([]uint, error)
+
+	// Update сохраняет импорт; содержимое файла и строки не изменяются
+	Update(imp *models.StatementImport) error
+
+	// Delete удаляет импорт вместе со строками; проведённые операции остаются
+	Delete(id uint) error
+
+	// ReplaceRows заменяет строки импорта
+	ReplaceRows(importID uint, rows []models.StatementImportRow) error
+
+	// ListRows возвращает строки импорта в порядке строк файла
+	ListRows(importID uint) ([]models.StatementImportRow, error)
+
+	// GetRow получает строку импорта по идентификатору
+	// Возвращает nil, если строка не найдена
+	GetRow(id uint) (*models.StatementImportRow, error)
+
+	// UpdateRow сохраняет строку импорта
+	UpdateRow(row *models.StatementImportRow) error
+
+	// ImportedFingerprints возвращает отпечатки из fingerprints, которые уже импортированы на счёт
+	ImportedFingerprints(accountID uint, fingerprints []string) (map[string]bool, error)
+
+	// AddFingerprint отмечает строку выписки импортированной на счёт
+	// Возвращает false, если строка с таким отпечатком уже импортирована
+	AddFingerprint(fingerprint *models.StatementFingerprint) (bool, error)
+
+	// ReassignCategory переносит строки импортов с категорией from в категорию to
+	ReassignCategory(from, to uint) error
+}
+
+// statementRepository реализует интерфейс StatementRepository
+type statementRepository struct {
+	db *gorm.DB
+}
+
+// NewStatementRepository создает новый экземпляр StatementRepository
+func NewStatementRepository(db *gorm.DB) StatementRepository {
+	return &statementRepository{db: db}
+}
+
+func (r *statementRepository) CreateProfile(profile *models.StatementProfile) error {
+	return r.db.Create(profile).Error
+}
+
+func (r *statementRepository) GetProfile(id uint) (*models.StatementProfile, error) {
+	var profile models.StatementProfile
+	result := r.db.First(&profile, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &profile, result.Error
+}
+
+func (r *statementRepository) ListProfiles(userID uint) ([]models.StatementProfile, error) {
+	var profiles []models.StatementProfile
+	err := r.db.Where("user_id = ?", userID).Order("name, id").Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *statementRepository) UpdateProfile(profile *models.StatementProfile) error {
+	return r.db.Save(profile).Error
+}
+
+func (r *statementRepository) DeleteProfile(id uint) error {
+	return r.db.Delete(&models.StatementProfile{}, id).Error
+}
+
+func (r *statementRepository) Create(imp *models.StatementImport) error {
+	return r.db.Omit("Rows").Create(imp).Error
+}
+
+func (r *statementRepository) GetByID(id uint) (*models.StatementImport, error) {
+	var imp models.StatementImport
+	result := r.db.Omit("file").First(&imp, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &imp, result.Error
+}
+
+func (r *statementRepository) Lock(id uint) (*models.StatementImport, error) {
+	var imp models.StatementImport
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&imp, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &imp, result.Error
+}
+
+func (r *statementRepository) LockByID(id uint) (*models.StatementImport, error) {
+	var imp models.StatementImport
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).First(&imp, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &imp, result.Error
+}
+
+func (r *statementRepository) FindUnfinished(userID, accountID uint, fileHash string) (*models.StatementImport, error) {
+	var imp models.StatementImport
+	result := r.db.Omit("file").
+		Where("user_id = ? AND account_id = ? AND file_hash = ?", userID, accountID, fileHash).
+		Where("status IN ?", []models.ImportStatus{models.ImportParsing, models.ImportReady}).
+		Order("id DESC").First(&imp)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &imp, result.Error
+}
+
+func (r *statementRepository) List(userID, familyID uint, limit int) ([]models.StatementImport, error) {
+	var list []models.StatementImport
+	db := r.db.Omit("file").Where("user_id = ?", userID)
+	if familyID != 0 {
+		db = db.Where("family_id = ?", familyID)
+	}
+	err := db.Order("id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *statementRepository) ListQueued(limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.StatementImport{}).
+		Where("status IN ?", []models.ImportStatus{models.ImportParsing, models.ImportCommitting}).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *statementRepository) Update(imp *models.StatementImport) error {
+	return r.db.Omit("File", "Rows").Save(imp).Error
+}
+
+func (r *statementRepository) Delete(id uint) error {
+	return r.db.Delete(&models.StatementImport{}, id).Error
+}
+
+func (r *statementRepository) ReplaceRows(importID uint, rows []models.StatementImportRow) error {
+	if err := r.db.Where("import_id = ?", importID).Delete(&models.StatementImportRow{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].ImportID = importID
+	}
+	return r.db.CreateInBatches(rows, 500).Error
+}
+
+func (r *statementRepository) ListRows(importID uint) ([]models.StatementImportRow, error) {
+	var rows []models.StatementImportRow
+	err := r.db.Where("import_id = ?", importID).Order("line, id").Find(&rows).Error
+	return rows, err
+}
+
+func (r *statementRepository) GetRow(id uint) (*models.StatementImportRow, error) {
+	var row models.StatementImportRow
+	result := r.db.First(&row, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &row, result.Error
+}
+
+func (r *statementRepository) UpdateRow(row *models.StatementImportRow) error {
+	return r.db.Save(row).Error
+}
+
+func (r *statementRepository) ImportedFingerprints(accountID uint, fingerprints []string) (map[string]bool, error) {
+	imported := make(map[string]bool)
+	for start := 0; start < len(fingerprints); start += fingerprintChunk {
+		chunk := fingerprints[start:min(start+fingerprintChunk, len(fingerprints))]
+		var found []string
+		err := r.db.Model(&models.StatementFingerprint{}).
+			Where("account_id = ? AND fingerprint IN ?", accountID, chunk).
+			Pluck("fingerprint", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, fp := range found {
+			imported[fp] = true
+		}
+	}
+	return imported, nil
+}
+
+func (r *statementRepository) AddFingerprint(fingerprint *models.StatementFingerprint) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(fingerprint)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *statementRepository) ReassignCategory(from, to uint) error {
+	return r.db.Model(&models.StatementImportRow{}).Where("category_id = ?", from).Update("category_id", to).Error
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TransactionCursor позиция в списке операций, упорядоченном по дате и идентификатору по убыванию
+type TransactionCursor struct {
+	Date time.Time
+	ID   uint
+}
+
+// TransactionFilter условия выборки операций семьи
+// Нулевые значения полей означают отсутствие условия
+type TransactionFilter struct {
+	FamilyID uint
+	DateFrom *time.Time
+	DateTo   *time.Time
+	// AccountID отбирает операции по счёту, включая входящие переводы
+	AccountID uint
+	// CategoryID отбирает операции категории и её подкатегорий
+	CategoryID uint
+	CreatedBy  uint
+	Direction  models.Direction
+	AmountMin  *int64
+	AmountMax  *int64
+	// After возвращает операции, следующие за курсором
+	After *TransactionCursor
+	Limit int
+}
+
+// sqlDate передаёт дату в запрос без времени и часового пояса,
+// чтобы сравнение с колонкой типа date не зависело от настроек сессии
+func sqlDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// TransactionRepository определяет интерфейс для работы с операциями в базе данных
+type TransactionRepository interface {
+	// Create создает операцию
+	Create(tx *models.Transaction) error
+
+	// GetByID получает операцию по идентификатору вместе с автором
+	// Возвращает nil, если операция не найдена
+	GetByID(id uint) (*models.Transaction, error)
+
+	// Update обновляет операцию
+	Update(tx *models.Transaction) error
+
+	// Delete удаляет операцию
+	Delete(id uint) error
+
+	// List возвращает операции по фильтру вместе с авторами,
+	// упорядоченные по дате и идентификатору по убыванию
+	List(filter TransactionFilter) ([]models.Transaction, error)
+
+	// CountByAccount возвращает количество операций по счёту, включая входящие переводы
+	CountByAccount(accountID uint) (int64, error)
+
+	// ReassignCategory переносит все операции категории from в категорию to
+	ReassignCategory(from, to uint) error
+
+	// SumByCategory возвращает суммы доходов и расходов семьи в валюте currency
+	// за период [from, to] по каждой категории; операции без категории и непроведённые
+	// операции не учитываются
+	SumByCategory(familyID uint, currency string, from, to time.Time) (map[uint]int64, error)
+
+	// SumForeignByCategory возвращает суммы доходов и расходов семьи в валютах, отличных от currency,
+	// за период [from, to] по категории, валюте и дате операции — для пересчёта по курсу на дату
+	SumForeignByCategory(familyID uint, currency string, from, to time.Time) ([]ForeignSum, error)
+
+	// SumByMember возвращает расходы участника за период по валюте и дате операции
+	// Отклонённые расходы не учитываются, расходы в ожидании одобрения учитываются
+	SumByMember(filter MemberSpendFilter) ([]DailySum, error)
+
+	// PayeeCategories возвращает категорию последней операции семьи с направлением direction
+	// для каждого получателя из payees; получатели сравниваются без учёта регистра,
+	// поэтому payees и ключи результата — в нижнем регистре. Операции без категории
+	// и архивные категории не учитываются
+	PayeeCategories(familyID uint, direction models.Direction, payees []string) (map[string]uint, error)
+}
+
+// MemberSpendFilter параметры выборки расходов участника
+type MemberSpendFilter struct {
+	FamilyID uint
+	UserID   uint
+	// AccountID счёт; nil — все счета семьи
+	AccountID *uint
+	From      time.Time
+	To        time.Time
+	// ExcludeID операция, которая не учитывается, например изменяемая
+	ExcludeID uint
+}
+
+// DailySum сумма операций в одной валюте за один день
+type DailySum struct {
+	Currency string
+	Date     time.Time
+	Total    int64
+}
+
+// ForeignSum сумма операций категории в одной валюте за один день
+type ForeignSum struct {
+	CategoryID uint
+	Currency   string
+	Date       time.Time
+	Total      int64
+}
+
+// transactionRepository реализует интерфейс TransactionRepository
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+// NewTransactionRepository создает новый экземпляр TransactionRepository
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+func (r *transactionRepository) Create(tx *models.Transaction) error {
+	return r.db.Create(tx).Error
+}
+
+func (r *transactionRepository) GetByID(id uint) (*models.Transaction, error) {
+	var tx models.Transaction
+	result := r.db.Preload("Author").First(&tx, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &tx, result.Error
+}
+
+func (r *transactionRepository) Update(tx *models.Transaction) error {
+	return r.db.Omit("Author").Save(tx).Error
+}
+
+func (r *transactionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Transaction{}, id).Error
+}
+
+func (r *transactionRepository) List(filter TransactionFilter) ([]models.Transaction, error) {
+	query := r.db.Preload("Author").Where("family_id = ?", filter.FamilyID)
+	if filter.DateFrom != nil {
+		query = query.Where("date >= ?", sqlDate(*filter.DateFrom))
+	}
+	if filter.DateTo != nil {
+		query = query.Where("date <= ?", sqlDate(*filter.DateTo))
+	}
+	if filter.AccountID != 0 {
+		query = query.Where("(account_id = ? OR counter_account_id = ?)", filter.AccountID, filter.AccountID)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id IN (SELECT id FROM categories WHERE id = ? OR parent_id = ?)",
+			filter.CategoryID, filter.CategoryID)
+	}
+	if filter.CreatedBy != 0 {
+		query = query.Where("created_by = ?", filter.CreatedBy)
+	}
+	if filter.Direction != "" {
+		query = query.Where("direction = ?", filter.Direction)
+	}
+	if filter.AmountMin != nil {
+		query = query.Where("amount >= ?", *filter.AmountMin)
+	}
+	if filter.AmountMax != nil {
+		query = query.Where("amount <= ?", *filter.AmountMax)
+	}
+	if filter.After != nil {
+		query = query.Where("(date, id) < (?::date, ?)", sqlDate(filter.After.Date), filter.After.ID)
+	}
+
+	var txs []models.Transaction
+	err := query.Order("date DESC, id DESC").Limit(filter.Limit).Find(&txs).Error
+	return txs, err
+}
+
+func (r *transactionRepository) CountByAccount(accountID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).Where("(account_id = ? OR counter_account_id = ?)", accountID, accountID).Count(&count).Error
+	return count, err
+}
+
+func (r *transactionRepository) ReassignCategory(from, to uint) error {
+	return r.db.Model(&models.Transaction{}).Where("category_id = ?", from).Update("category_id", to).Error
+}
+
+func (r *transactionRepository) SumByCategory(familyID uint, currency string, from, to time.Time) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Total      int64
+	}
+	err := r.db.Model(&models.Transaction{}).
+		Select("category_id, SUM(amount) AS total").
+		Where("family_id = ? AND currency = ? AND category_id IS NOT NULL", familyID, currency).
+		Where("direction <> ? AND status = ?", models.DirectionTransfer, models.TransactionPosted).
+		Where("date >= ? AND date <= ?", sqlDate(from), sqlDate(to)).
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		sums[row.CategoryID] = row.Total
+	}
+	return sums, nil
+}
+
+func (r *transactionRepository) SumForeignByCategory(familyID uint, currency string, from, to time.Time) ([]ForeignSum, error) {
+	var sums []ForeignSum
+	err := r.db.Model(&models.Transaction{}).
+		Select("category_id, currency, date, SUM(amount) AS total").
+		Where("family_id = ? AND currency <> ? AND category_id IS NOT NULL", familyID, currency).
+		Where("direction <> ? AND status = ?", models.DirectionTransfer, models.TransactionPosted).
+		Where("date >= ? AND date <= ?", sqlDate(from), sqlDate(to)).
+		Group("category_id, currency, date").
+		Scan(&sums).Error
+	return sums, err
+}
+
+func (r *transactionRepository) SumByMember(filter MemberSpendFilter) ([]DailySum, error) {
+	var sums []DailySum
+	db := r.db.Model(&models.Transaction{}).
+		Select("currency, date, SUM(amount) AS total").
+		Where("family_id = ? AND created_by = ? AND direction = ?", filter.FamilyID, filter.UserID, models.DirectionExpense).
+		Where("status <> ?", models.TransactionRejected).
+		Where("date >= ? AND date <= ?", sqlDate(filter.From), sqlDate(filter.To))
+	if filter.AccountID != nil {
+		db = db.Where("account_id = ?", *filter.AccountID)
+	}
+	if filter.ExcludeID != 0 {
+		db = db.Where("id <> ?", filter.ExcludeID)
+	}
+	err := db.Group("currency, date").Scan(&sums).Error
+	return sums, err
+}
+
+func (r *transactionRepository) PayeeCategories(familyID uint, direction models.Direction, payees []string) (map[string]uint, error) {
+	result := make(map[string]uint)
+	if len(payees) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		Payee      string
+		CategoryID uint
+	}
+	err := r.db.Table("transactions AS t").
+		Select("DISTINCT ON (lower(t.payee)) lower(t.payee) AS payee, t.category_id").
+		Joins("JOIN categories AS c ON c.id = t.category_id").
+		Where("t.family_id = ? AND t.direction = ? AND NOT c.archived", familyID, direction).
+		Where("lower(t.payee) IN ?", payees).
+		Order("lower(t.payee), t.date DESC, t.id DESC").
+		Scan(&rows).Error
+	for _, row := range rows {
+		result[row.Payee] = row.CategoryID
+	}
+	return result, err
+}
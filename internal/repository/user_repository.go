@@ -10,9 +10,9 @@ import (
 
 // UserRepository определяет интерфейс для работы с данными пользователей в базе данных
 type UserRepository interface {
-	// GetByEmail получает пользователя по email
+	// GetByEmailKey получает пользователя по ключу адреса (см. util.EmailNormalizer)
 	// Возвращает nil, если пользователь не найден
-	GetByEmail(email string) (*models.User, error)
+	GetByEmailKey(key string) (*models.User, error)
 
 	// Create создает нового пользователя
 	// Возвращает ошибку, если пользователь с таким email уже существует
@@ -33,9 +33,9 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetByEmailKey(key string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("email = ?", email).First(&user)
+	result := r.db.Where("email_key = ?", key).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrAccountNotFound возвращается, если счёт не существует или недоступен пользователю
+	ErrAccountNotFound = newError(KindNotFound, "счёт не найден")
+
+	// ErrAccountForbidden возвращается, если пользователь не может изменять счёт
+	ErrAccountForbidden = newError(KindForbidden, "недостаточно прав для изменения этого счёта")
+
+	// ErrAccountOwnerNotMember возвращается, если владелец личного счёта не состоит в семье
+	ErrAccountOwnerNotMember = newError(KindInvalid, "владелец счёта должен быть участником семьи")
+
+	// ErrAccountHasTransactions возвращается при удалении счёта, по которому есть операции
+	ErrAccountHasTransactions = newError(KindConflict, "по счёту есть операции: переместите счёт в архив вместо удаления")
+
+	// ErrAccountOrderMismatch возвращается, если список для сортировки не совпадает со счетами семьи
+	ErrAccountOrderMismatch = newError(KindInvalid, "список должен содержать каждый счёт семьи ровно один раз")
+)
+
+// AccountInput данные нового счёта
+type AccountInput struct {
+	Name string
+	Type models.AccountType
+	// Currency валюта счёта; по умолчанию валюта семьи
+	Currency string
+	// OpeningBalance начальный остаток в минимальных единицах валюты
+	OpeningBalance int64
+	// OwnerID владелец личного счёта; nil для общего счёта семьи
+	OwnerID *uint
+}
+
+// AccountChanges изменения счёта; пустые и nil поля не изменяются
+type AccountChanges struct {
+	Name           string
+	Type           models.AccountType
+	OpeningBalance *int64
+	Archived       *bool
+	SortOrder      *int
+}
+
+// CurrencyTotal сумма остатков счетов семьи в одной валюте
+type CurrencyTotal struct {
+	Currency string `json:"currency"`
+	// Amount сумма остатков в минимальных единицах валюты
+	Amount int64 `json:"amount"`
+	// Converted сумма в основной валюте семьи; nil, если курса нет
+	Converted *int64 `json:"converted"`
+	// Rate сколько единиц основной валюты стоит одна единица валюты; nil, если курса нет
+	Rate *string `json:"rate"`
+}
+
+// AccountTotals остатки счетов семьи по валютам и общий итог в основной валюте
+type AccountTotals struct {
+	BaseCurrency string `json:"base_currency"`
+	// Date дата курсов пересчёта — сегодняшний день в часовом поясе семьи
+	Date       time.Time       `json:"date"`
+	Currencies []CurrencyTotal `json:"currencies"`
+	// Total сумма пересчитанных остатков в основной валюте
+	Total int64 `json:"total"`
+	// Complete false, если для части валют нет курса и они не вошли в Total
+	Complete bool `json:"complete"`
+}
+
+// AccountService определяет интерфейс для работы со счетами
+type AccountService interface {
+	// Create создает счёт в семье
+	// Общий счёт могут создать владелец и взрослые; личный счёт для себя — любой
+	// участник, кроме наблюдателя; личный счёт для другого участника — только владелец
+	Create(user *models.User, familyID uint, input AccountInput) (*models.Account, error)
+
+	// Get возвращает счёт с текущим остатком; доступно любому участнику семьи
+	Get(user *models.User, accountID uint) (*models.Account, error)
+
+	// List возвращает счета семьи с текущими остатками; доступно любому участнику семьи
+	List(user *models.User, familyID uint, includeArchived bool) ([]models.Account, error)
+
+	// Update изменяет счёт; правила доступа совпадают с правилами создания
+	Update(user *models.User, accountID uint, changes AccountChanges) (*models.Account, error)
+
+	// Delete удаляет счёт без операций; счёт с операциями можно только архивировать
+	Delete(user *models.User, accountID uint) error
+
+	// Reorder задаёт порядок отображения счетов семьи; доступно владельцу и взрослым
+	Reorder(user *models.User, familyID uint, ids []uint) error
+
+	// Totals суммирует остатки активных счетов семьи по валютам и пересчитывает
+	// их в основную валюту семьи по курсу на сегодня; доступно любому участнику семьи
+	Totals(user *models.User, familyID uint) (*AccountTotals, error)
+}
+
+// accountService реализует интерфейс AccountService
+type accountService struct {
+	accountRepo repository.AccountRepository
+	txRepo      repository.TransactionRepository
+	familyRepo  repository.FamilyRepository
+	rates       ExchangeRateService
+}
+
+// NewAccountService создает новый экземпляр AccountService
+func NewAccountService(accountRepo repository.AccountRepository, txRepo repository.TransactionRepository,
+	familyRepo repository.FamilyRepository, rates ExchangeRateService) AccountService {
+	return &accountService{accountRepo: accountRepo, txRepo: txRepo, familyRepo: familyRepo, rates: rates}
+}
+
+// canEditAccount сообщает, может ли участник семьи изменять счёт
+func canEditAccount(member *models.FamilyMember, account *models.Account) bool {
+	if member.Role == models.RoleOwner {
+		return true
+	}
+	if account.Shared() {
+		return member.Role.CanManage()
+	}
+	if account.Supervised {
+		// Счёт ребёнка под присмотром ведут родители, а не сам ребёнок
+		return member.Role.CanManage()
+	}
+	return *account.OwnerID == member.UserID && member.Role != models.RoleViewer
+}
+
+func (s *accountService) Create(user *models.User, familyID uint, input AccountInput) (*models.Account, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if input.OwnerID != nil && *input.OwnerID != user.ID {
+		owner, err := s.familyRepo.GetMember(familyID, *input.OwnerID)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные семьи")
+		}
+		if owner == nil {
+			return nil, ErrAccountOwnerNotMember
+		}
+	}
+
+	account := &models.Account{
+		FamilyID:       familyID,
+		OwnerID:        input.OwnerID,
+		Name:           input.Name,
+		Type:           input.Type,
+		Currency:       input.Currency,
+		OpeningBalance: input.OpeningBalance,
+		CreatedBy:      user.ID,
+	}
+	if !canEditAccount(member, account) {
+		return nil, ErrAccountForbidden
+	}
+
+	if account.Currency == "" {
+		family, err := s.familyRepo.GetByID(familyID)
+		if err != nil || family == nil {
+			return nil, errors.New("не удалось получить данные семьи")
+		}
+		account.Currency = family.Currency
+	}
+	last, err := s.accountRepo.MaxSortOrder(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось создать счёт, попробуйте позже")
+	}
+	account.SortOrder = last + 1
+
+	if err := s.accountRepo.Create(account); err != nil {
+		return nil, errors.New("не удалось создать счёт, попробуйте позже")
+	}
+	account.Balance = account.OpeningBalance
+	return account, nil
+}
+
+func (s *accountService) Get(user *models.User, accountID uint) (*models.Account, error) {
+	account, _, err := s.load(user, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillBalances([]*models.Account{account}); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *accountService) List(user *models.User, familyID uint, includeArchived bool) ([]models.Account, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	accounts, err := s.accountRepo.ListByFamily(familyID, includeArchived)
+	if err != nil {
+		return nil, errors.New("не удалось получить список счетов")
+	}
+	refs := make([]*models.Account, len(accounts))
+	for i := range accounts {
+		refs[i] = &accounts[i]
+	}
+	if err := s.fillBalances(refs); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (s *accountService) Update(user *models.User, accountID uint, changes AccountChanges) (*models.Account, error) {
+	account, member, err := s.load(user, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEditAccount(member, account) {
+		return nil, ErrAccountForbidden
+	}
+
+	if changes.Name != "" {
+		account.Name = changes.Name
+	}
+	if changes.Type != "" {
+		account.Type = changes.Type
+	}
+	if changes.OpeningBalance != nil {
+		account.OpeningBalance = *changes.OpeningBalance
+	}
+	if changes.Archived != nil {
+		account.Archived = *changes.Archived
+	}
+	if changes.SortOrder != nil {
+		account.SortOrder = *changes.SortOrder
+	}
+	if err := s.accountRepo.Update(account); err != nil {
+		return nil, errors.New("не удалось обновить счёт, попробуйте позже")
+	}
+	if err := s.fillBalances([]*models.Account{account}); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *accountService) Delete(user *models.User, accountID uint) error {
+	account, member, err := s.load(user, accountID)
+	if err != nil {
+		return err
+	}
+	if !canEditAccount(member, account) {
+		return ErrAccountForbidden
+	}
+	count, err := s.txRepo.CountByAccount(account.ID)
+	if err != nil {
+		return errors.New("не удалось получить данные счёта")
+	}
+	if count > 0 {
+		return ErrAccountHasTransactions
+	}
+	if err := s.accountRepo.Delete(account.ID); err != nil {
+		return errors.New("не удалось удалить счёт, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *accountService) Reorder(user *models.User, familyID uint, ids []uint) error {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner, models.RoleAdult); err != nil {
+		return err
+	}
+	accounts, err := s.accountRepo.ListByFamily(familyID, true)
+	if err != nil {
+		return errors.New("не удалось получить список счетов")
+	}
+	if len(accounts) != len(ids) {
+		return ErrAccountOrderMismatch
+	}
+	known := make(map[uint]bool, len(accounts))
+	for _, a := range accounts {
+		known[a.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return ErrAccountOrderMismatch
+		}
+		// Повтор идентификатора тоже считается несовпадением
+		delete(known, id)
+	}
+	if err := s.accountRepo.Reorder(familyID, ids); err != nil {
+		return errors.New("не удалось изменить порядок счетов, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *accountService) Totals(user *models.User, familyID uint) (*AccountTotals, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil || family == nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	accounts, err := s.accountRepo.ListByFamily(familyID, false)
+	if err != nil {
+		return nil, errors.New("не удалось получить список счетов")
+	}
+	refs := make([]*models.Account, len(accounts))
+	for i := range accounts {
+		refs[i] = &accounts[i]
+	}
+	if err := s.fillBalances(refs); err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]int64)
+	currencies := []string{family.Currency}
+	for _, a := range accounts {
+		if _, ok := sums[a.Currency]; !ok {
+			currencies = append(currencies, a.Currency)
+		}
+		sums[a.Currency] += a.Balance
+	}
+	totals := &AccountTotals{
+		BaseCurrency: family.Currency,
+		Date:         familyDate(family),
+		Currencies:   make([]CurrencyTotal, 0, len(sums)),
+		Complete:     true,
+	}
+	table, err := s.rates.Table(currencies, totals.Date, totals.Date)
+	if err != nil {
+		return nil, err
+	}
+	for currency, amount := range sums {
+		total := CurrencyTotal{Currency: currency, Amount: amount}
+		if rate, ok := table.Rate(currency, family.Currency, totals.Date); ok {
+			if converted, err := money.Convert(amount, currency, family.Currency, rate); err == nil {
+				formatted := formatRate(rate)
+				total.Converted, total.Rate = &converted, &formatted
+				totals.Total += converted
+			}
+		}
+		if total.Converted == nil {
+			totals.Complete = false
+		}
+		totals.Currencies = append(totals.Currencies, total)
+	}
+	sort.Slice(totals.Currencies, func(i, j int) bool {
+		return totals.Currencies[i].Currency < totals.Currencies[j].Currency
+	})
+	return totals, nil
+}
+
+// load получает счёт и членство пользователя в семье счёта
+// Счёт чужой семьи неотличим от несуществующего
+func (s *accountService) load(user *models.User, accountID uint) (*models.Account, *models.FamilyMember, error) {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить данные счёта")
+	}
+	if account == nil {
+		return nil, nil, ErrAccountNotFound
+	}
+	member, err := requireMember(s.familyRepo, account.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, member, nil
+}
+
+// fillBalances вычисляет текущие остатки счетов
+func (s *accountService) fillBalances(accounts []*models.Account) error {
+	ids := make([]uint, len(accounts))
+	for i, a := range accounts {
+		ids[i] = a.ID
+	}
+	balances, err := s.accountRepo.Balances(ids)
+	if err != nil {
+		return errors.New("не удалось вычислить остатки счетов")
+	}
+	for _, a := range accounts {
+		a.Balance = balances[a.ID]
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrAllowanceNotFound возвращается, если карманные деньги не существуют или недоступны пользователю
+	ErrAllowanceNotFound = newError(KindNotFound, "карманные деньги не найдены")
+
+	// ErrAllowanceForbidden возвращается, если пользователь не может назначать карманные деньги и премии
+	ErrAllowanceForbidden = newError(KindForbidden, "назначать карманные деньги и премии могут только родители")
+
+	// ErrAllowanceNotChild возвращается, если получатель карманных денег не ребёнок в семье
+	ErrAllowanceNotChild = newError(KindInvalid, "карманные деньги назначаются только участнику семьи с ролью ребёнка")
+
+	// ErrAllowanceAccount возвращается, если деньги зачисляются не на личный счёт ребёнка
+	ErrAllowanceAccount = newError(KindInvalid, "карманные деньги зачисляются на личный счёт ребёнка")
+
+	// ErrAllowanceSameAccount возвращается, если счета списания и зачисления совпадают
+	ErrAllowanceSameAccount = newError(KindInvalid, "счета списания и зачисления должны различаться")
+
+	// ErrBonusNotFound возвращается, если премия не существует или недоступна пользователю
+	ErrBonusNotFound = newError(KindNotFound, "премия не найдена")
+
+	// ErrBonusPaid возвращается при удалении уже выплаченной премии
+	ErrBonusPaid = newError(KindConflict, "премия уже выплачена")
+)
+
+// allowancePayee получатель в переводе карманных денег на языке семьи
+var allowancePayee = map[string]string{
+	"ru": "Карманные деньги",
+	"en": "Allowance",
+}
+
+// AllowanceInput данные новых карманных денег
+type AllowanceInput struct {
+	ChildID       uint
+	FromAccountID uint
+	ToAccountID   uint
+	// Amount сумма выплаты в минимальных единицах валюты счетов
+	Amount int64
+	Rule   models.RecurrenceRule
+}
+
+// AllowanceChanges изменения карманных денег, действующие на будущие выплаты;
+// nil и нулевые поля не изменяются
+type AllowanceChanges struct {
+	FromAccountID uint
+	Amount        int64
+	// Rule новое расписание; даты выплат пересчитываются начиная с сегодняшнего дня
+	Rule *models.RecurrenceRule
+}
+
+// BonusInput данные премии за домашнее дело
+type BonusInput struct {
+	Chore string
+	// Amount сумма в минимальных единицах валюты карманных денег
+	Amount int64
+}
+
+// AllowanceService определяет интерфейс для работы с карманными деньгами детей
+// Карманные деньги и премии назначают родители — владелец и взрослые участники семьи;
+// счёт, на который приходят карманные деньги, становится счётом под присмотром:
+// ребёнок видит его остаток, но операции по нему проводят только родители
+type AllowanceService interface {
+	// Create назначает ребёнку карманные деньги и берёт его счёт под присмотр
+	Create(user *models.User, input AllowanceInput) (*models.Allowance, error)
+
+	// Get возвращает карманные деньги; ребёнку доступны только его собственные
+	Get(user *models.User, id uint) (*models.Allowance, error)
+
+	// List возвращает карманные деньги семьи; ребёнок видит только свои
+	List(user *models.User, familyID uint) ([]models.Allowance, error)
+
+	// Update изменяет сумму, счёт списания или расписание будущих выплат
+	Update(user *models.User, id uint, changes AllowanceChanges) (*models.Allowance, error)
+
+	// Pause приостанавливает или возобновляет выплаты
+	// Выплаты, пропущенные за время паузы, после возобновления не проводятся
+	Pause(user *models.User, id uint, paused bool) (*models.Allowance, error)
+
+	// Delete отменяет карманные деньги; проведённые переводы остаются
+	// Если на счёт ребёнка больше не приходят карманные деньги, присмотр снимается
+	Delete(user *models.User, id uint) error
+
+	// AddBonus назначает премию за домашнее дело; она выплачивается с ближайшими карманными деньгами
+	AddBonus(user *models.User, allowanceID uint, input BonusInput) (*models.AllowanceBonus, error)
+
+	// DeleteBonus удаляет ещё не выплаченную премию
+	DeleteBonus(user *models.User, bonusID uint) error
+
+	// Bonuses возвращает премии, начиная с последних
+	Bonuses(user *models.User, allowanceID uint) ([]models.AllowanceBonus, error)
+
+	// Payments возвращает проведённые выплаты, начиная с последних, не больше limit
+	Payments(user *models.User, allowanceID uint, limit int) ([]models.AllowancePayment, error)
+
+	// Run запускает планировщик, который проводит наступившие выплаты, и блокируется до отмены ctx
+	Run(ctx context.Context)
+}
+
+// allowanceService реализует интерфейс AllowanceService
+type allowanceService struct {
+	transactor    repository.Transactor
+	allowanceRepo repository.AllowanceRepository
+	accountRepo   repository.AccountRepository
+	familyRepo    repository.FamilyRepository
+	pollInterval  time.Duration
+}
+
+// NewAllowanceService создает новый экземпляр AllowanceService
+// Планировщик проверяет наступившие выплаты каждые pollInterval
+func NewAllowanceService(transactor repository.Transactor, allowanceRepo repository.AllowanceRepository,
+	accountRepo repository.AccountRepository, familyRepo repository.FamilyRepository,
+	pollInterval time.Duration) AllowanceService {
+	return &allowanceService{
+		transactor:    transactor,
+		allowanceRepo: allowanceRepo,
+		accountRepo:   accountRepo,
+		familyRepo:    familyRepo,
+		pollInterval:  pollInterval,
+	}
+}
+
+func (s *allowanceService) Create(user *models.User, input AllowanceInput) (*models.Allowance, error) {
+	from, member, err := postableAccount(s.accountRepo, s.familyRepo, user, input.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrAllowanceForbidden
+	}
+	child, err := s.familyRepo.GetMember(from.FamilyID, input.ChildID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if child == nil || child.Role != models.RoleChild {
+		return nil, ErrAllowanceNotChild
+	}
+	if input.ToAccountID == from.ID {
+		return nil, ErrAllowanceSameAccount
+	}
+	to, err := s.accountRepo.GetByID(input.ToAccountID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные счёта")
+	}
+	if to == nil || to.FamilyID != from.FamilyID {
+		return nil, ErrAccountNotFound
+	}
+	if to.OwnerID == nil || *to.OwnerID != child.UserID {
+		return nil, ErrAllowanceAccount
+	}
+	if to.Archived {
+		return nil, ErrAccountArchived
+	}
+	if to.Currency != from.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	rule := input.Rule
+	if err := checkRecurrenceRule(&rule); err != nil {
+		return nil, err
+	}
+
+	allowance := &models.Allowance{
+		FamilyID:      from.FamilyID,
+		ChildID:       child.UserID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        input.Amount,
+		Currency:      from.Currency,
+		Rule:          rule,
+		NextDate:      nextOccurrence(rule, rule.StartDate),
+		CreatedBy:     member.UserID,
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Allowances.Create(allowance); err != nil {
+			return err
+		}
+		if to.Supervised {
+			return nil
+		}
+		to.Supervised = true
+		return repos.Accounts.Update(to)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось сохранить карманные деньги, попробуйте позже")
+	}
+	return allowance, nil
+}
+
+func (s *allowanceService) Get(user *models.User, id uint) (*models.Allowance, error) {
+	allowance, _, err := s.load(user, id)
+	return allowance, err
+}
+
+func (s *allowanceService) List(user *models.User, familyID uint) ([]models.Allowance, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var childID uint
+	if member.Role == models.RoleChild {
+		childID = member.UserID
+	}
+	list, err := s.allowanceRepo.ListByFamily(familyID, childID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список карманных денег")
+	}
+	if list == nil {
+		list = []models.Allowance{}
+	}
+	return list, nil
+}
+
+func (s *allowanceService) Update(user *models.User, id uint, changes AllowanceChanges) (*models.Allowance, error) {
+	allowance, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.FromAccountID != 0 && changes.FromAccountID != allowance.FromAccountID {
+		if changes.FromAccountID == allowance.ToAccountID {
+			return nil, ErrAllowanceSameAccount
+		}
+		from, _, err := postableAccount(s.accountRepo, s.familyRepo, user, changes.FromAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if from.FamilyID != allowance.FamilyID {
+			return nil, ErrAccountFamilyMismatch
+		}
+		if from.Currency != allowance.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+		allowance.FromAccountID = from.ID
+	}
+	if changes.Amount != 0 {
+		allowance.Amount = changes.Amount
+	}
+	if changes.Rule != nil {
+		rule := *changes.Rule
+		if err := checkRecurrenceRule(&rule); err != nil {
+			return nil, err
+		}
+		today, err := familyToday(s.familyRepo, allowance.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		// Прошедшие даты по новому расписанию не выплачиваются
+		from := rule.StartDate
+		if today.After(from) {
+			from = today
+		}
+		allowance.Rule = rule
+		allowance.NextDate = nextOccurrence(rule, from)
+	}
+	if err := s.allowanceRepo.Update(allowance); err != nil {
+		return nil, errors.New("не удалось сохранить карманные деньги, попробуйте позже")
+	}
+	return allowance, nil
+}
+
+func (s *allowanceService) Pause(user *models.User, id uint, paused bool) (*models.Allowance, error) {
+	allowance, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Paused == paused {
+		return allowance, nil
+	}
+	if !paused && allowance.NextDate != nil {
+		today, err := familyToday(s.familyRepo, allowance.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if today.After(*allowance.NextDate) {
+			allowance.NextDate = nextOccurrence(allowance.Rule, today)
+		}
+	}
+	allowance.Paused = paused
+	if err := s.allowanceRepo.Update(allowance); err != nil {
+		return nil, errors.New("не удалось сохранить карманные деньги, попробуйте позже")
+	}
+	return allowance, nil
+}
+
+func (s *allowanceService) Delete(user *models.User, id uint) error {
+	allowance, err := s.editable(user, id)
+	if err != nil {
+		return err
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Allowances.Delete(allowance.ID); err != nil {
+			return err
+		}
+		remaining, err := repos.Allowances.CountByAccount(allowance.ToAccountID)
+		if err != nil || remaining > 0 {
+			return err
+		}
+		account, err := repos.Accounts.GetByID(allowance.ToAccountID)
+		if err != nil || account == nil || !account.Supervised {
+			return err
+		}
+		account.Supervised = false
+		return repos.Accounts.Update(account)
+	})
+	if err != nil {
+		return errors.New("не удалось удалить карманные деньги, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *allowanceService) AddBonus(user *models.User, allowanceID uint, input BonusInput) (*models.AllowanceBonus, error) {
+	allowance, err := s.editable(user, allowanceID)
+	if err != nil {
+		return nil, err
+	}
+	bonus := &models.AllowanceBonus{
+		AllowanceID: allowance.ID,
+		Chore:       input.Chore,
+		Amount:      input.Amount,
+		CreatedBy:   user.ID,
+	}
+	if err := s.allowanceRepo.CreateBonus(bonus); err != nil {
+		return nil, errors.New("не удалось сохранить премию, попробуйте позже")
+	}
+	return bonus, nil
+}
+
+func (s *allowanceService) DeleteBonus(user *models.User, bonusID uint) error {
+	bonus, err := s.allowanceRepo.GetBonus(bonusID)
+	if err != nil {
+		return errors.New("не удалось получить премию")
+	}
+	if bonus == nil {
+		return ErrBonusNotFound
+	}
+	if _, err := s.editable(user, bonus.AllowanceID); err != nil {
+		if err == ErrAllowanceNotFound {
+			return ErrBonusNotFound
+		}
+		return err
+	}
+	if bonus.TransactionID != nil {
+		return ErrBonusPaid
+	}
+	if err := s.allowanceRepo.DeleteBonus(bonus.ID); err != nil {
+		return errors.New("не удалось удалить премию, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *allowanceService) Bonuses(user *models.User, allowanceID uint) ([]models.AllowanceBonus, error) {
+	allowance, _, err := s.load(user, allowanceID)
+	if err != nil {
+		return nil, err
+	}
+	bonuses, err := s.allowanceRepo.ListBonuses(allowance.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список премий")
+	}
+	if bonuses == nil {
+		bonuses = []models.AllowanceBonus{}
+	}
+	return bonuses, nil
+}
+
+func (s *allowanceService) Payments(user *models.User, allowanceID uint, limit int) ([]models.AllowancePayment, error) {
+	allowance, _, err := s.load(user, allowanceID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.allowanceRepo.ListPayments(allowance.ID, limit)
+	if err != nil {
+		return nil, errors.New("не удалось получить список выплат")
+	}
+	if payments == nil {
+		payments = []models.AllowancePayment{}
+	}
+	return payments, nil
+}
+
+// editable получает карманные деньги, которые пользователь вправе изменять
+func (s *allowanceService) editable(user *models.User, id uint) (*models.Allowance, error) {
+	allowance, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrAllowanceForbidden
+	}
+	return allowance, nil
+}
+
+// load получает карманные деньги и членство пользователя в их семье
+// Карманные деньги чужой семьи или другого ребёнка неотличимы от несуществующих
+func (s *allowanceService) load(user *models.User, id uint) (*models.Allowance, *models.FamilyMember, error) {
+	allowance, err := s.allowanceRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить карманные деньги")
+	}
+	if allowance == nil {
+		return nil, nil, ErrAllowanceNotFound
+	}
+	member, err := requireMember(s.familyRepo, allowance.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrAllowanceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if member.Role == models.RoleChild && allowance.ChildID != member.UserID {
+		return nil, nil, ErrAllowanceNotFound
+	}
+	return allowance, member, nil
+}
+
+func (s *allowanceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Пока проход обрабатывает полную пачку, следующий запускается без ожидания
+		for ctx.Err() == nil && s.payDue() == recurringBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// payDue проводит наступившие выплаты одной пачки карманных денег
+// Возвращает количество карманных денег, по которым продвинулась дата следующей выплаты
+func (s *allowanceService) payDue() int {
+	// Точная дата проверяется по часовому поясу семьи, см. recurringService.postDue
+	until := dateOnly(time.Now().UTC()).AddDate(0, 0, 1)
+	ids, err := s.allowanceRepo.ListDue(until, recurringBatchSize)
+	if err != nil {
+		log.Printf("allowances: не удалось получить карманные деньги к выплате: %v", err)
+		return 0
+	}
+	advanced := 0
+	for _, id := range ids {
+		ok, err := s.payAllowance(id)
+		if err != nil {
+			log.Printf("allowances: не удалось выплатить карманные деньги %d: %v", id, err)
+			continue
+		}
+		if ok {
+			advanced++
+		}
+	}
+	return advanced
+}
+
+// payAllowance в одной транзакции проводит наступившие выплаты карманных денег вместе
+// с невыплаченными премиями и сдвигает дату следующей выплаты. Строка блокируется,
+// а выплата отмечается уникальной записью, поэтому она не проводится дважды
+func (s *allowanceService) payAllowance(id uint) (bool, error) {
+	advanced := false
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		advanced = false
+		allowance, err := repos.Allowances.LockByID(id)
+		if err != nil || allowance == nil || allowance.Paused || allowance.NextDate == nil {
+			return err
+		}
+		family, err := repos.Families.GetByID(allowance.FamilyID)
+		if err != nil || family == nil {
+			return errors.New("не удалось получить данные семьи")
+		}
+		today := familyDate(family)
+		if allowance.NextDate.After(today) {
+			return nil
+		}
+		advanced = true
+
+		from, err := repos.Accounts.GetByID(allowance.FromAccountID)
+		if err != nil {
+			return err
+		}
+		to, err := repos.Accounts.GetByID(allowance.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if from == nil || from.Archived || to == nil || to.Archived {
+			// По архивным счетам переводы не проводятся: приостанавливаем выплаты до решения родителей
+			log.Printf("allowances: счёт недоступен, карманные деньги %d приостановлены", allowance.ID)
+			allowance.Paused = true
+			return repos.Allowances.Update(allowance)
+		}
+
+		for n := 0; allowance.NextDate != nil && !allowance.NextDate.After(today) && n < maxCatchUpOccurrences; n++ {
+			date := dateOnly(*allowance.NextDate)
+			if err := payOccurrence(repos, allowance, family.Locale, date); err != nil {
+				return err
+			}
+			allowance.NextDate = nextOccurrence(allowance.Rule, date.AddDate(0, 0, 1))
+		}
+		return repos.Allowances.Update(allowance)
+	})
+	if err != nil {
+		return false, err
+	}
+	return advanced, nil
+}
+
+// payOccurrence проводит выплату карманных денег в дату date вместе с невыплаченными премиями
+// Вызывайте внутри Transactor.Transaction
+func payOccurrence(repos *repository.Repositories, allowance *models.Allowance, locale string, date time.Time) error {
+	bonuses, err := repos.Allowances.UnpaidBonuses(allowance.ID)
+	if err != nil {
+		return err
+	}
+	var bonus int64
+	ids := make([]uint, 0, len(bonuses))
+	for _, b := range bonuses {
+		bonus += b.Amount
+		ids = append(ids, b.ID)
+	}
+	payment := &models.AllowancePayment{
+		AllowanceID: allowance.ID,
+		Date:        date,
+		Amount:      allowance.Amount + bonus,
+		Bonus:       bonus,
+	}
+	created, err := repos.Allowances.CreatePayment(payment)
+	if err != nil || !created {
+		return err
+	}
+	payee, ok := allowancePayee[locale]
+	if !ok {
+		payee = allowancePayee["ru"]
+	}
+	tx := &models.Transaction{
+		FamilyID:         allowance.FamilyID,
+		AccountID:        allowance.FromAccountID,
+		Direction:        models.DirectionTransfer,
+		Amount:           payment.Amount,
+		Currency:         allowance.Currency,
+		CounterAccountID: &allowance.ToAccountID,
+		CounterAmount:    &payment.Amount,
+		CounterCurrency:  &allowance.Currency,
+		Date:             date,
+		Payee:            payee,
+		CreatedBy:        allowance.CreatedBy,
+	}
+	if err := postTransaction(repos, tx); err != nil {
+		return err
+	}
+	payment.TransactionID = &tx.ID
+	if err := repos.Allowances.UpdatePayment(payment); err != nil {
+		return err
+	}
+	return repos.Allowances.MarkBonusesPaid(ids, tx.ID)
+}
@@ -0,0 +1,592 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+// maxCategoryDepth ограничивает подъём по родительским категориям при проверке правила
+const maxCategoryDepth = 16
+
+var (
+	// ErrApprovalRuleNotFound возвращается, если правило не существует или недоступно пользователю
+	ErrApprovalRuleNotFound = newError(KindNotFound, "правило одобрения не найдено")
+
+	// ErrApprovalRuleEmpty возвращается, если у правила не задано ни одного условия
+	ErrApprovalRuleEmpty = newError(KindInvalid, "укажите хотя бы одно условие: сумму, категорию или роль")
+
+	// ErrApprovalRuleRole возвращается, если в правиле указана роль, которая не может проводить расходы
+	ErrApprovalRuleRole = newError(KindInvalid, "правило может относиться только к ролям owner, adult или child")
+
+	// ErrApprovalNotFound возвращается, если по операции нет запроса на одобрение или он недоступен пользователю
+	ErrApprovalNotFound = newError(KindNotFound, "запрос на одобрение не найден")
+
+	// ErrApprovalForbidden возвращается, если пользователь не может принять решение по расходу
+	ErrApprovalForbidden = newError(KindForbidden, "одобрять и отклонять расходы могут только владелец и взрослые")
+
+	// ErrApprovalSelf возвращается при попытке одобрить собственный расход
+	ErrApprovalSelf = newError(KindForbidden, "расход должен одобрить другой взрослый участник семьи")
+
+	// ErrApprovalDecided возвращается, если по расходу уже принято решение
+	ErrApprovalDecided = newError(KindConflict, "по расходу уже принято решение")
+)
+
+// ApprovalRuleInput условия правила одобрения; нулевые поля не проверяются
+type ApprovalRuleInput struct {
+	// MinAmount порог суммы в минимальных единицах валюты Currency
+	MinAmount int64
+	// Currency валюта порога; по умолчанию основная валюта семьи
+	Currency   string
+	CategoryID *uint
+	Role       models.FamilyRole
+}
+
+// ApprovalService определяет интерфейс для работы с одобрением расходов
+// Расход, подходящий под правило семьи, не проводится сразу: он ждёт решения
+// другого взрослого участника и до одобрения не влияет на остатки и бюджеты
+type ApprovalService interface {
+	// Rules возвращает правила семьи; доступно любому участнику семьи
+	Rules(user *models.User, familyID uint) ([]models.ApprovalRule, error)
+
+	// CreateRule добавляет правило; доступно владельцу семьи
+	CreateRule(user *models.User, familyID uint, input ApprovalRuleInput) (*models.ApprovalRule, error)
+
+	// UpdateRule заменяет условия правила; доступно владельцу семьи
+	// Расходы, уже ожидающие решения, не пересматриваются
+	UpdateRule(user *models.User, id uint, input ApprovalRuleInput) (*models.ApprovalRule, error)
+
+	// DeleteRule удаляет правило; доступно владельцу семьи
+	// Расходы, уже ожидающие решения, не пересматриваются
+	DeleteRule(user *models.User, id uint) error
+
+	// List возвращает запросы на одобрение семьи вместе с расходами, начиная с последних, не больше limit;
+	// владелец и взрослые видят все запросы, остальные — только свои. Пустой status не фильтрует
+	List(user *models.User, familyID uint, status models.ApprovalStatus, limit int) ([]models.Approval, error)
+
+	// Approve одобряет расход и проводит его; автор запроса не может одобрить его сам
+	Approve(user *models.User, transactionID uint, note string) (*models.Approval, error)
+
+	// Reject отклоняет расход; проводок у него не появляется
+	Reject(user *models.User, transactionID uint, note string) (*models.Approval, error)
+
+	// Match возвращает первое правило семьи, под которое подходит расход tx участника requester
+	// Возвращает nil, если правил нет, операция не расход или одобрить её некому
+	Match(tx *models.Transaction, requester uint) (*models.ApprovalRule, error)
+
+	// NotifyApprovers уведомляет владельца и взрослых, кроме автора запроса, о расходе,
+	// ожидающем одобрения. Ошибки доставки только записываются в журнал
+	NotifyApprovers(tx *models.Transaction, requester uint)
+}
+
+// approvalService реализует интерфейс ApprovalService
+type approvalService struct {
+	transactor      repository.Transactor
+	approvalRepo    repository.ApprovalRepository
+	txRepo          repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+	categoryRepo    repository.CategoryRepository
+	familyRepo      repository.FamilyRepository
+	rates           ExchangeRateService
+	alerts          BudgetAlertService
+	notificationSvc NotificationService
+	emailSvc        EmailService
+}
+
+// NewApprovalService создает новый экземпляр ApprovalService
+// rates пересчитывает расходы в валюту порога правила; после проведения
+// одобренного расхода alerts проверяет пороги бюджетов
+func NewApprovalService(transactor repository.Transactor, approvalRepo repository.ApprovalRepository,
+	txRepo repository.TransactionRepository, accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository, familyRepo repository.FamilyRepository,
+	rates ExchangeRateService, alerts BudgetAlertService,
+	notificationSvc NotificationService, emailSvc EmailService) ApprovalService {
+	return &approvalService{
+		transactor:      transactor,
+		approvalRepo:    approvalRepo,
+		txRepo:          txRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		familyRepo:      familyRepo,
+		rates:           rates,
+		alerts:          alerts,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+	}
+}
+
+func (s *approvalService) Rules(user *models.User, familyID uint) ([]models.ApprovalRule, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	rules, err := s.approvalRepo.ListRules(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить правила одобрения")
+	}
+	if rules == nil {
+		rules = []models.ApprovalRule{}
+	}
+	return rules, nil
+}
+
+func (s *approvalService) CreateRule(user *models.User, familyID uint, input ApprovalRuleInput) (*models.ApprovalRule, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	rule := &models.ApprovalRule{FamilyID: familyID, CreatedBy: user.ID}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.approvalRepo.CreateRule(rule); err != nil {
+		return nil, errors.New("не удалось сохранить правило, попробуйте позже")
+	}
+	return rule, nil
+}
+
+func (s *approvalService) UpdateRule(user *models.User, id uint, input ApprovalRuleInput) (*models.ApprovalRule, error) {
+	rule, err := s.loadRule(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.approvalRepo.UpdateRule(rule); err != nil {
+		return nil, errors.New("не удалось сохранить правило, попробуйте позже")
+	}
+	return rule, nil
+}
+
+func (s *approvalService) DeleteRule(user *models.User, id uint) error {
+	if _, err := s.loadRule(user, id); err != nil {
+		return err
+	}
+	if err := s.approvalRepo.DeleteRule(id); err != nil {
+		return errors.New("не удалось удалить правило, попробуйте позже")
+	}
+	return nil
+}
+
+// loadRule получает правило, которое пользователь вправе изменять
+// Правило чужой семьи неотличимо от несуществующего
+func (s *approvalService) loadRule(user *models.User, id uint) (*models.ApprovalRule, error) {
+	rule, err := s.approvalRepo.GetRule(id)
+	if err != nil {
+		return nil, errors.New("не удалось получить правило одобрения")
+	}
+	if rule == nil {
+		return nil, ErrApprovalRuleNotFound
+	}
+	_, err = requireMember(s.familyRepo, rule.FamilyID, user.ID, models.RoleOwner)
+	if err == ErrFamilyNotFound {
+		return nil, ErrApprovalRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// apply проверяет условия и записывает их в правило
+func (s *approvalService) apply(rule *models.ApprovalRule, input ApprovalRuleInput) error {
+	if input.MinAmount == 0 && input.CategoryID == nil && input.Role == "" {
+		return ErrApprovalRuleEmpty
+	}
+	if input.Role != "" && (!input.Role.Valid() || input.Role == models.RoleViewer) {
+		return ErrApprovalRuleRole
+	}
+	if input.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, rule.FamilyID, *input.CategoryID, models.DirectionExpense, false); err != nil {
+			return err
+		}
+	}
+	currency := input.Currency
+	if currency == "" {
+		family, err := s.familyRepo.GetByID(rule.FamilyID)
+		if err != nil || family == nil {
+			return errors.New("не удалось получить данные семьи")
+		}
+		currency = family.Currency
+	}
+	rule.MinAmount = input.MinAmount
+	rule.Currency = currency
+	rule.CategoryID = input.CategoryID
+	rule.Role = input.Role
+	return nil
+}
+
+func (s *approvalService) List(user *models.User, familyID uint, status models.ApprovalStatus, limit int) ([]models.Approval, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	query := repository.ApprovalQuery{FamilyID: familyID, Status: status, Limit: limit}
+	if !member.Role.CanManage() {
+		query.RequestedBy = member.UserID
+	}
+	list, err := s.approvalRepo.List(query)
+	if err != nil {
+		return nil, errors.New("не удалось получить список запросов на одобрение")
+	}
+	if list == nil {
+		list = []models.Approval{}
+	}
+	return list, nil
+}
+
+func (s *approvalService) Approve(user *models.User, transactionID uint, note string) (*models.Approval, error) {
+	return s.decide(user, transactionID, models.ApprovalApproved, note)
+}
+
+func (s *approvalService) Reject(user *models.User, transactionID uint, note string) (*models.Approval, error) {
+	return s.decide(user, transactionID, models.ApprovalRejected, note)
+}
+
+// decide записывает решение по расходу: одобренный расход проводится, отклонённый остаётся без проводок
+func (s *approvalService) decide(user *models.User, transactionID uint, status models.ApprovalStatus, note string) (*models.Approval, error) {
+	tx, err := s.txRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("не удалось получить операцию")
+	}
+	if tx == nil {
+		return nil, ErrApprovalNotFound
+	}
+	member, err := requireMember(s.familyRepo, tx.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, ErrApprovalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrApprovalForbidden
+	}
+
+	var approval *models.Approval
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		// Блокировка не даёт двум взрослым одновременно принять разные решения
+		approval, err = repos.Approvals.LockByTransaction(transactionID)
+		if err != nil {
+			return err
+		}
+		if approval == nil {
+			return ErrApprovalNotFound
+		}
+		if approval.RequestedBy == member.UserID {
+			return ErrApprovalSelf
+		}
+		if approval.Status != models.ApprovalPending {
+			return ErrApprovalDecided
+		}
+		// Операция могла измениться после первого чтения: берём её под блокировкой запроса
+		tx, err = repos.Transactions.GetByID(transactionID)
+		if err != nil {
+			return err
+		}
+		if tx == nil {
+			return ErrApprovalNotFound
+		}
+		if status == models.ApprovalApproved {
+			account, err := repos.Accounts.GetByID(tx.AccountID)
+			if err != nil {
+				return err
+			}
+			if account == nil {
+				return ErrAccountNotFound
+			}
+			if account.Archived {
+				return ErrAccountArchived
+			}
+			tx.Status = models.TransactionPosted
+		} else {
+			tx.Status = models.TransactionRejected
+		}
+		if err := repostTransaction(repos, tx); err != nil {
+			return err
+		}
+		now := time.Now()
+		approval.Status = status
+		approval.DecidedBy = &member.UserID
+		approval.DecidedAt = &now
+		approval.Note = note
+		return repos.Approvals.Save(approval)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось сохранить решение, попробуйте позже")
+	}
+	approval.Transaction = tx
+	if tx.Posted() {
+		s.alerts.CheckTransaction(tx)
+	}
+	s.notifyAuthor(user, approval)
+	return approval, nil
+}
+
+// requestApproval сохраняет запрос на одобрение расхода tx по правилу rule от имени requester
+// Прежнее решение по расходу, если оно было, сбрасывается
+// Вызывайте внутри Transactor.Transaction после сохранения операции
+func requestApproval(repos *repository.Repositories, tx *models.Transaction, rule *models.ApprovalRule, requester uint) error {
+	return repos.Approvals.Save(&models.Approval{
+		TransactionID: tx.ID,
+		FamilyID:      tx.FamilyID,
+		RuleID:        &rule.ID,
+		RequestedBy:   requester,
+		Status:        models.ApprovalPending,
+	})
+}
+
+func (s *approvalService) Match(tx *models.Transaction, requester uint) (*models.ApprovalRule, error) {
+	if tx.Direction != models.DirectionExpense {
+		return nil, nil
+	}
+	rules, err := s.approvalRepo.ListRules(tx.FamilyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить правила одобрения")
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	members, err := s.familyRepo.ListMembers(tx.FamilyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить участников семьи")
+	}
+	var role models.FamilyRole
+	approvers := 0
+	for _, m := range members {
+		if m.UserID == requester {
+			role = m.Role
+		} else if m.Role.CanManage() {
+			approvers++
+		}
+	}
+	// Правила не должны блокировать расходы семьи, в которой некому их одобрить
+	if approvers == 0 {
+		return nil, nil
+	}
+
+	var ancestors map[uint]bool
+	var table *RateTable
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Role != "" && rule.Role != role {
+			continue
+		}
+		if rule.CategoryID != nil {
+			if tx.CategoryID == nil {
+				continue
+			}
+			if ancestors == nil {
+				if ancestors, err = s.categoryChain(*tx.CategoryID); err != nil {
+					return nil, err
+				}
+			}
+			if !ancestors[*rule.CategoryID] {
+				continue
+			}
+		}
+		if rule.MinAmount > 0 {
+			amount := tx.Amount
+			if rule.Currency != tx.Currency {
+				if table == nil {
+					if table, err = s.rates.Table([]string{rule.Currency, tx.Currency}, tx.Date, tx.Date); err != nil {
+						return nil, err
+					}
+				}
+				converted, ok := table.Convert(tx.Amount, tx.Currency, rule.Currency, tx.Date)
+				if !ok {
+					// Без курса сравнить сумму с порогом нельзя: расход лучше показать взрослому
+					return rule, nil
+				}
+				amount = converted
+			}
+			if amount < rule.MinAmount {
+				continue
+			}
+		}
+		return rule, nil
+	}
+	return nil, nil
+}
+
+// categoryChain возвращает категорию вместе со всеми её родительскими категориями
+func (s *approvalService) categoryChain(categoryID uint) (map[uint]bool, error) {
+	chain := make(map[uint]bool)
+	id := &categoryID
+	for depth := 0; id != nil && !chain[*id] && depth < maxCategoryDepth; depth++ {
+		chain[*id] = true
+		category, err := s.categoryRepo.GetByID(*id)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные категории")
+		}
+		if category == nil {
+			break
+		}
+		id = category.ParentID
+	}
+	return chain, nil
+}
+
+func (s *approvalService) NotifyApprovers(tx *models.Transaction, requester uint) {
+	family, err := s.familyRepo.GetByID(tx.FamilyID)
+	if err != nil || family == nil {
+		log.Printf("approvals: не удалось получить семью %d: %v", tx.FamilyID, err)
+		return
+	}
+	members, err := s.familyRepo.ListMembers(tx.FamilyID)
+	if err != nil {
+		log.Printf("approvals: не удалось получить участников семьи %d: %v", tx.FamilyID, err)
+		return
+	}
+	data := mail.ApprovalRequestedData{
+		FamilyName: family.Name,
+		Amount:     tx.Amount,
+		Currency:   tx.Currency,
+		Date:       tx.Date,
+		Payee:      tx.Payee,
+		Note:       tx.Note,
+	}
+	for _, m := range members {
+		if m.UserID == requester && m.User != nil {
+			data.AuthorName = m.User.Name
+		}
+	}
+	if account, err := s.accountRepo.GetByID(tx.AccountID); err == nil && account != nil {
+		data.AccountName = account.Name
+	}
+	if tx.CategoryID != nil {
+		if category, err := s.categoryRepo.GetByID(*tx.CategoryID); err == nil && category != nil {
+			data.CategoryName = category.Name
+		}
+	}
+
+	title, body := approvalRequestedText(family.Locale, data)
+	for i := range members {
+		m := &members[i]
+		if m.User == nil || m.UserID == requester || !m.Role.CanManage() {
+			continue
+		}
+		familyID, txID := family.ID, tx.ID
+		notification := &models.Notification{
+			FamilyID:   &familyID,
+			Type:       models.NotificationApprovalRequested,
+			Title:      title,
+			Body:       body,
+			ObjectType: "transaction",
+			ObjectID:   &txID,
+		}
+		err := s.notificationSvc.Deliver(m.User, notification, func(to string) error {
+			return s.emailSvc.SendApprovalRequested(to, data)
+		})
+		if err != nil {
+			log.Printf("approvals: не удалось уведомить пользователя %d об операции %d: %v", m.UserID, tx.ID, err)
+		}
+	}
+}
+
+// notifyAuthor уведомляет автора запроса о решении по расходу
+// Ошибки доставки только записываются в журнал
+func (s *approvalService) notifyAuthor(decider *models.User, approval *models.Approval) {
+	tx := approval.Transaction
+	family, err := s.familyRepo.GetByID(tx.FamilyID)
+	if err != nil || family == nil {
+		log.Printf("approvals: не удалось получить семью %d: %v", tx.FamilyID, err)
+		return
+	}
+	members, err := s.familyRepo.ListMembers(tx.FamilyID)
+	if err != nil {
+		log.Printf("approvals: не удалось получить участников семьи %d: %v", tx.FamilyID, err)
+		return
+	}
+	var member *models.FamilyMember
+	for i := range members {
+		if members[i].UserID == approval.RequestedBy && members[i].User != nil {
+			member = &members[i]
+		}
+	}
+	// Автор мог покинуть семью, пока расход ждал решения
+	if member == nil {
+		return
+	}
+	data := mail.ApprovalDecidedData{
+		FamilyName:  family.Name,
+		DeciderName: decider.Name,
+		Approved:    approval.Status == models.ApprovalApproved,
+		Amount:      tx.Amount,
+		Currency:    tx.Currency,
+		Date:        tx.Date,
+		Payee:       tx.Payee,
+		Note:        approval.Note,
+	}
+	title, body := approvalDecidedText(family.Locale, data)
+	familyID, txID := family.ID, tx.ID
+	notification := &models.Notification{
+		FamilyID:   &familyID,
+		Type:       models.NotificationApprovalDecided,
+		Title:      title,
+		Body:       body,
+		ObjectType: "transaction",
+		ObjectID:   &txID,
+	}
+	err = s.notificationSvc.Deliver(member.User, notification, func(to string) error {
+		return s.emailSvc.SendApprovalDecided(to, data)
+	})
+	if err != nil {
+		log.Printf("approvals: не удалось уведомить пользователя %d о решении по операции %d: %v", member.UserID, tx.ID, err)
+	}
+}
+
+// approvalRequestedText формирует заголовок и текст уведомления о расходе, ожидающем одобрения
+func approvalRequestedText(locale string, data mail.ApprovalRequestedData) (string, string) {
+	amount := money.Display(data.Amount, data.Currency, locale)
+	if locale == "en" {
+		title := fmt.Sprintf("%s's expense of %s awaits approval", data.AuthorName, amount)
+		body := fmt.Sprintf("Account \"%s\", %s.", data.AccountName, data.Date.Format("2006-01-02"))
+		if data.Payee != "" {
+			body = fmt.Sprintf("%s, account \"%s\", %s.", data.Payee, data.AccountName, data.Date.Format("2006-01-02"))
+		}
+		return title, body
+	}
+	title := fmt.Sprintf("Расход %s (%s) ждёт одобрения", amount, data.AuthorName)
+	body := fmt.Sprintf("Счёт «%s», %s.", data.AccountName, data.Date.Format("02.01.2006"))
+	if data.Payee != "" {
+		body = fmt.Sprintf("%s, счёт «%s», %s.", data.Payee, data.AccountName, data.Date.Format("02.01.2006"))
+	}
+	return title, body
+}
+
+// approvalDecidedText формирует заголовок и текст уведомления о решении по расходу
+func approvalDecidedText(locale string, data mail.ApprovalDecidedData) (string, string) {
+	amount := money.Display(data.Amount, data.Currency, locale)
+	if locale == "en" {
+		title := fmt.Sprintf("%s rejected your expense of %s", data.DeciderName, amount)
+		body := "The expense does not affect the account balance."
+		if data.Approved {
+			title = fmt.Sprintf("%s approved your expense of %s", data.DeciderName, amount)
+			body = "The expense is posted."
+		}
+		if data.Note != "" {
+			body += " " + data.Note
+		}
+		return title, body
+	}
+	title := fmt.Sprintf("%s отклонил(а) ваш расход %s", data.DeciderName, amount)
+	body := "Расход не влияет на остаток счёта."
+	if data.Approved {
+		title = fmt.Sprintf("%s одобрил(а) ваш расход %s", data.DeciderName, amount)
+		body = "Расход проведён."
+	}
+	if data.Note != "" {
+		body += " " + data.Note
+	}
+	return title, body
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// memStore хранит в памяти данные, которые нужны сервисам операций и одобрений
+// Фейковые репозитории встраивают интерфейс, поэтому вызов нереализованного метода
+// завершает тест паникой
+type memStore struct {
+	family    models.Family
+	members   []models.FamilyMember
+	accounts  map[uint]*models.Account
+	txs       map[uint]*models.Transaction
+	postings  map[uint][]models.Posting
+	rules     []models.ApprovalRule
+	approvals map[uint]*models.Approval
+	nextID    uint
+}
+
+func (m *memStore) id() uint {
+	m.nextID++
+	return m.nextID
+}
+
+// balance возвращает сумму проводок по счёту
+func (m *memStore) balance(accountID uint) int64 {
+	var sum int64
+	for _, postings := range m.postings {
+		for _, p := range postings {
+			if p.AccountID != nil && *p.AccountID == accountID {
+				sum += p.Amount
+			}
+		}
+	}
+	return sum
+}
+
+type memTransactor struct{ repos *repository.Repositories }
+
+func (t memTransactor) Transaction(fn func(repos *repository.Repositories) error) error {
+	return fn(t.repos)
+}
+
+type memTransactions struct {
+	repository.TransactionRepository
+	m *memStore
+}
+
+func (r memTransactions) Create(tx *models.Transaction) error {
+	tx.ID = r.m.id()
+	saved := *tx
+	r.m.txs[tx.ID] = &saved
+	return nil
+}
+
+func (r memTransactions) GetByID(id uint) (*models.Transaction, error) {
+	tx, ok := r.m.txs[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *tx
+	return &copied, nil
+}
+
+func (r memTransactions) Update(tx *models.Transaction) error {
+	saved := *tx
+	r.m.txs[tx.ID] = &saved
+	return nil
+}
+
+type memLedger struct {
+	repository.LedgerRepository
+	m *memStore
+}
+
+func (r memLedger) ReplacePostings(transactionID uint, postings []models.Posting) error {
+	delete(r.m.postings, transactionID)
+	if len(postings) > 0 {
+		r.m.postings[transactionID] = postings
+	}
+	return nil
+}
+
+type memAccounts struct {
+	repository.AccountRepository
+	m *memStore
+}
+
+func (r memAccounts) GetByID(id uint) (*models.Account, error) {
+	account, ok := r.m.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *account
+	return &copied, nil
+}
+
+type memCategories struct {
+	repository.CategoryRepository
+}
+
+func (memCategories) GetByID(uint) (*models.Category, error) {
+	return nil, nil
+}
+
+type memFamilies struct {
+	repository.FamilyRepository
+	m *memStore
+}
+
+func (r memFamilies) GetByID(id uint) (*models.Family, error) {
+	if id != r.m.family.ID {
+		return nil, nil
+	}
+	family := r.m.family
+	return &family, nil
+}
+
+func (r memFamilies) GetMember(familyID, userID uint) (*models.FamilyMember, error) {
+	for _, member := range r.m.members {
+		if member.FamilyID == familyID && member.UserID == userID {
+			return &member, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memFamilies) ListMembers(familyID uint) ([]models.FamilyMember, error) {
+	var members []models.FamilyMember
+	for _, member := range r.m.members {
+		if member.FamilyID == familyID {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+type memApprovals struct {
+	repository.ApprovalRepository
+	m *memStore
+}
+
+func (r memApprovals) ListRules(familyID uint) ([]models.ApprovalRule, error) {
+	return r.m.rules, nil
+}
+
+func (r memApprovals) LockByTransaction(transactionID uint) (*models.Approval, error) {
+	approval, ok := r.m.approvals[transactionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *approval
+	return &copied, nil
+}
+
+func (r memApprovals) Save(approval *models.Approval) error {
+	if approval.ID == 0 {
+		approval.ID = r.m.id()
+	}
+	saved := *approval
+	r.m.approvals[approval.TransactionID] = &saved
+	return nil
+}
+
+func (r memApprovals) DeletePending(transactionID uint) error {
+	if approval, ok := r.m.approvals[transactionID]; ok && approval.Status == models.ApprovalPending {
+		delete(r.m.approvals, transactionID)
+	}
+	return nil
+}
+
+type memSplits struct {
+	repository.SplitRepository
+}
+
+func (memSplits) Current(uint) (*models.Split, error) {
+	return nil, nil
+}
+
+type noLimits struct{ SpendingLimitService }
+
+func (noLimits) Enforce(*repository.Repositories, *models.Transaction) error { return nil }
+
+type noAlerts struct{}
+
+func (noAlerts) CheckTransaction(*models.Transaction) {}
+
+type noNotifications struct{ NotificationService }
+
+func (noNotifications) Deliver(*models.User, *models.Notification, func(to string) error) error {
+	return nil
+}
+
+// approvalFixture семья из владельца и ребёнка с рублёвым счётом ребёнка и правилом:
+// расходы от 1000 ₽ ждут одобрения
+type approvalFixture struct {
+	store     *memStore
+	owner     *models.User
+	child     *models.User
+	accountID uint
+	txs       TransactionService
+	approvals ApprovalService
+}
+
+func newApprovalFixture() *approvalFixture {
+	m := &memStore{
+		family:    models.Family{ID: 1, Name: "Ивановы", Currency: "RUB", Timezone: "UTC", Locale: "ru"},
+		accounts:  map[uint]*models.Account{},
+		txs:       map[uint]*models.Transaction{},
+		postings:  map[uint][]models.Posting{},
+		approvals: map[uint]*models.Approval{},
+		nextID:    100,
+	}
+	owner := &models.User{ID: 1, Name: "Мама"}
+	child := &models.User{ID: 2, Name: "Петя"}
+	m.members = []models.FamilyMember{
+		{FamilyID: 1, UserID: owner.ID, Role: models.RoleOwner, User: owner},
+		{FamilyID: 1, UserID: child.ID, Role: models.RoleChild, User: child},
+	}
+	childID := child.ID
+	m.accounts[10] = &models.Account{ID: 10, FamilyID: 1, Name: "Карта Пети", Currency: "RUB", OwnerID: &childID}
+	m.rules = []models.ApprovalRule{{ID: 1, FamilyID: 1, MinAmount: 100000, Currency: "RUB"}}
+
+	repos := &repository.Repositories{
+		Transactions: memTransactions{m: m},
+		Ledger:       memLedger{m: m},
+		Accounts:     memAccounts{m: m},
+		Categories:   memCategories{},
+		Families:     memFamilies{m: m},
+		Approvals:    memApprovals{m: m},
+		Splits:       memSplits{},
+	}
+	transactor := memTransactor{repos: repos}
+	approvals := NewApprovalService(transactor, repos.Approvals, repos.Transactions, repos.Accounts,
+		repos.Categories, repos.Families, nil, noAlerts{}, noNotifications{}, nil)
+	txs := NewTransactionService(transactor, repos.Transactions, repos.Accounts, repos.Categories,
+		repos.Families, noAlerts{}, approvals, noLimits{})
+	return &approvalFixture{store: m, owner: owner, child: child, accountID: 10, txs: txs, approvals: approvals}
+}
+
+// createExpense записывает расход ребёнка на сумму amount копеек
+func (f *approvalFixture) createExpense(t *testing.T, amount int64) *models.Transaction {
+	t.Helper()
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tx, err := f.txs.Create(f.child, TransactionInput{
+		AccountID: f.accountID,
+		Direction: models.DirectionExpense,
+		Amount:    amount,
+		Date:      &date,
+		Payee:     "Магазин игрушек",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return tx
+}
+
+func TestCreateExpensePendingApproval(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 150000)
+
+	if tx.Status != models.TransactionPending {
+		t.Fatalf("status = %q, want %q", tx.Status, models.TransactionPending)
+	}
+	if len(f.store.postings[tx.ID]) != 0 {
+		t.Errorf("pending expense has %d postings, want none", len(f.store.postings[tx.ID]))
+	}
+	if got := f.store.balance(f.accountID); got != 0 {
+		t.Errorf("balance = %d, want 0", got)
+	}
+	approval := f.store.approvals[tx.ID]
+	if approval == nil || approval.Status != models.ApprovalPending || approval.RequestedBy != f.child.ID {
+		t.Fatalf("approval = %+v, want pending request by child", approval)
+	}
+}
+
+func TestCreateExpenseBelowThresholdPosted(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 50000)
+
+	if tx.Status != models.TransactionPosted {
+		t.Fatalf("status = %q, want %q", tx.Status, models.TransactionPosted)
+	}
+	if got := f.store.balance(f.accountID); got != -50000 {
+		t.Errorf("balance = %d, want -50000", got)
+	}
+	if approval := f.store.approvals[tx.ID]; approval != nil {
+		t.Errorf("approval = %+v, want none", approval)
+	}
+}
+
+func TestApproveExpensePosts(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 150000)
+	if got := f.store.balance(f.accountID); got != 0 {
+		t.Fatalf("balance before approval = %d, want 0", got)
+	}
+
+	approval, err := f.approvals.Approve(f.owner, tx.ID, "хорошо")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if approval.Status != models.ApprovalApproved || approval.DecidedBy == nil || *approval.DecidedBy != f.owner.ID {
+		t.Errorf("approval = %+v, want approved by owner", approval)
+	}
+	if status := f.store.txs[tx.ID].Status; status != models.TransactionPosted {
+		t.Errorf("status = %q, want %q", status, models.TransactionPosted)
+	}
+	if got := f.store.balance(f.accountID); got != -150000 {
+		t.Errorf("balance after approval = %d, want -150000", got)
+	}
+
+	if _, err := f.approvals.Reject(f.owner, tx.ID, ""); err != ErrApprovalDecided {
+		t.Errorf("second decision error = %v, want %v", err, ErrApprovalDecided)
+	}
+}
+
+func TestRejectExpenseKeepsBalance(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 150000)
+
+	approval, err := f.approvals.Reject(f.owner, tx.ID, "слишком дорого")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if approval.Status != models.ApprovalRejected {
+		t.Errorf("approval status = %q, want %q", approval.Status, models.ApprovalRejected)
+	}
+	if status := f.store.txs[tx.ID].Status; status != models.TransactionRejected {
+		t.Errorf("status = %q, want %q", status, models.TransactionRejected)
+	}
+	if len(f.store.postings[tx.ID]) != 0 {
+		t.Errorf("rejected expense has %d postings, want none", len(f.store.postings[tx.ID]))
+	}
+	if got := f.store.balance(f.accountID); got != 0 {
+		t.Errorf("balance after rejection = %d, want 0", got)
+	}
+}
+
+func TestApproveOwnExpenseForbidden(t *testing.T) {
+	f := newApprovalFixture()
+	f.store.members[1].Role = models.RoleAdult
+	tx := f.createExpense(t, 150000)
+
+	if _, err := f.approvals.Approve(f.child, tx.ID, ""); err != ErrApprovalSelf {
+		t.Errorf("Approve error = %v, want %v", err, ErrApprovalSelf)
+	}
+	if got := f.store.balance(f.accountID); got != 0 {
+		t.Errorf("balance = %d, want 0", got)
+	}
+}
+
+func TestUpdateIntoPendingRemovesPostings(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 50000)
+
+	updated, err := f.txs.Update(f.child, tx.ID, TransactionChanges{Amount: 200000})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Status != models.TransactionPending {
+		t.Errorf("status = %q, want %q", updated.Status, models.TransactionPending)
+	}
+	if got := f.store.balance(f.accountID); got != 0 {
+		t.Errorf("balance = %d, want 0", got)
+	}
+	if approval := f.store.approvals[tx.ID]; approval == nil || approval.Status != models.ApprovalPending {
+		t.Errorf("approval = %+v, want pending", approval)
+	}
+}
+
+func TestUpdatePendingToIncomeClosesApproval(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 150000)
+
+	updated, err := f.txs.Update(f.child, tx.ID, TransactionChanges{Direction: models.DirectionIncome})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Status != models.TransactionPosted {
+		t.Errorf("status = %q, want %q", updated.Status, models.TransactionPosted)
+	}
+	if approval := f.store.approvals[tx.ID]; approval != nil {
+		t.Errorf("approval = %+v, want none", approval)
+	}
+	if got := f.store.balance(f.accountID); got != 150000 {
+		t.Errorf("balance = %d, want 150000", got)
+	}
+}
+
+func TestUpdatePendingBelowThresholdClosesApproval(t *testing.T) {
+	f := newApprovalFixture()
+	tx := f.createExpense(t, 150000)
+
+	updated, err := f.txs.Update(f.child, tx.ID, TransactionChanges{Amount: 90000})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Status != models.TransactionPosted {
+		t.Errorf("status = %q, want %q", updated.Status, models.TransactionPosted)
+	}
+	if approval := f.store.approvals[tx.ID]; approval != nil {
+		t.Errorf("approval = %+v, want none", approval)
+	}
+	if got := f.store.balance(f.accountID); got != -90000 {
+		t.Errorf("balance = %d, want -90000", got)
+	}
+	if _, err := f.approvals.Approve(f.owner, tx.ID, ""); err != ErrApprovalNotFound {
+		t.Errorf("Approve error = %v, want %v", err, ErrApprovalNotFound)
+	}
+}
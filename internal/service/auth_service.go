@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
 	"family_finance_back/internal/models"
@@ -14,6 +15,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// codeTTL время жизни кода подтверждения
+const codeTTL = 90 * time.Second
+
+// ErrCodeEmailNotQueued возвращается, если письмо с кодом не удалось поставить в очередь отправки
+var ErrCodeEmailNotQueued = errors.New("не удалось поставить письмо с кодом в очередь отправки, повторите попытку позже")
+
 // AuthService определяет интерфейс для работы с авторизацией пользователей
 type AuthService interface {
 	// RequestLoginCode отправляет код подтверждения на email для входа
@@ -41,26 +48,36 @@ type AuthService interface {
 type authService struct {
 	userRepo    repository.UserRepository
 	emailSvc    EmailService
+	inviteSvc   InviteService
 	redisClient *redis.Client
 	jwtSecret   string
+	normalizer  util.EmailNormalizer
 	ctx         context.Context
 }
 
 // NewAuthService создает новый экземпляр AuthService
-func NewAuthService(userRepo repository.UserRepository, emailSvc EmailService, redisClient *redis.Client, jwtSecret string) AuthService {
+func NewAuthService(userRepo repository.UserRepository, emailSvc EmailService, inviteSvc InviteService, redisClient *redis.Client, jwtSecret string, normalizer util.EmailNormalizer) AuthService {
 	return &authService{
 		userRepo:    userRepo,
 		emailSvc:    emailSvc,
+		inviteSvc:   inviteSvc,
 		redisClient: redisClient,
 		jwtSecret:   jwtSecret,
+		normalizer:  normalizer,
 		ctx:         context.Background(),
 	}
 }
 
 // RequestLoginCode отправляет код, если почта существует
+// Код отправляется на адрес, указанный при регистрации, а не на введённый
 func (s *authService) RequestLoginCode(email string) (string, error) {
+	key, err := s.normalizer.Normalize(email)
+	if err != nil {
+		return "", err
+	}
+
 	// Проверяем, существует ли пользователь
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmailKey(key)
 	if err != nil {
 		return "", errors.New("не удалось получить данные пользователя, попробуйте позже")
 	}
@@ -72,7 +89,7 @@ func (s *authService) RequestLoginCode(email string) (string, error) {
 	tempID := uuid.New().String()
 
 	data := map[string]string{
-		"email": email,
+		"email": user.Email,
 		"code":  code,
 	}
 	serialized, err := json.Marshal(data)
@@ -80,13 +97,14 @@ func (s *authService) RequestLoginCode(email string) (string, error) {
 		return "", errors.New("не удалось сформировать данные для отправки кода")
 	}
 
-	err = s.redisClient.Set(s.ctx, "login:"+tempID, serialized, 90*time.Second).Err()
+	err = s.redisClient.Set(s.ctx, "login:"+tempID, serialized, codeTTL).Err()
 	if err != nil {
 		return "", errors.New("не удалось сохранить данные авторизации, повторите попытку позже")
 	}
 
-	if err = s.emailSvc.SendCode(email, code); err != nil {
-		return "", errors.New("не удалось отправить письмо с кодом, пожалуйста, проверьте адрес электронной почты")
+	if err = s.emailSvc.SendCode(user.Email, code); err != nil {
+		s.redisClient.Del(s.ctx, "login:"+tempID)
+		return "", ErrCodeEmailNotQueued
 	}
 
 	return tempID, nil
@@ -115,9 +133,20 @@ func (s *authService) VerifyLoginCode(tempID, code string) (string, error) {
 }
 
 // RequestRegistrationCode отправляет код для регистрации и сохраняет связь uuid -> email
+// Адрес сохраняется в том виде, в котором его ввёл пользователь (util.CanonicalEmail),
+// уникальность проверяется по ключу адреса
 func (s *authService) RequestRegistrationCode(email string) (string, error) {
+	email, err := util.CanonicalEmail(email)
+	if err != nil {
+		return "", err
+	}
+	key, err := s.normalizer.Normalize(email)
+	if err != nil {
+		return "", err
+	}
+
 	// Проверка существования пользователя
-	existingUser, err := s.userRepo.GetByEmail(email)
+	existingUser, err := s.userRepo.GetByEmailKey(key)
 	if err != nil {
 		return "", errors.New("не удалось проверить данные. Попробуйте позже")
 	}
@@ -129,23 +158,25 @@ func (s *authService) RequestRegistrationCode(email string) (string, error) {
 	tempID := uuid.New().String()
 
 	data := map[string]string{
-		"email": email,
-		"code":  code,
+		"email":     email,
+		"email_key": key,
+		"code":      code,
 	}
 	serialized, err := json.Marshal(data)
 	if err != nil {
 		return "", errors.New("не удалось сформировать данные для отправки кода")
 	}
 
-	// Сохраняем данные в Redis с TTL 90 секунд
-	err = s.redisClient.Set(s.ctx, "register:"+tempID, serialized, 90*time.Second).Err()
+	// Сохраняем данные в Redis на время жизни кода
+	err = s.redisClient.Set(s.ctx, "register:"+tempID, serialized, codeTTL).Err()
 	if err != nil {
 		return "", errors.New("не удалось сохранить данные регистрации, повторите попытку позже")
 	}
 
-	// Отправляем код на указанный email
+	// Ставим письмо с кодом в очередь отправки
 	if err = s.emailSvc.SendCode(email, code); err != nil {
-		return "", errors.New("не удалось отправить письмо с кодом, пожалуйста, проверьте адрес электронной почты")
+		s.redisClient.Del(s.ctx, "register:"+tempID)
+		return "", ErrCodeEmailNotQueued
 	}
 
 	return tempID, nil
@@ -173,10 +204,16 @@ func (s *authService) VerifyRegistrationCode(tempID, code, name, surname, nickna
 		Surname:  surname,
 		Nickname: nickname,
 		Email:    data["email"],
+		EmailKey: data["email_key"],
 	}
 	if err = s.userRepo.Create(newUser); err != nil {
 		return "", errors.New("не удалось создать пользователя, попробуйте позже")
 	}
+	// Приглашения, отправленные до регистрации, принимаются автоматически;
+	// ошибка не мешает регистрации — приглашения останутся в списке /invites
+	if _, err := s.inviteSvc.AcceptPendingForUser(newUser); err != nil {
+		log.Printf("auth: не удалось принять приглашения пользователя %d: %v", newUser.ID, err)
+	}
 	token, err := util.GenerateJWT(data["email"], s.jwtSecret)
 	if err != nil {
 		return "", errors.New("не удалось сгенерировать токен авторизации, повторите попытку позже")
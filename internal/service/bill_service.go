@@ -0,0 +1,736 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+const (
+	// billBatchSize количество счетов к оплате, которое проверяется за один проход напоминаний
+	billBatchSize = 100
+
+	// billOverdueDays за сколько прошлых дней список предстоящих счетов показывает неоплаченные сроки
+	billOverdueDays = 90
+)
+
+var (
+	// ErrBillNotFound возвращается, если счёт к оплате не существует или недоступен пользователю
+	ErrBillNotFound = newError(KindNotFound, "счёт к оплате не найден")
+
+	// ErrBillDueDateNotFound возвращается, если на указанную дату у счёта нет срока оплаты
+	ErrBillDueDateNotFound = newError(KindInvalid, "на эту дату нет срока оплаты счёта")
+
+	// ErrBillAlreadyPaid возвращается при повторной оплате срока
+	ErrBillAlreadyPaid = newError(KindConflict, "счёт за этот срок уже оплачен")
+
+	// ErrBillNotPaid возвращается при отмене оплаты неоплаченного срока
+	ErrBillNotPaid = newError(KindConflict, "счёт за этот срок не оплачен")
+
+	// ErrBillTransactionLinked возвращается, если операция уже оплачивает другой срок
+	ErrBillTransactionLinked = newError(KindConflict, "операция уже отмечена как оплата другого счёта")
+
+	// ErrBillTransactionInvalid возвращается, если операцией нельзя оплатить счёт
+	ErrBillTransactionInvalid = newError(KindInvalid, "оплатой счёта может быть только расход в валюте счёта")
+
+	// ErrBillAccountRequired возвращается, если для проведения оплаты не указан счёт
+	ErrBillAccountRequired = newError(KindInvalid, "укажите счёт, с которого проводится оплата")
+)
+
+// BillInput данные нового счёта к оплате
+type BillInput struct {
+	Name string
+	// Amount сумма в минимальных единицах валюты
+	Amount int64
+	// Currency валюта счёта к оплате; если не указана, берётся валюта счёта AccountID
+	Currency   string
+	AccountID  *uint
+	CategoryID *uint
+	// Rule сроки оплаты; пустой Frequency означает разовый счёт со сроком Rule.StartDate
+	Rule       models.RecurrenceRule
+	RemindDays int
+}
+
+// BillChanges изменения счёта к оплате; nil и нулевые поля не изменяются
+type BillChanges struct {
+	Name   *string
+	Amount int64
+	// AccountID новый счёт оплаты; указатель на 0 снимает счёт
+	AccountID *uint
+	// CategoryID новая категория; указатель на 0 снимает категорию
+	CategoryID *uint
+	Rule       *models.RecurrenceRule
+	RemindDays *int
+}
+
+// BillPayment оплата срока счёта
+// Если указан TransactionID, оплатой отмечается существующий расход; если Record — проводится
+// новый расход со счёта AccountID или счёта оплаты; иначе срок просто отмечается оплаченным
+type BillPayment struct {
+	DueDate       time.Time
+	TransactionID uint
+	Record        bool
+	AccountID     uint
+	// Amount сумма нового расхода; по умолчанию сумма счёта
+	Amount int64
+	// Date дата нового расхода; по умолчанию сегодня
+	Date *time.Time
+}
+
+// UpcomingBill срок оплаты счёта
+type UpcomingBill struct {
+	BillID  uint      `json:"bill_id"`
+	Name    string    `json:"name"`
+	DueDate time.Time `json:"due_date"`
+	// DaysLeft сколько дней осталось до срока; отрицательное значение — срок прошёл
+	DaysLeft int `json:"days_left"`
+	// Amount сумма в минимальных единицах валюты Currency
+	Amount        int64             `json:"amount"`
+	Currency      string            `json:"currency"`
+	AccountID     *uint             `json:"account_id"`
+	CategoryID    *uint             `json:"category_id"`
+	Status        models.BillStatus `json:"status"`
+	TransactionID *uint             `json:"transaction_id"`
+	PaidAt        *time.Time        `json:"paid_at"`
+}
+
+// BillService определяет интерфейс для работы со счетами к оплате
+type BillService interface {
+	// Create создает счёт к оплате; доступно владельцу и взрослым семьи
+	Create(user *models.User, familyID uint, input BillInput) (*models.Bill, error)
+
+	// Get возвращает счёт к оплате; доступно любому участнику семьи
+	Get(user *models.User, id uint) (*models.Bill, error)
+
+	// List возвращает счета к оплате семьи; доступно любому участнику семьи
+	List(user *models.User, familyID uint) ([]models.Bill, error)
+
+	// Update изменяет счёт к оплате; отметки об оплате прошедших сроков сохраняются
+	Update(user *models.User, id uint, changes BillChanges) (*models.Bill, error)
+
+	// Delete удаляет счёт к оплате; проведённые оплаты остаются в операциях
+	Delete(user *models.User, id uint) error
+
+	// Upcoming возвращает сроки оплаты счетов семьи на ближайшие days дней вместе
+	// с неоплаченными просроченными сроками; доступно любому участнику семьи
+	Upcoming(user *models.User, familyID uint, days int) ([]UpcomingBill, error)
+
+	// Pay отмечает срок счёта оплаченным
+	Pay(user *models.User, id uint, payment BillPayment) (*UpcomingBill, error)
+
+	// Unpay снимает отметку об оплате срока; связанная операция остаётся
+	Unpay(user *models.User, id uint, dueDate time.Time) (*UpcomingBill, error)
+
+	// Run запускает отправку напоминаний о сроках оплаты и блокируется до отмены ctx
+	Run(ctx context.Context)
+}
+
+// billService реализует интерфейс BillService
+type billService struct {
+	transactor      repository.Transactor
+	billRepo        repository.BillRepository
+	accountRepo     repository.AccountRepository
+	categoryRepo    repository.CategoryRepository
+	txRepo          repository.TransactionRepository
+	familyRepo      repository.FamilyRepository
+	notificationSvc NotificationService
+	emailSvc        EmailService
+	alerts          BudgetAlertService
+	pollInterval    time.Duration
+}
+
+// NewBillService создает новый экземпляр BillService
+// Напоминания о сроках оплаты проверяются каждые pollInterval
+func NewBillService(transactor repository.Transactor, billRepo repository.BillRepository,
+	accountRepo repository.AccountRepository, categoryRepo repository.CategoryRepository,
+	txRepo repository.TransactionRepository, familyRepo repository.FamilyRepository,
+	notificationSvc NotificationService, emailSvc EmailService, alerts BudgetAlertService,
+	pollInterval time.Duration) BillService {
+	return &billService{
+		transactor:      transactor,
+		billRepo:        billRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		txRepo:          txRepo,
+		familyRepo:      familyRepo,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+		alerts:          alerts,
+		pollInterval:    pollInterval,
+	}
+}
+
+func (s *billService) Create(user *models.User, familyID uint, input BillInput) (*models.Bill, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner, models.RoleAdult); err != nil {
+		return nil, err
+	}
+	bill := &models.Bill{
+		FamilyID:   familyID,
+		Name:       input.Name,
+		Amount:     input.Amount,
+		Currency:   input.Currency,
+		RemindDays: input.RemindDays,
+		Rule:       input.Rule,
+		CreatedBy:  user.ID,
+	}
+	if input.AccountID != nil {
+		if err := s.setAccount(bill, *input.AccountID); err != nil {
+			return nil, err
+		}
+	}
+	if input.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, familyID, *input.CategoryID, models.DirectionExpense, false); err != nil {
+			return nil, err
+		}
+		bill.CategoryID = input.CategoryID
+	}
+	if err := checkBillRule(&bill.Rule); err != nil {
+		return nil, err
+	}
+	if err := s.billRepo.Create(bill); err != nil {
+		return nil, errors.New("не удалось сохранить счёт к оплате, попробуйте позже")
+	}
+	return bill, nil
+}
+
+func (s *billService) Get(user *models.User, id uint) (*models.Bill, error) {
+	bill, _, err := s.load(user, id)
+	return bill, err
+}
+
+func (s *billService) List(user *models.User, familyID uint) ([]models.Bill, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	bills, err := s.billRepo.ListByFamily(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список счетов к оплате")
+	}
+	if bills == nil {
+		bills = []models.Bill{}
+	}
+	return bills, nil
+}
+
+func (s *billService) Update(user *models.User, id uint, changes BillChanges) (*models.Bill, error) {
+	bill, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Name != nil {
+		bill.Name = *changes.Name
+	}
+	if changes.Amount != 0 {
+		bill.Amount = changes.Amount
+	}
+	switch {
+	case changes.AccountID != nil && *changes.AccountID == 0:
+		bill.AccountID = nil
+	case changes.AccountID != nil:
+		if err := s.setAccount(bill, *changes.AccountID); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case changes.CategoryID != nil && *changes.CategoryID == 0:
+		bill.CategoryID = nil
+	case changes.CategoryID != nil:
+		if err := checkCategory(s.categoryRepo, bill.FamilyID, *changes.CategoryID, models.DirectionExpense, false); err != nil {
+			return nil, err
+		}
+		bill.CategoryID = changes.CategoryID
+	}
+	if changes.Rule != nil {
+		rule := *changes.Rule
+		if err := checkBillRule(&rule); err != nil {
+			return nil, err
+		}
+		bill.Rule = rule
+	}
+	if changes.RemindDays != nil {
+		bill.RemindDays = *changes.RemindDays
+	}
+	if err := s.billRepo.Update(bill); err != nil {
+		return nil, errors.New("не удалось сохранить счёт к оплате, попробуйте позже")
+	}
+	return bill, nil
+}
+
+func (s *billService) Delete(user *models.User, id uint) error {
+	bill, err := s.editable(user, id)
+	if err != nil {
+		return err
+	}
+	if err := s.billRepo.Delete(bill.ID); err != nil {
+		return errors.New("не удалось удалить счёт к оплате, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *billService) Upcoming(user *models.User, familyID uint, days int) ([]UpcomingBill, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	today, err := familyToday(s.familyRepo, familyID)
+	if err != nil {
+		return nil, err
+	}
+	bills, err := s.billRepo.ListByFamily(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список счетов к оплате")
+	}
+	from, to := today.AddDate(0, 0, -billOverdueDays), today.AddDate(0, 0, days)
+	ids := make([]uint, len(bills))
+	for i := range bills {
+		ids[i] = bills[i].ID
+	}
+	instances, err := s.billRepo.ListInstances(ids, from, to)
+	if err != nil {
+		return nil, errors.New("не удалось получить оплаты счетов")
+	}
+	type key struct {
+		billID uint
+		date   time.Time
+	}
+	byDate := make(map[key]*models.BillInstance, len(instances))
+	for i := range instances {
+		byDate[key{instances[i].BillID, dateOnly(instances[i].DueDate)}] = &instances[i]
+	}
+
+	result := make([]UpcomingBill, 0)
+	for i := range bills {
+		bill := &bills[i]
+		for _, date := range billDueDates(bill, from, to) {
+			item := upcomingBill(bill, byDate[key{bill.ID, date}], date, today)
+			// Оплаченные прошедшие сроки в списке предстоящих не нужны
+			if date.Before(today) && item.Status == models.BillPaid {
+				continue
+			}
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DueDate.Before(result[j].DueDate)
+	})
+	return result, nil
+}
+
+func (s *billService) Pay(user *models.User, id uint, payment BillPayment) (*UpcomingBill, error) {
+	bill, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	date := dateOnly(payment.DueDate)
+	if !isBillDueDate(bill, date) {
+		return nil, ErrBillDueDateNotFound
+	}
+	instance, err := s.billRepo.GetInstance(bill.ID, date)
+	if err != nil {
+		return nil, errors.New("не удалось получить оплату счёта")
+	}
+	if instance != nil && instance.Status == models.BillPaid {
+		return nil, ErrBillAlreadyPaid
+	}
+	if instance == nil {
+		instance = &models.BillInstance{BillID: bill.ID, DueDate: date}
+	}
+	now := time.Now()
+	instance.Status = models.BillPaid
+	instance.PaidAt = &now
+
+	var posted *models.Transaction
+	switch {
+	case payment.TransactionID != 0:
+		if err := s.checkPayment(bill, payment.TransactionID); err != nil {
+			return nil, err
+		}
+		instance.TransactionID = &payment.TransactionID
+		err = s.billRepo.SaveInstance(instance)
+	case payment.Record:
+		if posted, err = s.paymentTransaction(user, bill, payment); err != nil {
+			return nil, err
+		}
+		err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+			if err := postTransaction(repos, posted); err != nil {
+				return err
+			}
+			instance.TransactionID = &posted.ID
+			return repos.Bills.SaveInstance(instance)
+		})
+	default:
+		instance.TransactionID = nil
+		err = s.billRepo.SaveInstance(instance)
+	}
+	if err != nil {
+		return nil, errors.New("не удалось отметить оплату счёта, попробуйте позже")
+	}
+	if posted != nil {
+		s.alerts.CheckTransaction(posted)
+	}
+	today, err := familyToday(s.familyRepo, bill.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	result := upcomingBill(bill, instance, date, today)
+	return &result, nil
+}
+
+func (s *billService) Unpay(user *models.User, id uint, dueDate time.Time) (*UpcomingBill, error) {
+	bill, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	date := dateOnly(dueDate)
+	instance, err := s.billRepo.GetInstance(bill.ID, date)
+	if err != nil {
+		return nil, errors.New("не удалось получить оплату счёта")
+	}
+	if instance == nil || instance.Status != models.BillPaid {
+		return nil, ErrBillNotPaid
+	}
+	instance.Status = models.BillUnpaid
+	instance.TransactionID = nil
+	instance.PaidAt = nil
+	if err := s.billRepo.SaveInstance(instance); err != nil {
+		return nil, errors.New("не удалось снять отметку об оплате, попробуйте позже")
+	}
+	today, err := familyToday(s.familyRepo, bill.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	result := upcomingBill(bill, instance, date, today)
+	return &result, nil
+}
+
+// checkPayment проверяет, что операцией можно оплатить счёт
+func (s *billService) checkPayment(bill *models.Bill, transactionID uint) error {
+	tx, err := s.txRepo.GetByID(transactionID)
+	if err != nil {
+		return errors.New("не удалось получить данные операции")
+	}
+	if tx == nil || tx.FamilyID != bill.FamilyID {
+		return ErrTransactionNotFound
+	}
+	if tx.Direction != models.DirectionExpense || tx.Currency != bill.Currency {
+		return ErrBillTransactionInvalid
+	}
+	linked, err := s.billRepo.FindInstanceByTransaction(tx.ID)
+	if err != nil {
+		return errors.New("не удалось получить оплату счёта")
+	}
+	if linked != nil {
+		return ErrBillTransactionLinked
+	}
+	return nil
+}
+
+// paymentTransaction готовит расход, которым оплачивается счёт
+func (s *billService) paymentTransaction(user *models.User, bill *models.Bill, payment BillPayment) (*models.Transaction, error) {
+	accountID := payment.AccountID
+	if accountID == 0 && bill.AccountID != nil {
+		accountID = *bill.AccountID
+	}
+	if accountID == 0 {
+		return nil, ErrBillAccountRequired
+	}
+	account, member, err := postableAccount(s.accountRepo, s.familyRepo, user, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.FamilyID != bill.FamilyID {
+		return nil, ErrAccountFamilyMismatch
+	}
+	if account.Currency != bill.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	if bill.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, bill.FamilyID, *bill.CategoryID, models.DirectionExpense, false); err != nil {
+			return nil, err
+		}
+	}
+	amount := payment.Amount
+	if amount == 0 {
+		amount = bill.Amount
+	}
+	var date time.Time
+	if payment.Date != nil {
+		date = dateOnly(*payment.Date)
+	} else if date, err = familyToday(s.familyRepo, bill.FamilyID); err != nil {
+		return nil, err
+	}
+	return &models.Transaction{
+		FamilyID:   bill.FamilyID,
+		AccountID:  account.ID,
+		Direction:  models.DirectionExpense,
+		Amount:     amount,
+		Currency:   account.Currency,
+		CategoryID: bill.CategoryID,
+		Date:       date,
+		Payee:      bill.Name,
+		CreatedBy:  member.UserID,
+	}, nil
+}
+
+// setAccount проверяет счёт семьи и назначает его счётом оплаты
+// Если валюта счёта к оплате не указана, она берётся из счёта, иначе должна с ним совпадать
+func (s *billService) setAccount(bill *models.Bill, accountID uint) error {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return errors.New("не удалось получить данные счёта")
+	}
+	if account == nil || account.FamilyID != bill.FamilyID {
+		return ErrAccountNotFound
+	}
+	if account.Archived {
+		return ErrAccountArchived
+	}
+	if bill.Currency == "" {
+		bill.Currency = account.Currency
+	} else if account.Currency != bill.Currency {
+		return ErrCurrencyMismatch
+	}
+	bill.AccountID = &account.ID
+	return nil
+}
+
+// editable получает счёт к оплате, который пользователь вправе изменять и оплачивать
+func (s *billService) editable(user *models.User, id uint) (*models.Bill, error) {
+	bill, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrFamilyForbidden
+	}
+	return bill, nil
+}
+
+// load получает счёт к оплате и членство пользователя в его семье
+// Счёт чужой семьи неотличим от несуществующего
+func (s *billService) load(user *models.User, id uint) (*models.Bill, *models.FamilyMember, error) {
+	bill, err := s.billRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить счёт к оплате")
+	}
+	if bill == nil {
+		return nil, nil, ErrBillNotFound
+	}
+	member, err := requireMember(s.familyRepo, bill.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrBillNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return bill, member, nil
+}
+
+// checkBillRule проверяет сроки оплаты; у разового счёта остаётся только дата срока
+func checkBillRule(rule *models.RecurrenceRule) error {
+	if rule.Frequency == "" {
+		*rule = models.RecurrenceRule{Interval: 1, StartDate: dateOnly(rule.StartDate)}
+		return nil
+	}
+	return checkRecurrenceRule(rule)
+}
+
+// billDueDates возвращает сроки оплаты счёта в периоде [from, to]
+func billDueDates(bill *models.Bill, from, to time.Time) []time.Time {
+	if !bill.Recurring() {
+		if bill.Rule.StartDate.Before(from) || bill.Rule.StartDate.After(to) {
+			return nil
+		}
+		return []time.Time{dateOnly(bill.Rule.StartDate)}
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	return occurrencesBetween(bill.Rule, from, to, days)
+}
+
+// isBillDueDate сообщает, приходится ли на дату срок оплаты счёта
+func isBillDueDate(bill *models.Bill, date time.Time) bool {
+	if !bill.Recurring() {
+		return dateOnly(bill.Rule.StartDate).Equal(date)
+	}
+	return isOccurrence(bill.Rule, date)
+}
+
+// upcomingBill описывает срок оплаты счёта; instance может быть nil, если по сроку ещё ничего не отмечено
+func upcomingBill(bill *models.Bill, instance *models.BillInstance, date, today time.Time) UpcomingBill {
+	item := UpcomingBill{
+		BillID:     bill.ID,
+		Name:       bill.Name,
+		DueDate:    date,
+		DaysLeft:   int(date.Sub(today).Hours() / 24),
+		Amount:     bill.Amount,
+		Currency:   bill.Currency,
+		AccountID:  bill.AccountID,
+		CategoryID: bill.CategoryID,
+		Status:     models.BillUnpaid,
+	}
+	if instance != nil && instance.Status == models.BillPaid {
+		item.Status = models.BillPaid
+		item.TransactionID = instance.TransactionID
+		item.PaidAt = instance.PaidAt
+	} else if date.Before(today) {
+		item.Status = models.BillOverdue
+	}
+	return item
+}
+
+func (s *billService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		s.remindDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// remindDue отправляет напоминания по срокам, до которых осталось не больше RemindDays дней
+// Отправка отмечается уникальной записью о сроке, поэтому после перезапуска или на другой
+// реплике напоминание не уходит повторно
+func (s *billService) remindDue(ctx context.Context) {
+	families := make(map[uint]*billFamily)
+	var afterID uint
+	for ctx.Err() == nil {
+		bills, err := s.billRepo.ListAfter(afterID, billBatchSize)
+		if err != nil {
+			log.Printf("bills: не удалось получить счета к оплате: %v", err)
+			return
+		}
+		for i := range bills {
+			bill := &bills[i]
+			afterID = bill.ID
+			family, ok := families[bill.FamilyID]
+			if !ok {
+				family = s.loadFamily(bill.FamilyID)
+				families[bill.FamilyID] = family
+			}
+			if family == nil {
+				continue
+			}
+			for _, date := range billDueDates(bill, family.today, family.today.AddDate(0, 0, bill.RemindDays)) {
+				claimed, err := s.billRepo.ClaimReminder(bill.ID, date)
+				if err != nil {
+					log.Printf("bills: не удалось отметить напоминание по счёту %d: %v", bill.ID, err)
+					continue
+				}
+				if claimed {
+					s.remind(family, bill, date)
+				}
+			}
+		}
+		if len(bills) < billBatchSize {
+			return
+		}
+	}
+}
+
+// billFamily данные семьи, нужные для напоминаний
+type billFamily struct {
+	family  *models.Family
+	members []models.FamilyMember
+	today   time.Time
+}
+
+// loadFamily получает семью и её участников; nil, если данные получить не удалось
+func (s *billService) loadFamily(familyID uint) *billFamily {
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil || family == nil {
+		log.Printf("bills: не удалось получить семью %d: %v", familyID, err)
+		return nil
+	}
+	members, err := s.familyRepo.ListMembers(familyID)
+	if err != nil {
+		log.Printf("bills: не удалось получить участников семьи %d: %v", familyID, err)
+		return nil
+	}
+	today, err := familyToday(s.familyRepo, familyID)
+	if err != nil {
+		log.Printf("bills: %v", err)
+		return nil
+	}
+	return &billFamily{family: family, members: members, today: today}
+}
+
+// remind уведомляет владельца и взрослых семьи о сроке оплаты счёта
+func (s *billService) remind(family *billFamily, bill *models.Bill, date time.Time) {
+	data := mail.BillReminderData{
+		FamilyName: family.family.Name,
+		BillName:   bill.Name,
+		Amount:     bill.Amount,
+		Currency:   bill.Currency,
+		DueDate:    date,
+		DaysLeft:   int(date.Sub(family.today).Hours() / 24),
+	}
+	if bill.AccountID != nil {
+		account, err := s.accountRepo.GetByID(*bill.AccountID)
+		balances, balanceErr := s.accountRepo.Balances([]uint{*bill.AccountID})
+		if err == nil && balanceErr == nil && account != nil {
+			data.AccountName = account.Name
+			data.Balance = balances[account.ID]
+			if data.Balance < bill.Amount {
+				data.Shortfall = bill.Amount - data.Balance
+			}
+		}
+	}
+	title, body := billReminderText(family.family.Locale, data)
+	for i := range family.members {
+		member := &family.members[i]
+		if !member.Role.CanManage() || member.User == nil {
+			continue
+		}
+		familyID, billID := family.family.ID, bill.ID
+		notification := &models.Notification{
+			FamilyID:   &familyID,
+			Type:       models.NotificationBillReminder,
+			Title:      title,
+			Body:       body,
+			ObjectType: "bill",
+			ObjectID:   &billID,
+		}
+		err := s.notificationSvc.Deliver(member.User, notification, func(to string) error {
+			return s.emailSvc.SendBillReminder(to, data)
+		})
+		if err != nil {
+			log.Printf("bills: не удалось уведомить пользователя %d о счёте %d: %v", member.UserID, billID, err)
+		}
+	}
+}
+
+// billReminderText формирует заголовок и текст напоминания на языке семьи
+func billReminderText(locale string, data mail.BillReminderData) (string, string) {
+	amount := money.Display(data.Amount, data.Currency, locale)
+	if locale == "en" {
+		title := fmt.Sprintf("“%s” is due on %s", data.BillName, data.DueDate.Format("Jan 2"))
+		if data.DaysLeft == 0 {
+			title = fmt.Sprintf("“%s” is due today", data.BillName)
+		}
+		body := fmt.Sprintf("Amount due: %s.", amount)
+		if data.Shortfall > 0 {
+			body += fmt.Sprintf(" “%s” is short by %s.", data.AccountName, money.Display(data.Shortfall, data.Currency, locale))
+		}
+		return title, body
+	}
+	title := fmt.Sprintf("«%s»: срок оплаты %s", data.BillName, data.DueDate.Format("02.01.2006"))
+	if data.DaysLeft == 0 {
+		title = fmt.Sprintf("«%s»: срок оплаты сегодня", data.BillName)
+	}
+	body := fmt.Sprintf("К оплате %s.", amount)
+	if data.Shortfall > 0 {
+		body += fmt.Sprintf(" На счёте «%s» не хватает %s.", data.AccountName, money.Display(data.Shortfall, data.Currency, locale))
+	}
+	return title, body
+}
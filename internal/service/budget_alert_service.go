@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+// BudgetAlertService определяет интерфейс проверки порогов бюджетов
+type BudgetAlertService interface {
+	// CheckTransaction проверяет бюджеты, в которые попадает проведённая операция расхода,
+	// и уведомляет владельца и взрослых семьи о впервые достигнутых порогах
+	// Ошибки проверки не влияют на сохранение операции и только записываются в журнал
+	CheckTransaction(tx *models.Transaction)
+}
+
+// budgetAlertService реализует интерфейс BudgetAlertService
+type budgetAlertService struct {
+	budgetRepo      repository.BudgetRepository
+	categoryRepo    repository.CategoryRepository
+	txRepo          repository.TransactionRepository
+	familyRepo      repository.FamilyRepository
+	notificationSvc NotificationService
+	emailSvc        EmailService
+	rates           ExchangeRateService
+}
+
+// NewBudgetAlertService создает новый экземпляр BudgetAlertService
+func NewBudgetAlertService(budgetRepo repository.BudgetRepository, categoryRepo repository.CategoryRepository,
+	txRepo repository.TransactionRepository, familyRepo repository.FamilyRepository,
+	notificationSvc NotificationService, emailSvc EmailService, rates ExchangeRateService) BudgetAlertService {
+	return &budgetAlertService{
+		budgetRepo:      budgetRepo,
+		categoryRepo:    categoryRepo,
+		txRepo:          txRepo,
+		familyRepo:      familyRepo,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+		rates:           rates,
+	}
+}
+
+// budgetAlert сработавший порог по строке бюджета
+type budgetAlert struct {
+	budget    *models.Budget
+	category  models.Category
+	item      BudgetProgressItem
+	threshold int
+	percent   int
+}
+
+func (s *budgetAlertService) CheckTransaction(tx *models.Transaction) {
+	if tx.Direction != models.DirectionExpense || tx.CategoryID == nil || !tx.Posted() {
+		return
+	}
+	alerts, err := s.evaluate(tx)
+	if err != nil {
+		log.Printf("budget alerts: не удалось проверить бюджеты для операции %d: %v", tx.ID, err)
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+	family, err := s.familyRepo.GetByID(tx.FamilyID)
+	if err != nil || family == nil {
+		log.Printf("budget alerts: не удалось получить семью %d: %v", tx.FamilyID, err)
+		return
+	}
+	members, err := s.familyRepo.ListMembers(tx.FamilyID)
+	if err != nil {
+		log.Printf("budget alerts: не удалось получить участников семьи %d: %v", tx.FamilyID, err)
+		return
+	}
+	for _, alert := range alerts {
+		s.notify(family, members, alert)
+	}
+}
+
+// evaluate находит строки бюджетов, к которым относится операция, и отмечает
+// достигнутые пороги. Для каждой строки возвращается только наибольший новый порог
+func (s *budgetAlertService) evaluate(tx *models.Transaction) ([]budgetAlert, error) {
+	budgets, err := s.budgetRepo.ListByFamily(tx.FamilyID, &tx.Date, &tx.Date)
+	if err != nil {
+		return nil, err
+	}
+	var categories map[uint]models.Category
+	var alerts []budgetAlert
+	for i := range budgets {
+		budget := &budgets[i]
+		if len(budget.AlertThresholds) == 0 {
+			continue
+		}
+		if categories == nil {
+			if categories, err = familyCategories(s.categoryRepo, tx.FamilyID); err != nil {
+				return nil, err
+			}
+		}
+		// Операция учитывается в строке своей категории и в строке родительской
+		category, ok := categories[*tx.CategoryID]
+		if !ok {
+			return nil, nil
+		}
+		var lineIDs []uint
+		for _, line := range budget.Lines {
+			if line.CategoryID == category.ID || (category.ParentID != nil && line.CategoryID == *category.ParentID) {
+				lineIDs = append(lineIDs, line.CategoryID)
+			}
+		}
+		if len(lineIDs) == 0 {
+			continue
+		}
+
+		// Операции в других валютах учитываются по курсу на дату операции
+		results, _, err := evaluateBudget(s.budgetRepo, s.txRepo, s.rates, budget, categories, maxRolloverPeriods)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range lineIDs {
+			item := results[id]
+			limit := item.Planned + item.CarriedOver
+			if limit <= 0 {
+				continue
+			}
+			percent := int(item.Actual * 100 / limit)
+			fresh := 0
+			for _, threshold := range budget.AlertThresholds {
+				if percent < threshold {
+					break
+				}
+				recorded, err := s.budgetRepo.RecordAlert(budget.ID, id, threshold)
+				if err != nil {
+					return nil, err
+				}
+				if recorded {
+					fresh = threshold
+				}
+			}
+			if fresh > 0 {
+				alerts = append(alerts, budgetAlert{
+					budget:    budget,
+					category:  categories[id],
+					item:      item,
+					threshold: fresh,
+					percent:   percent,
+				})
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// notify доставляет предупреждение владельцу и взрослым участникам семьи
+func (s *budgetAlertService) notify(family *models.Family, members []models.FamilyMember, alert budgetAlert) {
+	title, body := budgetAlertText(family.Locale, alert)
+	data := mail.BudgetAlertData{
+		FamilyName:   family.Name,
+		CategoryName: alert.category.Name,
+		Threshold:    alert.threshold,
+		Percent:      alert.percent,
+		Planned:      alert.item.Planned + alert.item.CarriedOver,
+		Actual:       alert.item.Actual,
+		Remaining:    alert.item.Remaining,
+		Currency:     alert.budget.Currency,
+		PeriodStart:  alert.budget.StartDate,
+		PeriodEnd:    alert.budget.EndDate,
+	}
+	for i := range members {
+		member := &members[i]
+		if !member.Role.CanManage() || member.User == nil {
+			continue
+		}
+		familyID, budgetID := family.ID, alert.budget.ID
+		notification := &models.Notification{
+			FamilyID:   &familyID,
+			Type:       models.NotificationBudgetAlert,
+			Title:      title,
+			Body:       body,
+			ObjectType: "budget",
+			ObjectID:   &budgetID,
+		}
+		err := s.notificationSvc.Deliver(member.User, notification, func(to string) error {
+			return s.emailSvc.SendBudgetAlert(to, data)
+		})
+		if err != nil {
+			log.Printf("budget alerts: не удалось уведомить пользователя %d о бюджете %d: %v", member.UserID, budgetID, err)
+		}
+	}
+}
+
+// budgetAlertText формирует заголовок и текст уведомления на языке семьи
+func budgetAlertText(locale string, alert budgetAlert) (string, string) {
+	currency := alert.budget.Currency
+	planned := money.Display(alert.item.Planned+alert.item.CarriedOver, currency, locale)
+	actual := money.Display(alert.item.Actual, currency, locale)
+	if locale == "en" {
+		period := alert.budget.StartDate.Format("Jan 2") + " – " + alert.budget.EndDate.Format("Jan 2, 2006")
+		title := fmt.Sprintf("Budget “%s” is %d%% spent", alert.category.Name, alert.percent)
+		if alert.percent >= 100 {
+			title = fmt.Sprintf("Budget “%s” exceeded", alert.category.Name)
+		}
+		return title, fmt.Sprintf("Spent %s of %s planned for %s (threshold %d%%).", actual, planned, period, alert.threshold)
+	}
+	period := alert.budget.StartDate.Format("02.01.2006") + " — " + alert.budget.EndDate.Format("02.01.2006")
+	title := fmt.Sprintf("Бюджет «%s» израсходован на %d%%", alert.category.Name, alert.percent)
+	if alert.percent >= 100 {
+		title = fmt.Sprintf("Бюджет «%s» превышен", alert.category.Name)
+	}
+	return title, fmt.Sprintf("Потрачено %s из запланированных %s за период %s (порог %d%%).", actual, planned, period, alert.threshold)
+}
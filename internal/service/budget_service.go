@@ -0,0 +1,622 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+const (
+	// maxCustomBudgetDays наибольшая длина произвольного периода бюджета
+	maxCustomBudgetDays = 366
+
+	// maxRolloverPeriods сколько предыдущих периодов учитывается при переносе остатка
+	maxRolloverPeriods = 12
+)
+
+var (
+	// ErrBudgetNotFound возвращается, если бюджет не существует или недоступен пользователю
+	ErrBudgetNotFound = newError(KindNotFound, "бюджет не найден")
+
+	// ErrBudgetExists возвращается, если бюджет на этот период уже есть
+	ErrBudgetExists = newError(KindConflict, "бюджет на этот период уже существует")
+
+	// ErrBudgetEndRequired возвращается, если для произвольного периода не указана дата окончания
+	ErrBudgetEndRequired = newError(KindInvalid, "для произвольного периода укажите дату окончания")
+
+	// ErrBudgetPeriodInvalid возвращается, если дата окончания раньше даты начала или период слишком длинный
+	ErrBudgetPeriodInvalid = newError(KindInvalid, "период бюджета должен длиться от одного дня до года")
+
+	// ErrBudgetDuplicateCategory возвращается, если категория указана в бюджете несколько раз
+	ErrBudgetDuplicateCategory = newError(KindInvalid, "каждая категория может встречаться в бюджете только один раз")
+)
+
+// BudgetLineInput плановая сумма по категории
+type BudgetLineInput struct {
+	CategoryID uint
+	// Amount плановая сумма в минимальных единицах валюты бюджета
+	Amount int64
+	// Rollover переносит неизрасходованный остаток предыдущего периода
+	Rollover bool
+}
+
+// BudgetInput данные нового бюджета
+type BudgetInput struct {
+	Period models.BudgetPeriod
+	// StartDate любой день периода для месяца и недели; первый день для произвольного периода
+	StartDate time.Time
+	// EndDate последний день произвольного периода; для месяца и недели не используется
+	EndDate *time.Time
+	// Currency валюта бюджета; по умолчанию валюта семьи
+	Currency string
+	// AlertThresholds пороги предупреждений в процентах; nil — models.DefaultAlertThresholds
+	AlertThresholds models.Thresholds
+	Lines           []BudgetLineInput
+}
+
+// BudgetChanges изменения бюджета; nil поля не изменяются
+type BudgetChanges struct {
+	// Lines новые строки бюджета; заменяют текущие целиком
+	Lines *[]BudgetLineInput
+	// AlertThresholds новые пороги предупреждений; пустой список отключает предупреждения
+	AlertThresholds *models.Thresholds
+}
+
+// BudgetProgressItem исполнение бюджета по категории
+type BudgetProgressItem struct {
+	CategoryID   uint             `json:"category_id"`
+	CategoryName string           `json:"category_name"`
+	Kind         models.Direction `json:"kind"`
+	// Planned плановая сумма периода
+	Planned int64 `json:"planned"`
+	// CarriedOver неизрасходованный остаток, перенесённый из предыдущего периода
+	CarriedOver int64 `json:"carried_over"`
+	// Actual фактическая сумма операций категории и её подкатегорий
+	Actual int64 `json:"actual"`
+	// Remaining остаток: план плюс перенос минус факт; отрицателен при перерасходе
+	Remaining int64 `json:"remaining"`
+}
+
+// BudgetProgress исполнение бюджета
+type BudgetProgress struct {
+	Budget *models.Budget       `json:"budget"`
+	Items  []BudgetProgressItem `json:"items"`
+	// Expense итоги по категориям расходов
+	Expense BudgetTotals `json:"expense"`
+	// Income итоги по категориям доходов
+	Income BudgetTotals `json:"income"`
+	// MissingRates валюты операций, для которых не найден курс к валюте бюджета;
+	// такие операции не учтены в фактических суммах
+	MissingRates []string `json:"missing_rates"`
+	// Base итоги, пересчитанные в основную валюту семьи; nil, если валюта бюджета
+	// совпадает с основной или курса нет
+	Base *BudgetBaseTotals `json:"base"`
+}
+
+// BudgetBaseTotals итоги бюджета в основной валюте семьи
+type BudgetBaseTotals struct {
+	Currency string `json:"currency"`
+	// Date дата курса: последний день периода или сегодняшний день, если период не закончился
+	Date time.Time `json:"date"`
+	// Rate сколько единиц основной валюты стоит одна единица валюты бюджета
+	Rate    string       `json:"rate"`
+	Expense BudgetTotals `json:"expense"`
+	Income  BudgetTotals `json:"income"`
+}
+
+// BudgetTotals итоги исполнения бюджета по виду категорий
+type BudgetTotals struct {
+	Planned     int64 `json:"planned"`
+	CarriedOver int64 `json:"carried_over"`
+	Actual      int64 `json:"actual"`
+	Remaining   int64 `json:"remaining"`
+}
+
+// BudgetService определяет интерфейс для работы с бюджетами
+type BudgetService interface {
+	// Create создает бюджет семьи на период; доступно владельцу и взрослым
+	Create(user *models.User, familyID uint, input BudgetInput) (*models.Budget, error)
+
+	// Get возвращает бюджет; доступно любому участнику семьи
+	Get(user *models.User, budgetID uint) (*models.Budget, error)
+
+	// List возвращает бюджеты семьи, пересекающиеся с периодом [from, to];
+	// доступно любому участнику семьи
+	List(user *models.User, familyID uint, from, to *time.Time) ([]models.Budget, error)
+
+	// Update изменяет плановые суммы и пороги предупреждений бюджета; доступно владельцу и взрослым
+	Update(user *models.User, budgetID uint, changes BudgetChanges) (*models.Budget, error)
+
+	// Delete удаляет бюджет; доступно владельцу и взрослым
+	Delete(user *models.User, budgetID uint) error
+
+	// Progress сравнивает план бюджета с фактическими операциями периода;
+	// доступно любому участнику семьи
+	Progress(user *models.User, budgetID uint) (*BudgetProgress, error)
+
+	// CopyForward создает копию бюджета на следующий период или на период,
+	// начинающийся с start; архивные категории не копируются. Доступно владельцу и взрослым
+	CopyForward(user *models.User, budgetID uint, start *time.Time) (*models.Budget, error)
+}
+
+// budgetService реализует интерфейс BudgetService
+type budgetService struct {
+	transactor   repository.Transactor
+	budgetRepo   repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+	txRepo       repository.TransactionRepository
+	familyRepo   repository.FamilyRepository
+	rates        ExchangeRateService
+}
+
+// NewBudgetService создает новый экземпляр BudgetService
+func NewBudgetService(transactor repository.Transactor, budgetRepo repository.BudgetRepository,
+	categoryRepo repository.CategoryRepository, txRepo repository.TransactionRepository,
+	familyRepo repository.FamilyRepository, rates ExchangeRateService) BudgetService {
+	return &budgetService{
+		transactor:   transactor,
+		budgetRepo:   budgetRepo,
+		categoryRepo: categoryRepo,
+		txRepo:       txRepo,
+		familyRepo:   familyRepo,
+		rates:        rates,
+	}
+}
+
+// budgetBounds вычисляет первый и последний день периода бюджета
+// Месяц и неделя выравниваются по календарю: 1-е число и понедельник
+func budgetBounds(period models.BudgetPeriod, start time.Time, end *time.Time) (time.Time, time.Time, error) {
+	y, m, d := start.Date()
+	switch period {
+	case models.BudgetMonthly:
+		first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 1, -1), nil
+	case models.BudgetWeekly:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return monday, monday.AddDate(0, 0, 6), nil
+	}
+	if end == nil {
+		return time.Time{}, time.Time{}, ErrBudgetEndRequired
+	}
+	first := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	ey, em, ed := end.Date()
+	last := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+	if last.Before(first) || last.Sub(first) >= maxCustomBudgetDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrBudgetPeriodInvalid
+	}
+	return first, last, nil
+}
+
+// nextBudgetStart возвращает первый день периода, следующего за бюджетом
+func nextBudgetStart(budget *models.Budget) time.Time {
+	return budget.EndDate.AddDate(0, 0, 1)
+}
+
+func (s *budgetService) Create(user *models.User, familyID uint, input BudgetInput) (*models.Budget, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner, models.RoleAdult); err != nil {
+		return nil, err
+	}
+	start, end, err := budgetBounds(input.Period, input.StartDate, input.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.checkLines(familyID, input.Lines, nil)
+	if err != nil {
+		return nil, err
+	}
+	currency := input.Currency
+	if currency == "" {
+		family, err := s.familyRepo.GetByID(familyID)
+		if err != nil || family == nil {
+			return nil, errors.New("не удалось получить данные семьи")
+		}
+		currency = family.Currency
+	}
+	thresholds := input.AlertThresholds
+	if thresholds == nil {
+		thresholds = models.DefaultAlertThresholds
+	}
+	return s.create(&models.Budget{
+		FamilyID:        familyID,
+		Period:          input.Period,
+		StartDate:       start,
+		EndDate:         end,
+		Currency:        currency,
+		AlertThresholds: thresholds,
+		CreatedBy:       user.ID,
+		Lines:           lines,
+	})
+}
+
+// create сохраняет бюджет, если на его период бюджета ещё нет
+func (s *budgetService) create(budget *models.Budget) (*models.Budget, error) {
+	existing, err := s.budgetRepo.FindByStart(budget.FamilyID, budget.Period, budget.StartDate)
+	if err != nil {
+		return nil, errors.New("не удалось создать бюджет, попробуйте позже")
+	}
+	if existing != nil {
+		return nil, ErrBudgetExists
+	}
+	if err := s.budgetRepo.Create(budget); err != nil {
+		return nil, errors.New("не удалось создать бюджет, попробуйте позже")
+	}
+	if budget.Lines == nil {
+		budget.Lines = []models.BudgetLine{}
+	}
+	return budget, nil
+}
+
+func (s *budgetService) Get(user *models.User, budgetID uint) (*models.Budget, error) {
+	budget, _, err := s.load(user, budgetID)
+	return budget, err
+}
+
+func (s *budgetService) List(user *models.User, familyID uint, from, to *time.Time) ([]models.Budget, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	budgets, err := s.budgetRepo.ListByFamily(familyID, from, to)
+	if err != nil {
+		return nil, errors.New("не удалось получить список бюджетов")
+	}
+	if budgets == nil {
+		budgets = []models.Budget{}
+	}
+	return budgets, nil
+}
+
+func (s *budgetService) Update(user *models.User, budgetID uint, changes BudgetChanges) (*models.Budget, error) {
+	budget, member, err := s.load(user, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrFamilyForbidden
+	}
+	var lines []models.BudgetLine
+	if changes.Lines != nil {
+		if lines, err = s.checkLines(budget.FamilyID, *changes.Lines, budget.Lines); err != nil {
+			return nil, err
+		}
+	}
+	if changes.AlertThresholds != nil {
+		budget.AlertThresholds = *changes.AlertThresholds
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if changes.Lines != nil {
+			if err := repos.Budgets.ReplaceLines(budget.ID, lines); err != nil {
+				return err
+			}
+		}
+		return repos.Budgets.Update(budget)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось обновить бюджет, попробуйте позже")
+	}
+	budget, err = s.budgetRepo.GetByID(budget.ID)
+	if err != nil || budget == nil {
+		return nil, errors.New("не удалось получить данные бюджета")
+	}
+	return budget, nil
+}
+
+func (s *budgetService) Delete(user *models.User, budgetID uint) error {
+	budget, member, err := s.load(user, budgetID)
+	if err != nil {
+		return err
+	}
+	if !member.Role.CanManage() {
+		return ErrFamilyForbidden
+	}
+	if err := s.budgetRepo.Delete(budget.ID); err != nil {
+		return errors.New("не удалось удалить бюджет, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *budgetService) CopyForward(user *models.User, budgetID uint, start *time.Time) (*models.Budget, error) {
+	source, member, err := s.load(user, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrFamilyForbidden
+	}
+
+	first := nextBudgetStart(source)
+	if start != nil {
+		first = *start
+	}
+	var last *time.Time
+	if source.Period == models.BudgetCustom {
+		// Произвольный период копируется с той же длительностью
+		end := first.AddDate(0, 0, int(source.EndDate.Sub(source.StartDate).Hours()/24))
+		last = &end
+	}
+	from, to, err := budgetBounds(source.Period, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := familyCategories(s.categoryRepo, source.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]models.BudgetLine, 0, len(source.Lines))
+	for _, line := range source.Lines {
+		if c, ok := categories[line.CategoryID]; !ok || c.Archived {
+			continue
+		}
+		lines = append(lines, models.BudgetLine{CategoryID: line.CategoryID, Amount: line.Amount, Rollover: line.Rollover})
+	}
+	return s.create(&models.Budget{
+		FamilyID:        source.FamilyID,
+		Period:          source.Period,
+		StartDate:       from,
+		EndDate:         to,
+		Currency:        source.Currency,
+		AlertThresholds: source.AlertThresholds,
+		CreatedBy:       user.ID,
+		Lines:           lines,
+	})
+}
+
+func (s *budgetService) Progress(user *models.User, budgetID uint) (*BudgetProgress, error) {
+	budget, _, err := s.load(user, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := familyCategories(s.categoryRepo, budget.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	results, missing, err := evaluateBudget(s.budgetRepo, s.txRepo, s.rates, budget, categories, maxRolloverPeriods)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &BudgetProgress{
+		Budget:       budget,
+		Items:        make([]BudgetProgressItem, 0, len(budget.Lines)),
+		MissingRates: missing,
+	}
+	for _, line := range budget.Lines {
+		item := results[line.CategoryID]
+		category := categories[line.CategoryID]
+		item.CategoryName = category.Name
+		item.Kind = category.Kind
+		progress.Items = append(progress.Items, item)
+
+		totals := &progress.Expense
+		if item.Kind == models.DirectionIncome {
+			totals = &progress.Income
+		}
+		totals.Planned += item.Planned
+		totals.CarriedOver += item.CarriedOver
+		totals.Actual += item.Actual
+		totals.Remaining += item.Remaining
+	}
+	if progress.Base, err = s.baseTotals(progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// baseTotals пересчитывает итоги бюджета в основную валюту семьи по курсу
+// на последний день периода, а для текущего периода — на сегодня
+func (s *budgetService) baseTotals(progress *BudgetProgress) (*BudgetBaseTotals, error) {
+	budget := progress.Budget
+	family, err := s.familyRepo.GetByID(budget.FamilyID)
+	if err != nil || family == nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if family.Currency == budget.Currency {
+		return nil, nil
+	}
+	date := budget.EndDate
+	if today := familyDate(family); today.Before(date) {
+		date = today
+	}
+	table, err := s.rates.Table([]string{budget.Currency, family.Currency}, date, date)
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := table.Rate(budget.Currency, family.Currency, date)
+	if !ok {
+		return nil, nil
+	}
+	var convErr error
+	convert := func(amount int64) int64 {
+		converted, err := money.Convert(amount, budget.Currency, family.Currency, rate)
+		if err != nil {
+			convErr = err
+		}
+		return converted
+	}
+	base := &BudgetBaseTotals{
+		Currency: family.Currency,
+		Date:     date,
+		Rate:     formatRate(rate),
+		Expense:  progress.Expense.convert(convert),
+		Income:   progress.Income.convert(convert),
+	}
+	if convErr != nil {
+		// Итоги в основной валюте не помещаются в допустимый диапазон сумм
+		return nil, nil
+	}
+	return base, nil
+}
+
+// convert возвращает итоги, пересчитанные функцией convert
+func (t BudgetTotals) convert(convert func(int64) int64) BudgetTotals {
+	return BudgetTotals{
+		Planned:     convert(t.Planned),
+		CarriedOver: convert(t.CarriedOver),
+		Actual:      convert(t.Actual),
+		Remaining:   convert(t.Remaining),
+	}
+}
+
+// evaluateBudget вычисляет исполнение строк бюджета
+// Операции в других валютах пересчитываются в валюту бюджета по курсу на дату операции;
+// возвращаются также валюты, для которых курс не найден.
+// Перенос остатка берётся из бюджета того же вида, заканчивающегося накануне начала этого;
+// depth ограничивает глубину цепочки предыдущих периодов
+func evaluateBudget(budgetRepo repository.BudgetRepository, txRepo repository.TransactionRepository, rates ExchangeRateService,
+	budget *models.Budget, categories map[uint]models.Category, depth int) (map[uint]BudgetProgressItem, []string, error) {
+	sums, err := txRepo.SumByCategory(budget.FamilyID, budget.Currency, budget.StartDate, budget.EndDate)
+	if err != nil {
+		return nil, nil, errors.New("не удалось вычислить исполнение бюджета")
+	}
+	foreign, err := txRepo.SumForeignByCategory(budget.FamilyID, budget.Currency, budget.StartDate, budget.EndDate)
+	if err != nil {
+		return nil, nil, errors.New("не удалось вычислить исполнение бюджета")
+	}
+	missing, err := addForeignSums(rates, budget, foreign, sums)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var previous map[uint]BudgetProgressItem
+	if depth > 0 && budget.HasRollover() {
+		prev, err := budgetRepo.FindEndingOn(budget.FamilyID, budget.Period, budget.StartDate.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, nil, errors.New("не удалось вычислить исполнение бюджета")
+		}
+		if prev != nil && prev.Currency == budget.Currency {
+			var prevMissing []string
+			if previous, prevMissing, err = evaluateBudget(budgetRepo, txRepo, rates, prev, categories, depth-1); err != nil {
+				return nil, nil, err
+			}
+			missing = mergeCurrencies(missing, prevMissing)
+		}
+	}
+
+	results := make(map[uint]BudgetProgressItem, len(budget.Lines))
+	for _, line := range budget.Lines {
+		item := BudgetProgressItem{CategoryID: line.CategoryID, Planned: line.Amount}
+		item.Actual = sums[line.CategoryID]
+		for id, c := range categories {
+			if c.ParentID != nil && *c.ParentID == line.CategoryID {
+				item.Actual += sums[id]
+			}
+		}
+		if prev, ok := previous[line.CategoryID]; ok && line.Rollover && prev.Remaining > 0 {
+			item.CarriedOver = prev.Remaining
+		}
+		item.Remaining = item.Planned + item.CarriedOver - item.Actual
+		results[line.CategoryID] = item
+	}
+	return results, missing, nil
+}
+
+// addForeignSums добавляет к суммам по категориям операции в других валютах, пересчитанные
+// в валюту бюджета по курсу на дату операции, и возвращает валюты без курса
+func addForeignSums(rates ExchangeRateService, budget *models.Budget, foreign []repository.ForeignSum, sums map[uint]int64) ([]string, error) {
+	missing := []string{}
+	if len(foreign) == 0 {
+		return missing, nil
+	}
+	currencies := []string{budget.Currency}
+	for _, f := range foreign {
+		currencies = mergeCurrencies(currencies, []string{f.Currency})
+	}
+	table, err := rates.Table(currencies, budget.StartDate, budget.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range foreign {
+		converted, ok := table.Convert(f.Total, f.Currency, budget.Currency, f.Date)
+		if !ok {
+			missing = mergeCurrencies(missing, []string{f.Currency})
+			continue
+		}
+		sums[f.CategoryID] += converted
+	}
+	return missing, nil
+}
+
+// mergeCurrencies возвращает упорядоченное объединение списков кодов валют
+func mergeCurrencies(list, more []string) []string {
+	for _, code := range more {
+		i := sort.SearchStrings(list, code)
+		if i < len(list) && list[i] == code {
+			continue
+		}
+		list = append(list, "")
+		copy(list[i+1:], list[i:])
+		list[i] = code
+	}
+	return list
+}
+
+// checkLines проверяет строки бюджета: категории принадлежат семье, не повторяются
+// и не находятся в архиве. Архивные категории, уже запланированные в бюджете (current), допускаются
+func (s *budgetService) checkLines(familyID uint, input []BudgetLineInput, current []models.BudgetLine) ([]models.BudgetLine, error) {
+	categories, err := familyCategories(s.categoryRepo, familyID)
+	if err != nil {
+		return nil, err
+	}
+	planned := make(map[uint]bool, len(current))
+	for _, line := range current {
+		planned[line.CategoryID] = true
+	}
+	seen := make(map[uint]bool, len(input))
+	lines := make([]models.BudgetLine, 0, len(input))
+	for _, in := range input {
+		category, ok := categories[in.CategoryID]
+		if !ok {
+			return nil, ErrCategoryNotFound
+		}
+		if category.Archived && !planned[in.CategoryID] {
+			return nil, ErrCategoryArchived
+		}
+		if seen[in.CategoryID] {
+			return nil, ErrBudgetDuplicateCategory
+		}
+		seen[in.CategoryID] = true
+		lines = append(lines, models.BudgetLine{CategoryID: in.CategoryID, Amount: in.Amount, Rollover: in.Rollover})
+	}
+	return lines, nil
+}
+
+// familyCategories возвращает все категории семьи, включая архивные, по идентификатору
+func familyCategories(categoryRepo repository.CategoryRepository, familyID uint) (map[uint]models.Category, error) {
+	categories, err := categoryRepo.ListByFamily(familyID, true)
+	if err != nil {
+		return nil, errors.New("не удалось получить список категорий")
+	}
+	result := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		result[c.ID] = c
+	}
+	return result, nil
+}
+
+// load получает бюджет и членство пользователя в его семье
+// Бюджет чужой семьи неотличим от несуществующего
+func (s *budgetService) load(user *models.User, budgetID uint) (*models.Budget, *models.FamilyMember, error) {
+	budget, err := s.budgetRepo.GetByID(budgetID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить данные бюджета")
+	}
+	if budget == nil {
+		return nil, nil, ErrBudgetNotFound
+	}
+	member, err := requireMember(s.familyRepo, budget.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrBudgetNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if budget.Lines == nil {
+		budget.Lines = []models.BudgetLine{}
+	}
+	return budget, member, nil
+}
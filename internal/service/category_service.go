@@ -0,0 +1,361 @@
+package service
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrCategoryNotFound возвращается, если категория не существует или недоступна пользователю
+	ErrCategoryNotFound = newError(KindNotFound, "категория не найдена")
+
+	// ErrCategoryKindRequired возвращается, если для категории верхнего уровня не указан вид
+	ErrCategoryKindRequired = newError(KindInvalid, "укажите вид категории: income или expense")
+
+	// ErrCategoryKindMismatch возвращается, если вид категории не совпадает с видом родительской
+	// или объединяемой категории
+	ErrCategoryKindMismatch = newError(KindInvalid, "категории доходов и расходов нельзя смешивать")
+
+	// ErrCategoryParentInvalid возвращается, если родительская категория сама является подкатегорией
+	ErrCategoryParentInvalid = newError(KindInvalid, "подкатегорию можно создать только в категории верхнего уровня")
+
+	// ErrCategoryArchived возвращается при использовании архивной категории
+	ErrCategoryArchived = newError(KindConflict, "категория в архиве: верните её из архива, чтобы использовать")
+
+	// ErrCategoryOrderMismatch возвращается, если список для сортировки не совпадает с категориями уровня
+	ErrCategoryOrderMismatch = newError(KindInvalid, "список должен содержать каждую категорию уровня ровно один раз")
+
+	// ErrCategoryMergeSame возвращается при объединении категории с самой собой
+	ErrCategoryMergeSame = newError(KindInvalid, "нельзя объединить категорию с самой собой")
+
+	// ErrCategoryMergeIntoChild возвращается при объединении категории с её подкатегорией
+	ErrCategoryMergeIntoChild = newError(KindInvalid, "нельзя объединить категорию с её подкатегорией")
+
+	// ErrCategoryMergeDepth возвращается, если после объединения подкатегории окажутся на третьем уровне
+	ErrCategoryMergeDepth = newError(KindConflict, "у категории есть подкатегории: объедините её с категорией верхнего уровня")
+
+	// ErrCategoryDirectionMismatch возвращается, если вид категории не совпадает с направлением операции
+	ErrCategoryDirectionMismatch = newError(KindInvalid, "категория доходов не подходит для расхода и наоборот")
+)
+
+// CategoryInput данные новой категории
+type CategoryInput struct {
+	// ParentID родительская категория; nil для категории верхнего уровня
+	ParentID *uint
+	// Kind вид категории; для подкатегории по умолчанию вид родительской
+	Kind  models.Direction
+	Name  string
+	Icon  string
+	Color string
+}
+
+// CategoryChanges изменения категории; nil поля не изменяются
+type CategoryChanges struct {
+	Name  *string
+	Icon  *string
+	Color *string
+}
+
+// CategoryService определяет интерфейс для работы с категориями
+type CategoryService interface {
+	// List возвращает дерево категорий семьи; доступно любому участнику семьи
+	// Семьям, созданным до появления категорий, при первом запросе создаётся набор по умолчанию
+	List(user *models.User, familyID uint, includeArchived bool) ([]models.Category, error)
+
+	// Create создает категорию или подкатегорию; доступно владельцу и взрослым
+	Create(user *models.User, familyID uint, input CategoryInput) (*models.Category, error)
+
+	// Update переименовывает категорию или меняет её оформление; доступно владельцу и взрослым
+	Update(user *models.User, categoryID uint, changes CategoryChanges) (*models.Category, error)
+
+	// Archive архивирует категорию вместе с подкатегориями или возвращает из архива;
+	// доступно владельцу и взрослым. Операции архивной категории сохраняют её
+	Archive(user *models.User, categoryID uint, archived bool) (*models.Category, error)
+
+	// Reorder задаёт порядок категорий одного уровня: верхнего (parentID = nil)
+	// или подкатегорий parentID; доступно владельцу и взрослым
+	Reorder(user *models.User, familyID uint, parentID *uint, ids []uint) error
+
+	// Merge атомарно переносит операции, строки бюджетов и подкатегории категории source
+	// в target и удаляет source; доступно владельцу и взрослым
+	Merge(user *models.User, sourceID, targetID uint) (*models.Category, error)
+}
+
+// categoryService реализует интерфейс CategoryService
+type categoryService struct {
+	transactor   repository.Transactor
+	categoryRepo repository.CategoryRepository
+	familyRepo   repository.FamilyRepository
+}
+
+// NewCategoryService создает новый экземпляр CategoryService
+func NewCategoryService(transactor repository.Transactor, categoryRepo repository.CategoryRepository,
+	familyRepo repository.FamilyRepository) CategoryService {
+	return &categoryService{transactor: transactor, categoryRepo: categoryRepo, familyRepo: familyRepo}
+}
+
+func (s *categoryService) List(user *models.User, familyID uint, includeArchived bool) ([]models.Category, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureSeeded(familyID); err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.ListByFamily(familyID, includeArchived)
+	if err != nil {
+		return nil, errors.New("не удалось получить список категорий")
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// ensureSeeded создает набор категорий по умолчанию, если у семьи ещё нет ни одной категории
+func (s *categoryService) ensureSeeded(familyID uint) error {
+	count, err := s.categoryRepo.CountByFamily(familyID)
+	if err != nil {
+		return errors.New("не удалось получить список категорий")
+	}
+	if count > 0 {
+		return nil
+	}
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil || family == nil {
+		return errors.New("не удалось получить данные семьи")
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		return seedCategories(repos, familyID, family.Locale)
+	})
+	if err != nil {
+		return errors.New("не удалось создать категории по умолчанию")
+	}
+	return nil
+}
+
+// buildCategoryTree раскладывает подкатегории по родителям
+// Ожидает список, в котором категории верхнего уровня идут раньше подкатегорий
+func buildCategoryTree(categories []models.Category) []models.Category {
+	roots := make([]models.Category, 0, len(categories))
+	index := make(map[uint]int)
+	for _, c := range categories {
+		if c.ParentID == nil {
+			index[c.ID] = len(roots)
+			roots = append(roots, c)
+			continue
+		}
+		// Подкатегории архивного родителя без include_archived не выдаются
+		if i, ok := index[*c.ParentID]; ok {
+			roots[i].Children = append(roots[i].Children, c)
+		}
+	}
+	return roots
+}
+
+func (s *categoryService) Create(user *models.User, familyID uint, input CategoryInput) (*models.Category, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner, models.RoleAdult); err != nil {
+		return nil, err
+	}
+	category := &models.Category{
+		FamilyID: familyID,
+		Kind:     input.Kind,
+		Name:     input.Name,
+		Icon:     input.Icon,
+		Color:    input.Color,
+	}
+	if input.ParentID != nil {
+		parent, err := s.categoryRepo.GetByID(*input.ParentID)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные категории")
+		}
+		if parent == nil || parent.FamilyID != familyID {
+			return nil, ErrCategoryNotFound
+		}
+		if parent.ParentID != nil {
+			return nil, ErrCategoryParentInvalid
+		}
+		if parent.Archived {
+			return nil, ErrCategoryArchived
+		}
+		if category.Kind == "" {
+			category.Kind = parent.Kind
+		}
+		if category.Kind != parent.Kind {
+			return nil, ErrCategoryKindMismatch
+		}
+		if category.Icon == "" {
+			category.Icon = parent.Icon
+		}
+		if category.Color == "" {
+			category.Color = parent.Color
+		}
+		category.ParentID = &parent.ID
+	}
+	if category.Kind == "" {
+		return nil, ErrCategoryKindRequired
+	}
+
+	last, err := s.categoryRepo.MaxSortOrder(familyID, category.ParentID)
+	if err != nil {
+		return nil, errors.New("не удалось создать категорию, попробуйте позже")
+	}
+	category.SortOrder = last + 1
+	if err := s.categoryRepo.Create(category); err != nil {
+		return nil, errors.New("не удалось создать категорию, попробуйте позже")
+	}
+	return category, nil
+}
+
+func (s *categoryService) Update(user *models.User, categoryID uint, changes CategoryChanges) (*models.Category, error) {
+	category, err := s.loadForManage(user, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Name != nil {
+		category.Name = *changes.Name
+	}
+	if changes.Icon != nil {
+		category.Icon = *changes.Icon
+	}
+	if changes.Color != nil {
+		category.Color = *changes.Color
+	}
+	if err := s.categoryRepo.Update(category); err != nil {
+		return nil, errors.New("не удалось обновить категорию, попробуйте позже")
+	}
+	return category, nil
+}
+
+func (s *categoryService) Archive(user *models.User, categoryID uint, archived bool) (*models.Category, error) {
+	category, err := s.loadForManage(user, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	// Подкатегорию нельзя вернуть из архива отдельно от архивного родителя
+	if !archived && category.ParentID != nil {
+		parent, err := s.categoryRepo.GetByID(*category.ParentID)
+		if err != nil || parent == nil {
+			return nil, errors.New("не удалось получить данные категории")
+		}
+		if parent.Archived {
+			return nil, ErrCategoryArchived
+		}
+	}
+	if err := s.categoryRepo.SetArchived(category.ID, archived); err != nil {
+		return nil, errors.New("не удалось обновить категорию, попробуйте позже")
+	}
+	category.Archived = archived
+	return category, nil
+}
+
+func (s *categoryService) Reorder(user *models.User, familyID uint, parentID *uint, ids []uint) error {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner, models.RoleAdult); err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.ListByFamily(familyID, true)
+	if err != nil {
+		return errors.New("не удалось получить список категорий")
+	}
+	known := make(map[uint]bool)
+	for _, c := range categories {
+		if (parentID == nil && c.ParentID == nil) || (parentID != nil && c.ParentID != nil && *c.ParentID == *parentID) {
+			known[c.ID] = true
+		}
+	}
+	if len(known) != len(ids) {
+		return ErrCategoryOrderMismatch
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return ErrCategoryOrderMismatch
+		}
+		// Повтор идентификатора тоже считается несовпадением
+		delete(known, id)
+	}
+	if err := s.categoryRepo.Reorder(familyID, ids); err != nil {
+		return errors.New("не удалось изменить порядок категорий, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *categoryService) Merge(user *models.User, sourceID, targetID uint) (*models.Category, error) {
+	if sourceID == targetID {
+		return nil, ErrCategoryMergeSame
+	}
+	source, err := s.loadForManage(user, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.loadForManage(user, targetID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case source.FamilyID != target.FamilyID:
+		return nil, ErrCategoryNotFound
+	case source.Kind != target.Kind:
+		return nil, ErrCategoryKindMismatch
+	case target.Archived:
+		return nil, ErrCategoryArchived
+	case target.ParentID != nil && *target.ParentID == source.ID:
+		return nil, ErrCategoryMergeIntoChild
+	}
+	children, err := s.categoryRepo.ListChildren(source.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные категории")
+	}
+	if len(children) > 0 && target.ParentID != nil {
+		return nil, ErrCategoryMergeDepth
+	}
+
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Transactions.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Budgets.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Approvals.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Recurring.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Bills.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Spending.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Statements.ReassignCategory(source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := repos.Categories.Reparent(source.ID, target.ID); err != nil {
+			return err
+		}
+		return repos.Categories.Delete(source.ID)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось объединить категории, попробуйте позже")
+	}
+	return target, nil
+}
+
+// loadForManage получает категорию, которую пользователь вправе изменять
+// Категория чужой семьи неотличима от несуществующей
+func (s *categoryService) loadForManage(user *models.User, categoryID uint) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные категории")
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	_, err = requireMember(s.familyRepo, category.FamilyID, user.ID, models.RoleOwner, models.RoleAdult)
+	if err == ErrFamilyNotFound {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
@@ -0,0 +1,675 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrDebtNotFound возвращается, если запись о долге не существует или недоступна пользователю
+	ErrDebtNotFound = newError(KindNotFound, "запись о долге не найдена")
+
+	// ErrDebtForbidden возвращается, если участник не может записать или удалить долг
+	ErrDebtForbidden = newError(KindForbidden, "записывать долги других участников могут только владелец и взрослые, удалять запись — её автор и владелец семьи")
+
+	// ErrDebtPartyNotMember возвращается, если кредитор или должник не состоит в семье
+	ErrDebtPartyNotMember = newError(KindInvalid, "кредитор и должник должны состоять в семье")
+
+	// ErrDebtSameParty возвращается, если кредитор и должник совпадают
+	ErrDebtSameParty = newError(KindInvalid, "кредитор и должник должны различаться")
+
+	// ErrDebtNothingToSettle возвращается при погашении, если участник никому не должен
+	// или получателю никто не должен
+	ErrDebtNothingToSettle = newError(KindConflict, "участник никому не должен или получателю никто не должен в этой валюте")
+
+	// ErrDebtSettleExceeds возвращается, если сумма погашения больше долга участника
+	// или больше того, что должны получателю
+	ErrDebtSettleExceeds = newError(KindInvalid, "сумма погашения больше долга")
+)
+
+// DebtInput данные займа между участниками семьи
+type DebtInput struct {
+	LenderID   uint
+	BorrowerID uint
+	// Amount сумма в минимальных единицах валюты
+	Amount int64
+	// Currency валюта; по умолчанию основная валюта семьи
+	Currency string
+	// Date дата займа; по умолчанию текущая дата в часовом поясе семьи
+	Date *time.Time
+	Note string
+}
+
+// SettleInput данные погашения долга
+type SettleInput struct {
+	// FromID должник, который возвращает долг
+	FromID uint
+	// ToID кредитор, которому возвращают долг
+	ToID uint
+	// Currency валюта долга; по умолчанию основная валюта семьи
+	Currency string
+	// Amount сумма погашения; 0 — весь долг
+	Amount int64
+	// Date дата погашения; по умолчанию текущая дата в часовом поясе семьи
+	Date *time.Time
+	Note string
+}
+
+// DebtBalance итоговый долг одного участника перед другим в одной валюте
+type DebtBalance struct {
+	DebtorID   uint   `json:"debtor_id"`
+	CreditorID uint   `json:"creditor_id"`
+	Currency   string `json:"currency"`
+	Amount     int64  `json:"amount"`
+}
+
+// MemberDebtBalance итог участника по всем долгам в одной валюте
+type MemberDebtBalance struct {
+	UserID   uint   `json:"user_id"`
+	Currency string `json:"currency"`
+	// Net сколько участнику должны остальные; отрицателен, если должен он
+	Net int64 `json:"net"`
+}
+
+// SettlementSuggestion перевод, предлагаемый для погашения долгов
+type SettlementSuggestion struct {
+	FromID   uint   `json:"from_id"`
+	ToID     uint   `json:"to_id"`
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// DebtSummary долги между участниками семьи с учётом разделённых расходов, займов и погашений
+type DebtSummary struct {
+	// Balances итоговые долги по парам участников
+	Balances []DebtBalance `json:"balances"`
+	// Members итоги участников
+	Members []MemberDebtBalance `json:"members"`
+	// SettleUp переводы, которыми можно погасить все долги
+	SettleUp []SettlementSuggestion `json:"settle_up"`
+}
+
+// DebtService определяет интерфейс для учёта долгов между участниками семьи
+type DebtService interface {
+	// Summary возвращает долги между участниками и предложение, как их погасить;
+	// доступно любому участнику семьи
+	Summary(user *models.User, familyID uint) (*DebtSummary, error)
+
+	// List возвращает записи о займах и погашениях, начиная с последних;
+	// если memberID не 0 — только записи с этим участником. Доступно любому участнику семьи
+	List(user *models.User, familyID, memberID uint) ([]models.Debt, error)
+
+	// Lend записывает заём между участниками семьи и уведомляет обе стороны
+	// Свой заём может записать любой участник, кроме наблюдателя; чужой — владелец и взрослые
+	Lend(user *models.User, familyID uint, input DebtInput) (*models.Debt, error)
+
+	// Settle записывает погашение долга и уведомляет обе стороны; права те же, что у Lend
+	// Погашение проверяется по итогам участников, как в предложении SettleUp: должник может
+	// вернуть не больше, чем должен всем, и не больше, чем должны получателю, даже если
+	// напрямую должен другому участнику. По умолчанию сумма — наибольшая из возможных
+	Settle(user *models.User, familyID uint, input SettleInput) (*models.Debt, error)
+
+	// Delete удаляет ошибочную запись; доступно её автору и владельцу семьи
+	Delete(user *models.User, debtID uint) error
+}
+
+// debtService реализует интерфейс DebtService
+type debtService struct {
+	transactor      repository.Transactor
+	debtRepo        repository.DebtRepository
+	splitRepo       repository.SplitRepository
+	familyRepo      repository.FamilyRepository
+	notificationSvc NotificationService
+	emailSvc        EmailService
+}
+
+// NewDebtService создает новый экземпляр DebtService
+func NewDebtService(transactor repository.Transactor, debtRepo repository.DebtRepository,
+	splitRepo repository.SplitRepository, familyRepo repository.FamilyRepository,
+	notificationSvc NotificationService, emailSvc EmailService) DebtService {
+	return &debtService{
+		transactor:      transactor,
+		debtRepo:        debtRepo,
+		splitRepo:       splitRepo,
+		familyRepo:      familyRepo,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+	}
+}
+
+func (s *debtService) Summary(user *models.User, familyID uint) (*DebtSummary, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	balances, err := debtBalances(s.splitRepo, s.debtRepo, familyID)
+	if err != nil {
+		return nil, err
+	}
+	members := memberBalances(balances)
+	return &DebtSummary{Balances: balances, Members: members, SettleUp: settleUp(members)}, nil
+}
+
+func (s *debtService) List(user *models.User, familyID, memberID uint) ([]models.Debt, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	debts, err := s.debtRepo.ListByFamily(familyID, memberID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список долгов")
+	}
+	if debts == nil {
+		debts = []models.Debt{}
+	}
+	return debts, nil
+}
+
+func (s *debtService) Lend(user *models.User, familyID uint, input DebtInput) (*models.Debt, error) {
+	debt := &models.Debt{
+		FamilyID:   familyID,
+		Kind:       models.DebtLoan,
+		LenderID:   input.LenderID,
+		BorrowerID: input.BorrowerID,
+		Amount:     input.Amount,
+		Currency:   input.Currency,
+		Note:       input.Note,
+		CreatedBy:  user.ID,
+	}
+	family, members, err := s.prepare(user, debt, input.Date)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.debtRepo.Create(debt); err != nil {
+		return nil, errors.New("не удалось сохранить долг, попробуйте позже")
+	}
+	s.notify(user, family, members, debt)
+	return debt, nil
+}
+
+func (s *debtService) Settle(user *models.User, familyID uint, input SettleInput) (*models.Debt, error) {
+	debt := &models.Debt{
+		FamilyID:   familyID,
+		Kind:       models.DebtSettlement,
+		LenderID:   input.ToID,
+		BorrowerID: input.FromID,
+		Amount:     input.Amount,
+		Currency:   input.Currency,
+		Note:       input.Note,
+		CreatedBy:  user.ID,
+	}
+	family, members, err := s.prepare(user, debt, input.Date)
+	if err != nil {
+		return nil, err
+	}
+	// Строка семьи блокируется, чтобы два одновременных погашения не прошли одну и ту же проверку
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if _, err := repos.Families.Lock(familyID); err != nil {
+			return err
+		}
+		balances, err := debtBalances(repos.Splits, repos.Debts, familyID)
+		if err != nil {
+			return err
+		}
+		settleable := settleable(memberBalances(balances), debt.BorrowerID, debt.LenderID, debt.Currency)
+		if settleable <= 0 {
+			return ErrDebtNothingToSettle
+		}
+		if debt.Amount == 0 {
+			debt.Amount = settleable
+		}
+		if debt.Amount > settleable {
+			return ErrDebtSettleExceeds
+		}
+		return repos.Debts.Create(debt)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось сохранить погашение, попробуйте позже")
+	}
+	s.notify(user, family, members, debt)
+	return debt, nil
+}
+
+func (s *debtService) Delete(user *models.User, debtID uint) error {
+	debt, err := s.debtRepo.GetByID(debtID)
+	if err != nil {
+		return errors.New("не удалось получить запись о долге")
+	}
+	if debt == nil {
+		return ErrDebtNotFound
+	}
+	member, err := requireMember(s.familyRepo, debt.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return ErrDebtNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if member.Role != models.RoleOwner && debt.CreatedBy != user.ID {
+		return ErrDebtForbidden
+	}
+	if err := s.debtRepo.Delete(debt.ID); err != nil {
+		return errors.New("не удалось удалить запись о долге, попробуйте позже")
+	}
+	return nil
+}
+
+// prepare проверяет права и стороны записи, подставляет валюту и дату по умолчанию
+// и возвращает семью с участниками для уведомлений
+func (s *debtService) prepare(user *models.User, debt *models.Debt, date *time.Time) (*models.Family, []models.FamilyMember, error) {
+	member, err := requireMember(s.familyRepo, debt.FamilyID, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	party := user.ID == debt.LenderID || user.ID == debt.BorrowerID
+	if member.Role == models.RoleViewer || (!party && !member.Role.CanManage()) {
+		return nil, nil, ErrDebtForbidden
+	}
+	if debt.LenderID == debt.BorrowerID {
+		return nil, nil, ErrDebtSameParty
+	}
+	members, err := s.familyRepo.ListMembers(debt.FamilyID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить участников семьи")
+	}
+	found := 0
+	for _, m := range members {
+		if m.UserID == debt.LenderID || m.UserID == debt.BorrowerID {
+			found++
+		}
+	}
+	if found != 2 {
+		return nil, nil, ErrDebtPartyNotMember
+	}
+
+	family, err := s.familyRepo.GetByID(debt.FamilyID)
+	if err != nil || family == nil {
+		return nil, nil, errors.New("не удалось получить данные семьи")
+	}
+	if debt.Currency == "" {
+		debt.Currency = family.Currency
+	}
+	if date != nil {
+		debt.Date = *date
+	} else {
+		debt.Date = familyDate(family)
+	}
+	return family, members, nil
+}
+
+// debtBalances вычисляет итоговые долги по парам участников: разделённые расходы плюс займы минус погашения
+// Встречные долги пары и долги, образующие цикл, взаимно погашаются (см. cancelCycles)
+func debtBalances(splitRepo repository.SplitRepository, debtRepo repository.DebtRepository, familyID uint) ([]DebtBalance, error) {
+	fromSplits, err := splitRepo.Debts(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось вычислить долги")
+	}
+	fromDebts, err := debtRepo.Sums(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось вычислить долги")
+	}
+
+	type pair struct {
+		low, high uint
+		currency  string
+	}
+	// net сколько участник low должен участнику high
+	net := make(map[pair]int64)
+	for _, sum := range append(fromSplits, fromDebts...) {
+		if sum.BorrowerID < sum.LenderID {
+			net[pair{sum.BorrowerID, sum.LenderID, sum.Currency}] += sum.Total
+		} else {
+			net[pair{sum.LenderID, sum.BorrowerID, sum.Currency}] -= sum.Total
+		}
+	}
+	balances := make([]DebtBalance, 0, len(net))
+	for p, amount := range net {
+		switch {
+		case amount > 0:
+			balances = append(balances, DebtBalance{DebtorID: p.low, CreditorID: p.high, Currency: p.currency, Amount: amount})
+		case amount < 0:
+			balances = append(balances, DebtBalance{DebtorID: p.high, CreditorID: p.low, Currency: p.currency, Amount: -amount})
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		if a.DebtorID != b.DebtorID {
+			return a.DebtorID < b.DebtorID
+		}
+		return a.CreditorID < b.CreditorID
+	})
+	return cancelCycles(balances), nil
+}
+
+// cancelCycles взаимно погашает долги, образующие цикл: если A должен B, B должен C, а C должен A,
+// каждый долг цикла уменьшается на наименьший из них. Итоги участников при этом не меняются
+// Такой цикл появляется, например, после погашения по предложению SettleUp: A возвращает
+// долг сразу C, а не B, который должен C. balances должны быть отсортированы, как в debtBalances
+func cancelCycles(balances []DebtBalance) []DebtBalance {
+	type edge struct {
+		debtor, creditor uint
+		currency         string
+	}
+	amounts := make(map[edge]int64, len(balances))
+	graphs := make(map[string]map[uint][]uint)
+	var currencies []string
+	for _, b := range balances {
+		if graphs[b.Currency] == nil {
+			graphs[b.Currency] = make(map[uint][]uint)
+			currencies = append(currencies, b.Currency)
+		}
+		amounts[edge{b.DebtorID, b.CreditorID, b.Currency}] = b.Amount
+		graphs[b.Currency][b.DebtorID] = append(graphs[b.Currency][b.DebtorID], b.CreditorID)
+	}
+
+	for _, currency := range currencies {
+		graph := graphs[currency]
+		open := func(debtor, creditor uint) bool { return amounts[edge{debtor, creditor, currency}] > 0 }
+		for {
+			cycle := findCycle(graph, open)
+			if cycle == nil {
+				break
+			}
+			var least int64
+			for i, debtor := range cycle {
+				amount := amounts[edge{debtor, cycle[(i+1)%len(cycle)], currency}]
+				if least == 0 || amount < least {
+					least = amount
+				}
+			}
+			for i, debtor := range cycle {
+				amounts[edge{debtor, cycle[(i+1)%len(cycle)], currency}] -= least
+			}
+		}
+	}
+
+	result := make([]DebtBalance, 0, len(balances))
+	for _, b := range balances {
+		if amount := amounts[edge{b.DebtorID, b.CreditorID, b.Currency}]; amount > 0 {
+			b.Amount = amount
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// findCycle ищет цикл в графе долгов graph (должник -> кредиторы) по рёбрам, для которых open
+// возвращает true. Возвращает участников цикла по порядку или nil, если циклов нет
+func findCycle(graph map[uint][]uint, open func(debtor, creditor uint) bool) []uint {
+	debtors := make([]uint, 0, len(graph))
+	for debtor := range graph {
+		debtors = append(debtors, debtor)
+	}
+	sort.Slice(debtors, func(i, j int) bool { return debtors[i] < debtors[j] })
+
+	const (
+		unvisited = iota
+		inPath
+		done
+	)
+	state := make(map[uint]int)
+	var path []uint
+	var visit func(node uint) []uint
+	visit = func(node uint) []uint {
+		state[node] = inPath
+		path = append(path, node)
+		for _, next := range graph[node] {
+			if !open(node, next) {
+				continue
+			}
+			switch state[next] {
+			case inPath:
+				for i, n := range path {
+					if n == next {
+						return append([]uint(nil), path[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = done
+		return nil
+	}
+	for _, debtor := range debtors {
+		if state[debtor] == unvisited {
+			if cycle := visit(debtor); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// settleable возвращает, сколько debtor может вернуть creditor в валюте currency:
+// не больше, чем debtor должен всем, и не больше, чем должны creditor
+func settleable(members []MemberDebtBalance, debtor, creditor uint, currency string) int64 {
+	var owes, owed int64
+	for _, m := range members {
+		if m.Currency != currency {
+			continue
+		}
+		switch m.UserID {
+		case debtor:
+			owes = -m.Net
+		case creditor:
+			owed = m.Net
+		}
+	}
+	return min(owes, owed)
+}
+
+// owed возвращает, сколько debtor должен creditor в валюте currency; отрицательно, если наоборот
+func (s *debtService) owed(familyID, debtor, creditor uint, currency string) (int64, error) {
+	balances, err := debtBalances(s.splitRepo, s.debtRepo, familyID)
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range balances {
+		if b.Currency != currency {
+			continue
+		}
+		if b.DebtorID == debtor && b.CreditorID == creditor {
+			return b.Amount, nil
+		}
+		if b.DebtorID == creditor && b.CreditorID == debtor {
+			return -b.Amount, nil
+		}
+	}
+	return 0, nil
+}
+
+// memberBalances суммирует долги по участникам и валютам
+func memberBalances(balances []DebtBalance) []MemberDebtBalance {
+	type key struct {
+		userID   uint
+		currency string
+	}
+	net := make(map[key]int64)
+	for _, b := range balances {
+		net[key{b.CreditorID, b.Currency}] += b.Amount
+		net[key{b.DebtorID, b.Currency}] -= b.Amount
+	}
+	members := make([]MemberDebtBalance, 0, len(net))
+	for k, amount := range net {
+		if amount != 0 {
+			members = append(members, MemberDebtBalance{UserID: k.userID, Currency: k.currency, Net: amount})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Currency != members[j].Currency {
+			return members[i].Currency < members[j].Currency
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members
+}
+
+// settleUp предлагает переводы, которые погашают все долги, отдельно по каждой валюте
+// Сначала сводятся должники и кредиторы с одинаковыми суммами — каждая такая пара
+// закрывается одним переводом; остальные долги гасятся жадно: наибольший должник платит
+// наибольшему кредитору. Переводов получается не больше, чем участников с долгами, минус один
+func settleUp(members []MemberDebtBalance) []SettlementSuggestion {
+	type side struct {
+		userID uint
+		amount int64
+	}
+	byCurrency := make(map[string][2][]side)
+	var currencies []string
+	for _, m := range members {
+		sides, ok := byCurrency[m.Currency]
+		if !ok {
+			currencies = append(currencies, m.Currency)
+		}
+		if m.Net < 0 {
+			sides[0] = append(sides[0], side{m.UserID, -m.Net})
+		} else {
+			sides[1] = append(sides[1], side{m.UserID, m.Net})
+		}
+		byCurrency[m.Currency] = sides
+	}
+	sort.Strings(currencies)
+
+	bySize := func(list []side) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].amount != list[j].amount {
+				return list[i].amount > list[j].amount
+			}
+			return list[i].userID < list[j].userID
+		})
+	}
+	suggestions := []SettlementSuggestion{}
+	for _, currency := range currencies {
+		debtors, creditors := byCurrency[currency][0], byCurrency[currency][1]
+		bySize(debtors)
+		bySize(creditors)
+
+		// Пары с одинаковыми суммами
+		for i := range debtors {
+			for j := range creditors {
+				if creditors[j].amount == debtors[i].amount && debtors[i].amount > 0 {
+					suggestions = append(suggestions, SettlementSuggestion{
+						FromID: debtors[i].userID, ToID: creditors[j].userID, Currency: currency, Amount: debtors[i].amount,
+					})
+					debtors[i].amount, creditors[j].amount = 0, 0
+					break
+				}
+			}
+		}
+
+		for {
+			bySize(debtors)
+			bySize(creditors)
+			if len(debtors) == 0 || len(creditors) == 0 || debtors[0].amount == 0 || creditors[0].amount == 0 {
+				break
+			}
+			amount := min(debtors[0].amount, creditors[0].amount)
+			suggestions = append(suggestions, SettlementSuggestion{
+				FromID: debtors[0].userID, ToID: creditors[0].userID, Currency: currency, Amount: amount,
+			})
+			debtors[0].amount -= amount
+			creditors[0].amount -= amount
+		}
+	}
+	return suggestions
+}
+
+// notify уведомляет кредитора и должника о записи; автор записи тоже получает уведомление,
+// если он одна из сторон. Ошибки доставки только записываются в журнал
+func (s *debtService) notify(author *models.User, family *models.Family, members []models.FamilyMember, debt *models.Debt) {
+	names := make(map[uint]string, len(members))
+	recipients := make([]*models.User, 0, 2)
+	for i := range members {
+		m := &members[i]
+		if m.User == nil {
+			continue
+		}
+		names[m.UserID] = m.User.Name
+		if m.UserID == debt.LenderID || m.UserID == debt.BorrowerID {
+			recipients = append(recipients, m.User)
+		}
+	}
+
+	data := mail.DebtRecordedData{
+		FamilyName:   family.Name,
+		AuthorName:   author.Name,
+		Kind:         string(debt.Kind),
+		LenderName:   names[debt.LenderID],
+		BorrowerName: names[debt.BorrowerID],
+		Amount:       debt.Amount,
+		Currency:     debt.Currency,
+		Date:         debt.Date,
+		Note:         debt.Note,
+		DebtorName:   names[debt.BorrowerID],
+		CreditorName: names[debt.LenderID],
+	}
+	owed, err := s.owed(family.ID, debt.BorrowerID, debt.LenderID, debt.Currency)
+	if err != nil {
+		log.Printf("debts: не удалось вычислить долг после записи %d: %v", debt.ID, err)
+	}
+	data.Balance = owed
+	if owed < 0 {
+		data.DebtorName, data.CreditorName, data.Balance = data.CreditorName, data.DebtorName, -owed
+	}
+
+	title, body := debtText(family.Locale, data)
+	for _, recipient := range recipients {
+		familyID, debtID := family.ID, debt.ID
+		notification := &models.Notification{
+			FamilyID:   &familyID,
+			Type:       models.NotificationDebtRecorded,
+			Title:      title,
+			Body:       body,
+			ObjectType: "debt",
+			ObjectID:   &debtID,
+		}
+		err := s.notificationSvc.Deliver(recipient, notification, func(to string) error {
+			return s.emailSvc.SendDebtRecorded(to, data)
+		})
+		if err != nil {
+			log.Printf("debts: не удалось уведомить пользователя %d о записи %d: %v", recipient.ID, debt.ID, err)
+		}
+	}
+}
+
+// debtText формирует заголовок и текст уведомления о долге на языке семьи
+func debtText(locale string, data mail.DebtRecordedData) (string, string) {
+	amount := money.Display(data.Amount, data.Currency, locale)
+	balance := money.Display(data.Balance, data.Currency, locale)
+	if locale == "en" {
+		title := fmt.Sprintf("%s lent %s %s", data.LenderName, data.BorrowerName, amount)
+		if data.Kind == string(models.DebtSettlement) {
+			title = fmt.Sprintf("%s paid back %s %s", data.BorrowerName, data.LenderName, amount)
+		}
+		body := "No debts left between you in this currency."
+		if data.Balance > 0 {
+			body = fmt.Sprintf("%s now owes %s %s.", data.DebtorName, data.CreditorName, balance)
+		}
+		return title, body
+	}
+	title := fmt.Sprintf("%s дал(а) в долг %s %s", data.LenderName, data.BorrowerName, amount)
+	if data.Kind == string(models.DebtSettlement) {
+		title = fmt.Sprintf("%s вернул(а) %s %s", data.BorrowerName, data.LenderName, amount)
+	}
+	body := "Взаимных долгов в этой валюте больше нет."
+	if data.Balance > 0 {
+		body = fmt.Sprintf("Теперь %s должен(а) %s %s.", data.DebtorName, data.CreditorName, balance)
+	}
+	return title, body
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+func TestSettleUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []MemberDebtBalance
+		want    []SettlementSuggestion
+	}{
+		{
+			name: "без долгов",
+			want: []SettlementSuggestion{},
+		},
+		{
+			name: "цепочка сводится к одному переводу",
+			members: []MemberDebtBalance{
+				{UserID: 1, Currency: "RUB", Net: -100},
+				{UserID: 3, Currency: "RUB", Net: 100},
+			},
+			want: []SettlementSuggestion{{FromID: 1, ToID: 3, Currency: "RUB", Amount: 100}},
+		},
+		{
+			name: "равные суммы сводятся в пары",
+			members: []MemberDebtBalance{
+				{UserID: 1, Currency: "RUB", Net: -300},
+				{UserID: 2, Currency: "RUB", Net: -200},
+				{UserID: 3, Currency: "RUB", Net: 200},
+				{UserID: 4, Currency: "RUB", Net: 300},
+			},
+			want: []SettlementSuggestion{
+				{FromID: 1, ToID: 4, Currency: "RUB", Amount: 300},
+				{FromID: 2, ToID: 3, Currency: "RUB", Amount: 200},
+			},
+		},
+		{
+			name: "наибольший должник платит наибольшему кредитору",
+			members: []MemberDebtBalance{
+				{UserID: 1, Currency: "RUB", Net: -500},
+				{UserID: 2, Currency: "RUB", Net: -100},
+				{UserID: 3, Currency: "RUB", Net: 400},
+				{UserID: 4, Currency: "RUB", Net: 200},
+			},
+			want: []SettlementSuggestion{
+				{FromID: 1, ToID: 3, Currency: "RUB", Amount: 400},
+				{FromID: 1, ToID: 4, Currency: "RUB", Amount: 100},
+				{FromID: 2, ToID: 4, Currency: "RUB", Amount: 100},
+			},
+		},
+		{
+			name: "валюты не смешиваются",
+			members: []MemberDebtBalance{
+				{UserID: 1, Currency: "EUR", Net: 50},
+				{UserID: 2, Currency: "EUR", Net: -50},
+				{UserID: 1, Currency: "RUB", Net: -70},
+				{UserID: 2, Currency: "RUB", Net: 70},
+			},
+			want: []SettlementSuggestion{
+				{FromID: 2, ToID: 1, Currency: "EUR", Amount: 50},
+				{FromID: 1, ToID: 2, Currency: "RUB", Amount: 70},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settleUp(tt.members)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("settleUp = %+v, want %+v", got, tt.want)
+			}
+			// Переводы должны обнулять итог каждого участника
+			type key struct {
+				userID   uint
+				currency string
+			}
+			net := make(map[key]int64)
+			for _, m := range tt.members {
+				net[key{m.UserID, m.Currency}] += m.Net
+			}
+			for _, s := range got {
+				net[key{s.FromID, s.Currency}] += s.Amount
+				net[key{s.ToID, s.Currency}] -= s.Amount
+			}
+			for k, amount := range net {
+				if amount != 0 {
+					t.Errorf("after settle up %+v net = %d, want 0", k, amount)
+				}
+			}
+		})
+	}
+}
+
+func TestCancelCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []DebtBalance
+		want     []DebtBalance
+	}{
+		{
+			name: "цепочка без цикла не меняется",
+			balances: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "RUB", Amount: 100},
+				{DebtorID: 2, CreditorID: 3, Currency: "RUB", Amount: 100},
+			},
+			want: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "RUB", Amount: 100},
+				{DebtorID: 2, CreditorID: 3, Currency: "RUB", Amount: 100},
+			},
+		},
+		{
+			name: "равный цикл погашается полностью",
+			balances: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "RUB", Amount: 100},
+				{DebtorID: 2, CreditorID: 3, Currency: "RUB", Amount: 100},
+				{DebtorID: 3, CreditorID: 1, Currency: "RUB", Amount: 100},
+			},
+			want: []DebtBalance{},
+		},
+		{
+			name: "цикл уменьшается на наименьший долг",
+			balances: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "RUB", Amount: 300},
+				{DebtorID: 2, CreditorID: 3, Currency: "RUB", Amount: 100},
+				{DebtorID: 3, CreditorID: 1, Currency: "RUB", Amount: 200},
+			},
+			want: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "RUB", Amount: 200},
+				{DebtorID: 3, CreditorID: 1, Currency: "RUB", Amount: 100},
+			},
+		},
+		{
+			name: "в разных валютах цикла нет",
+			balances: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "EUR", Amount: 10},
+				{DebtorID: 2, CreditorID: 1, Currency: "RUB", Amount: 10},
+			},
+			want: []DebtBalance{
+				{DebtorID: 1, CreditorID: 2, Currency: "EUR", Amount: 10},
+				{DebtorID: 2, CreditorID: 1, Currency: "RUB", Amount: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cancelCycles(tt.balances)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cancelCycles = %+v, want %+v", got, tt.want)
+			}
+			if before, after := memberBalances(tt.balances), memberBalances(got); !reflect.DeepEqual(before, after) {
+				t.Errorf("member balances changed: %+v, want %+v", after, before)
+			}
+		})
+	}
+}
+
+type memDebts struct {
+	repository.DebtRepository
+	debts []models.Debt
+}
+
+func (r *memDebts) Create(debt *models.Debt) error {
+	debt.ID = uint(len(r.debts) + 1)
+	r.debts = append(r.debts, *debt)
+	return nil
+}
+
+func (r *memDebts) Sums(uint) ([]repository.DebtSum, error) {
+	var sums []repository.DebtSum
+	for _, d := range r.debts {
+		total := d.Amount
+		if d.Kind == models.DebtSettlement {
+			total = -total
+		}
+		sums = append(sums, repository.DebtSum{LenderID: d.LenderID, BorrowerID: d.BorrowerID, Currency: d.Currency, Total: total})
+	}
+	return sums, nil
+}
+
+type noSplitDebts struct{ repository.SplitRepository }
+
+func (noSplitDebts) Debts(uint) ([]repository.DebtSum, error) { return nil, nil }
+
+func (r memFamilies) Lock(id uint) (*models.Family, error) {
+	return r.GetByID(id)
+}
+
+// newDebtFixture семья из трёх взрослых: 1 должен 2 сто рублей, а 2 должен 3 сто рублей
+func newDebtFixture() (*debtService, *memDebts) {
+	m := &memStore{family: models.Family{ID: 1, Name: "Ивановы", Currency: "RUB", Timezone: "UTC", Locale: "ru"}}
+	for id := uint(1); id <= 3; id++ {
+		m.members = append(m.members, models.FamilyMember{FamilyID: 1, UserID: id, Role: models.RoleAdult})
+	}
+	debts := &memDebts{debts: []models.Debt{
+		{FamilyID: 1, Kind: models.DebtLoan, LenderID: 2, BorrowerID: 1, Amount: 10000, Currency: "RUB"},
+		{FamilyID: 1, Kind: models.DebtLoan, LenderID: 3, BorrowerID: 2, Amount: 10000, Currency: "RUB"},
+	}}
+	repos := &repository.Repositories{Families: memFamilies{m: m}, Debts: debts, Splits: noSplitDebts{}}
+	svc := &debtService{
+		transactor:      memTransactor{repos: repos},
+		debtRepo:        debts,
+		splitRepo:       repos.Splits,
+		familyRepo:      repos.Families,
+		notificationSvc: noNotifications{},
+	}
+	return svc, debts
+}
+
+func TestSettleSuggestedTransfer(t *testing.T) {
+	svc, _ := newDebtFixture()
+	user := &models.User{ID: 1}
+
+	summary, err := svc.Summary(user, 1)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	want := []SettlementSuggestion{{FromID: 1, ToID: 3, Currency: "RUB", Amount: 10000}}
+	if !reflect.DeepEqual(summary.SettleUp, want) {
+		t.Fatalf("SettleUp = %+v, want %+v", summary.SettleUp, want)
+	}
+
+	debt, err := svc.Settle(user, 1, SettleInput{FromID: 1, ToID: 3})
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+	if debt.Amount != 10000 {
+		t.Errorf("settled amount = %d, want 10000", debt.Amount)
+	}
+
+	summary, err = svc.Summary(user, 1)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if len(summary.Balances) != 0 || len(summary.Members) != 0 || len(summary.SettleUp) != 0 {
+		t.Errorf("summary after settlement = %+v, want no debts", summary)
+	}
+}
+
+func TestSettleLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input SettleInput
+		want  error
+	}{
+		{name: "больше долга", input: SettleInput{FromID: 1, ToID: 3, Amount: 10001}, want: ErrDebtSettleExceeds},
+		{name: "должник никому не должен", input: SettleInput{FromID: 3, ToID: 2}, want: ErrDebtNothingToSettle},
+		{name: "получателю никто не должен", input: SettleInput{FromID: 1, ToID: 2}, want: ErrDebtNothingToSettle},
+		{name: "другая валюта", input: SettleInput{FromID: 1, ToID: 3, Currency: "EUR"}, want: ErrDebtNothingToSettle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, debts := newDebtFixture()
+			if _, err := svc.Settle(&models.User{ID: 1}, 1, tt.input); err != tt.want {
+				t.Errorf("Settle error = %v, want %v", err, tt.want)
+			}
+			if len(debts.debts) != 2 {
+				t.Errorf("records = %d, want 2", len(debts.debts))
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// defaultCategory категория из набора по умолчанию с названиями на поддерживаемых языках
+type defaultCategory struct {
+	ru, en   string
+	icon     string
+	color    string
+	children []defaultCategory
+}
+
+// defaultExpenseCategories набор категорий расходов новой семьи
+var defaultExpenseCategories = []defaultCategory{
+	{ru: "Продукты", en: "Groceries", icon: "cart", color: "#4CAF50"},
+	{ru: "Кафе и рестораны", en: "Eating out", icon: "restaurant", color: "#FF9800"},
+	{ru: "Транспорт", en: "Transport", icon: "car", color: "#2196F3", children: []defaultCategory{
+		{ru: "Общественный транспорт", en: "Public transport", icon: "bus", color: "#2196F3"},
+		{ru: "Такси", en: "Taxi", icon: "taxi", color: "#2196F3"},
+		{ru: "Топливо", en: "Fuel", icon: "fuel", color: "#2196F3"},
+		{ru: "Парковка", en: "Parking", icon: "parking", color: "#2196F3"},
+	}},
+	{ru: "Жильё", en: "Housing", icon: "home", color: "#795548", children: []defaultCategory{
+		{ru: "Аренда", en: "Rent", icon: "key", color: "#795548"},
+		{ru: "Коммунальные услуги", en: "Utilities", icon: "bulb", color: "#795548"},
+		{ru: "Интернет и связь", en: "Internet and phone", icon: "wifi", color: "#795548"},
+		{ru: "Ремонт", en: "Repairs", icon: "tools", color: "#795548"},
+	}},
+	{ru: "Здоровье", en: "Health", icon: "heart", color: "#F44336"},
+	{ru: "Одежда и обувь", en: "Clothing", icon: "shirt", color: "#9C27B0"},
+	{ru: "Дети", en: "Children", icon: "child", color: "#FFC107"},
+	{ru: "Развлечения", en: "Entertainment", icon: "ticket", color: "#E91E63"},
+	{ru: "Подарки", en: "Gifts", icon: "gift", color: "#00BCD4"},
+	{ru: "Прочие расходы", en: "Other expenses", icon: "dots", color: "#9E9E9E"},
+}
+
+// defaultIncomeCategories набор категорий доходов новой семьи
+var defaultIncomeCategories = []defaultCategory{
+	{ru: "Зарплата", en: "Salary", icon: "briefcase", color: "#4CAF50"},
+	{ru: "Премии", en: "Bonuses", icon: "star", color: "#8BC34A"},
+	{ru: "Подработка", en: "Side income", icon: "laptop", color: "#009688"},
+	{ru: "Проценты и кэшбэк", en: "Interest and cashback", icon: "percent", color: "#3F51B5"},
+	{ru: "Подарки", en: "Gifts", icon: "gift", color: "#00BCD4"},
+	{ru: "Прочие доходы", en: "Other income", icon: "dots", color: "#9E9E9E"},
+}
+
+// name возвращает название категории на языке семьи; неизвестный язык считается русским
+func (c defaultCategory) name(locale string) string {
+	if locale == "en" {
+		return c.en
+	}
+	return c.ru
+}
+
+// seedCategories создает в семье набор категорий по умолчанию на языке семьи
+// Вызывайте внутри Transactor.Transaction, чтобы набор создался целиком
+func seedCategories(repos *repository.Repositories, familyID uint, locale string) error {
+	var parents []models.Category
+	var defaults []defaultCategory
+	for _, group := range []struct {
+		kind models.Direction
+		list []defaultCategory
+	}{
+		{models.DirectionExpense, defaultExpenseCategories},
+		{models.DirectionIncome, defaultIncomeCategories},
+	} {
+		for i, c := range group.list {
+			parents = append(parents, models.Category{
+				FamilyID:  familyID,
+				Kind:      group.kind,
+				Name:      c.name(locale),
+				Icon:      c.icon,
+				Color:     c.color,
+				SortOrder: i + 1,
+			})
+			defaults = append(defaults, c)
+		}
+	}
+	if err := repos.Categories.CreateBatch(parents); err != nil {
+		return err
+	}
+
+	var children []models.Category
+	for i, parent := range parents {
+		for j, c := range defaults[i].children {
+			parentID := parent.ID
+			children = append(children, models.Category{
+				FamilyID:  familyID,
+				ParentID:  &parentID,
+				Kind:      parent.Kind,
+				Name:      c.name(locale),
+				Icon:      c.icon,
+				Color:     c.color,
+				SortOrder: j + 1,
+			})
+		}
+	}
+	return repos.Categories.CreateBatch(children)
+}
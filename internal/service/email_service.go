@@ -1,68 +1,110 @@
 package service
 
 import (
-	"crypto/tls"
 	"fmt"
-	"net/smtp"
 
-	"family_finance_back/config"
-
-	"github.com/jordan-wright/email"
+	"family_finance_back/internal/mail"
 )
 
 // EmailService определяет интерфейс для отправки email-сообщений
 type EmailService interface {
 	// SendCode отправляет код подтверждения на указанный email
-	// Использует SMTP для отправки сообщения
+	// Письмо сохраняется в очередь отправки и доставляется асинхронно
+	// Возвращает ошибку, обёрнутую в ErrOutboxUnavailable, если очередь не приняла письмо
 	SendCode(to, code string) error
+
+	// SendFamilyInvite отправляет приглашение в семью со ссылкой для принятия
+	SendFamilyInvite(to string, data mail.FamilyInviteData) error
+
+	// SendBudgetAlert отправляет предупреждение о достижении порога бюджета
+	SendBudgetAlert(to string, data mail.BudgetAlertData) error
+
+	// SendBillReminder отправляет напоминание о сроке оплаты счёта
+	SendBillReminder(to string, data mail.BillReminderData) error
+
+	// SendDebtRecorded отправляет уведомление о записанном займе или погашении долга
+	SendDebtRecorded(to string, data mail.DebtRecordedData) error
+
+	// SendApprovalRequested отправляет уведомление о расходе, ожидающем одобрения
+	SendApprovalRequested(to string, data mail.ApprovalRequestedData) error
+
+	// SendApprovalDecided отправляет автору расхода уведомление о решении по нему
+	SendApprovalDecided(to string, data mail.ApprovalDecidedData) error
 }
 
 // emailService реализует интерфейс EmailService
 type emailService struct {
-	cfg config.Config
+	outbox   OutboxService
+	renderer *mail.Renderer
+	from     string
+	locale   string
 }
 
 // NewEmailService создает новый экземпляр EmailService
-func NewEmailService(cfg config.Config) EmailService {
-	return &emailService{cfg: cfg}
+// Письма отрисовываются из шаблонов на языке locale и ставятся в очередь outbox
+func NewEmailService(outbox OutboxService, renderer *mail.Renderer, from, locale string) EmailService {
+	return &emailService{outbox: outbox, renderer: renderer, from: from, locale: locale}
 }
 
-// SendCode отправляет код подтверждения на указанный email
-// Использует SMTP с TLS для безопасной отправки
-func (s *emailService) SendCode(to, code string) error {
-	e := email.NewEmail()
-	e.From = s.cfg.SMTPUsername
-	e.To = []string{to}
-	e.Subject = "Ваш код авторизации"
-	e.Text = []byte(fmt.Sprintf("Ваш код: %s\nКод действителен 90 секунд", code))
-
-	auth := smtp.PlainAuth("", s.cfg.SMTPUsername, s.cfg.SMTPPassword, s.cfg.SMTPHost)
-
-	// Устанавливаем TLS-конфигурацию
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: false,
-		ServerName:         s.cfg.SMTPHost,
-	}
-
-	// Устанавливаем соединение с SMTP-сервером
-	addr := fmt.Sprintf("%s:%d", s.cfg.SMTPHost, s.cfg.SMTPPort)
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+// send отрисовывает шаблон и ставит письмо в очередь отправки
+func (s *emailService) send(to, template string, data interface{}) error {
+	msg, err := s.compose(to, template, data)
 	if err != nil {
 		return err
 	}
-	c, err := smtp.NewClient(conn, s.cfg.SMTPHost)
+	return s.outbox.Enqueue(template, msg)
+}
+
+// compose отрисовывает шаблон в письмо
+func (s *emailService) compose(to, template string, data interface{}) (*mail.Message, error) {
+	content, err := s.renderer.Render(template, s.locale, data)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: %v", ErrOutboxUnavailable, err)
 	}
-	defer c.Quit()
+	return mail.NewMessage(s.from, []string{to}, content.Subject, content.Text, content.HTML), nil
+}
 
-	if err = c.Auth(auth); err != nil {
+// SendCode отправляет код подтверждения на указанный email
+// Письмо не доставляется после истечения кода
+func (s *emailService) SendCode(to, code string) error {
+	msg, err := s.compose(to, mail.TemplateVerificationCode, mail.VerificationCodeData{
+		Code:       code,
+		TTLSeconds: int(codeTTL.Seconds()),
+	})
+	if err != nil {
 		return err
 	}
+	expiresAt := msg.CreatedAt.Add(codeTTL)
+	msg.ExpiresAt = &expiresAt
+	return s.outbox.Enqueue(mail.TemplateVerificationCode, msg)
+}
 
-	if err = e.SendWithTLS(addr, auth, tlsConfig); err != nil {
-		return err
-	}
+// SendFamilyInvite отправляет приглашение в семью
+func (s *emailService) SendFamilyInvite(to string, data mail.FamilyInviteData) error {
+	return s.send(to, mail.TemplateFamilyInvite, data)
+}
+
+// SendBudgetAlert отправляет предупреждение о достижении порога бюджета
+func (s *emailService) SendBudgetAlert(to string, data mail.BudgetAlertData) error {
+	return s.send(to, mail.TemplateBudgetAlert, data)
+}
+
+// SendBillReminder отправляет напоминание о сроке оплаты счёта
+func (s *emailService) SendBillReminder(to string, data mail.BillReminderData) error {
+	return s.send(to, mail.TemplateBillReminder, data)
+}
+
+// SendDebtRecorded отправляет уведомление о записанном займе или погашении долга
+func (s *emailService) SendDebtRecorded(to string, data mail.DebtRecordedData) error {
+	return s.send(to, mail.TemplateDebtRecorded, data)
+}
+
+// SendApprovalRequested отправляет уведомление о расходе, ожидающем одобрения
+func (s *emailService) SendApprovalRequested(to string, data mail.ApprovalRequestedData) error {
+	return s.send(to, mail.TemplateApprovalRequested, data)
+}
 
-	return nil
+// SendApprovalDecided отправляет автору расхода уведомление о решении по нему
+func (s *emailService) SendApprovalDecided(to string, data mail.ApprovalDecidedData) error {
+	return s.send(to, mail.TemplateApprovalDecided, data)
 }
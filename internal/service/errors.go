@@ -0,0 +1,31 @@
+package service
+
+// ErrorKind классифицирует ошибки бизнес-логики, чтобы обработчики
+// могли выбрать подходящий HTTP-статус
+type ErrorKind int
+
+const (
+	// KindInvalid некорректные данные запроса
+	KindInvalid ErrorKind = iota + 1
+	// KindNotFound объект не найден или недоступен пользователю
+	KindNotFound
+	// KindForbidden у пользователя недостаточно прав
+	KindForbidden
+	// KindConflict действие противоречит текущему состоянию объекта
+	KindConflict
+)
+
+// Error ошибка бизнес-логики с сообщением для пользователя
+type Error struct {
+	Kind    ErrorKind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError создает ошибку бизнес-логики указанного вида
+func newError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
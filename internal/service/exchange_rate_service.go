@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+// RateImportResult итог загрузки курсов валют
+type RateImportResult struct {
+	// Imported количество сохранённых курсов
+	Imported int `json:"imported"`
+	// Skipped коды валют, которых нет в справочнике; их курсы не загружены
+	Skipped []string `json:"skipped"`
+	// From и To даты первого и последнего сохранённого курса
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+// ExchangeRateService определяет интерфейс для работы с курсами валют
+type ExchangeRateService interface {
+	// Currencies возвращает справочник валют ISO 4217
+	Currencies() []money.Currency
+
+	// Effective возвращает курсы, действующие на дату date;
+	// если currency не пуста — только курсы пар с этой валютой
+	Effective(date time.Time, currency string) ([]models.ExchangeRate, error)
+
+	// Import сохраняет курсы из источника source; курс той же пары на ту же дату заменяется
+	// Курсы валют, которых нет в справочнике, пропускаются
+	Import(rates []models.ExchangeRate, source string) (*RateImportResult, error)
+
+	// Table загружает курсы между валютами currencies, действующие в периоде [from, to]
+	Table(currencies []string, from, to time.Time) (*RateTable, error)
+}
+
+// exchangeRateService реализует интерфейс ExchangeRateService
+type exchangeRateService struct {
+	rateRepo repository.ExchangeRateRepository
+	pivot    string
+	maxAge   int
+}
+
+// NewExchangeRateService создает новый экземпляр ExchangeRateService
+// Курсы между валютами без прямой котировки вычисляются через валюту pivot;
+// курс считается действующим не дольше maxAgeDays дней после даты котировки
+func NewExchangeRateService(rateRepo repository.ExchangeRateRepository, pivot string, maxAgeDays int) ExchangeRateService {
+	return &exchangeRateService{rateRepo: rateRepo, pivot: pivot, maxAge: maxAgeDays}
+}
+
+func (s *exchangeRateService) Currencies() []money.Currency {
+	return money.Currencies()
+}
+
+func (s *exchangeRateService) Effective(date time.Time, currency string) ([]models.ExchangeRate, error) {
+	rates, err := s.rateRepo.Effective(dateOnly(date), currency)
+	if err != nil {
+		return nil, errors.New("не удалось получить курсы валют")
+	}
+	if rates == nil {
+		rates = []models.ExchangeRate{}
+	}
+	return rates, nil
+}
+
+func (s *exchangeRateService) Import(rates []models.ExchangeRate, source string) (*RateImportResult, error) {
+	type key struct {
+		base, quote string
+		date        time.Time
+	}
+	result := &RateImportResult{Skipped: []string{}}
+	skipped := make(map[string]bool)
+	// Повтор пары на ту же дату в одном файле заменяет предыдущее значение
+	unique := make(map[key]int, len(rates))
+	list := make([]models.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		known := true
+		for _, code := range []string{rate.Base, rate.Quote} {
+			if cur, ok := money.LookupCurrency(code); !ok || cur.Code != code {
+				known = false
+				if !skipped[code] {
+					skipped[code] = true
+					result.Skipped = append(result.Skipped, code)
+				}
+			}
+		}
+		if !known || rate.Base == rate.Quote {
+			continue
+		}
+		rate.ID = 0
+		rate.Date = dateOnly(rate.Date)
+		rate.Source = source
+		k := key{rate.Base, rate.Quote, rate.Date}
+		if i, ok := unique[k]; ok {
+			list[i] = rate
+			continue
+		}
+		unique[k] = len(list)
+		list = append(list, rate)
+
+		date := rate.Date
+		if result.From == nil || date.Before(*result.From) {
+			result.From = &date
+		}
+		if result.To == nil || date.After(*result.To) {
+			result.To = &date
+		}
+	}
+	sort.Strings(result.Skipped)
+	if err := s.rateRepo.Upsert(list); err != nil {
+		return nil, errors.New("не удалось сохранить курсы валют")
+	}
+	result.Imported = len(list)
+	return result, nil
+}
+
+func (s *exchangeRateService) Table(currencies []string, from, to time.Time) (*RateTable, error) {
+	codes := append([]string{s.pivot}, currencies...)
+	rates, err := s.rateRepo.List(codes, dateOnly(from).AddDate(0, 0, -s.maxAge), dateOnly(to))
+	if err != nil {
+		return nil, errors.New("не удалось получить курсы валют")
+	}
+	table := &RateTable{pivot: s.pivot, maxAge: s.maxAge, pairs: make(map[[2]string][]datedRate)}
+	for _, rate := range rates {
+		value, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok || value.Sign() <= 0 {
+			continue
+		}
+		pair := [2]string{rate.Base, rate.Quote}
+		table.pairs[pair] = append(table.pairs[pair], datedRate{date: dateOnly(rate.Date), rate: value})
+	}
+	return table, nil
+}
+
+// datedRate курс пары валют, действующий с даты date
+type datedRate struct {
+	date time.Time
+	rate *big.Rat
+}
+
+// RateTable курсы валют за период для пересчёта сумм на дату операции
+// Курс пары ищется напрямую, по обратной котировке или через опорную валюту
+type RateTable struct {
+	pivot  string
+	maxAge int
+	// pairs курсы пар [base, quote] в порядке даты
+	pairs map[[2]string][]datedRate
+}
+
+// Rate возвращает курс: сколько единиц валюты to стоит одна единица from на дату date
+// Возвращает false, если действующего курса нет
+func (t *RateTable) Rate(from, to string, date time.Time) (*big.Rat, bool) {
+	if from == to {
+		return big.NewRat(1, 1), true
+	}
+	date = dateOnly(date)
+	if rate, ok := t.quote(from, to, date); ok {
+		return rate, true
+	}
+	if from == t.pivot || to == t.pivot {
+		return nil, false
+	}
+	first, ok := t.quote(from, t.pivot, date)
+	if !ok {
+		return nil, false
+	}
+	second, ok := t.quote(t.pivot, to, date)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).Mul(first, second), true
+}
+
+// Convert пересчитывает сумму в минимальных единицах валюты from в валюту to по курсу на дату date
+// Возвращает false, если действующего курса нет
+func (t *RateTable) Convert(amount int64, from, to string, date time.Time) (int64, bool) {
+	rate, ok := t.Rate(from, to, date)
+	if !ok {
+		return 0, false
+	}
+	converted, err := money.Convert(amount, from, to, rate)
+	if err != nil {
+		return 0, false
+	}
+	return converted, true
+}
+
+// quote ищет прямую или обратную котировку пары на дату date
+func (t *RateTable) quote(base, quote string, date time.Time) (*big.Rat, bool) {
+	if rate, ok := t.latest([2]string{base, quote}, date); ok {
+		return rate, true
+	}
+	if rate, ok := t.latest([2]string{quote, base}, date); ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
+
+// latest возвращает последний курс пары не позже date, если он не старше maxAge дней
+func (t *RateTable) latest(pair [2]string, date time.Time) (*big.Rat, bool) {
+	rates := t.pairs[pair]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].date.After(date) })
+	if i == 0 {
+		return nil, false
+	}
+	found := rates[i-1]
+	if found.date.AddDate(0, 0, t.maxAge).Before(date) {
+		return nil, false
+	}
+	return found.rate, true
+}
+
+// formatRate форматирует курс с точностью хранения курсов (10 знаков после запятой)
+func formatRate(rate *big.Rat) string {
+	return strings.TrimRight(strings.TrimRight(rate.FloatString(10), "0"), ".")
+}
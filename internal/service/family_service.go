@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrFamilyNotFound возвращается, если семья не существует или пользователь в ней не состоит
+	ErrFamilyNotFound = newError(KindNotFound, "семья не найдена")
+
+	// ErrFamilyForbidden возвращается, если роли пользователя недостаточно для действия
+	ErrFamilyForbidden = newError(KindForbidden, "недостаточно прав для этого действия в семье")
+
+	// ErrFamilyMemberNotFound возвращается, если указанный пользователь не состоит в семье
+	ErrFamilyMemberNotFound = newError(KindNotFound, "участник семьи не найден")
+
+	// ErrLastOwner возвращается, если действие оставит семью без владельца
+	ErrLastOwner = newError(KindConflict, "в семье должен остаться хотя бы один владелец: сначала назначьте владельцем другого участника")
+)
+
+// FamilyService определяет интерфейс для работы с семьями
+type FamilyService interface {
+	// Create создает семью; создатель становится её владельцем
+	// Вместе с семьей создается набор категорий по умолчанию на языке locale
+	Create(user *models.User, name, currency, timezone, locale string) (*models.Family, error)
+
+	// Rename переименовывает семью; доступно только владельцу
+	Rename(user *models.User, familyID uint, name string) (*models.Family, error)
+
+	// SetCurrency задаёт основную валюту семьи, в которую пересчитываются итоги; доступно только владельцу
+	SetCurrency(user *models.User, familyID uint, currency string) (*models.Family, error)
+
+	// ListMine возвращает семьи пользователя вместе с его ролью в каждой
+	ListMine(user *models.User) ([]models.FamilyMember, error)
+
+	// Members возвращает участников семьи; доступно любому участнику
+	Members(user *models.User, familyID uint) ([]models.FamilyMember, error)
+
+	// ChangeRole изменяет роль участника; доступно только владельцу
+	ChangeRole(user *models.User, familyID, memberID uint, role models.FamilyRole) error
+
+	// Leave выводит пользователя из семьи
+	// Последний участник удаляет семью; последний владелец не может выйти,
+	// пока в семье есть другие участники
+	Leave(user *models.User, familyID uint) error
+}
+
+// familyService реализует интерфейс FamilyService
+type familyService struct {
+	transactor repository.Transactor
+	familyRepo repository.FamilyRepository
+}
+
+// NewFamilyService создает новый экземпляр FamilyService
+func NewFamilyService(transactor repository.Transactor, familyRepo repository.FamilyRepository) FamilyService {
+	return &familyService{transactor: transactor, familyRepo: familyRepo}
+}
+
+// requireMember проверяет, что пользователь состоит в семье и, если роли указаны,
+// имеет одну из них. Возвращает членство пользователя
+func requireMember(familyRepo repository.FamilyRepository, familyID, userID uint, roles ...models.FamilyRole) (*models.FamilyMember, error) {
+	member, err := familyRepo.GetMember(familyID, userID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if member == nil {
+		return nil, ErrFamilyNotFound
+	}
+	if len(roles) == 0 {
+		return member, nil
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return member, nil
+		}
+	}
+	return nil, ErrFamilyForbidden
+}
+
+func (s *familyService) Create(user *models.User, name, currency, timezone, locale string) (*models.Family, error) {
+	family := &models.Family{
+		Name:      name,
+		Currency:  currency,
+		Timezone:  timezone,
+		Locale:    locale,
+		CreatedBy: user.ID,
+	}
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Families.Create(family, user.ID); err != nil {
+			return err
+		}
+		return seedCategories(repos, family.ID, family.Locale)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось создать семью, попробуйте позже")
+	}
+	return family, nil
+}
+
+func (s *familyService) Rename(user *models.User, familyID uint, name string) (*models.Family, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if family == nil {
+		return nil, ErrFamilyNotFound
+	}
+	family.Name = name
+	if err := s.familyRepo.Update(family); err != nil {
+		return nil, errors.New("не удалось переименовать семью, попробуйте позже")
+	}
+	return family, nil
+}
+
+func (s *familyService) SetCurrency(user *models.User, familyID uint, currency string) (*models.Family, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if family == nil {
+		return nil, ErrFamilyNotFound
+	}
+	family.Currency = currency
+	if err := s.familyRepo.Update(family); err != nil {
+		return nil, errors.New("не удалось изменить валюту семьи, попробуйте позже")
+	}
+	return family, nil
+}
+
+func (s *familyService) ListMine(user *models.User) ([]models.FamilyMember, error) {
+	members, err := s.familyRepo.ListByUser(user.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список семей")
+	}
+	return members, nil
+}
+
+func (s *familyService) Members(user *models.User, familyID uint) ([]models.FamilyMember, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	members, err := s.familyRepo.ListMembers(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список участников")
+	}
+	return members, nil
+}
+
+func (s *familyService) ChangeRole(user *models.User, familyID, memberID uint, role models.FamilyRole) error {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return err
+	}
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		// Блокировка владельцев не даёт двум владельцам одновременно снять друг друга
+		owners, err := repos.Families.LockOwners(familyID)
+		if err != nil {
+			return err
+		}
+		// Пока запрос ждал блокировки, пользователь мог перестать быть владельцем
+		if !hasMember(owners, user.ID) {
+			return ErrFamilyForbidden
+		}
+		target, err := repos.Families.GetMember(familyID, memberID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return ErrFamilyMemberNotFound
+		}
+		if target.Role == role {
+			return nil
+		}
+		if target.Role == models.RoleOwner && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+		return repos.Families.UpdateMemberRole(familyID, memberID, role)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+	if err != nil {
+		return errors.New("не удалось изменить роль участника, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *familyService) Leave(user *models.User, familyID uint) error {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return err
+	}
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		owners, err := repos.Families.LockOwners(familyID)
+		if err != nil {
+			return err
+		}
+		// Роль читается под блокировкой: её мог изменить другой владелец
+		member, err := repos.Families.GetMember(familyID, user.ID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return ErrFamilyNotFound
+		}
+		total, err := repos.Families.CountMembers(familyID, "")
+		if err != nil {
+			return err
+		}
+		// Последний участник забирает семью с собой
+		if total == 1 {
+			return repos.Families.Delete(familyID)
+		}
+		if member.Role == models.RoleOwner && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+		return repos.Families.RemoveMember(familyID, user.ID)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+	if err != nil {
+		return errors.New("не удалось выйти из семьи, попробуйте позже")
+	}
+	return nil
+}
+
+// hasMember сообщает, есть ли пользователь среди участников members
+func hasMember(members []models.FamilyMember, userID uint) bool {
+	for _, m := range members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
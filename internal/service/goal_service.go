@@ -0,0 +1,490 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+const (
+	// goalHistoryMonths за сколько последних месяцев взносов считается средний взнос
+	goalHistoryMonths = 6
+
+	// goalMinHistoryDays наименьший период, на который делится сумма взносов при расчёте
+	// среднего взноса; не даёт одному свежему взносу дать завышенный прогноз
+	goalMinHistoryDays = 30
+
+	// goalMaxProjectionDays дальше скольких дней прогноз достижения цели не строится
+	goalMaxProjectionDays = 100 * 366
+)
+
+// goalDaysPerMonth средняя длина месяца в днях: 365,25 / 12
+var goalDaysPerMonth = big.NewRat(1461, 48)
+
+var (
+	// ErrGoalNotFound возвращается, если цель не существует или недоступна пользователю
+	ErrGoalNotFound = newError(KindNotFound, "цель не найдена")
+
+	// ErrGoalForbidden возвращается, если пользователь не может изменять цель или делать в неё взносы
+	ErrGoalForbidden = newError(KindForbidden, "изменять цель могут участники, которые ведут её счёт накоплений")
+
+	// ErrGoalAccountType возвращается, если для цели выбран счёт не для накоплений
+	ErrGoalAccountType = newError(KindInvalid, "копить на цель можно только на накопительном счёте или вкладе")
+
+	// ErrGoalSameAccount возвращается при взносе со счёта накоплений самой цели
+	ErrGoalSameAccount = newError(KindInvalid, "взнос делается с другого счёта на счёт накоплений цели")
+)
+
+// GoalInput данные новой цели
+type GoalInput struct {
+	Name string
+	// TargetAmount сколько нужно накопить, в минимальных единицах валюты
+	TargetAmount int64
+	// Currency валюта цели; если не указана, берётся валюта счёта накоплений
+	Currency  string
+	Deadline  *time.Time
+	AccountID uint
+}
+
+// GoalChanges изменения цели; nil и нулевые поля не изменяются
+// Счёт накоплений после создания цели не меняется
+type GoalChanges struct {
+	Name         *string
+	TargetAmount int64
+	Deadline     *time.Time
+	// ClearDeadline снимает срок цели
+	ClearDeadline bool
+}
+
+// ContributionInput данные взноса в цель
+type ContributionInput struct {
+	// FromAccountID счёт, с которого переводится взнос
+	FromAccountID uint
+	// Amount сумма списания в минимальных единицах валюты счёта списания
+	Amount int64
+	// Rate курс: сколько единиц валюты цели стоит единица валюты счёта списания
+	// Обязателен, если валюты различаются
+	Rate *big.Rat
+	// Date дата взноса; по умолчанию текущая дата в часовом поясе семьи
+	Date *time.Time
+	Note string
+}
+
+// GoalProgress состояние цели: сколько накоплено и когда цель будет достигнута
+// Суммы в минимальных единицах валюты цели
+type GoalProgress struct {
+	Goal *models.Goal `json:"goal"`
+	// Saved сумма взносов
+	Saved int64 `json:"saved"`
+	// Remaining сколько осталось накопить; 0, если цель достигнута
+	Remaining int64 `json:"remaining"`
+	// Percent доля накопленного в процентах, округлённая вниз; может быть больше 100
+	Percent int64 `json:"percent"`
+	// Completed цель достигнута
+	Completed bool `json:"completed"`
+	// MonthsLeft сколько месяцев осталось до срока, с округлением вверх; 0 — срок прошёл;
+	// nil, если срок не задан
+	MonthsLeft *int `json:"months_left"`
+	// RequiredMonthly ежемесячный взнос, нужный, чтобы успеть к сроку; если срок прошёл —
+	// весь остаток; nil, если срок не задан или цель достигнута
+	RequiredMonthly *int64 `json:"required_monthly"`
+	// AverageMonthly средний взнос в месяц за последние полгода
+	AverageMonthly int64 `json:"average_monthly"`
+	// ProjectedDate когда цель будет достигнута при среднем взносе; nil, если цель
+	// достигнута, взносов за последние полгода не было или прогноз дальше ста лет
+	ProjectedDate *time.Time `json:"projected_date"`
+	// OnTrack цель будет достигнута к сроку при среднем взносе; nil, если срок не задан
+	OnTrack *bool `json:"on_track"`
+}
+
+// GoalService определяет интерфейс для работы с целями накоплений
+type GoalService interface {
+	// Create создает цель; доступно участникам, которые вправе вести счёт накоплений:
+	// для общего счёта — владельцу и взрослым, для личного — его владельцу
+	Create(user *models.User, familyID uint, input GoalInput) (*GoalProgress, error)
+
+	// Get возвращает цель с прогнозом; доступно любому участнику семьи
+	Get(user *models.User, goalID uint) (*GoalProgress, error)
+
+	// List возвращает цели семьи с прогнозами; доступно любому участнику семьи
+	List(user *models.User, familyID uint) ([]GoalProgress, error)
+
+	// Update изменяет цель; права те же, что у Create
+	Update(user *models.User, goalID uint, changes GoalChanges) (*GoalProgress, error)
+
+	// Delete удаляет цель; переводы взносов остаются в операциях
+	Delete(user *models.User, goalID uint) error
+
+	// Contribute переводит взнос со счёта пользователя на счёт накоплений цели
+	// В общую цель может сделать взнос любой участник, кроме наблюдателя; в личную —
+	// те, кто вправе вести её счёт
+	Contribute(user *models.User, goalID uint, input ContributionInput) (*models.GoalContribution, error)
+
+	// Contributions возвращает взносы в цель, начиная с последних; доступно любому участнику семьи
+	Contributions(user *models.User, goalID uint) ([]models.GoalContribution, error)
+}
+
+// goalService реализует интерфейс GoalService
+type goalService struct {
+	transactor  repository.Transactor
+	goalRepo    repository.GoalRepository
+	accountRepo repository.AccountRepository
+	familyRepo  repository.FamilyRepository
+}
+
+// NewGoalService создает новый экземпляр GoalService
+func NewGoalService(transactor repository.Transactor, goalRepo repository.GoalRepository,
+	accountRepo repository.AccountRepository, familyRepo repository.FamilyRepository) GoalService {
+	return &goalService{
+		transactor:  transactor,
+		goalRepo:    goalRepo,
+		accountRepo: accountRepo,
+		familyRepo:  familyRepo,
+	}
+}
+
+func (s *goalService) Create(user *models.User, familyID uint, input GoalInput) (*GoalProgress, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountRepo.GetByID(input.AccountID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные счёта")
+	}
+	if account == nil || account.FamilyID != familyID {
+		return nil, ErrAccountNotFound
+	}
+	if !canEditAccount(member, account) {
+		return nil, ErrGoalForbidden
+	}
+	if account.Type != models.AccountSavings && account.Type != models.AccountDeposit {
+		return nil, ErrGoalAccountType
+	}
+	if account.Archived {
+		return nil, ErrAccountArchived
+	}
+	if input.Currency != "" && input.Currency != account.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	goal := &models.Goal{
+		FamilyID:     familyID,
+		Name:         input.Name,
+		TargetAmount: input.TargetAmount,
+		Currency:     account.Currency,
+		AccountID:    account.ID,
+		CreatedBy:    user.ID,
+	}
+	if input.Deadline != nil {
+		deadline := dateOnly(*input.Deadline)
+		goal.Deadline = &deadline
+	}
+	if err := s.goalRepo.Create(goal); err != nil {
+		return nil, errors.New("не удалось сохранить цель, попробуйте позже")
+	}
+	return s.progress(goal)
+}
+
+func (s *goalService) Get(user *models.User, goalID uint) (*GoalProgress, error) {
+	goal, _, err := s.load(user, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(goal)
+}
+
+func (s *goalService) List(user *models.User, familyID uint) ([]GoalProgress, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	today, err := familyToday(s.familyRepo, familyID)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := s.goalRepo.ListByFamily(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список целей")
+	}
+	ids := make([]uint, len(goals))
+	for i := range goals {
+		ids[i] = goals[i].ID
+	}
+	contributions, err := s.goalRepo.Contributions(ids)
+	if err != nil {
+		return nil, errors.New("не удалось получить взносы в цели")
+	}
+	byGoal := make(map[uint][]models.GoalContribution, len(goals))
+	for _, c := range contributions {
+		byGoal[c.GoalID] = append(byGoal[c.GoalID], c)
+	}
+	result := make([]GoalProgress, len(goals))
+	for i := range goals {
+		result[i] = goalProgress(&goals[i], byGoal[goals[i].ID], today)
+	}
+	return result, nil
+}
+
+func (s *goalService) Update(user *models.User, goalID uint, changes GoalChanges) (*GoalProgress, error) {
+	goal, err := s.editable(user, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Name != nil {
+		goal.Name = *changes.Name
+	}
+	if changes.TargetAmount != 0 {
+		goal.TargetAmount = changes.TargetAmount
+	}
+	switch {
+	case changes.ClearDeadline:
+		goal.Deadline = nil
+	case changes.Deadline != nil:
+		deadline := dateOnly(*changes.Deadline)
+		goal.Deadline = &deadline
+	}
+	if err := s.goalRepo.Update(goal); err != nil {
+		return nil, errors.New("не удалось сохранить цель, попробуйте позже")
+	}
+	return s.progress(goal)
+}
+
+func (s *goalService) Delete(user *models.User, goalID uint) error {
+	goal, err := s.editable(user, goalID)
+	if err != nil {
+		return err
+	}
+	if err := s.goalRepo.Delete(goal.ID); err != nil {
+		return errors.New("не удалось удалить цель, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *goalService) Contribute(user *models.User, goalID uint, input ContributionInput) (*models.GoalContribution, error) {
+	goal, member, err := s.load(user, goalID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.accountRepo.GetByID(goal.AccountID)
+	if err != nil || to == nil {
+		return nil, errors.New("не удалось получить данные счёта")
+	}
+	if member.Role == models.RoleViewer || (!to.Shared() && !canEditAccount(member, to)) {
+		return nil, ErrGoalForbidden
+	}
+	if to.Archived {
+		return nil, ErrAccountArchived
+	}
+	if input.FromAccountID == to.ID {
+		return nil, ErrGoalSameAccount
+	}
+	from, _, err := postableAccount(s.accountRepo, s.familyRepo, user, input.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if from.FamilyID != goal.FamilyID {
+		return nil, ErrAccountFamilyMismatch
+	}
+
+	date := input.Date
+	if date == nil {
+		today, err := familyToday(s.familyRepo, goal.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		date = &today
+	}
+	tx := &models.Transaction{
+		FamilyID:         goal.FamilyID,
+		AccountID:        from.ID,
+		Direction:        models.DirectionTransfer,
+		Currency:         from.Currency,
+		CounterAccountID: &to.ID,
+		CounterCurrency:  &to.Currency,
+		Date:             dateOnly(*date),
+		Payee:            goal.Name,
+		Note:             input.Note,
+		CreatedBy:        user.ID,
+	}
+	switch {
+	case from.Currency == to.Currency && input.Rate != nil:
+		return nil, ErrTransferRateUnexpected
+	case from.Currency != to.Currency && input.Rate == nil:
+		return nil, ErrTransferRateRequired
+	case input.Rate != nil:
+		rate := input.Rate.FloatString(10)
+		tx.Rate = &rate
+	}
+	if err := applyTransferAmount(tx, input.Amount); err != nil {
+		return nil, err
+	}
+
+	contribution := &models.GoalContribution{GoalID: goal.ID}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := postTransaction(repos, tx); err != nil {
+			return err
+		}
+		contribution.TransactionID = tx.ID
+		return repos.Goals.AddContribution(contribution)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось сохранить взнос, попробуйте позже")
+	}
+	contribution.Transaction = tx
+	return contribution, nil
+}
+
+func (s *goalService) Contributions(user *models.User, goalID uint) ([]models.GoalContribution, error) {
+	goal, _, err := s.load(user, goalID)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := s.goalRepo.Contributions([]uint{goal.ID})
+	if err != nil {
+		return nil, errors.New("не удалось получить взносы в цель")
+	}
+	if contributions == nil {
+		contributions = []models.GoalContribution{}
+	}
+	return contributions, nil
+}
+
+// progress вычисляет состояние одной цели на сегодняшний день семьи
+func (s *goalService) progress(goal *models.Goal) (*GoalProgress, error) {
+	today, err := familyToday(s.familyRepo, goal.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := s.goalRepo.Contributions([]uint{goal.ID})
+	if err != nil {
+		return nil, errors.New("не удалось получить взносы в цель")
+	}
+	result := goalProgress(goal, contributions, today)
+	return &result, nil
+}
+
+// editable получает цель, которую пользователь вправе изменять
+func (s *goalService) editable(user *models.User, goalID uint) (*models.Goal, error) {
+	goal, member, err := s.load(user, goalID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountRepo.GetByID(goal.AccountID)
+	if err != nil || account == nil {
+		return nil, errors.New("не удалось получить данные счёта")
+	}
+	if !canEditAccount(member, account) {
+		return nil, ErrGoalForbidden
+	}
+	return goal, nil
+}
+
+// load получает цель и членство пользователя в её семье
+// Цель чужой семьи неотличима от несуществующей
+func (s *goalService) load(user *models.User, goalID uint) (*models.Goal, *models.FamilyMember, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить цель")
+	}
+	if goal == nil {
+		return nil, nil, ErrGoalNotFound
+	}
+	member, err := requireMember(s.familyRepo, goal.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return goal, member, nil
+}
+
+// goalProgress вычисляет состояние цели по её взносам на дату today
+// Средний взнос — сумма взносов за последние goalHistoryMonths месяцев, делённая на длину
+// периода с первого взноса в этом окне (не меньше goalMinHistoryDays дней); прогноз
+// предполагает, что взносы продолжатся в том же темпе
+func goalProgress(goal *models.Goal, contributions []models.GoalContribution, today time.Time) GoalProgress {
+	result := GoalProgress{Goal: goal}
+
+	windowStart := today.AddDate(0, -goalHistoryMonths, 0)
+	var recent int64
+	first := today
+	for _, c := range contributions {
+		if c.Transaction == nil || c.Transaction.CounterAmount == nil {
+			continue
+		}
+		amount, date := *c.Transaction.CounterAmount, dateOnly(c.Transaction.Date)
+		result.Saved += amount
+		if date.After(windowStart) && !date.After(today) {
+			recent += amount
+			if date.Before(first) {
+				first = date
+			}
+		}
+	}
+
+	result.Remaining = max(goal.TargetAmount-result.Saved, 0)
+	result.Completed = result.Remaining == 0
+	result.Percent = floorRat(new(big.Rat).Mul(big.NewRat(result.Saved, goal.TargetAmount), big.NewRat(100, 1))).Int64()
+
+	historyDays := max(int64(today.Sub(first).Hours()/24)+1, goalMinHistoryDays)
+	if recent > 0 {
+		// Средний взнос в месяц: recent / historyDays * goalDaysPerMonth
+		average := new(big.Rat).Mul(big.NewRat(recent, historyDays), goalDaysPerMonth)
+		result.AverageMonthly = floorRat(average).Int64()
+	}
+	if !result.Completed && recent > 0 {
+		// Дней до цели: остаток, делённый на средний взнос в день, с округлением вверх
+		days := new(big.Rat).Mul(big.NewRat(result.Remaining, recent), big.NewRat(historyDays, 1))
+		whole := floorRat(days)
+		if !days.IsInt() {
+			whole.Add(whole, big.NewInt(1))
+		}
+		if whole.Cmp(big.NewInt(goalMaxProjectionDays)) <= 0 {
+			projected := today.AddDate(0, 0, int(whole.Int64()))
+			result.ProjectedDate = &projected
+		}
+	}
+
+	if goal.Deadline != nil {
+		deadline := dateOnly(*goal.Deadline)
+		months := monthsUntil(today, deadline)
+		result.MonthsLeft = &months
+		if !result.Completed {
+			required := result.Remaining
+			if months > 0 {
+				required = ceilDiv(result.Remaining, int64(months))
+			}
+			result.RequiredMonthly = &required
+		}
+		onTrack := result.Completed || (result.ProjectedDate != nil && !result.ProjectedDate.After(deadline))
+		result.OnTrack = &onTrack
+	}
+	return result
+}
+
+// monthsUntil возвращает, сколько месяцев осталось от today до deadline, с округлением вверх:
+// неполный месяц считается целым. Если срок сегодня — 1, если прошёл — 0
+func monthsUntil(today, deadline time.Time) int {
+	if deadline.Before(today) {
+		return 0
+	}
+	months := (deadline.Year()-today.Year())*12 + int(deadline.Month()-today.Month())
+	if deadline.Day() > today.Day() {
+		months++
+	}
+	return max(months, 1)
+}
+
+// ceilDiv делит неотрицательное a на положительное b с округлением вверх
+func ceilDiv(a, b int64) int64 {
+	return a/b + min(a%b, 1)
+}
+
+// floorRat округляет неотрицательное число вниз до целого
+func floorRat(r *big.Rat) *big.Int {
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+	"family_finance_back/internal/util"
+)
+
+// inviteTokenPurpose первая часть подписанного токена приглашения;
+// не позволяет использовать токены другого назначения
+const inviteTokenPurpose = "invite"
+
+// inviteKeyPurpose метка, с которой из общего секрета выводится ключ подписи приглашений;
+// благодаря ей тот же секрет, что подписывает JWT, не подписывает приглашения напрямую
+const inviteKeyPurpose = "family_finance/invite-token/v1"
+
+var (
+	// ErrInviteNotFound возвращается, если приглашение не существует или адресовано другому пользователю
+	ErrInviteNotFound = newError(KindNotFound, "приглашение не найдено")
+
+	// ErrInviteNotPending возвращается, если на приглашение уже ответили или его отозвали
+	ErrInviteNotPending = newError(KindConflict, "приглашение уже принято, отклонено или отозвано")
+
+	// ErrInviteExpired возвращается, если срок действия приглашения истёк
+	ErrInviteExpired = newError(KindConflict, "срок действия приглашения истёк, попросите владельца семьи отправить новое")
+
+	// ErrAlreadyFamilyMember возвращается, если приглашённый уже состоит в семье
+	ErrAlreadyFamilyMember = newError(KindConflict, "пользователь уже состоит в этой семье")
+
+	// ErrInviteEmailNotQueued возвращается, если письмо с приглашением не удалось поставить в очередь отправки
+	ErrInviteEmailNotQueued = errors.New("приглашение создано, но письмо не удалось поставить в очередь отправки; отправьте приглашение повторно позже")
+)
+
+// InviteService определяет интерфейс для работы с приглашениями в семью
+type InviteService interface {
+	// Create приглашает адрес в семью с указанной ролью и отправляет письмо со ссылкой
+	// Предыдущее ожидающее приглашение на тот же адрес отзывается; доступно только владельцу
+	Create(user *models.User, familyID uint, email string, role models.FamilyRole) (*models.FamilyInvite, error)
+
+	// ListForFamily возвращает действующие приглашения семьи; доступно только владельцу
+	ListForFamily(user *models.User, familyID uint) ([]models.FamilyInvite, error)
+
+	// Revoke отзывает ожидающее приглашение; доступно только владельцу семьи
+	Revoke(user *models.User, inviteID uint) error
+
+	// ListMine возвращает действующие приглашения на адрес пользователя
+	ListMine(user *models.User) ([]models.FamilyInvite, error)
+
+	// Accept принимает приглашение по токену из письма или по идентификатору
+	// Возвращает членство пользователя в семье
+	Accept(user *models.User, token string, inviteID uint) (*models.FamilyMember, error)
+
+	// Decline отклоняет приглашение по токену из письма или по идентификатору
+	Decline(user *models.User, token string, inviteID uint) error
+
+	// AcceptPendingForUser принимает все действующие приглашения на адрес пользователя
+	// Вызывается после регистрации, чтобы новый пользователь сразу попал в семьи
+	AcceptPendingForUser(user *models.User) ([]models.FamilyMember, error)
+}
+
+// InviteOptions параметры приглашений
+type InviteOptions struct {
+	// Secret общий секрет приложения; ключ подписи приглашений выводится из него
+	// через HKDF и с ключом подписи JWT не совпадает
+	Secret string
+	// TTL срок действия приглашения
+	TTL time.Duration
+	// AppURL публичный адрес приложения для ссылки в письме
+	AppURL string
+}
+
+// inviteService реализует интерфейс InviteService
+type inviteService struct {
+	inviteRepo repository.InviteRepository
+	familyRepo repository.FamilyRepository
+	userRepo   repository.UserRepository
+	emailSvc   EmailService
+	normalizer util.EmailNormalizer
+	opts       InviteOptions
+	// key ключ подписи токенов приглашений
+	key string
+}
+
+// NewInviteService создает новый экземпляр InviteService
+func NewInviteService(inviteRepo repository.InviteRepository, familyRepo repository.FamilyRepository, userRepo repository.UserRepository,
+	emailSvc EmailService, normalizer util.EmailNormalizer, opts InviteOptions) InviteService {
+	return &inviteService{
+		inviteRepo: inviteRepo,
+		familyRepo: familyRepo,
+		userRepo:   userRepo,
+		emailSvc:   emailSvc,
+		normalizer: normalizer,
+		opts:       opts,
+		key:        util.DeriveKey(opts.Secret, inviteKeyPurpose),
+	}
+}
+
+func (s *inviteService) Create(user *models.User, familyID uint, email string, role models.FamilyRole) (*models.FamilyInvite, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	email, err := util.CanonicalEmail(email)
+	if err != nil {
+		return nil, newError(KindInvalid, err.Error())
+	}
+	key, err := s.normalizer.Normalize(email)
+	if err != nil {
+		return nil, newError(KindInvalid, err.Error())
+	}
+
+	invitee, err := s.userRepo.GetByEmailKey(key)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные пользователя, попробуйте позже")
+	}
+	if invitee != nil {
+		member, err := s.familyRepo.GetMember(familyID, invitee.ID)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные семьи")
+		}
+		if member != nil {
+			return nil, ErrAlreadyFamilyMember
+		}
+	}
+
+	family, err := s.familyRepo.GetByID(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if family == nil {
+		return nil, ErrFamilyNotFound
+	}
+
+	nonce, err := util.RandomHex(16)
+	if err != nil {
+		return nil, errors.New("не удалось создать приглашение, попробуйте позже")
+	}
+	token := util.SignToken(s.key, inviteTokenPurpose, nonce)
+
+	if err := s.inviteRepo.RevokePending(familyID, key); err != nil {
+		return nil, errors.New("не удалось отозвать предыдущее приглашение, попробуйте позже")
+	}
+	invite := &models.FamilyInvite{
+		FamilyID:  familyID,
+		Email:     email,
+		EmailKey:  key,
+		Role:      role,
+		TokenHash: util.HashToken(token),
+		Status:    models.InvitePending,
+		InvitedBy: user.ID,
+		ExpiresAt: time.Now().Add(s.opts.TTL),
+	}
+	if err := s.inviteRepo.Create(invite); err != nil {
+		return nil, errors.New("не удалось создать приглашение, попробуйте позже")
+	}
+
+	err = s.emailSvc.SendFamilyInvite(email, mail.FamilyInviteData{
+		FamilyName:  family.Name,
+		InviterName: strings.TrimSpace(user.Name + " " + user.Surname),
+		Role:        string(role),
+		AcceptURL:   s.acceptURL(token),
+		ExpiresAt:   invite.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		log.Printf("invites: не удалось отправить приглашение %d: %v", invite.ID, err)
+		return nil, ErrInviteEmailNotQueued
+	}
+	invite.Family = family
+	return invite, nil
+}
+
+// acceptURL формирует ссылку на принятие приглашения
+func (s *inviteService) acceptURL(token string) string {
+	return strings.TrimRight(s.opts.AppURL, "/") + "/invites/accept?token=" + url.QueryEscape(token)
+}
+
+func (s *inviteService) ListForFamily(user *models.User, familyID uint) ([]models.FamilyInvite, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	invites, err := s.inviteRepo.ListPendingByFamily(familyID, time.Now())
+	if err != nil {
+		return nil, errors.New("не удалось получить список приглашений")
+	}
+	return invites, nil
+}
+
+func (s *inviteService) Revoke(user *models.User, inviteID uint) error {
+	invite, err := s.inviteRepo.GetByID(inviteID)
+	if err != nil {
+		return errors.New("не удалось получить приглашение")
+	}
+	if invite == nil {
+		return ErrInviteNotFound
+	}
+	if _, err := requireMember(s.familyRepo, invite.FamilyID, user.ID, models.RoleOwner); err != nil {
+		if err == ErrFamilyNotFound {
+			return ErrInviteNotFound
+		}
+		return err
+	}
+	ok, err := s.inviteRepo.SetStatus(invite.ID, models.InviteRevoked)
+	if err != nil {
+		return errors.New("не удалось отозвать приглашение, попробуйте позже")
+	}
+	if !ok {
+		return ErrInviteNotPending
+	}
+	return nil
+}
+
+func (s *inviteService) ListMine(user *models.User) ([]models.FamilyInvite, error) {
+	invites, err := s.inviteRepo.ListPendingByEmailKey(user.EmailKey, time.Now())
+	if err != nil {
+		return nil, errors.New("не удалось получить список приглашений")
+	}
+	return invites, nil
+}
+
+func (s *inviteService) Accept(user *models.User, token string, inviteID uint) (*models.FamilyMember, error) {
+	invite, err := s.resolve(user, token, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	return s.accept(user, invite)
+}
+
+func (s *inviteService) Decline(user *models.User, token string, inviteID uint) error {
+	invite, err := s.resolve(user, token, inviteID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.inviteRepo.SetStatus(invite.ID, models.InviteDeclined)
+	if err != nil {
+		return errors.New("не удалось отклонить приглашение, попробуйте позже")
+	}
+	if !ok {
+		return ErrInviteNotPending
+	}
+	return nil
+}
+
+func (s *inviteService) AcceptPendingForUser(user *models.User) ([]models.FamilyMember, error) {
+	invites, err := s.inviteRepo.ListPendingByEmailKey(user.EmailKey, time.Now())
+	if err != nil {
+		return nil, errors.New("не удалось получить список приглашений")
+	}
+	var joined []models.FamilyMember
+	for i := range invites {
+		member, err := s.accept(user, &invites[i])
+		if err != nil {
+			log.Printf("invites: не удалось принять приглашение %d для пользователя %d: %v", invites[i].ID, user.ID, err)
+			continue
+		}
+		joined = append(joined, *member)
+	}
+	return joined, nil
+}
+
+// resolve находит приглашение по токену или идентификатору и проверяет,
+// что оно адресовано пользователю и ещё действует
+func (s *inviteService) resolve(user *models.User, token string, inviteID uint) (*models.FamilyInvite, error) {
+	var (
+		invite *models.FamilyInvite
+		err    error
+	)
+	if token != "" {
+		parts, verr := util.VerifyToken(s.key, token)
+		if verr != nil || len(parts) != 2 || parts[0] != inviteTokenPurpose {
+			return nil, ErrInviteNotFound
+		}
+		invite, err = s.inviteRepo.GetByTokenHash(util.HashToken(token))
+	} else {
+		invite, err = s.inviteRepo.GetByID(inviteID)
+	}
+	if err != nil {
+		return nil, errors.New("не удалось получить приглашение")
+	}
+	// Чужое приглашение неотличимо от несуществующего
+	if invite == nil || invite.EmailKey != user.EmailKey {
+		return nil, ErrInviteNotFound
+	}
+	if invite.Status != models.InvitePending {
+		return nil, ErrInviteNotPending
+	}
+	if invite.Expired(time.Now()) {
+		return nil, ErrInviteExpired
+	}
+	return invite, nil
+}
+
+// accept добавляет пользователя в семью по действующему приглашению
+func (s *inviteService) accept(user *models.User, invite *models.FamilyInvite) (*models.FamilyMember, error) {
+	existing, err := s.familyRepo.GetMember(invite.FamilyID, user.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные семьи")
+	}
+	if existing != nil {
+		// Пользователь уже в семье: приглашение больше не нужно
+		if _, err := s.inviteRepo.SetStatus(invite.ID, models.InviteAccepted); err != nil {
+			log.Printf("invites: не удалось закрыть приглашение %d: %v", invite.ID, err)
+		}
+		return nil, ErrAlreadyFamilyMember
+	}
+
+	ok, err := s.inviteRepo.Accept(invite, user.ID)
+	if err != nil {
+		return nil, errors.New("не удалось принять приглашение, попробуйте позже")
+	}
+	if !ok {
+		return nil, ErrInviteNotPending
+	}
+	member, err := s.familyRepo.GetMember(invite.FamilyID, user.ID)
+	if err != nil || member == nil {
+		return nil, errors.New("приглашение принято, но не удалось получить данные семьи")
+	}
+	member.Family = invite.Family
+	return member, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// LedgerReport результат проверки целостности главной книги
+type LedgerReport struct {
+	// Balanced true, если нарушений не найдено
+	Balanced bool `json:"balanced"`
+	// Unbalanced записи журнала, сумма проводок которых в валюте не равна нулю
+	Unbalanced []repository.UnbalancedEntry `json:"unbalanced"`
+	// WithoutPostings операции без проводок
+	WithoutPostings []uint `json:"without_postings"`
+	// CurrencyMismatches проводки в валюте, отличной от валюты счёта
+	CurrencyMismatches []repository.MismatchedPosting `json:"currency_mismatches"`
+	// CheckedAt время проверки
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// LedgerService определяет интерфейс проверки главной книги
+type LedgerService interface {
+	// Check проверяет инварианты главной книги: сумма проводок каждой записи
+	// журнала в каждой валюте равна нулю, у каждой операции есть проводки,
+	// а валюта проводки совпадает с валютой счёта
+	Check() (*LedgerReport, error)
+}
+
+// ledgerService реализует интерфейс LedgerService
+type ledgerService struct {
+	ledgerRepo repository.LedgerRepository
+}
+
+// NewLedgerService создает новый экземпляр LedgerService
+func NewLedgerService(ledgerRepo repository.LedgerRepository) LedgerService {
+	return &ledgerService{ledgerRepo: ledgerRepo}
+}
+
+func (s *ledgerService) Check() (*LedgerReport, error) {
+	unbalanced, err := s.ledgerRepo.Unbalanced()
+	if err != nil {
+		return nil, errors.New("не удалось проверить баланс проводок")
+	}
+	missing, err := s.ledgerRepo.WithoutPostings()
+	if err != nil {
+		return nil, errors.New("не удалось проверить наличие проводок")
+	}
+	mismatches, err := s.ledgerRepo.CurrencyMismatches()
+	if err != nil {
+		return nil, errors.New("не удалось проверить валюты проводок")
+	}
+	report := &LedgerReport{
+		Unbalanced:         unbalanced,
+		WithoutPostings:    missing,
+		CurrencyMismatches: mismatches,
+		CheckedAt:          time.Now(),
+	}
+	if report.Unbalanced == nil {
+		report.Unbalanced = []repository.UnbalancedEntry{}
+	}
+	if report.WithoutPostings == nil {
+		report.WithoutPostings = []uint{}
+	}
+	if report.CurrencyMismatches == nil {
+		report.CurrencyMismatches = []repository.MismatchedPosting{}
+	}
+	report.Balanced = len(unbalanced) == 0 && len(missing) == 0 && len(mismatches) == 0
+	return report, nil
+}
+
+// postingsFor строит сбалансированные проводки операции:
+//   - доход: счёт +сумма, служебный счёт доходов −сумма;
+//   - расход: счёт −сумма, служебный счёт расходов +сумма;
+//   - перевод в одной валюте: счёт списания −сумма, счёт зачисления +сумма;
+//   - перевод между валютами: дополнительно две проводки по служебному счёту
+//     обмена валют, чтобы сумма в каждой валюте оставалась нулевой
+//
+// У непроведённой операции (ожидающей одобрения или отклонённой) проводок нет
+func postingsFor(tx *models.Transaction) []models.Posting {
+	if !tx.Posted() {
+		return nil
+	}
+	onAccount := func(accountID uint, amount int64, currency string) models.Posting {
+		id := accountID
+		return models.Posting{AccountID: &id, Amount: amount, Currency: currency}
+	}
+	onNominal := func(account models.NominalAccount, amount int64, currency string) models.Posting {
+		return models.Posting{Nominal: account, Amount: amount, Currency: currency}
+	}
+
+	switch tx.Direction {
+	case models.DirectionIncome:
+		return []models.Posting{
+			onAccount(tx.AccountID, tx.Amount, tx.Currency),
+			onNominal(models.NominalIncome, -tx.Amount, tx.Currency),
+		}
+	case models.DirectionExpense:
+		return []models.Posting{
+			onAccount(tx.AccountID, -tx.Amount, tx.Currency),
+			onNominal(models.NominalExpense, tx.Amount, tx.Currency),
+		}
+	}
+
+	postings := []models.Posting{onAccount(tx.AccountID, -tx.Amount, tx.Currency)}
+	if *tx.CounterCurrency != tx.Currency {
+		postings = append(postings,
+			onNominal(models.NominalExchange, tx.Amount, tx.Currency),
+			onNominal(models.NominalExchange, -*tx.CounterAmount, *tx.CounterCurrency),
+		)
+	}
+	return append(postings, onAccount(*tx.CounterAccountID, *tx.CounterAmount, *tx.CounterCurrency))
+}
+
+// postTransaction сохраняет новую операцию вместе с её проводками
+// Операция без состояния считается проведённой
+// Вызывайте внутри Transactor.Transaction, чтобы операция и проводки записались атомарно
+func postTransaction(repos *repository.Repositories, tx *models.Transaction) error {
+	if tx.Status == "" {
+		tx.Status = models.TransactionPosted
+	}
+	if err := repos.Transactions.Create(tx); err != nil {
+		return err
+	}
+	return repos.Ledger.ReplacePostings(tx.ID, postingsFor(tx))
+}
+
+// repostTransaction сохраняет изменённую операцию и пересоздаёт её проводки
+// Вызывайте внутри Transactor.Transaction
+func repostTransaction(repos *repository.Repositories, tx *models.Transaction) error {
+	if err := repos.Transactions.Update(tx); err != nil {
+		return err
+	}
+	return repos.Ledger.ReplacePostings(tx.ID, postingsFor(tx))
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// ErrNotificationTypeInvalid возвращается при указании неизвестного вида уведомлений
+var ErrNotificationTypeInvalid = newError(KindInvalid, "неизвестный вид уведомлений")
+
+// NotificationPage страница ленты уведомлений
+type NotificationPage struct {
+	Items []models.Notification `json:"items"`
+	// UnreadCount общее количество непрочитанных уведомлений пользователя
+	UnreadCount int64 `json:"unread_count"`
+	// NextBefore значение before для следующей страницы; 0, если страница последняя
+	NextBefore uint `json:"next_before"`
+}
+
+// NotificationService определяет интерфейс для работы с уведомлениями пользователей
+type NotificationService interface {
+	// List возвращает страницу ленты уведомлений пользователя от новых к старым
+	// beforeID > 0 возвращает уведомления старше указанного
+	List(user *models.User, unreadOnly bool, beforeID uint, limit int) (*NotificationPage, error)
+
+	// MarkRead отмечает уведомления пользователя прочитанными; пустой ids отмечает все
+	MarkRead(user *models.User, ids []uint) error
+
+	// Preferences возвращает настройки доставки всех видов уведомлений пользователя
+	Preferences(user *models.User) ([]models.NotificationPreference, error)
+
+	// UpdatePreference изменяет настройки доставки уведомлений одного вида; nil поля не изменяются
+	UpdatePreference(user *models.User, notificationType models.NotificationType, email, inApp *bool) (*models.NotificationPreference, error)
+
+	// Deliver доставляет уведомление получателю с учётом его настроек:
+	// сохраняет его в ленту и вызывает sendEmail с адресом получателя
+	Deliver(recipient *models.User, notification *models.Notification, sendEmail func(to string) error) error
+}
+
+// notificationService реализует интерфейс NotificationService
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewNotificationService создает новый экземпляр NotificationService
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo}
+}
+
+func (s *notificationService) List(user *models.User, unreadOnly bool, beforeID uint, limit int) (*NotificationPage, error) {
+	// Запрашиваем на одно уведомление больше, чтобы узнать, есть ли следующая страница
+	items, err := s.notificationRepo.List(user.ID, unreadOnly, beforeID, limit+1)
+	if err != nil {
+		return nil, errors.New("не удалось получить уведомления")
+	}
+	unread, err := s.notificationRepo.CountUnread(user.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить уведомления")
+	}
+	page := &NotificationPage{Items: items, UnreadCount: unread}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextBefore = page.Items[limit-1].ID
+	}
+	if page.Items == nil {
+		page.Items = []models.Notification{}
+	}
+	return page, nil
+}
+
+func (s *notificationService) MarkRead(user *models.User, ids []uint) error {
+	if err := s.notificationRepo.MarkRead(user.ID, ids); err != nil {
+		return errors.New("не удалось отметить уведомления прочитанными")
+	}
+	return nil
+}
+
+func (s *notificationService) Preferences(user *models.User) ([]models.NotificationPreference, error) {
+	saved, err := s.preferences(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		result = append(result, saved(t))
+	}
+	return result, nil
+}
+
+func (s *notificationService) UpdatePreference(user *models.User, notificationType models.NotificationType, email, inApp *bool) (*models.NotificationPreference, error) {
+	if !notificationType.Valid() {
+		return nil, ErrNotificationTypeInvalid
+	}
+	saved, err := s.preferences(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	preference := saved(notificationType)
+	if email != nil {
+		preference.Email = *email
+	}
+	if inApp != nil {
+		preference.InApp = *inApp
+	}
+	if err := s.notificationRepo.SavePreference(&preference); err != nil {
+		return nil, errors.New("не удалось сохранить настройки уведомлений")
+	}
+	return &preference, nil
+}
+
+func (s *notificationService) Deliver(recipient *models.User, notification *models.Notification, sendEmail func(to string) error) error {
+	saved, err := s.preferences(recipient.ID)
+	if err != nil {
+		return err
+	}
+	preference := saved(notification.Type)
+	if preference.InApp {
+		notification.UserID = recipient.ID
+		if err := s.notificationRepo.Create(notification); err != nil {
+			return fmt.Errorf("не удалось сохранить уведомление: %w", err)
+		}
+	}
+	if preference.Email && sendEmail != nil {
+		if err := sendEmail(recipient.Email); err != nil {
+			log.Printf("notifications: не удалось отправить письмо пользователю %d: %v", recipient.ID, err)
+		}
+	}
+	return nil
+}
+
+// preferences загружает сохранённые настройки пользователя и возвращает функцию,
+// отдающую настройку вида уведомлений; без сохранённой настройки доставка включена везде
+func (s *notificationService) preferences(userID uint) (func(models.NotificationType) models.NotificationPreference, error) {
+	list, err := s.notificationRepo.ListPreferences(userID)
+	if err != nil {
+		return nil, errors.New("не удалось получить настройки уведомлений")
+	}
+	byType := make(map[models.NotificationType]models.NotificationPreference, len(list))
+	for _, p := range list {
+		byType[p.Type] = p
+	}
+	return func(t models.NotificationType) models.NotificationPreference {
+		if p, ok := byType[t]; ok {
+			return p
+		}
+		return models.NotificationPreference{UserID: userID, Type: t, Email: true, InApp: true}
+	}, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// ErrOutboxUnavailable возвращается, если письмо не удалось сохранить в очередь отправки
+var ErrOutboxUnavailable = errors.New("не удалось поставить письмо в очередь отправки")
+
+// ErrOutboxMessageNotFound возвращается, если письмо отсутствует в очереди
+var ErrOutboxMessageNotFound = errors.New("письмо не найдено")
+
+// ErrOutboxNotReplayable возвращается при попытке повторить письмо, которое не в dead-letter
+var ErrOutboxNotReplayable = errors.New("повторить можно только письмо в статусе dead")
+
+// ErrOutboxExpiring возвращается при попытке повторить письмо со сроком действия, например с кодом
+// подтверждения: к моменту повтора код истёк бы или уже истёк, пользователь запросит новый
+var ErrOutboxExpiring = errors.New("письмо со сроком действия повторить нельзя")
+
+// errOutboxExpired причина, по которой просроченное письмо переведено в dead-letter
+const errOutboxExpired = "срок действия письма истёк"
+
+// OutboxService определяет интерфейс очереди исходящих писем
+type OutboxService interface {
+	// Enqueue сохраняет письмо в очередь; доставка выполняется воркерами асинхронно
+	// Возвращает ErrOutboxUnavailable, если очередь не приняла письмо
+	Enqueue(template string, msg *mail.Message) error
+
+	// List возвращает письма с указанным статусом (все, если статус пустой)
+	List(status models.OutboxStatus, limit, offset int) ([]models.EmailOutbox, error)
+
+	// Replay возвращает письмо из dead-letter в очередь отправки
+	// Письма со сроком действия не повторяются
+	Replay(id uint) error
+
+	// Run запускает пул воркеров доставки и блокируется до отмены ctx
+	Run(ctx context.Context)
+}
+
+// OutboxOptions параметры доставки писем из очереди
+type OutboxOptions struct {
+	// Workers количество параллельных воркеров
+	Workers int
+	// BatchSize количество писем, которое воркер забирает за один опрос
+	BatchSize int
+	// PollInterval интервал опроса очереди
+	PollInterval time.Duration
+	// Lease время, на которое письмо закрепляется за воркером
+	Lease time.Duration
+	// MaxAttempts количество попыток, после которого письмо уходит в dead-letter
+	MaxAttempts int
+	// BaseBackoff задержка перед первой повторной попыткой; далее удваивается
+	BaseBackoff time.Duration
+	// MaxBackoff максимальная задержка между попытками
+	MaxBackoff time.Duration
+}
+
+// outboxService реализует интерфейс OutboxService
+type outboxService struct {
+	repo      repository.OutboxRepository
+	transport mail.Transport
+	opts      OutboxOptions
+}
+
+// NewOutboxService создает новый экземпляр OutboxService
+func NewOutboxService(repo repository.OutboxRepository, transport mail.Transport, opts OutboxOptions) OutboxService {
+	return &outboxService{repo: repo, transport: transport, opts: opts}
+}
+
+func (s *outboxService) Enqueue(template string, msg *mail.Message) error {
+	records := make([]models.EmailOutbox, 0, len(msg.To))
+	for _, to := range msg.To {
+		record := models.EmailOutbox{
+			MessageID:     msg.ID,
+			Template:      template,
+			Sender:        msg.From,
+			Recipient:     to,
+			Subject:       msg.Subject,
+			TextBody:      msg.Text,
+			HTMLBody:      msg.HTML,
+			Status:        models.OutboxPending,
+			NextAttemptAt: time.Now(),
+			ExpiresAt:     msg.ExpiresAt,
+		}
+		if len(msg.To) > 1 {
+			record.MessageID = fmt.Sprintf("%s.%s", msg.ID, to)
+		}
+		records = append(records, record)
+	}
+	// Письма всем получателям сохраняются вместе, чтобы повтор после ошибки не задвоил их
+	if err := s.repo.Create(records); err != nil {
+		log.Printf("outbox: не удалось сохранить письмо %s: %v", msg.ID, err)
+		return ErrOutboxUnavailable
+	}
+	return nil
+}
+
+func (s *outboxService) List(status models.OutboxStatus, limit, offset int) ([]models.EmailOutbox, error) {
+	msgs, err := s.repo.List(status, limit, offset)
+	if err != nil {
+		return nil, errors.New("не удалось получить список писем")
+	}
+	return msgs, nil
+}
+
+func (s *outboxService) Replay(id uint) error {
+	msg, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("не удалось получить письмо")
+	}
+	if msg == nil {
+		return ErrOutboxMessageNotFound
+	}
+	if msg.Status != models.OutboxDead {
+		return ErrOutboxNotReplayable
+	}
+	if msg.ExpiresAt != nil {
+		return ErrOutboxExpiring
+	}
+	if err := s.repo.Requeue(id); err != nil {
+		return errors.New("не удалось вернуть письмо в очередь")
+	}
+	return nil
+}
+
+func (s *outboxService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work опрашивает очередь и доставляет письма до отмены ctx
+func (s *outboxService) work(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Пока воркер получает полные пачки, забираем следующую без ожидания
+		for ctx.Err() == nil && s.deliverBatch() == s.opts.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverBatch забирает и доставляет одну пачку писем
+// Возвращает количество обработанных писем
+func (s *outboxService) deliverBatch() int {
+	msgs, err := s.repo.ClaimDue(s.opts.BatchSize, s.opts.Lease)
+	if err != nil {
+		log.Printf("outbox: не удалось получить письма из очереди: %v", err)
+		return 0
+	}
+	for i := range msgs {
+		s.deliver(&msgs[i])
+	}
+	return len(msgs)
+}
+
+// deliver отправляет письмо и фиксирует результат: доставлено,
+// повторная попытка с экспоненциальной задержкой или dead-letter
+// Письмо со сроком действия не отправляется после его истечения и не повторяется,
+// если следующая попытка пришлась бы на время после срока
+// Результат сохраняется, только пока письмо закреплено за воркером (record.LockedUntil)
+func (s *outboxService) deliver(record *models.EmailOutbox) {
+	if record.LockedUntil == nil {
+		log.Printf("outbox: письмо %d получено без аренды и пропущено", record.ID)
+		return
+	}
+	lease := *record.LockedUntil
+	if record.ExpiresAt != nil && !time.Now().Before(*record.ExpiresAt) {
+		s.expire(record, errOutboxExpired)
+		return
+	}
+	msg := &mail.Message{
+		ID:        record.MessageID,
+		From:      record.Sender,
+		To:        []string{record.Recipient},
+		Subject:   record.Subject,
+		Text:      record.TextBody,
+		HTML:      record.HTMLBody,
+		CreatedAt: record.CreatedAt,
+		ExpiresAt: record.ExpiresAt,
+	}
+
+	err := s.transport.Send(msg)
+	if err == nil {
+		ok, markErr := s.repo.MarkSent(record.ID, lease)
+		if markErr != nil {
+			log.Printf("outbox: письмо %d доставлено, но статус не сохранён: %v", record.ID, markErr)
+		} else if !ok {
+			s.leaseLost(record)
+		}
+		return
+	}
+
+	next := time.Now().Add(s.backoff(record.Attempts))
+	if record.ExpiresAt != nil && (mail.IsPermanent(err) || record.Attempts >= s.opts.MaxAttempts ||
+		!next.Before(*record.ExpiresAt)) {
+		log.Printf("outbox: письмо %d для %s не доставлено до истечения срока: %v", record.ID, record.Recipient, err)
+		s.expire(record, err.Error())
+		return
+	}
+
+	if mail.IsPermanent(err) || record.Attempts >= s.opts.MaxAttempts {
+		log.Printf("outbox: письмо %d для %s перемещено в dead-letter после %d попыток: %v",
+			record.ID, record.Recipient, record.Attempts, err)
+		ok, markErr := s.repo.MarkDead(record.ID, lease, err.Error())
+		if markErr != nil {
+			log.Printf("outbox: не удалось перевести письмо %d в dead-letter: %v", record.ID, markErr)
+		} else if !ok {
+			s.leaseLost(record)
+		}
+		return
+	}
+
+	ok, markErr := s.repo.MarkRetry(record.ID, lease, next, err.Error())
+	if markErr != nil {
+		log.Printf("outbox: не удалось запланировать повтор письма %d: %v", record.ID, markErr)
+	} else if !ok {
+		s.leaseLost(record)
+	}
+}
+
+// expire переводит письмо со сроком действия в dead-letter и очищает его тело
+func (s *outboxService) expire(record *models.EmailOutbox, reason string) {
+	ok, err := s.repo.MarkExpired(record.ID, *record.LockedUntil, reason)
+	if err != nil {
+		log.Printf("outbox: не удалось перевести просроченное письмо %d в dead-letter: %v", record.ID, err)
+	} else if !ok {
+		s.leaseLost(record)
+	}
+}
+
+// leaseLost записывает в журнал, что результат отправки не сохранён: аренда письма истекла,
+// и его уже обрабатывает другой воркер
+func (s *outboxService) leaseLost(record *models.EmailOutbox) {
+	log.Printf("outbox: аренда письма %d истекла, результат попытки %d не сохранён", record.ID, record.Attempts)
+}
+
+// backoff вычисляет задержку перед следующей попыткой: BaseBackoff * 2^(attempt-1)
+// с ограничением MaxBackoff и случайным разбросом ±20%
+func (s *outboxService) backoff(attempt int) time.Duration {
+	d := s.opts.BaseBackoff
+	for i := 1; i < attempt && d < s.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.opts.MaxBackoff {
+		d = s.opts.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5*2+1)) - d/5
+	return d + jitter
+}
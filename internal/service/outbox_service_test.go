@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"family_finance_back/internal/mail"
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+// memOutbox запоминает, чем закончилась доставка писем
+type memOutbox struct {
+	repository.OutboxRepository
+	records map[uint]*models.EmailOutbox
+	retried map[uint]time.Time
+}
+
+func newMemOutbox(records ...models.EmailOutbox) *memOutbox {
+	r := &memOutbox{records: map[uint]*models.EmailOutbox{}, retried: map[uint]time.Time{}}
+	for i := range records {
+		r.records[records[i].ID] = &records[i]
+	}
+	return r
+}
+
+func (r *memOutbox) GetByID(id uint) (*models.EmailOutbox, error) {
+	return r.records[id], nil
+}
+
+// claimed сообщает, закреплено ли письмо арендой lockedUntil, как в OutboxRepository
+func (r *memOutbox) claimed(id uint, lockedUntil time.Time) bool {
+	record := r.records[id]
+	return record.Status == models.OutboxSending && record.LockedUntil != nil && record.LockedUntil.Equal(lockedUntil)
+}
+
+func (r *memOutbox) MarkSent(id uint, lockedUntil time.Time) (bool, error) {
+	if !r.claimed(id, lockedUntil) {
+		return false, nil
+	}
+	r.records[id].Status = models.OutboxSent
+	r.records[id].TextBody, r.records[id].HTMLBody = "", ""
+	return true, nil
+}
+
+func (r *memOutbox) MarkRetry(id uint, lockedUntil, next time.Time, lastErr string) (bool, error) {
+	if !r.claimed(id, lockedUntil) {
+		return false, nil
+	}
+	r.records[id].Status = models.OutboxPending
+	r.retried[id] = next
+	return true, nil
+}
+
+func (r *memOutbox) MarkDead(id uint, lockedUntil time.Time, lastErr string) (bool, error) {
+	if !r.claimed(id, lockedUntil) {
+		return false, nil
+	}
+	r.records[id].Status = models.OutboxDead
+	return true, nil
+}
+
+func (r *memOutbox) MarkExpired(id uint, lockedUntil time.Time, lastErr string) (bool, error) {
+	if !r.claimed(id, lockedUntil) {
+		return false, nil
+	}
+	r.records[id].Status = models.OutboxDead
+	r.records[id].LastError = lastErr
+	r.records[id].TextBody, r.records[id].HTMLBody = "", ""
+	return true, nil
+}
+
+// failingTransport возвращает err на каждую отправку и считает попытки
+type failingTransport struct {
+	err   error
+	sends int
+}
+
+func (t *failingTransport) Send(*mail.Message) error {
+	t.sends++
+	return t.err
+}
+
+func outboxRecord(id uint, expiresAt *time.Time) models.EmailOutbox {
+	lockedUntil := time.Now().Add(5 * time.Minute).Truncate(time.Microsecond)
+	return models.EmailOutbox{
+		ID:          id,
+		Template:    mail.TemplateVerificationCode,
+		Recipient:   "user@example.com",
+		TextBody:    "Код: 12345",
+		HTMLBody:    "<p>Код: 12345</p>",
+		Status:      models.OutboxSending,
+		Attempts:    1,
+		ExpiresAt:   expiresAt,
+		LockedUntil: &lockedUntil,
+	}
+}
+
+var testOutboxOptions = OutboxOptions{MaxAttempts: 5, BaseBackoff: time.Minute, MaxBackoff: time.Hour}
+
+func TestOutboxExpiredMessageNotSent(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	repo := newMemOutbox(outboxRecord(1, &expired))
+	transport := &failingTransport{}
+	svc := &outboxService{repo: repo, transport: transport, opts: testOutboxOptions}
+
+	svc.deliver(repo.records[1])
+
+	if transport.sends != 0 {
+		t.Errorf("sends = %d, want 0", transport.sends)
+	}
+	record := repo.records[1]
+	if record.Status != models.OutboxDead || record.TextBody != "" || record.HTMLBody != "" {
+		t.Errorf("record = %+v, want dead with empty bodies", record)
+	}
+}
+
+func TestOutboxNoRetryAfterExpiry(t *testing.T) {
+	// Первая повторная попытка через минуту, а код истекает раньше
+	expiresAt := time.Now().Add(30 * time.Second)
+	repo := newMemOutbox(outboxRecord(1, &expiresAt))
+	svc := &outboxService{repo: repo, transport: &failingTransport{err: errors.New("timeout")}, opts: testOutboxOptions}
+
+	svc.deliver(repo.records[1])
+
+	if _, ok := repo.retried[1]; ok {
+		t.Error("expiring message scheduled for retry after its expiry")
+	}
+	if record := repo.records[1]; record.Status != models.OutboxDead || record.TextBody != "" {
+		t.Errorf("record = %+v, want dead with empty body", record)
+	}
+}
+
+func TestOutboxRetryBeforeExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	repo := newMemOutbox(outboxRecord(1, &expiresAt))
+	svc := &outboxService{repo: repo, transport: &failingTransport{err: errors.New("timeout")}, opts: testOutboxOptions}
+
+	svc.deliver(repo.records[1])
+
+	next, ok := repo.retried[1]
+	if !ok || !next.Before(expiresAt) {
+		t.Errorf("retry at %v (scheduled %t), want before %v", next, ok, expiresAt)
+	}
+}
+
+func TestOutboxSentMessagePurged(t *testing.T) {
+	repo := newMemOutbox(outboxRecord(1, nil))
+	svc := &outboxService{repo: repo, transport: &failingTransport{}, opts: testOutboxOptions}
+
+	svc.deliver(repo.records[1])
+
+	if record := repo.records[1]; record.Status != models.OutboxSent || record.TextBody != "" || record.HTMLBody != "" {
+		t.Errorf("record = %+v, want sent with empty bodies", record)
+	}
+}
+
+func TestOutboxReplayExpiringRejected(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	record := outboxRecord(1, &expiresAt)
+	record.Status = models.OutboxDead
+	svc := &outboxService{repo: newMemOutbox(record), opts: testOutboxOptions}
+
+	if err := svc.Replay(1); err != ErrOutboxExpiring {
+		t.Errorf("Replay error = %v, want %v", err, ErrOutboxExpiring)
+	}
+}
+
+func TestOutboxLeaseLostKeepsNewClaim(t *testing.T) {
+	repo := newMemOutbox(outboxRecord(1, nil))
+	// Пока первый воркер отправлял письмо, аренда истекла и письмо забрал второй
+	stale := repo.records[1]
+	copied := *stale
+	reclaimed := copied.LockedUntil.Add(time.Minute)
+	copied.LockedUntil, copied.Attempts = &reclaimed, 2
+	repo.records[1] = &copied
+	svc := &outboxService{repo: repo, transport: &failingTransport{err: errors.New("timeout")}, opts: testOutboxOptions}
+
+	svc.deliver(stale)
+
+	if _, ok := repo.retried[1]; ok {
+		t.Error("stale worker scheduled a retry over the new claim")
+	}
+	if record := repo.records[1]; record.Status != models.OutboxSending || record.Attempts != 2 {
+		t.Errorf("record = %+v, want still sending by the second worker", record)
+	}
+}
+
+func TestOutboxEnqueueAllRecipientsAtOnce(t *testing.T) {
+	repo := &batchOutbox{err: errors.New("connection reset")}
+	svc := &outboxService{repo: repo, opts: testOutboxOptions}
+	msg := &mail.Message{ID: "m1", To: []string{"a@example.com", "b@example.com"}}
+
+	if err := svc.Enqueue(mail.TemplateVerificationCode, msg); err != ErrOutboxUnavailable {
+		t.Fatalf("Enqueue error = %v, want %v", err, ErrOutboxUnavailable)
+	}
+	repo.err = nil
+	if err := svc.Enqueue(mail.TemplateVerificationCode, msg); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if len(repo.batches) != 1 || len(repo.batches[0]) != 2 {
+		t.Fatalf("batches = %+v, want one batch with both recipients", repo.batches)
+	}
+	if id := repo.batches[0][1].MessageID; id != "m1.b@example.com" {
+		t.Errorf("message id = %q, want %q", id, "m1.b@example.com")
+	}
+}
+
+// batchOutbox сохраняет письма пачками; при err не сохраняет ничего
+type batchOutbox struct {
+	repository.OutboxRepository
+	err     error
+	batches [][]models.EmailOutbox
+}
+
+func (r *batchOutbox) Create(msgs []models.EmailOutbox) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.batches = append(r.batches, msgs)
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"time"
+
+	"family_finance_back/internal/models"
+)
+
+// maxRecurrenceInterval наибольший интервал правила повторения
+const maxRecurrenceInterval = 999
+
+var (
+	// ErrRecurrenceInvalid возвращается, если правило повторения задано некорректно
+	ErrRecurrenceInvalid = newError(KindInvalid, "некорректное правило повторения")
+
+	// ErrRecurrenceMonthDay возвращается, если день месяца указан не для ежемесячного повтора
+	ErrRecurrenceMonthDay = newError(KindInvalid, "день месяца указывается только для ежемесячного повтора")
+
+	// ErrRecurrenceBusinessDay возвращается, если перенос на рабочий день указан для ежедневного или еженедельного повтора
+	ErrRecurrenceBusinessDay = newError(KindInvalid, "перенос на рабочий день доступен только для ежемесячного и ежегодного повтора")
+
+	// ErrRecurrenceEndBeforeStart возвращается, если дата окончания повторений раньше даты начала
+	ErrRecurrenceEndBeforeStart = newError(KindInvalid, "дата окончания повторений не может быть раньше даты начала")
+)
+
+// checkRecurrenceRule проверяет правило повторения и приводит даты к дню без времени
+func checkRecurrenceRule(rule *models.RecurrenceRule) error {
+	if !rule.Frequency.Valid() || rule.Interval < 1 || rule.Interval > maxRecurrenceInterval ||
+		rule.MonthDay < -1 || rule.MonthDay > 31 {
+		return ErrRecurrenceInvalid
+	}
+	if rule.MonthDay != 0 && rule.Frequency != models.RecurMonthly {
+		return ErrRecurrenceMonthDay
+	}
+	// При ежедневном и еженедельном повторе перенос с выходных дал бы повторные даты
+	if rule.BusinessDay && (rule.Frequency == models.RecurDaily || rule.Frequency == models.RecurWeekly) {
+		return ErrRecurrenceBusinessDay
+	}
+	rule.StartDate = dateOnly(rule.StartDate)
+	if rule.EndDate != nil {
+		end := dateOnly(*rule.EndDate)
+		if end.Before(rule.StartDate) {
+			return ErrRecurrenceEndBeforeStart
+		}
+		rule.EndDate = &end
+	}
+	return nil
+}
+
+// dateOnly отбрасывает время и часовой пояс даты
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// occurrenceAt вычисляет k-е по счёту (с нуля) повторение правила без учёта даты окончания
+func occurrenceAt(rule models.RecurrenceRule, k int) time.Time {
+	start := rule.StartDate
+	var date time.Time
+	switch rule.Frequency {
+	case models.RecurDaily:
+		return start.AddDate(0, 0, k*rule.Interval)
+	case models.RecurWeekly:
+		return start.AddDate(0, 0, 7*k*rule.Interval)
+	case models.RecurMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(k*rule.Interval), 1, 0, 0, 0, 0, time.UTC)
+		day := rule.MonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		date = clampDay(first, day)
+	default:
+		first := time.Date(start.Year()+k*rule.Interval, start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		date = clampDay(first, start.Day())
+	}
+	if rule.BusinessDay {
+		shifted := date
+		switch date.Weekday() {
+		case time.Saturday:
+			shifted = date.AddDate(0, 0, -1)
+		case time.Sunday:
+			shifted = date.AddDate(0, 0, -2)
+		}
+		// Пятница до даты начала отбросила бы первое повторение: переносим на понедельник
+		if shifted.Before(start) {
+			shifted = date.AddDate(0, 0, int(time.Monday+7-date.Weekday())%7)
+		}
+		date = shifted
+	}
+	return date
+}
+
+// clampDay возвращает день day месяца first; -1 и дни за концом месяца означают последний день
+func clampDay(first time.Time, day int) time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if day == -1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// nextOccurrence возвращает первое повторение правила не раньше from
+// Возвращает nil, если повторения закончились
+func nextOccurrence(rule models.RecurrenceRule, from time.Time) *time.Time {
+	from = dateOnly(from)
+	start := rule.StartDate
+	// Оценка номера повторения; перенос на рабочий день сдвигает даты не больше чем на два дня,
+	// поэтому начинаем с предыдущего номера
+	k := 0
+	if from.After(start) {
+		days := int(from.Sub(start).Hours() / 24)
+		months := (from.Year()-start.Year())*12 + int(from.Month()) - int(start.Month())
+		switch rule.Frequency {
+		case models.RecurDaily:
+			k = days / rule.Interval
+		case models.RecurWeekly:
+			k = days / (7 * rule.Interval)
+		case models.RecurMonthly:
+			k = months / rule.Interval
+		default:
+			k = months / 12 / rule.Interval
+		}
+		if k > 0 {
+			k--
+		}
+	}
+	for ; ; k++ {
+		date := occurrenceAt(rule, k)
+		if date.Before(from) || date.Before(start) {
+			continue
+		}
+		if rule.EndDate != nil && date.After(*rule.EndDate) {
+			return nil
+		}
+		return &date
+	}
+}
+
+// isOccurrence сообщает, приходится ли на дату повторение правила
+func isOccurrence(rule models.RecurrenceRule, date time.Time) bool {
+	next := nextOccurrence(rule, date)
+	return next != nil && next.Equal(dateOnly(date))
+}
+
+// occurrencesBetween возвращает даты повторений правила в периоде [from, to], не больше limit
+func occurrencesBetween(rule models.RecurrenceRule, from, to time.Time, limit int) []time.Time {
+	var dates []time.Time
+	for next := nextOccurrence(rule, from); next != nil && !next.After(to) && len(dates) < limit; {
+		dates = append(dates, *next)
+		next = nextOccurrence(rule, next.AddDate(0, 0, 1))
+	}
+	return dates
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"family_finance_back/internal/models"
+)
+
+// day возвращает дату без времени
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	end := day(2026, 6, 30)
+	tests := []struct {
+		name string
+		rule models.RecurrenceRule
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "31-е число в феврале — последний день месяца",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, StartDate: day(2026, 1, 31)},
+			from: day(2026, 2, 1),
+			want: day(2026, 2, 28),
+		},
+		{
+			name: "31-е число в високосном феврале",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, StartDate: day(2028, 1, 31)},
+			from: day(2028, 2, 1),
+			want: day(2028, 2, 29),
+		},
+		{
+			name: "после короткого месяца возвращается 31-е число",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, StartDate: day(2026, 1, 31)},
+			from: day(2026, 3, 1),
+			want: day(2026, 3, 31),
+		},
+		{
+			name: "последний день месяца",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, MonthDay: -1, StartDate: day(2026, 1, 10)},
+			from: day(2026, 4, 1),
+			want: day(2026, 4, 30),
+		},
+		{
+			name: "29 февраля раз в год",
+			rule: models.RecurrenceRule{Frequency: models.RecurYearly, Interval: 1, StartDate: day(2028, 2, 29)},
+			from: day(2029, 1, 1),
+			want: day(2029, 2, 28),
+		},
+		{
+			name: "суббота переносится на пятницу",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, MonthDay: 15, BusinessDay: true, StartDate: day(2026, 1, 1)},
+			from: day(2026, 8, 1),
+			want: day(2026, 8, 14),
+		},
+		{
+			name: "последний рабочий день месяца",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, MonthDay: -1, BusinessDay: true, StartDate: day(2026, 1, 1)},
+			from: day(2026, 5, 1),
+			want: day(2026, 5, 29),
+		},
+		{
+			name: "первое повторение в субботу не раньше даты начала",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, MonthDay: 15, BusinessDay: true, StartDate: day(2026, 8, 15)},
+			from: day(2026, 8, 15),
+			want: day(2026, 8, 17),
+		},
+		{
+			name: "первое повторение в воскресенье не раньше даты начала",
+			rule: models.RecurrenceRule{Frequency: models.RecurYearly, Interval: 1, BusinessDay: true, StartDate: day(2026, 3, 1)},
+			from: day(2026, 1, 1),
+			want: day(2026, 3, 2),
+		},
+		{
+			name: "следующие повторения снова переносятся на пятницу",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, MonthDay: 15, BusinessDay: true, StartDate: day(2026, 8, 15)},
+			from: day(2026, 11, 1),
+			want: day(2026, 11, 13),
+		},
+		{
+			name: "каждые две недели",
+			rule: models.RecurrenceRule{Frequency: models.RecurWeekly, Interval: 2, StartDate: day(2026, 10, 5)},
+			from: day(2026, 10, 6),
+			want: day(2026, 10, 19),
+		},
+		{
+			name: "после даты окончания повторений нет",
+			rule: models.RecurrenceRule{Frequency: models.RecurMonthly, Interval: 1, StartDate: day(2026, 1, 31), EndDate: &end},
+			from: day(2026, 7, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextOccurrence(tt.rule, tt.from)
+			if tt.want.IsZero() {
+				if got != nil {
+					t.Errorf("nextOccurrence = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || !got.Equal(tt.want) {
+				t.Errorf("nextOccurrence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,664 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+const (
+	// recurringBatchSize количество шаблонов, которое планировщик обрабатывает за один проход
+	recurringBatchSize = 50
+
+	// maxCatchUpOccurrences сколько пропущенных повторений одного шаблона проводится за один проход;
+	// остальные проводятся в следующих проходах
+	maxCatchUpOccurrences = 100
+)
+
+var (
+	// ErrRecurringNotFound возвращается, если регулярная операция не существует или недоступна пользователю
+	ErrRecurringNotFound = newError(KindNotFound, "регулярная операция не найдена")
+
+	// ErrRecurringForbidden возвращается, если пользователь не может изменять регулярную операцию
+	ErrRecurringForbidden = newError(KindForbidden, "изменять регулярную операцию может только её автор или владелец семьи")
+
+	// ErrRecurringFinished возвращается при изменении повторения у регулярной операции, повторения которой закончились
+	ErrRecurringFinished = newError(KindConflict, "повторения регулярной операции закончились")
+
+	// ErrOccurrenceNotFound возвращается, если на указанную дату нет повторения
+	ErrOccurrenceNotFound = newError(KindInvalid, "на эту дату нет повторения регулярной операции")
+
+	// ErrOccurrencePassed возвращается при изменении повторения, дата которого уже прошла
+	ErrOccurrencePassed = newError(KindConflict, "повторение уже прошло; изменить можно только предстоящие повторения")
+
+	// ErrOccurrencePosted возвращается при изменении повторения, по которому уже проведена операция
+	ErrOccurrencePosted = newError(KindConflict, "операция по этому повторению уже проведена; измените её в списке операций")
+)
+
+// RecurringInput данные новой регулярной операции
+type RecurringInput struct {
+	AccountID uint
+	Direction models.Direction
+	// Amount сумма в минимальных единицах валюты счёта
+	Amount     int64
+	CategoryID *uint
+	Payee      string
+	Note       string
+	Rule       models.RecurrenceRule
+}
+
+// RecurringChanges изменения регулярной операции, действующие на все будущие повторения;
+// nil и нулевые поля не изменяются
+type RecurringChanges struct {
+	AccountID uint
+	Direction models.Direction
+	Amount    int64
+	// CategoryID новая категория; указатель на 0 снимает категорию
+	CategoryID *uint
+	Payee      *string
+	Note       *string
+	// Rule новое правило; даты повторений пересчитываются начиная с сегодняшнего дня
+	Rule *models.RecurrenceRule
+}
+
+// OccurrenceChanges изменения одного предстоящего повторения; nil и нулевые поля не изменяются
+type OccurrenceChanges struct {
+	Amount     int64
+	CategoryID *uint
+	Payee      *string
+	Note       *string
+}
+
+// Occurrence повторение регулярной операции с учётом изменений этого повторения
+type Occurrence struct {
+	Date   time.Time               `json:"date"`
+	Status models.OccurrenceStatus `json:"status"`
+	// Amount сумма в минимальных единицах валюты Currency
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	CategoryID *uint  `json:"category_id"`
+	Payee      string `json:"payee"`
+	Note       string `json:"note"`
+	// TransactionID проведённая операция; только для status = posted
+	TransactionID *uint `json:"transaction_id"`
+}
+
+// RecurringService определяет интерфейс для работы с регулярными операциями
+type RecurringService interface {
+	// Create создает регулярную операцию по счёту, который пользователь вправе вести
+	Create(user *models.User, input RecurringInput) (*models.RecurringTransaction, error)
+
+	// Get возвращает регулярную операцию; доступно любому участнику семьи
+	Get(user *models.User, id uint) (*models.RecurringTransaction, error)
+
+	// List возвращает регулярные операции семьи; доступно любому участнику семьи
+	List(user *models.User, familyID uint) ([]models.RecurringTransaction, error)
+
+	// Update изменяет регулярную операцию для всех будущих повторений;
+	// уже проведённые операции не меняются. Доступно автору и владельцу семьи
+	Update(user *models.User, id uint, changes RecurringChanges) (*models.RecurringTransaction, error)
+
+	// UpdateOccurrence изменяет только одно предстоящее повторение в дату date
+	UpdateOccurrence(user *models.User, id uint, date time.Time, changes OccurrenceChanges) (*Occurrence, error)
+
+	// Skip пропускает предстоящее повторение в дату date или, если skip = false, возвращает его
+	Skip(user *models.User, id uint, date time.Time, skip bool) (*Occurrence, error)
+
+	// Pause приостанавливает или возобновляет регулярную операцию
+	// Повторения, пропущенные за время паузы, после возобновления не проводятся
+	Pause(user *models.User, id uint, paused bool) (*models.RecurringTransaction, error)
+
+	// Delete удаляет регулярную операцию; проведённые по ней операции остаются
+	Delete(user *models.User, id uint) error
+
+	// Occurrences возвращает проведённые, пропущенные и предстоящие повторения в периоде [from, to],
+	// не больше limit; доступно любому участнику семьи
+	Occurrences(user *models.User, id uint, from, to time.Time, limit int) ([]Occurrence, error)
+
+	// Run запускает планировщик, который проводит наступившие повторения, и блокируется до отмены ctx
+	Run(ctx context.Context)
+}
+
+// recurringService реализует интерфейс RecurringService
+type recurringService struct {
+	transactor    repository.Transactor
+	recurringRepo repository.RecurringRepository
+	accountRepo   repository.AccountRepository
+	categoryRepo  repository.CategoryRepository
+	familyRepo    repository.FamilyRepository
+	alerts        BudgetAlertService
+	approvals     ApprovalService
+	limits        SpendingLimitService
+	pollInterval  time.Duration
+}
+
+// NewRecurringService создает новый экземпляр RecurringService
+// Планировщик проверяет наступившие повторения каждые pollInterval
+func NewRecurringService(transactor repository.Transactor, recurringRepo repository.RecurringRepository,
+	accountRepo repository.AccountRepository, categoryRepo repository.CategoryRepository,
+	familyRepo repository.FamilyRepository, alerts BudgetAlertService, approvals ApprovalService,
+	limits SpendingLimitService, pollInterval time.Duration) RecurringService {
+	return &recurringService{
+		transactor:    transactor,
+		recurringRepo: recurringRepo,
+		accountRepo:   accountRepo,
+		categoryRepo:  categoryRepo,
+		familyRepo:    familyRepo,
+		alerts:        alerts,
+		approvals:     approvals,
+		limits:        limits,
+		pollInterval:  pollInterval,
+	}
+}
+
+// canEditRecurring сообщает, может ли участник семьи изменять регулярную операцию
+func canEditRecurring(member *models.FamilyMember, recurring *models.RecurringTransaction) bool {
+	if member.Role == models.RoleOwner {
+		return true
+	}
+	return recurring.CreatedBy == member.UserID && member.Role != models.RoleViewer
+}
+
+func (s *recurringService) Create(user *models.User, input RecurringInput) (*models.RecurringTransaction, error) {
+	account, member, err := postableAccount(s.accountRepo, s.familyRepo, user, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if input.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, account.FamilyID, *input.CategoryID, input.Direction, false); err != nil {
+			return nil, err
+		}
+	}
+	rule := input.Rule
+	if err := checkRecurrenceRule(&rule); err != nil {
+		return nil, err
+	}
+	recurring := &models.RecurringTransaction{
+		FamilyID:   account.FamilyID,
+		AccountID:  account.ID,
+		Direction:  input.Direction,
+		Amount:     input.Amount,
+		Currency:   account.Currency,
+		CategoryID: input.CategoryID,
+		Payee:      input.Payee,
+		Note:       input.Note,
+		Rule:       rule,
+		NextDate:   nextOccurrence(rule, rule.StartDate),
+		CreatedBy:  member.UserID,
+	}
+	if err := s.recurringRepo.Create(recurring); err != nil {
+		return nil, errors.New("не удалось сохранить регулярную операцию, попробуйте позже")
+	}
+	return recurring, nil
+}
+
+func (s *recurringService) Get(user *models.User, id uint) (*models.RecurringTransaction, error) {
+	recurring, _, err := s.load(user, id)
+	return recurring, err
+}
+
+func (s *recurringService) List(user *models.User, familyID uint) ([]models.RecurringTransaction, error) {
+	if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+		return nil, err
+	}
+	list, err := s.recurringRepo.ListByFamily(familyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список регулярных операций")
+	}
+	if list == nil {
+		list = []models.RecurringTransaction{}
+	}
+	return list, nil
+}
+
+func (s *recurringService) Update(user *models.User, id uint, changes RecurringChanges) (*models.RecurringTransaction, error) {
+	recurring, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.AccountID != 0 && changes.AccountID != recurring.AccountID {
+		account, _, err := postableAccount(s.accountRepo, s.familyRepo, user, changes.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if account.FamilyID != recurring.FamilyID {
+			return nil, ErrAccountFamilyMismatch
+		}
+		if account.Currency != recurring.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+		recurring.AccountID = account.ID
+	}
+	directionChanged := changes.Direction != "" && changes.Direction != recurring.Direction
+	if changes.Direction != "" {
+		recurring.Direction = changes.Direction
+	}
+	if changes.Amount != 0 {
+		recurring.Amount = changes.Amount
+	}
+	switch {
+	case changes.CategoryID != nil && *changes.CategoryID == 0:
+		recurring.CategoryID = nil
+	case changes.CategoryID != nil:
+		if err := checkCategory(s.categoryRepo, recurring.FamilyID, *changes.CategoryID, recurring.Direction, false); err != nil {
+			return nil, err
+		}
+		recurring.CategoryID = changes.CategoryID
+	case directionChanged && recurring.CategoryID != nil:
+		if err := checkCategory(s.categoryRepo, recurring.FamilyID, *recurring.CategoryID, recurring.Direction, true); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Payee != nil {
+		recurring.Payee = *changes.Payee
+	}
+	if changes.Note != nil {
+		recurring.Note = *changes.Note
+	}
+	if changes.Rule != nil {
+		rule := *changes.Rule
+		if err := checkRecurrenceRule(&rule); err != nil {
+			return nil, err
+		}
+		today, err := familyToday(s.familyRepo, recurring.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		// Прошедшие даты по новому правилу не проводятся
+		from := rule.StartDate
+		if today.After(from) {
+			from = today
+		}
+		recurring.Rule = rule
+		recurring.NextDate = nextOccurrence(rule, from)
+	}
+	if err := s.recurringRepo.Update(recurring); err != nil {
+		return nil, errors.New("не удалось сохранить регулярную операцию, попробуйте позже")
+	}
+	return recurring, nil
+}
+
+func (s *recurringService) UpdateOccurrence(user *models.User, id uint, date time.Time, changes OccurrenceChanges) (*Occurrence, error) {
+	recurring, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := s.upcoming(recurring, date)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Amount != 0 {
+		occurrence.Amount = &changes.Amount
+	}
+	if changes.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, recurring.FamilyID, *changes.CategoryID, recurring.Direction, false); err != nil {
+			return nil, err
+		}
+		occurrence.CategoryID = changes.CategoryID
+	}
+	if changes.Payee != nil {
+		occurrence.Payee = changes.Payee
+	}
+	if changes.Note != nil {
+		occurrence.Note = changes.Note
+	}
+	if err := s.recurringRepo.SaveOccurrence(occurrence); err != nil {
+		return nil, errors.New("не удалось сохранить повторение, попробуйте позже")
+	}
+	result := resolveOccurrence(recurring, occurrence, occurrence.Date)
+	return &result, nil
+}
+
+func (s *recurringService) Skip(user *models.User, id uint, date time.Time, skip bool) (*Occurrence, error) {
+	recurring, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := s.upcoming(recurring, date)
+	if err != nil {
+		return nil, err
+	}
+	occurrence.Status = models.OccurrenceScheduled
+	if skip {
+		occurrence.Status = models.OccurrenceSkipped
+	}
+	if err := s.recurringRepo.SaveOccurrence(occurrence); err != nil {
+		return nil, errors.New("не удалось сохранить повторение, попробуйте позже")
+	}
+	result := resolveOccurrence(recurring, occurrence, occurrence.Date)
+	return &result, nil
+}
+
+func (s *recurringService) Pause(user *models.User, id uint, paused bool) (*models.RecurringTransaction, error) {
+	recurring, err := s.editable(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if recurring.Paused == paused {
+		return recurring, nil
+	}
+	if !paused && recurring.NextDate != nil {
+		today, err := familyToday(s.familyRepo, recurring.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if today.After(*recurring.NextDate) {
+			recurring.NextDate = nextOccurrence(recurring.Rule, today)
+		}
+	}
+	recurring.Paused = paused
+	if err := s.recurringRepo.Update(recurring); err != nil {
+		return nil, errors.New("не удалось сохранить регулярную операцию, попробуйте позже")
+	}
+	return recurring, nil
+}
+
+func (s *recurringService) Delete(user *models.User, id uint) error {
+	recurring, err := s.editable(user, id)
+	if err != nil {
+		return err
+	}
+	if err := s.recurringRepo.Delete(recurring.ID); err != nil {
+		return errors.New("не удалось удалить регулярную операцию, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *recurringService) Occurrences(user *models.User, id uint, from, to time.Time, limit int) ([]Occurrence, error) {
+	recurring, _, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := s.recurringRepo.ListOccurrences(recurring.ID, from, to)
+	if err != nil {
+		return nil, errors.New("не удалось получить повторения регулярной операции")
+	}
+	byDate := make(map[time.Time]*models.RecurringOccurrence, len(recorded))
+	for i := range recorded {
+		byDate[dateOnly(recorded[i].Date)] = &recorded[i]
+	}
+
+	result := make([]Occurrence, 0)
+	// Прошедшие повторения известны только по записям; предстоящие вычисляются по правилу
+	for i := range recorded {
+		if recurring.NextDate == nil || recorded[i].Date.Before(*recurring.NextDate) {
+			result = append(result, resolveOccurrence(recurring, &recorded[i], dateOnly(recorded[i].Date)))
+		}
+	}
+	if recurring.NextDate != nil {
+		start := from
+		if recurring.NextDate.After(start) {
+			start = *recurring.NextDate
+		}
+		for _, date := range occurrencesBetween(recurring.Rule, start, to, limit) {
+			result = append(result, resolveOccurrence(recurring, byDate[date], date))
+		}
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// resolveOccurrence применяет к шаблону изменения повторения в дату date
+// occurrence может быть nil, если повторение не отличается от шаблона
+func resolveOccurrence(recurring *models.RecurringTransaction, occurrence *models.RecurringOccurrence, date time.Time) Occurrence {
+	result := Occurrence{
+		Date:       date,
+		Status:     models.OccurrencePlanned,
+		Amount:     recurring.Amount,
+		Currency:   recurring.Currency,
+		CategoryID: recurring.CategoryID,
+		Payee:      recurring.Payee,
+		Note:       recurring.Note,
+	}
+	if occurrence == nil {
+		return result
+	}
+	result.Status = occurrence.Status
+	result.TransactionID = occurrence.TransactionID
+	if occurrence.Amount != nil {
+		result.Amount = *occurrence.Amount
+	}
+	if occurrence.CategoryID != nil {
+		result.CategoryID = occurrence.CategoryID
+	}
+	if occurrence.Payee != nil {
+		result.Payee = *occurrence.Payee
+	}
+	if occurrence.Note != nil {
+		result.Note = *occurrence.Note
+	}
+	return result
+}
+
+// upcoming возвращает запись о предстоящем повторении в дату date для изменения
+// Если повторение ещё не отличалось от шаблона, возвращается новая запись
+func (s *recurringService) upcoming(recurring *models.RecurringTransaction, date time.Time) (*models.RecurringOccurrence, error) {
+	date = dateOnly(date)
+	if recurring.NextDate == nil {
+		return nil, ErrRecurringFinished
+	}
+	if !isOccurrence(recurring.Rule, date) {
+		return nil, ErrOccurrenceNotFound
+	}
+	occurrence, err := s.recurringRepo.GetOccurrence(recurring.ID, date)
+	if err != nil {
+		return nil, errors.New("не удалось получить повторение регулярной операции")
+	}
+	if occurrence != nil && occurrence.Status == models.OccurrencePosted {
+		return nil, ErrOccurrencePosted
+	}
+	if date.Before(*recurring.NextDate) {
+		return nil, ErrOccurrencePassed
+	}
+	if occurrence == nil {
+		occurrence = &models.RecurringOccurrence{RecurringID: recurring.ID, Date: date, Status: models.OccurrenceScheduled}
+	}
+	return occurrence, nil
+}
+
+// editable получает регулярную операцию, которую пользователь вправе изменять
+func (s *recurringService) editable(user *models.User, id uint) (*models.RecurringTransaction, error) {
+	recurring, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canEditRecurring(member, recurring) {
+		return nil, ErrRecurringForbidden
+	}
+	return recurring, nil
+}
+
+// load получает регулярную операцию и членство пользователя в её семье
+// Регулярная операция чужой семьи неотличима от несуществующей
+func (s *recurringService) load(user *models.User, id uint) (*models.RecurringTransaction, *models.FamilyMember, error) {
+	recurring, err := s.recurringRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить регулярную операцию")
+	}
+	if recurring == nil {
+		return nil, nil, ErrRecurringNotFound
+	}
+	member, err := requireMember(s.familyRepo, recurring.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrRecurringNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return recurring, member, nil
+}
+
+func (s *recurringService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Пока проход обрабатывает полную пачку шаблонов, следующий запускается без ожидания
+		for ctx.Err() == nil && s.postDue() == recurringBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// postDue проводит наступившие повторения одной пачки шаблонов
+// Возвращает количество шаблонов, по которым продвинулась дата следующего повторения
+func (s *recurringService) postDue() int {
+	// В часовых поясах восточнее UTC завтрашний по UTC день может уже наступить;
+	// точная дата проверяется по часовому поясу семьи
+	until := dateOnly(time.Now().UTC()).AddDate(0, 0, 1)
+	ids, err := s.recurringRepo.ListDue(until, recurringBatchSize)
+	if err != nil {
+		log.Printf("recurring: не удалось получить регулярные операции к проведению: %v", err)
+		return 0
+	}
+	advanced := 0
+	for _, id := range ids {
+		posted, ok, err := s.postRecurring(id)
+		if err != nil {
+			log.Printf("recurring: не удалось провести регулярную операцию %d: %v", id, err)
+			continue
+		}
+		if ok {
+			advanced++
+		}
+		s.notifyPosted(posted)
+	}
+	return advanced
+}
+
+// postRecurring в одной транзакции проводит наступившие повторения шаблона и сдвигает дату
+// следующего повторения. Строка шаблона блокируется, а проведённые повторения отмечаются
+// уникальной записью, поэтому повторный запуск после перезапуска или на другой реплике
+// не проводит операцию дважды
+func (s *recurringService) postRecurring(id uint) ([]*models.Transaction, bool, error) {
+	var posted []*models.Transaction
+	advanced := false
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		posted, advanced = nil, false
+		recurring, err := repos.Recurring.LockByID(id)
+		if err != nil || recurring == nil || recurring.Paused || recurring.NextDate == nil {
+			return err
+		}
+		today, err := familyToday(repos.Families, recurring.FamilyID)
+		if err != nil {
+			return err
+		}
+		if recurring.NextDate.After(today) {
+			return nil
+		}
+		advanced = true
+
+		account, err := repos.Accounts.GetByID(recurring.AccountID)
+		if err != nil {
+			return err
+		}
+		if account == nil || account.Archived {
+			// По архивному счёту операции не проводятся: приостанавливаем шаблон до решения пользователя
+			log.Printf("recurring: счёт %d недоступен, регулярная операция %d приостановлена", recurring.AccountID, recurring.ID)
+			recurring.Paused = true
+			return repos.Recurring.Update(recurring)
+		}
+
+		for n := 0; recurring.NextDate != nil && !recurring.NextDate.After(today) && n < maxCatchUpOccurrences; n++ {
+			date := dateOnly(*recurring.NextDate)
+			occurrence, err := repos.Recurring.GetOccurrence(recurring.ID, date)
+			if err != nil {
+				return err
+			}
+			if occurrence == nil || occurrence.Status == models.OccurrenceScheduled {
+				tx, err := s.postOccurrence(repos, recurring, occurrence, date)
+				if err != nil {
+					return err
+				}
+				if tx != nil {
+					posted = append(posted, tx)
+				}
+			}
+			recurring.NextDate = nextOccurrence(recurring.Rule, date.AddDate(0, 0, 1))
+		}
+		return repos.Recurring.Update(recurring)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return posted, advanced, nil
+}
+
+// postOccurrence проводит операцию по повторению и отмечает повторение проведённым
+// Расход проверяется правилами одобрения и лимитами автора шаблона, как записанный вручную:
+// подходящий под правило сохраняется ожидающим одобрения, а превышающий лимит с действием
+// reject не проводится, и повторение отмечается пропущенным; тогда возвращается nil
+// Вызывайте внутри Transactor.Transaction
+func (s *recurringService) postOccurrence(repos *repository.Repositories, recurring *models.RecurringTransaction,
+	occurrence *models.RecurringOccurrence, date time.Time) (*models.Transaction, error) {
+	resolved := resolveOccurrence(recurring, occurrence, date)
+	tx := &models.Transaction{
+		FamilyID:   recurring.FamilyID,
+		AccountID:  recurring.AccountID,
+		Direction:  recurring.Direction,
+		Amount:     resolved.Amount,
+		Currency:   recurring.Currency,
+		CategoryID: resolved.CategoryID,
+		Date:       date,
+		Payee:      resolved.Payee,
+		Note:       resolved.Note,
+		CreatedBy:  recurring.CreatedBy,
+	}
+	if occurrence == nil {
+		occurrence = &models.RecurringOccurrence{RecurringID: recurring.ID, Date: date}
+	}
+
+	rule, err := s.approvals.Match(tx, recurring.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		tx.Status = models.TransactionPending
+	}
+	if err := s.limits.Enforce(repos, tx); err != nil {
+		var serviceErr *Error
+		if !errors.As(err, &serviceErr) {
+			return nil, err
+		}
+		log.Printf("recurring: повторение %s регулярной операции %d пропущено: %v",
+			date.Format("2006-01-02"), recurring.ID, serviceErr)
+		occurrence.Status = models.OccurrenceSkipped
+		return nil, repos.Recurring.SaveOccurrence(occurrence)
+	}
+	if err := postTransaction(repos, tx); err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		if err := requestApproval(repos, tx, rule, recurring.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+	occurrence.Status = models.OccurrencePosted
+	occurrence.TransactionID = &tx.ID
+	if err := repos.Recurring.SaveOccurrence(occurrence); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// notifyPosted проверяет пороги бюджетов по проведённым повторениям
+// и уведомляет взрослых о расходах, ожидающих одобрения
+func (s *recurringService) notifyPosted(posted []*models.Transaction) {
+	for _, tx := range posted {
+		if tx.Posted() {
+			s.alerts.CheckTransaction(tx)
+			continue
+		}
+		s.approvals.NotifyApprovers(tx, tx.CreatedBy)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+type memRecurring struct {
+	repository.RecurringRepository
+	recurring   models.RecurringTransaction
+	occurrences map[time.Time]*models.RecurringOccurrence
+}
+
+func (r *memRecurring) LockByID(uint) (*models.RecurringTransaction, error) {
+	recurring := r.recurring
+	return &recurring, nil
+}
+
+func (r *memRecurring) Update(recurring *models.RecurringTransaction) error {
+	r.recurring = *recurring
+	return nil
+}
+
+func (r *memRecurring) GetOccurrence(_ uint, date time.Time) (*models.RecurringOccurrence, error) {
+	occurrence, ok := r.occurrences[date]
+	if !ok {
+		return nil, nil
+	}
+	copied := *occurrence
+	return &copied, nil
+}
+
+func (r *memRecurring) SaveOccurrence(occurrence *models.RecurringOccurrence) error {
+	r.occurrences[occurrence.Date] = occurrence
+	return nil
+}
+
+func TestPostRecurringAppliesApprovalsAndLimits(t *testing.T) {
+	f := newApprovalFixture()
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	amount := func(v int64) *int64 { return &v }
+	// Шаблон ребёнка на три дня; суммы повторений изменены отдельно
+	recurring := &memRecurring{
+		recurring: models.RecurringTransaction{
+			ID: 7, FamilyID: 1, AccountID: f.accountID, Direction: models.DirectionExpense,
+			Amount: 50000, Currency: "RUB", Payee: "Кружок", CreatedBy: f.child.ID,
+			Rule:     models.RecurrenceRule{Frequency: models.RecurDaily, Interval: 1, StartDate: start, EndDate: &end},
+			NextDate: &start,
+		},
+		occurrences: map[time.Time]*models.RecurringOccurrence{
+			start.AddDate(0, 0, 1): {RecurringID: 7, Date: start.AddDate(0, 0, 1), Status: models.OccurrenceScheduled, Amount: amount(150000)},
+			end:                    {RecurringID: 7, Date: end, Status: models.OccurrenceScheduled, Amount: amount(500000)},
+		},
+	}
+	repos := &repository.Repositories{
+		Transactions: memTransactions{m: f.store},
+		Ledger:       memLedger{m: f.store},
+		Accounts:     memAccounts{m: f.store},
+		Families:     memFamilies{m: f.store},
+		Approvals:    memApprovals{m: f.store},
+		Recurring:    recurring,
+	}
+	svc := &recurringService{
+		transactor: memTransactor{repos: repos},
+		alerts:     noAlerts{},
+		approvals:  f.approvals,
+		limits:     rejectAbove{max: 300000},
+	}
+
+	posted, advanced, err := svc.postRecurring(7)
+	if err != nil {
+		t.Fatalf("postRecurring: %v", err)
+	}
+	if !advanced || recurring.recurring.NextDate != nil {
+		t.Errorf("advanced = %v, next date = %v, want finished template", advanced, recurring.recurring.NextDate)
+	}
+
+	if len(posted) != 2 {
+		t.Fatalf("posted %d transactions, want 2", len(posted))
+	}
+	if status := posted[0].Status; status != models.TransactionPosted {
+		t.Errorf("small expense status = %q, want %q", status, models.TransactionPosted)
+	}
+	if status := posted[1].Status; status != models.TransactionPending {
+		t.Errorf("large expense status = %q, want %q", status, models.TransactionPending)
+	}
+	if approval := f.store.approvals[posted[1].ID]; approval == nil || approval.RequestedBy != f.child.ID {
+		t.Errorf("approval = %+v, want pending request by template author", approval)
+	}
+	if got := f.store.balance(f.accountID); got != -50000 {
+		t.Errorf("balance = %d, want -50000", got)
+	}
+
+	for date, want := range map[time.Time]models.OccurrenceStatus{
+		start:                  models.OccurrencePosted,
+		start.AddDate(0, 0, 1): models.OccurrencePosted,
+		end:                    models.OccurrenceSkipped,
+	} {
+		occurrence := recurring.occurrences[date]
+		if occurrence == nil || occurrence.Status != want {
+			t.Errorf("occurrence %s = %+v, want status %q", date.Format("2006-01-02"), occurrence, want)
+		}
+	}
+	if occurrence := recurring.occurrences[end]; occurrence != nil && occurrence.TransactionID != nil {
+		t.Errorf("over-limit occurrence transaction = %d, want none", *occurrence.TransactionID)
+	}
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrSpendingLimitNotFound возвращается, если лимит не существует или недоступен пользователю
+	ErrSpendingLimitNotFound = newError(KindNotFound, "лимит расходов не найден")
+
+	// ErrSpendingLimitForbidden возвращается, если пользователь не может управлять лимитами или смотреть чужие лимиты
+	ErrSpendingLimitForbidden = newError(KindForbidden, "управлять лимитами и смотреть лимиты других участников могут только владелец и взрослые")
+
+	// ErrSpendingLimitMember возвращается, если участник лимита не состоит в семье
+	ErrSpendingLimitMember = newError(KindInvalid, "лимит назначается участнику семьи")
+
+	// ErrSpendingLimitExists возвращается, если у участника уже есть лимит на этот счёт и период
+	ErrSpendingLimitExists = newError(KindConflict, "у участника уже есть лимит на этот счёт и период")
+)
+
+// SpendingLimitInput данные нового лимита расходов
+type SpendingLimitInput struct {
+	UserID uint
+	// AccountID счёт; nil — расходы по всем счетам семьи
+	AccountID *uint
+	// Amount лимит в минимальных единицах валюты
+	Amount int64
+	// Currency валюта лимита; по умолчанию валюта счёта или основная валюта семьи
+	Currency string
+	Period   models.LimitPeriod
+	// Action действие при превышении; по умолчанию расход отклоняется
+	Action models.LimitAction
+}
+
+// SpendingLimitChanges изменения лимита; нулевые поля не изменяются
+type SpendingLimitChanges struct {
+	Amount int64
+	Period models.LimitPeriod
+	Action models.LimitAction
+}
+
+// LimitUsage расходы участника по лимиту за текущий период
+type LimitUsage struct {
+	Limit       models.SpendingLimit `json:"limit"`
+	PeriodStart time.Time            `json:"period_start"`
+	PeriodEnd   time.Time            `json:"period_end"`
+	// Spent расходы за период в валюте лимита
+	Spent int64 `json:"spent"`
+	// Remaining сколько ещё можно потратить; 0, если лимит исчерпан
+	Remaining int64 `json:"remaining"`
+	// MissingRates валюты расходов, для которых не найден курс к валюте лимита;
+	// такие расходы не учтены
+	MissingRates []string `json:"missing_rates"`
+}
+
+// SpendingLimitService определяет интерфейс для работы с лимитами расходов участников
+// Лимит ограничивает расходы, которые участник записывает сам, за день, неделю или месяц;
+// расход сверх лимита отклоняется или сохраняется с отметкой — в зависимости от лимита
+type SpendingLimitService interface {
+	// List возвращает лимиты семьи; владелец и взрослые видят все лимиты
+	// или лимиты участника memberID, остальные — только свои
+	List(user *models.User, familyID, memberID uint) ([]models.SpendingLimit, error)
+
+	// Create назначает лимит участнику; доступно владельцу и взрослым
+	Create(user *models.User, familyID uint, input SpendingLimitInput) (*models.SpendingLimit, error)
+
+	// Update изменяет сумму, период или действие лимита; доступно владельцу и взрослым
+	Update(user *models.User, id uint, changes SpendingLimitChanges) (*models.SpendingLimit, error)
+
+	// Delete удаляет лимит; доступно владельцу и взрослым
+	Delete(user *models.User, id uint) error
+
+	// Remaining возвращает расходы и остаток по каждому лимиту участника за текущий период семьи
+	// Без memberID — по лимитам самого пользователя; чужие лимиты видят владелец и взрослые
+	Remaining(user *models.User, familyID, memberID uint) ([]LimitUsage, error)
+
+	// Enforce проверяет расход tx по лимитам его автора до сохранения операции:
+	// возвращает ошибку, если превышен лимит с действием reject, и устанавливает tx.Flagged,
+	// если превышен лимит с действием flag. Прежняя версия операции tx.ID не учитывается
+	// Вызывайте внутри Transactor.Transaction: лимиты автора блокируются до конца транзакции
+	Enforce(repos *repository.Repositories, tx *models.Transaction) error
+}
+
+// spendingLimitService реализует интерфейс SpendingLimitService
+type spendingLimitService struct {
+	limitRepo   repository.SpendingLimitRepository
+	txRepo      repository.TransactionRepository
+	accountRepo repository.AccountRepository
+	familyRepo  repository.FamilyRepository
+	rates       ExchangeRateService
+}
+
+// NewSpendingLimitService создает новый экземпляр SpendingLimitService
+// rates пересчитывает расходы в других валютах в валюту лимита
+func NewSpendingLimitService(limitRepo repository.SpendingLimitRepository, txRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository, familyRepo repository.FamilyRepository,
+	rates ExchangeRateService) SpendingLimitService {
+	return &spendingLimitService{
+		limitRepo:   limitRepo,
+		txRepo:      txRepo,
+		accountRepo: accountRepo,
+		familyRepo:  familyRepo,
+		rates:       rates,
+	}
+}
+
+func (s *spendingLimitService) List(user *models.User, familyID, memberID uint) ([]models.SpendingLimit, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		memberID = member.UserID
+	}
+	limits, err := s.limitRepo.ListByFamily(familyID, memberID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список лимитов")
+	}
+	if limits == nil {
+		limits = []models.SpendingLimit{}
+	}
+	return limits, nil
+}
+
+func (s *spendingLimitService) Create(user *models.User, familyID uint, input SpendingLimitInput) (*models.SpendingLimit, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrSpendingLimitForbidden
+	}
+	target, err := s.familyRepo.GetMember(familyID, input.UserID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные участника")
+	}
+	if target == nil {
+		return nil, ErrSpendingLimitMember
+	}
+
+	currency := input.Currency
+	if input.AccountID != nil {
+		account, err := s.accountRepo.GetByID(*input.AccountID)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные счёта")
+		}
+		if account == nil || account.FamilyID != familyID {
+			return nil, ErrAccountNotFound
+		}
+		if currency != "" && currency != account.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+		currency = account.Currency
+	}
+	if currency == "" {
+		family, err := s.familyRepo.GetByID(familyID)
+		if err != nil || family == nil {
+			return nil, errors.New("не удалось получить данные семьи")
+		}
+		currency = family.Currency
+	}
+
+	action := input.Action
+	if action == "" {
+		action = models.LimitReject
+	}
+	limit := &models.SpendingLimit{
+		FamilyID:  familyID,
+		UserID:    input.UserID,
+		AccountID: input.AccountID,
+		Amount:    input.Amount,
+		Currency:  currency,
+		Period:    input.Period,
+		Action:    action,
+		CreatedBy: user.ID,
+	}
+	if err := s.checkUnique(limit); err != nil {
+		return nil, err
+	}
+	if err := s.limitRepo.Create(limit); err != nil {
+		return nil, errors.New("не удалось сохранить лимит, попробуйте позже")
+	}
+	return limit, nil
+}
+
+func (s *spendingLimitService) Update(user *models.User, id uint, changes SpendingLimitChanges) (*models.SpendingLimit, error) {
+	limit, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Amount != 0 {
+		limit.Amount = changes.Amount
+	}
+	if changes.Action != "" {
+		limit.Action = changes.Action
+	}
+	if changes.Period != "" && changes.Period != limit.Period {
+		limit.Period = changes.Period
+		if err := s.checkUnique(limit); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.limitRepo.Update(limit); err != nil {
+		return nil, errors.New("не удалось сохранить лимит, попробуйте позже")
+	}
+	return limit, nil
+}
+
+func (s *spendingLimitService) Delete(user *models.User, id uint) error {
+	if _, err := s.load(user, id); err != nil {
+		return err
+	}
+	if err := s.limitRepo.Delete(id); err != nil {
+		return errors.New("не удалось удалить лимит, попробуйте позже")
+	}
+	return nil
+}
+
+// load получает лимит, которым пользователь вправе управлять
+// Лимит чужой семьи неотличим от несуществующего
+func (s *spendingLimitService) load(user *models.User, id uint) (*models.SpendingLimit, error) {
+	limit, err := s.limitRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("не удалось получить лимит")
+	}
+	if limit == nil {
+		return nil, ErrSpendingLimitNotFound
+	}
+	member, err := requireMember(s.familyRepo, limit.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, ErrSpendingLimitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrSpendingLimitForbidden
+	}
+	return limit, nil
+}
+
+// checkUnique проверяет, что у участника нет другого лимита на тот же счёт и период
+func (s *spendingLimitService) checkUnique(limit *models.SpendingLimit) error {
+	existing, err := s.limitRepo.ListByFamily(limit.FamilyID, limit.UserID)
+	if err != nil {
+		return errors.New("не удалось получить список лимитов")
+	}
+	for _, other := range existing {
+		sameAccount := (other.AccountID == nil) == (limit.AccountID == nil) &&
+			(other.AccountID == nil || *other.AccountID == *limit.AccountID)
+		if other.ID != limit.ID && sameAccount && other.Period == limit.Period {
+			return ErrSpendingLimitExists
+		}
+	}
+	return nil
+}
+
+func (s *spendingLimitService) Remaining(user *models.User, familyID, memberID uint) ([]LimitUsage, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == 0 {
+		memberID = member.UserID
+	}
+	if memberID != member.UserID && !member.Role.CanManage() {
+		return nil, ErrSpendingLimitForbidden
+	}
+	limits, err := s.limitRepo.ListByFamily(familyID, memberID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список лимитов")
+	}
+	today, err := familyToday(s.familyRepo, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]LimitUsage, 0, len(limits))
+	for _, limit := range limits {
+		from, to := limitBounds(limit.Period, today)
+		spent, missing, err := s.spent(s.txRepo, &limit, from, to, 0)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, LimitUsage{
+			Limit:        limit,
+			PeriodStart:  from,
+			PeriodEnd:    to,
+			Spent:        spent,
+			Remaining:    max(limit.Amount-spent, 0),
+			MissingRates: missing,
+		})
+	}
+	return usage, nil
+}
+
+func (s *spendingLimitService) Enforce(repos *repository.Repositories, tx *models.Transaction) error {
+	tx.Flagged = false
+	if tx.Direction != models.DirectionExpense || tx.Status == models.TransactionRejected {
+		return nil
+	}
+	limits, err := repos.Limits.LockByMember(tx.FamilyID, tx.CreatedBy)
+	if err != nil {
+		return err
+	}
+	for i := range limits {
+		limit := &limits[i]
+		if limit.AccountID != nil && *limit.AccountID != tx.AccountID {
+			continue
+		}
+		amount := tx.Amount
+		if tx.Currency != limit.Currency {
+			table, err := s.rates.Table([]string{limit.Currency, tx.Currency}, tx.Date, tx.Date)
+			if err != nil {
+				return err
+			}
+			converted, ok := table.Convert(tx.Amount, tx.Currency, limit.Currency, tx.Date)
+			// Без курса расход нельзя сравнить с лимитом
+			if !ok {
+				continue
+			}
+			amount = converted
+		}
+		from, to := limitBounds(limit.Period, tx.Date)
+		spent, _, err := s.spent(repos.Transactions, limit, from, to, tx.ID)
+		if err != nil {
+			return err
+		}
+		if spent+amount <= limit.Amount {
+			continue
+		}
+		if limit.Action == models.LimitReject {
+			return limitExceeded(limit, spent)
+		}
+		tx.Flagged = true
+	}
+	return nil
+}
+
+// spent возвращает расходы участника лимита за период [from, to] в валюте лимита
+// и валюты, расходы в которых не пересчитаны из-за отсутствия курса
+func (s *spendingLimitService) spent(txRepo repository.TransactionRepository, limit *models.SpendingLimit,
+	from, to time.Time, excludeID uint) (int64, []string, error) {
+	sums, err := txRepo.SumByMember(repository.MemberSpendFilter{
+		FamilyID:  limit.FamilyID,
+		UserID:    limit.UserID,
+		AccountID: limit.AccountID,
+		From:      from,
+		To:        to,
+		ExcludeID: excludeID,
+	})
+	if err != nil {
+		return 0, nil, errors.New("не удалось вычислить расходы участника")
+	}
+	var total int64
+	currencies := []string{limit.Currency}
+	for _, sum := range sums {
+		if sum.Currency == limit.Currency {
+			total += sum.Total
+			continue
+		}
+		currencies = mergeCurrencies(currencies, []string{sum.Currency})
+	}
+	missing := []string{}
+	if len(currencies) == 1 {
+		return total, missing, nil
+	}
+	table, err := s.rates.Table(currencies, from, to)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, sum := range sums {
+		if sum.Currency == limit.Currency {
+			continue
+		}
+		converted, ok := table.Convert(sum.Total, sum.Currency, limit.Currency, sum.Date)
+		if !ok {
+			missing = mergeCurrencies(missing, []string{sum.Currency})
+			continue
+		}
+		total += converted
+	}
+	return total, missing, nil
+}
+
+// limitBounds возвращает первый и последний день периода лимита, содержащего дату date
+func limitBounds(period models.LimitPeriod, date time.Time) (time.Time, time.Time) {
+	y, m, d := date.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	switch period {
+	case models.LimitWeekly:
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return monday, monday.AddDate(0, 0, 6)
+	case models.LimitMonthly:
+		first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 1, -1)
+	}
+	return day, day
+}
+
+// limitExceeded формирует ошибку о превышении лимита с остатком на период
+func limitExceeded(limit *models.SpendingLimit, spent int64) error {
+	period := "день"
+	switch limit.Period {
+	case models.LimitWeekly:
+		period = "неделю"
+	case models.LimitMonthly:
+		period = "месяц"
+	}
+	remaining := money.Display(max(limit.Amount-spent, 0), limit.Currency, "ru")
+	total := money.Display(limit.Amount, limit.Currency, "ru")
+	return newError(KindConflict, fmt.Sprintf("расход превышает лимит участника: на %s осталось %s из %s", period, remaining, total))
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrSpendingRequestNotFound возвращается, если запрос на расход не существует или недоступен пользователю
+	ErrSpendingRequestNotFound = newError(KindNotFound, "запрос на расход не найден")
+
+	// ErrSpendingRequestForbidden возвращается, если пользователь не может принять решение по запросу
+	ErrSpendingRequestForbidden = newError(KindForbidden, "одобрять и отклонять запросы на расход могут только родители")
+
+	// ErrSpendingRequestAccount возвращается при запросе по счёту, который не под присмотром или принадлежит другому
+	ErrSpendingRequestAccount = newError(KindInvalid, "запрос на расход создаётся только по своему счёту под присмотром родителей")
+
+	// ErrSpendingRequestReviewed возвращается при изменении запроса, по которому уже принято решение
+	ErrSpendingRequestReviewed = newError(KindConflict, "по запросу уже принято решение")
+
+	// ErrSpendingRequestNotOwner возвращается, если запрос отзывает не его автор
+	ErrSpendingRequestNotOwner = newError(KindForbidden, "отозвать запрос может только его автор")
+
+	// ErrInsufficientFunds возвращается, если на счёте недостаточно денег для расхода
+	ErrInsufficientFunds = newError(KindConflict, "на счёте недостаточно денег")
+)
+
+// SpendingRequestInput данные запроса ребёнка на расход
+type SpendingRequestInput struct {
+	AccountID uint
+	// Amount сумма в минимальных единицах валюты счёта
+	Amount     int64
+	CategoryID *uint
+	Payee      string
+	Note       string
+}
+
+// SpendingRequestService определяет интерфейс для работы с запросами детей на расход
+// Ребёнок не проводит расходы по счёту под присмотром сам: он просит разрешения,
+// а одобренный родителем расход проводится автоматически
+type SpendingRequestService interface {
+	// Create создает запрос на расход по собственному счёту пользователя под присмотром
+	Create(user *models.User, input SpendingRequestInput) (*models.SpendingRequest, error)
+
+	// List возвращает запросы семьи, начиная с последних, не больше limit;
+	// родители видят все запросы, остальные — только свои. Пустой status не фильтрует
+	List(user *models.User, familyID uint, status models.SpendingStatus, limit int) ([]models.SpendingRequest, error)
+
+	// Approve одобряет запрос и проводит расход по счёту ребёнка датой текущего дня семьи
+	Approve(user *models.User, id uint, note string) (*models.SpendingRequest, error)
+
+	// Reject отклоняет запрос
+	Reject(user *models.User, id uint, note string) (*models.SpendingRequest, error)
+
+	// Cancel отзывает запрос, пока по нему не принято решение; доступно автору запроса
+	Cancel(user *models.User, id uint) (*models.SpendingRequest, error)
+}
+
+// spendingRequestService реализует интерфейс SpendingRequestService
+type spendingRequestService struct {
+	transactor   repository.Transactor
+	requestRepo  repository.SpendingRequestRepository
+	accountRepo  repository.AccountRepository
+	categoryRepo repository.CategoryRepository
+	familyRepo   repository.FamilyRepository
+	alerts       BudgetAlertService
+}
+
+// NewSpendingRequestService создает новый экземпляр SpendingRequestService
+// После проведения одобренного расхода alerts проверяет пороги бюджетов
+func NewSpendingRequestService(transactor repository.Transactor, requestRepo repository.SpendingRequestRepository,
+	accountRepo repository.AccountRepository, categoryRepo repository.CategoryRepository,
+	familyRepo repository.FamilyRepository, alerts BudgetAlertService) SpendingRequestService {
+	return &spendingRequestService{
+		transactor:   transactor,
+		requestRepo:  requestRepo,
+		accountRepo:  accountRepo,
+		categoryRepo: categoryRepo,
+		familyRepo:   familyRepo,
+		alerts:       alerts,
+	}
+}
+
+func (s *spendingRequestService) Create(user *models.User, input SpendingRequestInput) (*models.SpendingRequest, error) {
+	account, err := s.accountRepo.GetByID(input.AccountID)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные счёта")
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	member, err := requireMember(s.familyRepo, account.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !account.Supervised || account.OwnerID == nil || *account.OwnerID != member.UserID {
+		return nil, ErrSpendingRequestAccount
+	}
+	if account.Archived {
+		return nil, ErrAccountArchived
+	}
+	if input.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, account.FamilyID, *input.CategoryID, models.DirectionExpense, false); err != nil {
+			return nil, err
+		}
+	}
+	request := &models.SpendingRequest{
+		FamilyID:    account.FamilyID,
+		AccountID:   account.ID,
+		RequestedBy: member.UserID,
+		Amount:      input.Amount,
+		Currency:    account.Currency,
+		CategoryID:  input.CategoryID,
+		Payee:       input.Payee,
+		Note:        input.Note,
+		Status:      models.SpendingPending,
+	}
+	if err := s.requestRepo.Create(request); err != nil {
+		return nil, errors.New("не удалось сохранить запрос, попробуйте позже")
+	}
+	return request, nil
+}
+
+func (s *spendingRequestService) List(user *models.User, familyID uint, status models.SpendingStatus, limit int) ([]models.SpendingRequest, error) {
+	member, err := requireMember(s.familyRepo, familyID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	query := repository.SpendingRequestQuery{FamilyID: familyID, Status: status, Limit: limit}
+	if !member.Role.CanManage() {
+		query.RequestedBy = member.UserID
+	}
+	list, err := s.requestRepo.List(query)
+	if err != nil {
+		return nil, errors.New("не удалось получить список запросов")
+	}
+	if list == nil {
+		list = []models.SpendingRequest{}
+	}
+	return list, nil
+}
+
+func (s *spendingRequestService) Approve(user *models.User, id uint, note string) (*models.SpendingRequest, error) {
+	request, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrSpendingRequestForbidden
+	}
+	today, err := familyToday(s.familyRepo, request.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *models.Transaction
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		// Блокировка не даёт двум родителям одновременно провести один расход дважды
+		request, err = repos.Spending.LockByID(id)
+		if err != nil {
+			return err
+		}
+		if request == nil {
+			return ErrSpendingRequestNotFound
+		}
+		if request.Status != models.SpendingPending {
+			return ErrSpendingRequestReviewed
+		}
+		account, err := repos.Accounts.GetByID(request.AccountID)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return ErrAccountNotFound
+		}
+		if account.Archived {
+			return ErrAccountArchived
+		}
+		balances, err := repos.Accounts.Balances([]uint{account.ID})
+		if err != nil {
+			return err
+		}
+		if balances[account.ID] < request.Amount {
+			return ErrInsufficientFunds
+		}
+		tx = &models.Transaction{
+			FamilyID:   request.FamilyID,
+			AccountID:  account.ID,
+			Direction:  models.DirectionExpense,
+			Amount:     request.Amount,
+			Currency:   request.Currency,
+			CategoryID: request.CategoryID,
+			Date:       today,
+			Payee:      request.Payee,
+			Note:       request.Note,
+			CreatedBy:  member.UserID,
+		}
+		if err := postTransaction(repos, tx); err != nil {
+			return err
+		}
+		review(request, member, models.SpendingApproved, note)
+		request.TransactionID = &tx.ID
+		return repos.Spending.Update(request)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось провести расход, попробуйте позже")
+	}
+	s.alerts.CheckTransaction(tx)
+	return request, nil
+}
+
+func (s *spendingRequestService) Reject(user *models.User, id uint, note string) (*models.SpendingRequest, error) {
+	request, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, ErrSpendingRequestForbidden
+	}
+	if request.Status != models.SpendingPending {
+		return nil, ErrSpendingRequestReviewed
+	}
+	review(request, member, models.SpendingRejected, note)
+	if err := s.requestRepo.Update(request); err != nil {
+		return nil, errors.New("не удалось сохранить запрос, попробуйте позже")
+	}
+	return request, nil
+}
+
+func (s *spendingRequestService) Cancel(user *models.User, id uint) (*models.SpendingRequest, error) {
+	request, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != member.UserID {
+		return nil, ErrSpendingRequestNotOwner
+	}
+	if request.Status != models.SpendingPending {
+		return nil, ErrSpendingRequestReviewed
+	}
+	request.Status = models.SpendingCancelled
+	if err := s.requestRepo.Update(request); err != nil {
+		return nil, errors.New("не удалось сохранить запрос, попробуйте позже")
+	}
+	return request, nil
+}
+
+// review записывает решение родителя по запросу
+func review(request *models.SpendingRequest, member *models.FamilyMember, status models.SpendingStatus, note string) {
+	now := time.Now()
+	request.Status = status
+	request.ReviewedBy = &member.UserID
+	request.ReviewedAt = &now
+	request.ReviewNote = note
+}
+
+// load получает запрос и членство пользователя в его семье
+// Запрос чужой семьи или, для детей, чужой запрос неотличим от несуществующего
+func (s *spendingRequestService) load(user *models.User, id uint) (*models.SpendingRequest, *models.FamilyMember, error) {
+	request, err := s.requestRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить запрос на расход")
+	}
+	if request == nil {
+		return nil, nil, ErrSpendingRequestNotFound
+	}
+	member, err := requireMember(s.familyRepo, request.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrSpendingRequestNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !member.Role.CanManage() && request.RequestedBy != member.UserID {
+		return nil, nil, ErrSpendingRequestNotFound
+	}
+	return request, member, nil
+}
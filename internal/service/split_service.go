@@ -0,0 +1,332 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrSplitNotFound возвращается, если операция не разделена
+	ErrSplitNotFound = newError(KindNotFound, "операция не разделена между участниками")
+
+	// ErrSplitNotExpense возвращается при попытке разделить доход или перевод
+	ErrSplitNotExpense = newError(KindInvalid, "разделить можно только расход")
+
+	// ErrSplitParticipantNotMember возвращается, если участник разделения не состоит в семье
+	ErrSplitParticipantNotMember = newError(KindInvalid, "участники разделения и плательщик должны состоять в семье операции")
+
+	// ErrSplitDuplicateParticipant возвращается, если участник указан несколько раз
+	ErrSplitDuplicateParticipant = newError(KindInvalid, "каждый участник может встречаться в разделении только один раз")
+
+	// ErrSplitExactSum возвращается, если сумма точных долей не равна сумме операции
+	ErrSplitExactSum = newError(KindInvalid, "сумма долей должна быть равна сумме операции")
+
+	// ErrSplitPercentSum возвращается, если сумма процентов не равна 100
+	ErrSplitPercentSum = newError(KindInvalid, "сумма процентов должна быть равна 100")
+)
+
+// SplitShareInput доля участника в разделении
+type SplitShareInput struct {
+	UserID uint
+	// Amount доля в минимальных единицах валюты; только для способа exact
+	Amount int64
+	// Percent доля в процентах; только для способа percent
+	Percent *big.Rat
+}
+
+// SplitInput данные разделения расхода
+type SplitInput struct {
+	Method models.SplitMethod
+	// PaidBy участник, который заплатил; по умолчанию автор операции
+	PaidBy uint
+	Shares []SplitShareInput
+}
+
+// SplitService определяет интерфейс для разделения расходов между участниками семьи
+type SplitService interface {
+	// Get возвращает действующее разделение операции; доступно любому участнику семьи
+	Get(user *models.User, transactionID uint) (*models.Split, error)
+
+	// History возвращает все версии разделения операции, начиная с последней;
+	// доступно любому участнику семьи
+	History(user *models.User, transactionID uint) ([]models.Split, error)
+
+	// Set разделяет расход между участниками семьи или заменяет действующее разделение
+	// новой версией; доступно автору операции и владельцу семьи
+	Set(user *models.User, transactionID uint, input SplitInput) (*models.Split, error)
+
+	// Cancel отменяет разделение операции, сохраняя его в истории;
+	// доступно автору операции и владельцу семьи
+	Cancel(user *models.User, transactionID uint) error
+}
+
+// splitService реализует интерфейс SplitService
+type splitService struct {
+	splitRepo  repository.SplitRepository
+	txRepo     repository.TransactionRepository
+	familyRepo repository.FamilyRepository
+}
+
+// NewSplitService создает новый экземпляр SplitService
+func NewSplitService(splitRepo repository.SplitRepository, txRepo repository.TransactionRepository,
+	familyRepo repository.FamilyRepository) SplitService {
+	return &splitService{splitRepo: splitRepo, txRepo: txRepo, familyRepo: familyRepo}
+}
+
+func (s *splitService) Get(user *models.User, transactionID uint) (*models.Split, error) {
+	if _, _, err := s.load(user, transactionID); err != nil {
+		return nil, err
+	}
+	split, err := s.splitRepo.Current(transactionID)
+	if err != nil {
+		return nil, errors.New("не удалось получить разделение операции")
+	}
+	if split == nil {
+		return nil, ErrSplitNotFound
+	}
+	return split, nil
+}
+
+func (s *splitService) History(user *models.User, transactionID uint) ([]models.Split, error) {
+	if _, _, err := s.load(user, transactionID); err != nil {
+		return nil, err
+	}
+	splits, err := s.splitRepo.History(transactionID)
+	if err != nil {
+		return nil, errors.New("не удалось получить историю разделения операции")
+	}
+	if splits == nil {
+		splits = []models.Split{}
+	}
+	return splits, nil
+}
+
+func (s *splitService) Set(user *models.User, transactionID uint, input SplitInput) (*models.Split, error) {
+	tx, member, err := s.load(user, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEditTransaction(member, tx) {
+		return nil, ErrTransactionForbidden
+	}
+	if tx.Direction != models.DirectionExpense {
+		return nil, ErrSplitNotExpense
+	}
+
+	members, err := s.familyRepo.ListMembers(tx.FamilyID)
+	if err != nil {
+		return nil, errors.New("не удалось получить участников семьи")
+	}
+	isMember := make(map[uint]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+	paidBy := input.PaidBy
+	if paidBy == 0 {
+		paidBy = tx.CreatedBy
+	}
+	if !isMember[paidBy] {
+		return nil, ErrSplitParticipantNotMember
+	}
+	seen := make(map[uint]bool, len(input.Shares))
+	for _, share := range input.Shares {
+		if !isMember[share.UserID] {
+			return nil, ErrSplitParticipantNotMember
+		}
+		if seen[share.UserID] {
+			return nil, ErrSplitDuplicateParticipant
+		}
+		seen[share.UserID] = true
+	}
+
+	shares, err := splitShares(tx.Amount, input.Method, input.Shares)
+	if err != nil {
+		return nil, err
+	}
+	split := &models.Split{
+		TransactionID: tx.ID,
+		FamilyID:      tx.FamilyID,
+		Method:        input.Method,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		PaidBy:        paidBy,
+		CreatedBy:     user.ID,
+		Shares:        shares,
+	}
+	if err := s.splitRepo.Replace(split); err != nil {
+		return nil, errors.New("не удалось сохранить разделение операции, попробуйте позже")
+	}
+	// Возвращаем версию с данными участников
+	return s.Get(user, tx.ID)
+}
+
+func (s *splitService) Cancel(user *models.User, transactionID uint) error {
+	tx, member, err := s.load(user, transactionID)
+	if err != nil {
+		return err
+	}
+	if !canEditTransaction(member, tx) {
+		return ErrTransactionForbidden
+	}
+	cancelled, err := s.splitRepo.Cancel(tx.ID)
+	if err != nil {
+		return errors.New("не удалось отменить разделение операции, попробуйте позже")
+	}
+	if !cancelled {
+		return ErrSplitNotFound
+	}
+	return nil
+}
+
+// load получает операцию и членство пользователя в её семье
+// Операция чужой семьи неотличима от несуществующей
+func (s *splitService) load(user *models.User, transactionID uint) (*models.Transaction, *models.FamilyMember, error) {
+	tx, err := s.txRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить операцию")
+	}
+	if tx == nil {
+		return nil, nil, ErrTransactionNotFound
+	}
+	member, err := requireMember(s.familyRepo, tx.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, member, nil
+}
+
+// splitShares вычисляет доли участников в сумме amount
+// Доли упорядочиваются по идентификатору участника; копейки, оставшиеся после
+// деления поровну или по процентам, распределяются детерминированно (см. allocate)
+func splitShares(amount int64, method models.SplitMethod, input []SplitShareInput) ([]models.SplitShare, error) {
+	input = append([]SplitShareInput(nil), input...)
+	sort.Slice(input, func(i, j int) bool { return input[i].UserID < input[j].UserID })
+
+	shares := make([]models.SplitShare, len(input))
+	weights := make([]*big.Rat, len(input))
+	total := new(big.Rat)
+	var exact int64
+	for i, in := range input {
+		shares[i].UserID = in.UserID
+		switch method {
+		case models.SplitEqual:
+			weights[i] = big.NewRat(1, 1)
+		case models.SplitPercent:
+			weights[i] = in.Percent
+			percent := in.Percent.FloatString(2)
+			shares[i].Percent = &percent
+		case models.SplitExact:
+			shares[i].Amount = in.Amount
+			exact += in.Amount
+			continue
+		}
+		total.Add(total, weights[i])
+	}
+
+	switch method {
+	case models.SplitExact:
+		if exact != amount {
+			return nil, ErrSplitExactSum
+		}
+		return shares, nil
+	case models.SplitPercent:
+		if total.Cmp(big.NewRat(100, 1)) != 0 {
+			return nil, ErrSplitPercentSum
+		}
+	}
+	for i, part := range allocate(amount, weights) {
+		shares[i].Amount = part
+	}
+	return shares, nil
+}
+
+// allocate делит amount пропорционально весам методом наибольших остатков:
+// каждая доля округляется вниз, а оставшиеся минимальные единицы достаются долям
+// с наибольшей дробной частью; при равных дробных частях — доле, стоящей раньше
+func allocate(amount int64, weights []*big.Rat) []int64 {
+	total := new(big.Rat)
+	for _, w := range weights {
+		total.Add(total, w)
+	}
+	parts := make([]int64, len(weights))
+	if total.Sign() == 0 {
+		return parts
+	}
+	fractions := make([]*big.Rat, len(weights))
+	rest := amount
+	for i, w := range weights {
+		exact := new(big.Rat).Mul(big.NewRat(amount, 1), w)
+		exact.Quo(exact, total)
+		floor := new(big.Int).Quo(exact.Num(), exact.Denom())
+		parts[i] = floor.Int64()
+		fractions[i] = exact.Sub(exact, new(big.Rat).SetInt(floor))
+		rest -= parts[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]].Cmp(fractions[order[b]]) > 0
+	})
+	for i := 0; rest > 0; i++ {
+		parts[order[i%len(order)]]++
+		rest--
+	}
+	return parts
+}
+
+// adjustSplit приводит действующее разделение к изменённой операции новой версией от имени editor
+// Если операция больше не расход, разделение отменяется. При изменении суммы доли
+// пересчитываются: поровну и по процентам — тем же способом, точные суммы — пропорционально
+// прежним долям
+func adjustSplit(repos *repository.Repositories, tx *models.Transaction, editor uint) error {
+	current, err := repos.Splits.Current(tx.ID)
+	if err != nil || current == nil {
+		return err
+	}
+	if tx.Direction != models.DirectionExpense {
+		_, err := repos.Splits.Cancel(tx.ID)
+		return err
+	}
+	if current.Amount == tx.Amount {
+		return nil
+	}
+
+	weights := make([]*big.Rat, len(current.Shares))
+	shares := make([]models.SplitShare, len(current.Shares))
+	for i, share := range current.Shares {
+		shares[i] = models.SplitShare{UserID: share.UserID, Percent: share.Percent}
+		switch {
+		case current.Method == models.SplitEqual:
+			weights[i] = big.NewRat(1, 1)
+		case current.Method == models.SplitPercent && share.Percent != nil:
+			weights[i], _ = new(big.Rat).SetString(*share.Percent)
+		default:
+			weights[i] = big.NewRat(share.Amount, 1)
+		}
+		if weights[i] == nil {
+			weights[i] = new(big.Rat)
+		}
+	}
+	for i, part := range allocate(tx.Amount, weights) {
+		shares[i].Amount = part
+	}
+	return repos.Splits.Replace(&models.Split{
+		TransactionID: tx.ID,
+		FamilyID:      tx.FamilyID,
+		Method:        current.Method,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		PaidBy:        current.PaidBy,
+		CreatedBy:     editor,
+		Shares:        shares,
+	})
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"family_finance_back/internal/models"
+)
+
+func TestAllocate(t *testing.T) {
+	rats := func(values ...string) []*big.Rat {
+		weights := make([]*big.Rat, len(values))
+		for i, v := range values {
+			weights[i], _ = new(big.Rat).SetString(v)
+		}
+		return weights
+	}
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []*big.Rat
+		want    []int64
+	}{
+		{name: "делится без остатка", amount: 900, weights: rats("1", "1", "1"), want: []int64{300, 300, 300}},
+		{name: "копейка достаётся первому", amount: 100, weights: rats("1", "1", "1"), want: []int64{34, 33, 33}},
+		{name: "две копейки — первым двум", amount: 101, weights: rats("1", "1", "1"), want: []int64{34, 34, 33}},
+		{name: "наибольший остаток важнее порядка", amount: 100, weights: rats("33.3", "33.3", "33.4"), want: []int64{33, 33, 34}},
+		{name: "проценты с дробной частью", amount: 1, weights: rats("12.5", "87.5"), want: []int64{0, 1}},
+		{name: "семь долей одной копейки", amount: 3, weights: rats("1", "1", "1", "1", "1", "1", "1"), want: []int64{1, 1, 1, 0, 0, 0, 0}},
+		{name: "нулевой вес", amount: 1000, weights: rats("0", "50", "50"), want: []int64{0, 500, 500}},
+		{name: "большая сумма", amount: 1_000_000_000_000_000, weights: rats("1", "2"), want: []int64{333_333_333_333_333, 666_666_666_666_667}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocate(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate(%d) = %v, want %v", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSharesSumMatchesAmount(t *testing.T) {
+	percent := func(v string) *big.Rat {
+		r, _ := new(big.Rat).SetString(v)
+		return r
+	}
+	tests := []struct {
+		name    string
+		amount  int64
+		method  models.SplitMethod
+		input   []SplitShareInput
+		want    []int64
+		wantErr error
+	}{
+		{
+			name:   "поровну, участники упорядочиваются",
+			amount: 1000,
+			method: models.SplitEqual,
+			input:  []SplitShareInput{{UserID: 3}, {UserID: 1}, {UserID: 2}},
+			want:   []int64{334, 333, 333},
+		},
+		{
+			name:   "по процентам",
+			amount: 9999,
+			method: models.SplitPercent,
+			input: []SplitShareInput{
+				{UserID: 1, Percent: percent("33.33")},
+				{UserID: 2, Percent: percent("33.33")},
+				{UserID: 3, Percent: percent("33.34")},
+			},
+			want: []int64{3333, 3333, 3333},
+		},
+		{
+			name:   "точные суммы",
+			amount: 1000,
+			method: models.SplitExact,
+			input:  []SplitShareInput{{UserID: 1, Amount: 700}, {UserID: 2, Amount: 300}},
+			want:   []int64{700, 300},
+		},
+		{
+			name:    "проценты не дают 100",
+			amount:  1000,
+			method:  models.SplitPercent,
+			input:   []SplitShareInput{{UserID: 1, Percent: percent("50")}, {UserID: 2, Percent: percent("49.99")}},
+			wantErr: ErrSplitPercentSum,
+		},
+		{
+			name:    "точные суммы не сходятся",
+			amount:  1000,
+			method:  models.SplitExact,
+			input:   []SplitShareInput{{UserID: 1, Amount: 700}, {UserID: 2, Amount: 299}},
+			wantErr: ErrSplitExactSum,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := splitShares(tt.amount, tt.method, tt.input)
+			if err != tt.wantErr {
+				t.Fatalf("splitShares error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make([]int64, len(shares))
+			var sum int64
+			for i, share := range shares {
+				if i > 0 && shares[i-1].UserID >= share.UserID {
+					t.Errorf("shares not ordered by user: %+v", shares)
+				}
+				got[i] = share.Amount
+				sum += share.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shares = %v, want %v", got, tt.want)
+			}
+			if sum != tt.amount {
+				t.Errorf("sum of shares = %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestSplitSharesEqualSumsForAnyAmount(t *testing.T) {
+	for participants := 1; participants <= 7; participants++ {
+		input := make([]SplitShareInput, participants)
+		for i := range input {
+			input[i].UserID = uint(i + 1)
+		}
+		for amount := int64(0); amount <= 500; amount++ {
+			shares, err := splitShares(amount, models.SplitEqual, input)
+			if err != nil {
+				t.Fatalf("splitShares(%d, %d participants): %v", amount, participants, err)
+			}
+			var sum, lo, hi int64 = 0, amount, 0
+			for _, share := range shares {
+				sum += share.Amount
+				if share.Amount < lo {
+					lo = share.Amount
+				}
+				if share.Amount > hi {
+					hi = share.Amount
+				}
+			}
+			if sum != amount || hi-lo > 1 {
+				t.Fatalf("splitShares(%d, %d participants) = %+v, want shares summing to amount within one unit", amount, participants, shares)
+			}
+		}
+	}
+}
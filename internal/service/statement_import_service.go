@@ -0,0 +1,915 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+	"family_finance_back/internal/statement"
+)
+
+var (
+	// ErrStatementProfileNotFound возвращается, если профиль сопоставления не существует или принадлежит другому пользователю
+	ErrStatementProfileNotFound = newError(KindNotFound, "профиль сопоставления не найден")
+
+	// ErrStatementImportNotFound возвращается, если импорт не существует или загружен другим пользователем
+	ErrStatementImportNotFound = newError(KindNotFound, "импорт выписки не найден")
+
+	// ErrStatementRowNotFound возвращается, если строка импорта не существует или недоступна пользователю
+	ErrStatementRowNotFound = newError(KindNotFound, "строка выписки не найдена")
+
+	// ErrStatementImportForbidden возвращается, если пользователь не может импортировать выписки
+	ErrStatementImportForbidden = newError(KindForbidden, "импортировать выписки могут только владелец и взрослые")
+
+	// ErrStatementImportBusy возвращается, если выписка ещё разбирается или её операции проводятся
+	ErrStatementImportBusy = newError(KindConflict, "выписка обрабатывается, повторите запрос позже")
+
+	// ErrStatementImportCompleted возвращается при попытке изменить завершённый импорт
+	ErrStatementImportCompleted = newError(KindConflict, "операции из выписки уже проведены")
+
+	// ErrStatementImportFailed возвращается при попытке провести выписку, которую не удалось разобрать
+	ErrStatementImportFailed = newError(KindConflict, "выписку не удалось разобрать; измените сопоставление столбцов")
+)
+
+const (
+	// importSyncSize файлы до этого размера разбираются при загрузке, большие — фоновой задачей
+	importSyncSize = 256 << 10
+
+	// importSyncRows выписки, в которых к проведению не больше строк, проводятся сразу,
+	// остальные — фоновой задачей
+	importSyncRows = 200
+
+	// importBatchSize сколько импортов фоновая задача обрабатывает за один проход
+	importBatchSize = 10
+
+	// importListLimit сколько последних импортов возвращает список
+	importListLimit = 100
+)
+
+// StatementUpload загружаемая выписка
+type StatementUpload struct {
+	AccountID uint
+	// ProfileID профиль сопоставления; 0 — без профиля
+	ProfileID uint
+	FileName  string
+	Data      []byte
+	// Mapping заменяет заполненные поля профиля; пустые поля определяются по файлу
+	Mapping models.StatementMapping
+}
+
+// ImportRowChanges изменения строки импорта; nil-поля не изменяются
+type ImportRowChanges struct {
+	// CategoryID новая категория; указатель на 0 снимает категорию
+	CategoryID *uint
+	Skip       *bool
+}
+
+// StatementImportService определяет интерфейс для импорта банковских выписок в CSV
+// Загруженная выписка разбирается в строки с предложенными категориями; после просмотра
+// и правки строки проводятся как операции счёта. Строка, уже импортированная на счёт,
+// повторно не проводится
+type StatementImportService interface {
+	// ListProfiles возвращает профили сопоставления пользователя
+	ListProfiles(user *models.User) ([]models.StatementProfile, error)
+
+	// CreateProfile сохраняет профиль сопоставления столбцов, например для выписок одного банка
+	CreateProfile(user *models.User, name string, mapping models.StatementMapping) (*models.StatementProfile, error)
+
+	// UpdateProfile переименовывает профиль и, если mapping не nil, заменяет сопоставление
+	UpdateProfile(user *models.User, id uint, name string, mapping *models.StatementMapping) (*models.StatementProfile, error)
+
+	// DeleteProfile удаляет профиль
+	DeleteProfile(user *models.User, id uint) error
+
+	// Upload загружает выписку по счёту; доступно владельцу и взрослым, которые могут
+	// проводить операции по счёту. Небольшой файл разбирается сразу, большой — в фоне
+	// Повторная загрузка того же файла без сопоставления возвращает незавершённый импорт
+	Upload(user *models.User, input StatementUpload) (*models.StatementImport, error)
+
+	// List возвращает последние импорты пользователя; familyID, если не 0, отбирает импорты семьи
+	List(user *models.User, familyID uint) ([]models.StatementImport, error)
+
+	// Get возвращает импорт пользователя вместе со строками
+	Get(user *models.User, id uint) (*models.StatementImport, error)
+
+	// Remap заново разбирает файл импорта с другим профилем или сопоставлением
+	// Правки строк при этом теряются
+	Remap(user *models.User, id, profileID uint, mapping models.StatementMapping) (*models.StatementImport, error)
+
+	// UpdateRow изменяет категорию строки или исключает строку из проведения
+	UpdateRow(user *models.User, rowID uint, changes ImportRowChanges) (*models.StatementImportRow, error)
+
+	// Commit проводит строки выписки как операции счёта; небольшие выписки проводятся сразу,
+	// большие — в фоне. Расходы проходят те же проверки, что и созданные вручную: подходящие
+	// под правило одобрения ждут решения, а строки, превышающие лимит с действием reject,
+	// не проводятся и помечаются ошибкой
+	Commit(user *models.User, id uint) (*models.StatementImport, error)
+
+	// Delete удаляет импорт; проведённые операции остаются
+	Delete(user *models.User, id uint) error
+
+	// Run запускает фоновую задачу, которая разбирает и проводит большие выписки,
+	// и блокируется до отмены ctx
+	Run(ctx context.Context)
+}
+
+// statementImportService реализует интерфейс StatementImportService
+type statementImportService struct {
+	transactor    repository.Transactor
+	statementRepo repository.StatementRepository
+	accountRepo   repository.AccountRepository
+	categoryRepo  repository.CategoryRepository
+	familyRepo    repository.FamilyRepository
+	alerts        BudgetAlertService
+	approvals     ApprovalService
+	limits        SpendingLimitService
+	pollInterval  time.Duration
+}
+
+// NewStatementImportService создает новый экземпляр StatementImportService
+// Проводимые строки проверяются так же, как в TransactionService: approvals решает, нужно ли
+// расходу одобрение, limits проверяет лимиты автора, а alerts — пороги бюджетов после проведения
+// Фоновая задача проверяет выписки, ждущие разбора или проведения, каждые pollInterval
+func NewStatementImportService(transactor repository.Transactor, statementRepo repository.StatementRepository,
+	accountRepo repository.AccountRepository, categoryRepo repository.CategoryRepository,
+	familyRepo repository.FamilyRepository, alerts BudgetAlertService, approvals ApprovalService,
+	limits SpendingLimitService, pollInterval time.Duration) StatementImportService {
+	return &statementImportService{
+		transactor:    transactor,
+		statementRepo: statementRepo,
+		accountRepo:   accountRepo,
+		categoryRepo:  categoryRepo,
+		familyRepo:    familyRepo,
+		alerts:        alerts,
+		approvals:     approvals,
+		limits:        limits,
+		pollInterval:  pollInterval,
+	}
+}
+
+func (s *statementImportService) ListProfiles(user *models.User) ([]models.StatementProfile, error) {
+	profiles, err := s.statementRepo.ListProfiles(user.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить список профилей")
+	}
+	if profiles == nil {
+		profiles = []models.StatementProfile{}
+	}
+	return profiles, nil
+}
+
+func (s *statementImportService) CreateProfile(user *models.User, name string, mapping models.StatementMapping) (*models.StatementProfile, error) {
+	profile := &models.StatementProfile{UserID: user.ID, Name: name, Mapping: mapping}
+	if err := s.statementRepo.CreateProfile(profile); err != nil {
+		return nil, errors.New("не удалось сохранить профиль")
+	}
+	return profile, nil
+}
+
+func (s *statementImportService) UpdateProfile(user *models.User, id uint, name string, mapping *models.StatementMapping) (*models.StatementProfile, error) {
+	profile, err := s.profile(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		profile.Name = name
+	}
+	if mapping != nil {
+		profile.Mapping = *mapping
+	}
+	if err := s.statementRepo.UpdateProfile(profile); err != nil {
+		return nil, errors.New("не удалось сохранить профиль")
+	}
+	return profile, nil
+}
+
+func (s *statementImportService) DeleteProfile(user *models.User, id uint) error {
+	if _, err := s.profile(user, id); err != nil {
+		return err
+	}
+	if err := s.statementRepo.DeleteProfile(id); err != nil {
+		return errors.New("не удалось удалить профиль")
+	}
+	return nil
+}
+
+// profile получает профиль сопоставления пользователя
+func (s *statementImportService) profile(user *models.User, id uint) (*models.StatementProfile, error) {
+	profile, err := s.statementRepo.GetProfile(id)
+	if err != nil {
+		return nil, errors.New("не удалось получить профиль")
+	}
+	if profile == nil || profile.UserID != user.ID {
+		return nil, ErrStatementProfileNotFound
+	}
+	return profile, nil
+}
+
+// resolveMapping накладывает заполненные поля mapping на сопоставление профиля profileID
+func (s *statementImportService) resolveMapping(user *models.User, profileID uint,
+	mapping models.StatementMapping) (*uint, models.StatementMapping, error) {
+	if profileID == 0 {
+		return nil, mapping, nil
+	}
+	profile, err := s.profile(user, profileID)
+	if err != nil {
+		return nil, mapping, err
+	}
+	return &profile.ID, overlayMapping(profile.Mapping, mapping), nil
+}
+
+func (s *statementImportService) Upload(user *models.User, input StatementUpload) (*models.StatementImport, error) {
+	account, _, err := s.importer(user, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	profileID, mapping, err := s.resolveMapping(user, input.ProfileID, input.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(input.Data)
+	hash := hex.EncodeToString(sum[:])
+	if input.ProfileID == 0 && input.Mapping == (models.StatementMapping{}) {
+		existing, err := s.statementRepo.FindUnfinished(user.ID, account.ID, hash)
+		if err != nil {
+			return nil, errors.New("не удалось получить данные импорта")
+		}
+		if existing != nil {
+			return s.Get(user, existing.ID)
+		}
+	}
+
+	imp := &models.StatementImport{
+		UserID:    user.ID,
+		FamilyID:  account.FamilyID,
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		ProfileID: profileID,
+		FileName:  input.FileName,
+		File:      input.Data,
+		FileHash:  hash,
+		Status:    models.ImportParsing,
+		Mapping:   mapping,
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Statements.Create(imp); err != nil {
+			return err
+		}
+		if len(imp.File) > importSyncSize {
+			return nil
+		}
+		return parseImport(repos, imp)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось сохранить выписку")
+	}
+	return s.Get(user, imp.ID)
+}
+
+func (s *statementImportService) List(user *models.User, familyID uint) ([]models.StatementImport, error) {
+	if familyID != 0 {
+		if _, err := requireMember(s.familyRepo, familyID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	list, err := s.statementRepo.List(user.ID, familyID, importListLimit)
+	if err != nil {
+		return nil, errors.New("не удалось получить список импортов")
+	}
+	if list == nil {
+		list = []models.StatementImport{}
+	}
+	return list, nil
+}
+
+func (s *statementImportService) Get(user *models.User, id uint) (*models.StatementImport, error) {
+	imp, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.statementRepo.ListRows(imp.ID)
+	if err != nil {
+		return nil, errors.New("не удалось получить строки выписки")
+	}
+	imp.Rows = rows
+	if imp.Rows == nil {
+		imp.Rows = []models.StatementImportRow{}
+	}
+	return imp, nil
+}
+
+// load получает импорт, загруженный пользователем, без содержимого файла и строк
+func (s *statementImportService) load(user *models.User, id uint) (*models.StatementImport, error) {
+	imp, err := s.statementRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("не удалось получить данные импорта")
+	}
+	if imp == nil || imp.UserID != user.ID {
+		return nil, ErrStatementImportNotFound
+	}
+	return imp, nil
+}
+
+func (s *statementImportService) Remap(user *models.User, id, profileID uint, mapping models.StatementMapping) (*models.StatementImport, error) {
+	if _, err := s.load(user, id); err != nil {
+		return nil, err
+	}
+	profile, resolved, err := s.resolveMapping(user, profileID, mapping)
+	if err != nil {
+		return nil, err
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		imp, err := repos.Statements.Lock(id)
+		if err != nil {
+			return err
+		}
+		if imp == nil {
+			return ErrStatementImportNotFound
+		}
+		if err := editable(imp); err != nil {
+			return err
+		}
+		imp.ProfileID = profile
+		imp.Mapping = resolved
+		if len(imp.File) > importSyncSize {
+			imp.Status = models.ImportParsing
+			return repos.Statements.Update(imp)
+		}
+		return parseImport(repos, imp)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось разобрать выписку")
+	}
+	return s.Get(user, id)
+}
+
+// editable проверяет, что строки импорта можно разбирать заново и править
+func editable(imp *models.StatementImport) error {
+	switch imp.Status {
+	case models.ImportParsing, models.ImportCommitting:
+		return ErrStatementImportBusy
+	case models.ImportCompleted:
+		return ErrStatementImportCompleted
+	}
+	return nil
+}
+
+func (s *statementImportService) UpdateRow(user *models.User, rowID uint, changes ImportRowChanges) (*models.StatementImportRow, error) {
+	row, err := s.statementRepo.GetRow(rowID)
+	if err != nil {
+		return nil, errors.New("не удалось получить строку выписки")
+	}
+	if row == nil {
+		return nil, ErrStatementRowNotFound
+	}
+	imp, err := s.load(user, row.ImportID)
+	if err == ErrStatementImportNotFound {
+		return nil, ErrStatementRowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if changes.CategoryID != nil && *changes.CategoryID != 0 {
+		if row.Error != "" {
+			return nil, newError(KindInvalid, "строку не удалось разобрать: "+row.Error)
+		}
+		if err := checkCategory(s.categoryRepo, imp.FamilyID, *changes.CategoryID, row.Direction, false); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		// Блокировка импорта не даёт изменить строку, пока её операции проводятся
+		locked, err := repos.Statements.Lock(imp.ID)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			return ErrStatementRowNotFound
+		}
+		if err := editable(locked); err != nil {
+			return err
+		}
+		row, err = repos.Statements.GetRow(rowID)
+		if err != nil || row == nil {
+			return ErrStatementRowNotFound
+		}
+		if changes.CategoryID != nil {
+			row.CategoryID = changes.CategoryID
+			if *changes.CategoryID == 0 {
+				row.CategoryID = nil
+			}
+		}
+		if changes.Skip != nil {
+			row.Skip = *changes.Skip
+		}
+		return repos.Statements.UpdateRow(row)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось сохранить строку выписки")
+	}
+	return row, nil
+}
+
+func (s *statementImportService) Commit(user *models.User, id uint) (*models.StatementImport, error) {
+	imp, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := s.importer(user, imp.AccountID); err != nil {
+		return nil, err
+	}
+	var posted []*models.Transaction
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		imp, err := repos.Statements.Lock(id)
+		if err != nil {
+			return err
+		}
+		if imp == nil {
+			return ErrStatementImportNotFound
+		}
+		if imp.Status == models.ImportFailed {
+			return ErrStatementImportFailed
+		}
+		if err := editable(imp); err != nil {
+			return err
+		}
+		rows, err := repos.Statements.ListRows(imp.ID)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for i := range rows {
+			if postable(&rows[i]) {
+				pending++
+			}
+		}
+		if pending > importSyncRows {
+			imp.Status = models.ImportCommitting
+			imp.Error = ""
+			return repos.Statements.Update(imp)
+		}
+		posted, err = s.commitImport(repos, imp, rows)
+		return err
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось провести операции из выписки")
+	}
+	s.notifyPosted(posted)
+	return s.Get(user, id)
+}
+
+// importer проверяет, что пользователь может импортировать выписки по счёту
+func (s *statementImportService) importer(user *models.User, accountID uint) (*models.Account, *models.FamilyMember, error) {
+	account, member, err := postableAccount(s.accountRepo, s.familyRepo, user, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !member.Role.CanManage() {
+		return nil, nil, ErrStatementImportForbidden
+	}
+	return account, member, nil
+}
+
+func (s *statementImportService) Delete(user *models.User, id uint) error {
+	if _, err := s.load(user, id); err != nil {
+		return err
+	}
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		imp, err := repos.Statements.Lock(id)
+		if err != nil {
+			return err
+		}
+		if imp == nil {
+			return ErrStatementImportNotFound
+		}
+		if imp.Status == models.ImportCommitting {
+			return ErrStatementImportBusy
+		}
+		return repos.Statements.Delete(id)
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+	if err != nil {
+		return errors.New("не удалось удалить импорт")
+	}
+	return nil
+}
+
+func (s *statementImportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Пока проход обрабатывает полную пачку выписок, следующий запускается без ожидания
+		for ctx.Err() == nil && s.processQueued() == importBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processQueued разбирает или проводит одну пачку выписок
+// Возвращает количество обработанных выписок
+func (s *statementImportService) processQueued() int {
+	ids, err := s.statementRepo.ListQueued(importBatchSize)
+	if err != nil {
+		log.Printf("imports: не удалось получить выписки к обработке: %v", err)
+		return 0
+	}
+	processed := 0
+	for _, id := range ids {
+		if err := s.process(id); err != nil {
+			log.Printf("imports: не удалось обработать выписку %d: %v", id, err)
+			continue
+		}
+		processed++
+	}
+	return processed
+}
+
+// process разбирает или проводит выписку в одной транзакции; строка импорта блокируется,
+// поэтому выписку не обработают одновременно на нескольких репликах
+// Если операции нельзя провести (счёт в архиве, у автора больше нет прав), выписка
+// возвращается к просмотру с причиной в Error
+func (s *statementImportService) process(id uint) error {
+	var (
+		rejected error
+		posted   []*models.Transaction
+	)
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		imp, err := repos.Statements.LockByID(id)
+		if err != nil || imp == nil {
+			return err
+		}
+		switch imp.Status {
+		case models.ImportParsing:
+			return parseImport(repos, imp)
+		case models.ImportCommitting:
+			rows, err := repos.Statements.ListRows(imp.ID)
+			if err != nil {
+				return err
+			}
+			posted, err = s.commitImport(repos, imp, rows)
+			var serviceErr *Error
+			if errors.As(err, &serviceErr) {
+				rejected = err
+			}
+			return err
+		}
+		return nil
+	})
+	if rejected == nil {
+		if err == nil {
+			s.notifyPosted(posted)
+		}
+		return err
+	}
+	return s.transactor.Transaction(func(repos *repository.Repositories) error {
+		imp, err := repos.Statements.LockByID(id)
+		if err != nil || imp == nil || imp.Status != models.ImportCommitting {
+			return err
+		}
+		imp.Status = models.ImportReady
+		imp.Error = rejected.Error()
+		return repos.Statements.Update(imp)
+	})
+}
+
+// parseImport разбирает файл импорта по его сопоставлению и заменяет строки импорта
+// Если файл не удалось разобрать, импорт получает состояние failed с причиной в Error
+// Вызывайте внутри Transactor.Transaction
+func parseImport(repos *repository.Repositories, imp *models.StatementImport) error {
+	imp.Error = ""
+	imp.TotalRows, imp.ErrorRows, imp.DuplicateRows, imp.ImportedRows = 0, 0, 0, 0
+	parsed, err := statement.Parse(imp.File, imp.Currency, imp.Mapping)
+	if err != nil {
+		imp.Status = models.ImportFailed
+		imp.Error = truncateRunes(err.Error(), 1000)
+		if err := repos.Statements.ReplaceRows(imp.ID, nil); err != nil {
+			return err
+		}
+		return repos.Statements.Update(imp)
+	}
+	imp.Mapping = parsed.Mapping
+
+	rows := make([]models.StatementImportRow, 0, len(parsed.Rows))
+	occurrences := make(map[string]int)
+	var fingerprints []string
+	for _, r := range parsed.Rows {
+		row := models.StatementImportRow{
+			Line:         r.Line,
+			Payee:        truncateRunes(strings.Join(strings.Fields(r.Payee), " "), 200),
+			Note:         truncateRunes(r.Note, 1000),
+			BankCategory: truncateRunes(r.Category, 200),
+			Error:        truncateRunes(r.Error, 500),
+		}
+		if row.Error == "" {
+			date := r.Date
+			row.Date = &date
+			row.Direction, row.Amount = models.DirectionIncome, r.Amount
+			if r.Amount < 0 {
+				row.Direction, row.Amount = models.DirectionExpense, -r.Amount
+			}
+			// Одинаковые операции в одной выписке (две покупки кофе за день) различаются
+			// порядковым номером, поэтому импортируются обе, а при повторной загрузке — ни одна
+			key := fmt.Sprintf("%d|%s|%d|%s|%s", imp.AccountID, date.Format(time.DateOnly), r.Amount,
+				strings.ToLower(row.Payee), row.Note)
+			occurrences[key]++
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, occurrences[key])))
+			row.Fingerprint = hex.EncodeToString(sum[:])
+			fingerprints = append(fingerprints, row.Fingerprint)
+		}
+		rows = append(rows, row)
+	}
+
+	imported, err := repos.Statements.ImportedFingerprints(imp.AccountID, fingerprints)
+	if err != nil {
+		return err
+	}
+	if err := proposeCategories(repos, imp.FamilyID, rows); err != nil {
+		return err
+	}
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case row.Error != "":
+			imp.ErrorRows++
+		case imported[row.Fingerprint]:
+			row.Duplicate = true
+			imp.DuplicateRows++
+		}
+	}
+	imp.TotalRows = len(rows)
+	imp.Status = models.ImportReady
+	if err := repos.Statements.ReplaceRows(imp.ID, rows); err != nil {
+		return err
+	}
+	return repos.Statements.Update(imp)
+}
+
+// proposeCategories предлагает категории строкам выписки: категорию последней операции семьи
+// с тем же получателем и направлением, а если такой нет — категорию семьи того же вида,
+// название которой совпадает с категорией банка
+// Вызывайте внутри Transactor.Transaction
+func proposeCategories(repos *repository.Repositories, familyID uint, rows []models.StatementImportRow) error {
+	payees := make(map[models.Direction][]string)
+	seen := make(map[models.Direction]map[string]bool)
+	for _, row := range rows {
+		payee := strings.ToLower(row.Payee)
+		if row.Error != "" || payee == "" || seen[row.Direction][payee] {
+			continue
+		}
+		if seen[row.Direction] == nil {
+			seen[row.Direction] = make(map[string]bool)
+		}
+		seen[row.Direction][payee] = true
+		payees[row.Direction] = append(payees[row.Direction], payee)
+	}
+	byPayee := make(map[models.Direction]map[string]uint)
+	for direction, list := range payees {
+		found, err := repos.Transactions.PayeeCategories(familyID, direction, list)
+		if err != nil {
+			return err
+		}
+		byPayee[direction] = found
+	}
+
+	categories, err := repos.Categories.ListByFamily(familyID, false)
+	if err != nil {
+		return err
+	}
+	byName := make(map[models.Direction]map[string]uint)
+	for _, c := range categories {
+		if byName[c.Kind] == nil {
+			byName[c.Kind] = make(map[string]uint)
+		}
+		name := strings.ToLower(c.Name)
+		if _, ok := byName[c.Kind][name]; !ok {
+			byName[c.Kind][name] = c.ID
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Error != "" {
+			continue
+		}
+		id, ok := byPayee[row.Direction][strings.ToLower(row.Payee)]
+		if !ok && row.BankCategory != "" {
+			id, ok = byName[row.Direction][strings.ToLower(strings.TrimSpace(row.BankCategory))]
+		}
+		if ok {
+			row.CategoryID = &id
+		}
+	}
+	return nil
+}
+
+// postable сообщает, будет ли строка проведена при подтверждении импорта
+func postable(row *models.StatementImportRow) bool {
+	return row.Error == "" && !row.Skip && !row.Duplicate && row.TransactionID == nil
+}
+
+// commitImport проводит строки выписки как операции счёта от имени загрузившего её пользователя
+// и возвращает созданные операции
+// Расход проверяется так же, как в TransactionService.Create: подходящий под правило одобрения
+// сохраняется ожидающим решения, а превышающий лимит с действием reject не проводится,
+// и строка помечается ошибкой лимита. Затем строка отмечается отпечатком на счёте; если отпечаток
+// уже есть — строку импортировали из другой выписки, и она отмечается повтором вместо проведения
+// Категория, которая с момента разбора удалена или архивирована, не устанавливается
+// Вызывайте внутри Transactor.Transaction после блокировки импорта; уведомления о проведённых
+// операциях отправляет notifyPosted после фиксации транзакции
+func (s *statementImportService) commitImport(repos *repository.Repositories, imp *models.StatementImport,
+	rows []models.StatementImportRow) ([]*models.Transaction, error) {
+	account, err := repos.Accounts.GetByID(imp.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.Archived {
+		return nil, ErrAccountArchived
+	}
+	member, err := repos.Families.GetMember(imp.FamilyID, imp.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || !member.Role.CanManage() || !canEditAccount(member, account) {
+		return nil, ErrStatementImportForbidden
+	}
+	categories, err := repos.Categories.ListByFamily(imp.FamilyID, false)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[uint]models.Direction, len(categories))
+	for _, c := range categories {
+		kinds[c.ID] = c.Kind
+	}
+
+	imp.Error = ""
+	imp.ImportedRows, imp.DuplicateRows, imp.ErrorRows = 0, 0, 0
+	var posted []*models.Transaction
+	for i := range rows {
+		row := &rows[i]
+		if postable(row) {
+			categoryID := row.CategoryID
+			if categoryID != nil && kinds[*categoryID] != row.Direction {
+				categoryID = nil
+			}
+			tx := &models.Transaction{
+				FamilyID:   imp.FamilyID,
+				AccountID:  imp.AccountID,
+				Direction:  row.Direction,
+				Amount:     row.Amount,
+				Currency:   account.Currency,
+				CategoryID: categoryID,
+				Date:       *row.Date,
+				Payee:      row.Payee,
+				Note:       row.Note,
+				CreatedBy:  imp.UserID,
+			}
+			if err := s.commitRow(repos, imp, row, tx); err != nil {
+				return nil, err
+			}
+			if row.TransactionID != nil {
+				posted = append(posted, tx)
+			}
+			if err := repos.Statements.UpdateRow(row); err != nil {
+				return nil, err
+			}
+		}
+		if row.TransactionID != nil {
+			imp.ImportedRows++
+		}
+		if row.Duplicate {
+			imp.DuplicateRows++
+		}
+		if row.Error != "" {
+			imp.ErrorRows++
+		}
+	}
+	now := time.Now().UTC()
+	imp.Status = models.ImportCompleted
+	imp.CompletedAt = &now
+	return posted, repos.Statements.Update(imp)
+}
+
+// commitRow проверяет операцию tx строки row правилами одобрения и лимитами и проводит её,
+// отмечая строку проведённой, повтором или ошибкой лимита
+func (s *statementImportService) commitRow(repos *repository.Repositories, imp *models.StatementImport,
+	row *models.StatementImportRow, tx *models.Transaction) error {
+	rule, err := s.approvals.Match(tx, imp.UserID)
+	if err != nil {
+		return err
+	}
+	if rule != nil {
+		tx.Status = models.TransactionPending
+	}
+	if err := s.limits.Enforce(repos, tx); err != nil {
+		var serviceErr *Error
+		if !errors.As(err, &serviceErr) {
+			return err
+		}
+		// Строку, уже импортированную из другой выписки, отмечаем повтором, а не ошибкой лимита
+		imported, err := repos.Statements.ImportedFingerprints(imp.AccountID, []string{row.Fingerprint})
+		if err != nil {
+			return err
+		}
+		if imported[row.Fingerprint] {
+			row.Duplicate = true
+		} else {
+			row.Error = truncateRunes(serviceErr.Error(), 500)
+		}
+		return nil
+	}
+
+	added, err := repos.Statements.AddFingerprint(&models.StatementFingerprint{
+		AccountID:   imp.AccountID,
+		Fingerprint: row.Fingerprint,
+	})
+	if err != nil {
+		return err
+	}
+	if !added {
+		row.Duplicate = true
+		return nil
+	}
+	if err := postTransaction(repos, tx); err != nil {
+		return err
+	}
+	if rule != nil {
+		if err := requestApproval(repos, tx, rule, imp.UserID); err != nil {
+			return err
+		}
+	}
+	row.TransactionID = &tx.ID
+	return nil
+}
+
+// notifyPosted проверяет пороги бюджетов по проведённым операциям импорта
+// и уведомляет взрослых о расходах, ожидающих одобрения
+func (s *statementImportService) notifyPosted(posted []*models.Transaction) {
+	for _, tx := range posted {
+		if tx.Posted() {
+			s.alerts.CheckTransaction(tx)
+			continue
+		}
+		s.approvals.NotifyApprovers(tx, tx.CreatedBy)
+	}
+}
+
+// overlayMapping возвращает сопоставление base, в котором заполненные поля заменены полями override
+func overlayMapping(base, override models.StatementMapping) models.StatementMapping {
+	pick := func(b, o string) string {
+		if o != "" {
+			return o
+		}
+		return b
+	}
+	return models.StatementMapping{
+		Encoding:         pick(base.Encoding, override.Encoding),
+		Delimiter:        pick(base.Delimiter, override.Delimiter),
+		DateFormat:       pick(base.DateFormat, override.DateFormat),
+		DecimalSeparator: pick(base.DecimalSeparator, override.DecimalSeparator),
+		DateColumn:       pick(base.DateColumn, override.DateColumn),
+		AmountColumn:     pick(base.AmountColumn, override.AmountColumn),
+		DebitColumn:      pick(base.DebitColumn, override.DebitColumn),
+		CreditColumn:     pick(base.CreditColumn, override.CreditColumn),
+		PayeeColumn:      pick(base.PayeeColumn, override.PayeeColumn),
+		NoteColumn:       pick(base.NoteColumn, override.NoteColumn),
+		CategoryColumn:   pick(base.CategoryColumn, override.CategoryColumn),
+	}
+}
+
+// truncateRunes обрезает строку до maxLen символов, чтобы она поместилась в колонку
+func truncateRunes(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	return string([]rune(s)[:maxLen])
+}
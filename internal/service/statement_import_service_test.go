@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/repository"
+)
+
+type memStatements struct {
+	repository.StatementRepository
+	fingerprints map[string]bool
+}
+
+func (r *memStatements) AddFingerprint(fp *models.StatementFingerprint) (bool, error) {
+	if r.fingerprints[fp.Fingerprint] {
+		return false, nil
+	}
+	r.fingerprints[fp.Fingerprint] = true
+	return true, nil
+}
+
+func (r *memStatements) ImportedFingerprints(_ uint, fingerprints []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	for _, fp := range fingerprints {
+		if r.fingerprints[fp] {
+			found[fp] = true
+		}
+	}
+	return found, nil
+}
+
+func (r *memStatements) UpdateRow(*models.StatementImportRow) error { return nil }
+
+func (r *memStatements) Update(*models.StatementImport) error { return nil }
+
+func (memCategories) ListByFamily(uint, bool) ([]models.Category, error) {
+	return nil, nil
+}
+
+// rejectAbove отклоняет расходы больше max, как лимит с действием reject
+type rejectAbove struct {
+	SpendingLimitService
+	max int64
+}
+
+func (l rejectAbove) Enforce(_ *repository.Repositories, tx *models.Transaction) error {
+	if tx.Direction == models.DirectionExpense && tx.Amount > l.max {
+		return newError(KindConflict, "расход превышает лимит участника")
+	}
+	return nil
+}
+
+// newImportFixture семья из approvalFixture, в которую добавлен взрослый, импортирующий
+// выписку по счёту ребёнка под присмотром; расходы больше 3000 ₽ отклоняются лимитом
+func newImportFixture(t *testing.T) (*approvalFixture, *statementImportService, *repository.Repositories, *memStatements) {
+	t.Helper()
+	f := newApprovalFixture()
+	adult := &models.User{ID: 3, Name: "Папа"}
+	f.store.members = append(f.store.members, models.FamilyMember{FamilyID: 1, UserID: adult.ID, Role: models.RoleAdult, User: adult})
+	f.store.accounts[f.accountID].Supervised = true
+
+	statements := &memStatements{fingerprints: map[string]bool{"imported": true}}
+	repos := &repository.Repositories{
+		Transactions: memTransactions{m: f.store},
+		Ledger:       memLedger{m: f.store},
+		Accounts:     memAccounts{m: f.store},
+		Categories:   memCategories{},
+		Families:     memFamilies{m: f.store},
+		Approvals:    memApprovals{m: f.store},
+		Statements:   statements,
+	}
+	svc := &statementImportService{
+		transactor: memTransactor{repos: repos},
+		alerts:     noAlerts{},
+		approvals:  f.approvals,
+		limits:     rejectAbove{max: 300000},
+	}
+	return f, svc, repos, statements
+}
+
+func importRow(fingerprint string, amount int64) models.StatementImportRow {
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return models.StatementImportRow{
+		Date:        &date,
+		Direction:   models.DirectionExpense,
+		Amount:      amount,
+		Payee:       "Магазин",
+		Fingerprint: fingerprint,
+	}
+}
+
+func TestCommitImportAppliesApprovalsAndLimits(t *testing.T) {
+	f, svc, repos, statements := newImportFixture(t)
+	imp := &models.StatementImport{ID: 1, FamilyID: 1, AccountID: f.accountID, UserID: 3}
+	rows := []models.StatementImportRow{
+		importRow("small", 50000),
+		importRow("large", 150000),
+		importRow("over-limit", 500000),
+		importRow("imported", 500000),
+	}
+
+	posted, err := svc.commitImport(repos, imp, rows)
+	if err != nil {
+		t.Fatalf("commitImport: %v", err)
+	}
+
+	if len(posted) != 2 {
+		t.Fatalf("posted %d transactions, want 2", len(posted))
+	}
+	if status := posted[0].Status; status != models.TransactionPosted {
+		t.Errorf("small expense status = %q, want %q", status, models.TransactionPosted)
+	}
+	if status := posted[1].Status; status != models.TransactionPending {
+		t.Errorf("large expense status = %q, want %q", status, models.TransactionPending)
+	}
+	if approval := f.store.approvals[posted[1].ID]; approval == nil || approval.RequestedBy != 3 {
+		t.Errorf("approval = %+v, want pending request by importer", approval)
+	}
+	if got := f.store.balance(f.accountID); got != -50000 {
+		t.Errorf("balance = %d, want -50000", got)
+	}
+
+	if rows[2].TransactionID != nil || rows[2].Error == "" {
+		t.Errorf("over-limit row = %+v, want error without transaction", rows[2])
+	}
+	if statements.fingerprints["over-limit"] {
+		t.Error("over-limit row fingerprint recorded, want it importable later")
+	}
+	if !rows[3].Duplicate || rows[3].Error != "" {
+		t.Errorf("imported row = %+v, want duplicate", rows[3])
+	}
+	if imp.ImportedRows != 2 || imp.ErrorRows != 1 || imp.DuplicateRows != 1 {
+		t.Errorf("counters imported=%d error=%d duplicate=%d, want 2/1/1",
+			imp.ImportedRows, imp.ErrorRows, imp.DuplicateRows)
+	}
+}
@@ -0,0 +1,700 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+	"family_finance_back/internal/repository"
+)
+
+var (
+	// ErrTransactionNotFound возвращается, если операция не существует или недоступна пользователю
+	ErrTransactionNotFound = newError(KindNotFound, "операция не найдена")
+
+	// ErrTransactionForbidden возвращается, если пользователь не может изменять операцию
+	ErrTransactionForbidden = newError(KindForbidden, "изменять операцию может только её автор или владелец семьи")
+
+	// ErrAccountArchived возвращается при попытке провести операцию по архивному счёту
+	ErrAccountArchived = newError(KindConflict, "счёт в архиве: верните его из архива, чтобы добавлять операции")
+
+	// ErrCurrencyMismatch возвращается, если валюта операции не совпадает с валютой счёта
+	ErrCurrencyMismatch = newError(KindInvalid, "валюта операции должна совпадать с валютой счёта")
+
+	// ErrAccountFamilyMismatch возвращается при переносе операции на счёт другой семьи
+	ErrAccountFamilyMismatch = newError(KindInvalid, "счёт принадлежит другой семье")
+
+	// ErrInvalidCursor возвращается, если курсор пагинации повреждён
+	ErrInvalidCursor = newError(KindInvalid, "некорректный курсор")
+
+	// ErrTransferSameAccount возвращается при переводе со счёта на тот же счёт
+	ErrTransferSameAccount = newError(KindInvalid, "счета списания и зачисления должны различаться")
+
+	// ErrTransferRateRequired возвращается, если для перевода между валютами не указан курс
+	ErrTransferRateRequired = newError(KindInvalid, "для перевода между счетами в разных валютах укажите курс")
+
+	// ErrTransferRateUnexpected возвращается, если курс указан для перевода в одной валюте
+	ErrTransferRateUnexpected = newError(KindInvalid, "курс указывается только для перевода между разными валютами")
+
+	// ErrTransferAmountTooSmall возвращается, если после пересчёта по курсу сумма зачисления равна нулю
+	ErrTransferAmountTooSmall = newError(KindInvalid, "сумма зачисления по указанному курсу меньше минимальной единицы валюты")
+
+	// ErrTransferImmutable возвращается при попытке изменить у перевода счета, направление или категорию
+	ErrTransferImmutable = newError(KindInvalid, "у перевода можно изменить только сумму, дату, получателя и комментарий; чтобы сменить счета, создайте перевод заново")
+
+	// ErrTransactionToTransfer возвращается при попытке превратить доход или расход в перевод
+	ErrTransactionToTransfer = newError(KindInvalid, "доход или расход нельзя превратить в перевод; создайте перевод отдельно")
+
+	// ErrTransactionRejected возвращается при изменении отклонённого расхода
+	ErrTransactionRejected = newError(KindConflict, "расход отклонён: его можно только удалить и записать заново")
+)
+
+// TransactionInput данные новой операции
+type TransactionInput struct {
+	AccountID uint
+	Direction models.Direction
+	// Amount сумма в минимальных единицах валюты
+	Amount int64
+	// Currency валюта операции; по умолчанию валюта счёта
+	Currency   string
+	CategoryID *uint
+	// Date дата операции; по умолчанию текущая дата в часовом поясе семьи
+	Date  *time.Time
+	Payee string
+	Note  string
+}
+
+// TransferInput данные перевода между счетами
+type TransferInput struct {
+	FromAccountID uint
+	ToAccountID   uint
+	// Amount сумма списания в минимальных единицах валюты счёта списания
+	Amount int64
+	// Rate курс: сколько единиц валюты счёта зачисления стоит единица валюты счёта списания
+	// Обязателен для перевода между разными валютами
+	Rate *big.Rat
+	// Date дата перевода; по умолчанию текущая дата в часовом поясе семьи
+	Date  *time.Time
+	Payee string
+	Note  string
+}
+
+// TransactionChanges изменения операции; nil и нулевые поля не изменяются
+type TransactionChanges struct {
+	AccountID uint
+	Direction models.Direction
+	Amount    int64
+	// CategoryID новая категория; указатель на 0 снимает категорию
+	CategoryID *uint
+	Date       *time.Time
+	Payee      *string
+	Note       *string
+}
+
+// TransactionQuery параметры выборки операций семьи
+type TransactionQuery struct {
+	FamilyID   uint
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	AccountID  uint
+	CategoryID uint
+	MemberID   uint
+	Direction  models.Direction
+	AmountMin  *int64
+	AmountMax  *int64
+	// Cursor значение next_cursor из предыдущей страницы
+	Cursor string
+	Limit  int
+}
+
+// TransactionPage страница списка операций
+type TransactionPage struct {
+	Items []models.Transaction `json:"items"`
+	// NextCursor курсор следующей страницы; пустой, если страница последняя
+	NextCursor string `json:"next_cursor"`
+}
+
+// TransactionService определяет интерфейс для работы с операциями
+type TransactionService interface {
+	// Create добавляет операцию по счёту
+	// Проводить операции можно по счетам, которые пользователь вправе изменять
+	// Расход, подходящий под правило одобрения семьи, сохраняется без проводок и ждёт решения
+	Create(user *models.User, input TransactionInput) (*models.Transaction, error)
+
+	// Transfer переводит деньги между счетами одной семьи, в том числе в разных валютах
+	// Пользователь должен иметь право вести оба счёта
+	Transfer(user *models.User, input TransferInput) (*models.Transaction, error)
+
+	// Get возвращает операцию; доступно любому участнику семьи
+	Get(user *models.User, id uint) (*models.Transaction, error)
+
+	// Update изменяет операцию и пересчитывает её проводки; доступно автору и владельцу семьи
+	// Ожидающий одобрения расход проверяется по правилам заново, проведённый — если он стал
+	// крупнее или сменил категорию; отклонённый расход изменить нельзя
+	Update(user *models.User, id uint, changes TransactionChanges) (*models.Transaction, error)
+
+	// Delete удаляет операцию; доступно автору и владельцу семьи
+	Delete(user *models.User, id uint) error
+
+	// List возвращает страницу операций семьи по фильтру; доступно любому участнику семьи
+	List(user *models.User, query TransactionQuery) (*TransactionPage, error)
+}
+
+// transactionService реализует интерфейс TransactionService
+type transactionService struct {
+	transactor   repository.Transactor
+	txRepo       repository.TransactionRepository
+	accountRepo  repository.AccountRepository
+	categoryRepo repository.CategoryRepository
+	familyRepo   repository.FamilyRepository
+	alerts       BudgetAlertService
+	approvals    ApprovalService
+	limits       SpendingLimitService
+}
+
+// NewTransactionService создает новый экземпляр TransactionService
+// После сохранения дохода или расхода alerts проверяет пороги бюджетов,
+// approvals решает, нужно ли расходу одобрение, а limits проверяет лимиты расходов автора
+func NewTransactionService(transactor repository.Transactor, txRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository, categoryRepo repository.CategoryRepository,
+	familyRepo repository.FamilyRepository, alerts BudgetAlertService, approvals ApprovalService,
+	limits SpendingLimitService) TransactionService {
+	return &transactionService{
+		transactor:   transactor,
+		txRepo:       txRepo,
+		accountRepo:  accountRepo,
+		categoryRepo: categoryRepo,
+		familyRepo:   familyRepo,
+		alerts:       alerts,
+		approvals:    approvals,
+		limits:       limits,
+	}
+}
+
+// canEditTransaction сообщает, может ли участник семьи изменять операцию
+func canEditTransaction(member *models.FamilyMember, tx *models.Transaction) bool {
+	if member.Role == models.RoleOwner {
+		return true
+	}
+	return tx.CreatedBy == member.UserID && member.Role != models.RoleViewer
+}
+
+func (s *transactionService) Create(user *models.User, input TransactionInput) (*models.Transaction, error) {
+	account, member, err := postableAccount(s.accountRepo, s.familyRepo, user, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Currency != "" && input.Currency != account.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	if input.CategoryID != nil {
+		if err := checkCategory(s.categoryRepo, account.FamilyID, *input.CategoryID, input.Direction, false); err != nil {
+			return nil, err
+		}
+	}
+
+	date := input.Date
+	if date == nil {
+		today, err := familyToday(s.familyRepo, account.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		date = &today
+	}
+
+	tx := &models.Transaction{
+		FamilyID:   account.FamilyID,
+		AccountID:  account.ID,
+		Direction:  input.Direction,
+		Amount:     input.Amount,
+		Currency:   account.Currency,
+		CategoryID: input.CategoryID,
+		Date:       *date,
+		Payee:      input.Payee,
+		Note:       input.Note,
+		CreatedBy:  member.UserID,
+	}
+	rule, err := s.approvals.Match(tx, member.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		tx.Status = models.TransactionPending
+	}
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := s.limits.Enforce(repos, tx); err != nil {
+			return err
+		}
+		if err := postTransaction(repos, tx); err != nil {
+			return err
+		}
+		if rule != nil {
+			return requestApproval(repos, tx, rule, member.UserID)
+		}
+		return nil
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("не удалось сохранить операцию, попробуйте позже")
+	}
+	tx.Author = user
+	s.notifyPosted(tx, member.UserID)
+	return tx, nil
+}
+
+// notifyPosted проверяет пороги бюджетов после проведения операции
+// или уведомляет взрослых о расходе, ожидающем одобрения
+func (s *transactionService) notifyPosted(tx *models.Transaction, requester uint) {
+	if tx.Posted() {
+		s.alerts.CheckTransaction(tx)
+		return
+	}
+	s.approvals.NotifyApprovers(tx, requester)
+}
+
+func (s *transactionService) Transfer(user *models.User, input TransferInput) (*models.Transaction, error) {
+	if input.FromAccountID == input.ToAccountID {
+		return nil, ErrTransferSameAccount
+	}
+	from, member, err := postableAccount(s.accountRepo, s.familyRepo, user, input.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	to, _, err := postableAccount(s.accountRepo, s.familyRepo, user, input.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if from.FamilyID != to.FamilyID {
+		return nil, ErrAccountFamilyMismatch
+	}
+
+	date := input.Date
+	if date == nil {
+		today, err := familyToday(s.familyRepo, from.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		date = &today
+	}
+
+	tx := &models.Transaction{
+		FamilyID:         from.FamilyID,
+		AccountID:        from.ID,
+		Direction:        models.DirectionTransfer,
+		Amount:           input.Amount,
+		Currency:         from.Currency,
+		CounterAccountID: &to.ID,
+		CounterCurrency:  &to.Currency,
+		Date:             *date,
+		Payee:            input.Payee,
+		Note:             input.Note,
+		CreatedBy:        member.UserID,
+	}
+	switch {
+	case from.Currency == to.Currency && input.Rate != nil:
+		return nil, ErrTransferRateUnexpected
+	case from.Currency != to.Currency && input.Rate == nil:
+		return nil, ErrTransferRateRequired
+	case input.Rate != nil:
+		rate := input.Rate.FloatString(10)
+		tx.Rate = &rate
+	}
+	if err := applyTransferAmount(tx, input.Amount); err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.Transaction(func(repos *repository.Repositories) error {
+		return postTransaction(repos, tx)
+	})
+	if err != nil {
+		return nil, errors.New("не удалось сохранить перевод, попробуйте позже")
+	}
+	tx.Author = user
+	return tx, nil
+}
+
+// applyTransferAmount устанавливает сумму списания перевода и пересчитывает сумму зачисления по курсу
+func applyTransferAmount(tx *models.Transaction, amount int64) error {
+	counter := amount
+	if tx.Rate != nil {
+		rate, ok := new(big.Rat).SetString(*tx.Rate)
+		if !ok {
+			return errors.New("некорректный курс перевода")
+		}
+		converted, err := money.Convert(amount, tx.Currency, *tx.CounterCurrency, rate)
+		if err != nil {
+			return newError(KindInvalid, err.Error())
+		}
+		if converted <= 0 {
+			return ErrTransferAmountTooSmall
+		}
+		counter = converted
+	}
+	tx.Amount = amount
+	tx.CounterAmount = &counter
+	return nil
+}
+
+func (s *transactionService) Get(user *models.User, id uint) (*models.Transaction, error) {
+	tx, _, err := s.load(user, id)
+	return tx, err
+}
+
+func (s *transactionService) Update(user *models.User, id uint, changes TransactionChanges) (*models.Transaction, error) {
+	tx, member, err := s.load(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canEditTransaction(member, tx) {
+		return nil, ErrTransactionForbidden
+	}
+	if tx.Status == models.TransactionRejected {
+		return nil, ErrTransactionRejected
+	}
+	// Изменять операцию можно, только если пользователь вправе вести её счёт
+	if _, _, err := postableAccount(s.accountRepo, s.familyRepo, user, tx.AccountID); err != nil {
+		return nil, err
+	}
+
+	if tx.IsTransfer() {
+		return s.updateTransfer(user, tx, changes)
+	}
+	if changes.Direction == models.DirectionTransfer {
+		return nil, ErrTransactionToTransfer
+	}
+
+	prev := *tx
+	if changes.AccountID != 0 && changes.AccountID != tx.AccountID {
+		account, _, err := postableAccount(s.accountRepo, s.familyRepo, user, changes.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if account.FamilyID != tx.FamilyID {
+			return nil, ErrAccountFamilyMismatch
+		}
+		if account.Currency != tx.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+		tx.AccountID = account.ID
+	}
+	directionChanged := changes.Direction != "" && changes.Direction != tx.Direction
+	if changes.Direction != "" {
+		tx.Direction = changes.Direction
+	}
+	if changes.Amount != 0 {
+		tx.Amount = changes.Amount
+	}
+	switch {
+	case changes.CategoryID != nil && *changes.CategoryID == 0:
+		tx.CategoryID = nil
+	case changes.CategoryID != nil && (tx.CategoryID == nil || *tx.CategoryID != *changes.CategoryID):
+		if err := checkCategory(s.categoryRepo, tx.FamilyID, *changes.CategoryID, tx.Direction, false); err != nil {
+			return nil, err
+		}
+		tx.CategoryID = changes.CategoryID
+	case directionChanged && tx.CategoryID != nil:
+		// Прежняя категория может быть архивной: операция остаётся в ней,
+		// но её вид должен подходить новому направлению
+		if err := checkCategory(s.categoryRepo, tx.FamilyID, *tx.CategoryID, tx.Direction, true); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Date != nil {
+		tx.Date = *changes.Date
+	}
+	if changes.Payee != nil {
+		tx.Payee = *changes.Payee
+	}
+	if changes.Note != nil {
+		tx.Note = *changes.Note
+	}
+
+	// Ожидающий расход проверяется заново при любой правке: если он больше не подходит
+	// ни под одно правило, repost проводит его и закрывает запрос на одобрение
+	var rule *models.ApprovalRule
+	categoryChanged := (prev.CategoryID == nil) != (tx.CategoryID == nil) ||
+		(prev.CategoryID != nil && *prev.CategoryID != *tx.CategoryID)
+	if !tx.Posted() || directionChanged || categoryChanged || tx.Amount > prev.Amount {
+		if rule, err = s.approvals.Match(tx, member.UserID); err != nil {
+			return nil, err
+		}
+		tx.Status = models.TransactionPosted
+		if rule != nil {
+			tx.Status = models.TransactionPending
+		}
+	}
+
+	// Лимиты проверяются заново, только если расход мог вырасти или попасть в другой период или счёт
+	checkLimits := directionChanged || tx.Amount > prev.Amount || tx.AccountID != prev.AccountID ||
+		!tx.Date.Equal(prev.Date)
+	if err := s.repost(tx, user.ID, rule, checkLimits); err != nil {
+		return nil, err
+	}
+	s.notifyPosted(tx, member.UserID)
+	return tx, nil
+}
+
+// updateTransfer изменяет перевод; счета и направление перевода не меняются
+func (s *transactionService) updateTransfer(user *models.User, tx *models.Transaction, changes TransactionChanges) (*models.Transaction, error) {
+	if (changes.AccountID != 0 && changes.AccountID != tx.AccountID) ||
+		(changes.Direction != "" && changes.Direction != models.DirectionTransfer) ||
+		(changes.CategoryID != nil && *changes.CategoryID != 0) {
+		return nil, ErrTransferImmutable
+	}
+	// Счёт зачисления тоже должен оставаться доступным пользователю
+	if _, _, err := postableAccount(s.accountRepo, s.familyRepo, user, *tx.CounterAccountID); err != nil {
+		return nil, err
+	}
+	if changes.Amount != 0 {
+		if err := applyTransferAmount(tx, changes.Amount); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Date != nil {
+		tx.Date = *changes.Date
+	}
+	if changes.Payee != nil {
+		tx.Payee = *changes.Payee
+	}
+	if changes.Note != nil {
+		tx.Note = *changes.Note
+	}
+	if err := s.repost(tx, user.ID, nil, false); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// repost атомарно сохраняет изменённую операцию вместе с новыми проводками,
+// приводит к ней разделение расхода от имени editor и обновляет запрос на одобрение:
+// по правилу rule расход снова ждёт решения, а ожидающий запрос операции, которая больше
+// не подходит ни под одно правило, например стала доходом или меньше порога, удаляется
+// При checkLimits расход перед сохранением проверяется по лимитам автора
+func (s *transactionService) repost(tx *models.Transaction, editor uint, rule *models.ApprovalRule, checkLimits bool) error {
+	err := s.transactor.Transaction(func(repos *repository.Repositories) error {
+		if checkLimits {
+			if err := s.limits.Enforce(repos, tx); err != nil {
+				return err
+			}
+		}
+		if err := repostTransaction(repos, tx); err != nil {
+			return err
+		}
+		if err := adjustSplit(repos, tx, editor); err != nil {
+			return err
+		}
+		if rule != nil {
+			return requestApproval(repos, tx, rule, editor)
+		}
+		if !tx.IsTransfer() {
+			return repos.Approvals.DeletePending(tx.ID)
+		}
+		return nil
+	})
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+	if err != nil {
+		return errors.New("не удалось сохранить операцию, попробуйте позже")
+	}
+	return nil
+}
+
+func (s *transactionService) Delete(user *models.User, id uint) error {
+	tx, member, err := s.load(user, id)
+	if err != nil {
+		return err
+	}
+	if !canEditTransaction(member, tx) {
+		return ErrTransactionForbidden
+	}
+	if err := s.checkSupervised(member, tx); err != nil {
+		return err
+	}
+	if err := s.txRepo.Delete(tx.ID); err != nil {
+		return errors.New("не удалось удалить операцию, попробуйте позже")
+	}
+	return nil
+}
+
+// checkSupervised не даёт удалить операцию по счёту под присмотром тому, кто не ведёт этот счёт,
+// например ребёнку — его прежнюю операцию по счёту, на который теперь приходят карманные деньги
+func (s *transactionService) checkSupervised(member *models.FamilyMember, tx *models.Transaction) error {
+	ids := []uint{tx.AccountID}
+	if tx.CounterAccountID != nil {
+		ids = append(ids, *tx.CounterAccountID)
+	}
+	for _, id := range ids {
+		account, err := s.accountRepo.GetByID(id)
+		if err != nil {
+			return errors.New("не удалось получить данные счёта")
+		}
+		if account != nil && account.Supervised && !canEditAccount(member, account) {
+			return ErrAccountForbidden
+		}
+	}
+	return nil
+}
+
+func (s *transactionService) List(user *models.User, query TransactionQuery) (*TransactionPage, error) {
+	if _, err := requireMember(s.familyRepo, query.FamilyID, user.ID); err != nil {
+		return nil, err
+	}
+	filter := repository.TransactionFilter{
+		FamilyID:   query.FamilyID,
+		DateFrom:   query.DateFrom,
+		DateTo:     query.DateTo,
+		AccountID:  query.AccountID,
+		CategoryID: query.CategoryID,
+		CreatedBy:  query.MemberID,
+		Direction:  query.Direction,
+		AmountMin:  query.AmountMin,
+		AmountMax:  query.AmountMax,
+		// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+		Limit: query.Limit + 1,
+	}
+	if query.Cursor != "" {
+		cursor, err := decodeTransactionCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	txs, err := s.txRepo.List(filter)
+	if err != nil {
+		return nil, errors.New("не удалось получить список операций")
+	}
+	page := &TransactionPage{Items: txs}
+	if len(txs) > query.Limit {
+		page.Items = txs[:query.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeTransactionCursor(repository.TransactionCursor{Date: last.Date, ID: last.ID})
+	}
+	if page.Items == nil {
+		page.Items = []models.Transaction{}
+	}
+	return page, nil
+}
+
+// load получает операцию и членство пользователя в её семье
+// Операция чужой семьи неотличима от несуществующей
+func (s *transactionService) load(user *models.User, id uint) (*models.Transaction, *models.FamilyMember, error) {
+	tx, err := s.txRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить операцию")
+	}
+	if tx == nil {
+		return nil, nil, ErrTransactionNotFound
+	}
+	member, err := requireMember(s.familyRepo, tx.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, member, nil
+}
+
+// postableAccount получает счёт, по которому пользователь может проводить операции
+func postableAccount(accountRepo repository.AccountRepository, familyRepo repository.FamilyRepository,
+	user *models.User, accountID uint) (*models.Account, *models.FamilyMember, error) {
+	account, err := accountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, nil, errors.New("не удалось получить данные счёта")
+	}
+	if account == nil {
+		return nil, nil, ErrAccountNotFound
+	}
+	member, err := requireMember(familyRepo, account.FamilyID, user.ID)
+	if err == ErrFamilyNotFound {
+		return nil, nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canEditAccount(member, account) {
+		return nil, nil, ErrAccountForbidden
+	}
+	if account.Archived {
+		return nil, nil, ErrAccountArchived
+	}
+	return account, member, nil
+}
+
+// checkCategory проверяет, что категория принадлежит семье и подходит направлению операции
+// Архивная категория допускается только для операций, которые уже в ней находятся
+func checkCategory(categoryRepo repository.CategoryRepository, familyID, categoryID uint,
+	direction models.Direction, allowArchived bool) error {
+	category, err := categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return errors.New("не удалось получить данные категории")
+	}
+	if category == nil || category.FamilyID != familyID {
+		return ErrCategoryNotFound
+	}
+	if category.Archived && !allowArchived {
+		return ErrCategoryArchived
+	}
+	if category.Kind != direction {
+		return ErrCategoryDirectionMismatch
+	}
+	return nil
+}
+
+// familyToday возвращает текущую дату в часовом поясе семьи
+func familyToday(familyRepo repository.FamilyRepository, familyID uint) (time.Time, error) {
+	family, err := familyRepo.GetByID(familyID)
+	if err != nil || family == nil {
+		return time.Time{}, errors.New("не удалось получить данные семьи")
+	}
+	return familyDate(family), nil
+}
+
+// familyDate возвращает текущую дату в часовом поясе уже загруженной семьи
+func familyDate(family *models.Family) time.Time {
+	loc, err := time.LoadLocation(family.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := time.Now().In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// encodeTransactionCursor кодирует позицию в списке операций в непрозрачную строку
+func encodeTransactionCursor(c repository.TransactionCursor) string {
+	raw := fmt.Sprintf("%s:%d", c.Date.Format(time.DateOnly), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTransactionCursor разбирает курсор, полученный от encodeTransactionCursor
+func decodeTransactionCursor(s string) (*repository.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	datePart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	date, err := time.Parse(time.DateOnly, datePart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.TransactionCursor{Date: date, ID: uint(id)}, nil
+}
@@ -25,15 +25,17 @@ type UserService interface {
 
 // userService реализует интерфейс UserService
 type userService struct {
-	userRepo  repository.UserRepository
-	jwtSecret string
+	userRepo   repository.UserRepository
+	jwtSecret  string
+	normalizer util.EmailNormalizer
 }
 
 // NewUserService создает новый экземпляр UserService
-func NewUserService(userRepo repository.UserRepository, jwtSecret string) UserService {
+func NewUserService(userRepo repository.UserRepository, jwtSecret string, normalizer util.EmailNormalizer) UserService {
 	return &userService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:   userRepo,
+		jwtSecret:  jwtSecret,
+		normalizer: normalizer,
 	}
 }
 
@@ -44,8 +46,13 @@ func (s *userService) GetUserByToken(token string) (*models.User, error) {
 		return nil, errors.New("недействительный токен")
 	}
 
-	// Получаем пользователя по email
-	user, err := s.userRepo.GetByEmail(claims.Email)
+	key, err := s.normalizer.Normalize(claims.Email)
+	if err != nil {
+		return nil, errors.New("недействительный токен")
+	}
+
+	// Получаем пользователя по ключу адреса
+	user, err := s.userRepo.GetByEmailKey(key)
 	if err != nil {
 		return nil, errors.New("не удалось получить данные пользователя")
 	}
@@ -57,7 +64,11 @@ func (s *userService) GetUserByToken(token string) (*models.User, error) {
 }
 
 func (s *userService) GetUserByEmail(email string) (*models.User, error) {
-	user, err := s.userRepo.GetByEmail(email)
+	key, err := s.normalizer.Normalize(email)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByEmailKey(key)
 	if err != nil {
 		return nil, errors.New("не удалось получить данные пользователя")
 	}
@@ -0,0 +1,635 @@
+// Package statement разбирает банковские выписки в CSV для импорта операций
+// Кодировка (UTF-8 или windows-1251), разделитель столбцов, строка заголовка, формат дат
+// и десятичный разделитель определяются по содержимому файла, если не заданы в сопоставлении
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"family_finance_back/internal/models"
+	"family_finance_back/internal/money"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// EncodingUTF8 кодировка UTF-8, в том числе с BOM
+	EncodingUTF8 = "utf-8"
+	// EncodingWindows1251 кодировка windows-1251, в которой выгружают выписки многие российские банки
+	EncodingWindows1251 = "windows-1251"
+)
+
+// delimiters допустимые разделители столбцов в порядке предпочтения при равном счёте
+var delimiters = []string{";", ",", "\t", "|"}
+
+// dateFormats допустимые форматы дат и соответствующие им раскладки time.Parse
+// При определении формата порядок важен: ДД/ММ проверяется раньше ММ/ДД
+var dateFormats = []struct {
+	format string
+	layout string
+}{
+	{"DD.MM.YYYY", "02.01.2006"},
+	{"YYYY-MM-DD", "2006-01-02"},
+	{"DD/MM/YYYY", "02/01/2006"},
+	{"MM/DD/YYYY", "01/02/2006"},
+	{"DD-MM-YYYY", "02-01-2006"},
+	{"DD.MM.YY", "02.01.06"},
+	{"YYYY.MM.DD", "2006.01.02"},
+	{"YYYY/MM/DD", "2006/01/02"},
+}
+
+const (
+	// sniffRecords сколько первых записей учитывается при определении разделителя
+	sniffRecords = 20
+	// headerScanRecords в скольких первых записях ищется строка заголовка
+	headerScanRecords = 20
+)
+
+var (
+	// ErrEmpty возвращается, если в выписке нет ни одной операции
+	ErrEmpty = errors.New("в выписке нет операций")
+
+	// ErrNoHeader возвращается, если не найдена строка заголовка со столбцами даты и суммы
+	ErrNoHeader = errors.New("не найдена строка заголовка со столбцами даты и суммы; задайте сопоставление столбцов")
+)
+
+// ValidEncoding сообщает, является ли значение поддерживаемой кодировкой
+func ValidEncoding(encoding string) bool {
+	return encoding == EncodingUTF8 || encoding == EncodingWindows1251
+}
+
+// ValidDelimiter сообщает, является ли значение допустимым разделителем столбцов
+func ValidDelimiter(delimiter string) bool {
+	for _, d := range delimiters {
+		if d == delimiter {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidDateFormat сообщает, является ли значение допустимым форматом даты, например DD.MM.YYYY
+func ValidDateFormat(format string) bool {
+	return dateLayout(format) != ""
+}
+
+// ValidDecimalSeparator сообщает, является ли значение допустимым десятичным разделителем
+func ValidDecimalSeparator(separator string) bool {
+	return separator == "," || separator == "."
+}
+
+// DateFormats возвращает допустимые форматы дат
+func DateFormats() []string {
+	list := make([]string, len(dateFormats))
+	for i, f := range dateFormats {
+		list[i] = f.format
+	}
+	return list
+}
+
+// dateLayout возвращает раскладку time.Parse для формата даты или пустую строку
+func dateLayout(format string) string {
+	for _, f := range dateFormats {
+		if f.format == format {
+			return f.layout
+		}
+	}
+	return ""
+}
+
+// Row операция из выписки
+type Row struct {
+	// Line номер строки файла, с которой начинается запись
+	Line int
+	// Date дата операции
+	Date time.Time
+	// Amount сумма в минимальных единицах валюты: отрицательная — списание, положительная — зачисление
+	Amount int64
+	// Payee получатель или описание операции
+	Payee string
+	// Note комментарий
+	Note string
+	// Category категория, присвоенная банком
+	Category string
+	// Error причина, по которой строку не удалось разобрать; пусто, если строка разобрана
+	Error string
+}
+
+// Statement разобранная выписка
+type Statement struct {
+	// Mapping параметры, с которыми выписка разобрана: заданные и определённые автоматически
+	// Столбцы указаны именами из заголовка или номерами, если заголовка нет
+	Mapping models.StatementMapping
+	// Rows операции в порядке строк файла
+	Rows []Row
+}
+
+// field поле операции, которому сопоставляется столбец
+type field int
+
+const (
+	fieldDate field = iota
+	fieldAmount
+	fieldDebit
+	fieldCredit
+	fieldPayee
+	fieldNote
+	fieldCategory
+	fieldCount
+)
+
+// synonyms названия столбцов в выписках разных банков, в порядке предпочтения
+var synonyms = [fieldCount][]string{
+	fieldDate: {"дата операции", "дата", "дата транзакции", "дата платежа", "дата проводки",
+		"date", "transaction date", "posting date", "booking date", "value date"},
+	fieldAmount: {"сумма в валюте счета", "сумма платежа", "сумма операции", "сумма",
+		"amount", "sum", "value"},
+	fieldDebit: {"расход", "списание", "сумма списания", "дебет", "debit", "withdrawal", "money out", "paid out"},
+	fieldCredit: {"приход", "поступление", "зачисление", "сумма зачисления", "кредит",
+		"credit", "deposit", "money in", "paid in"},
+	fieldPayee: {"описание", "получатель", "контрагент", "наименование", "назначение",
+		"description", "payee", "merchant", "counterparty", "name"},
+	fieldNote:     {"комментарий", "примечание", "назначение платежа", "note", "memo", "comment", "details", "reference"},
+	fieldCategory: {"категория", "category"},
+}
+
+// column возвращает заданный в сопоставлении столбец поля
+func column(m *models.StatementMapping, f field) *string {
+	switch f {
+	case fieldDate:
+		return &m.DateColumn
+	case fieldAmount:
+		return &m.AmountColumn
+	case fieldDebit:
+		return &m.DebitColumn
+	case fieldCredit:
+		return &m.CreditColumn
+	case fieldPayee:
+		return &m.PayeeColumn
+	case fieldNote:
+		return &m.NoteColumn
+	default:
+		return &m.CategoryColumn
+	}
+}
+
+// Parse разбирает выписку data в валюте currency по сопоставлению mapping
+// Столбцы задаются именами из строки заголовка или номерами, начиная с 1; если дата и сумма
+// заданы номерами, выписка читается без заголовка
+// Строки, которые не удалось разобрать, возвращаются с причиной в Row.Error;
+// ошибка возвращается, если нельзя разобрать файл целиком
+func Parse(data []byte, currency string, mapping models.StatementMapping) (*Statement, error) {
+	m := mapping
+	text, err := decode(data, &m.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if m.Delimiter == "" {
+		m.Delimiter = detectDelimiter(text)
+	}
+	records, lines, err := readRecords(text, m.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, start, err := locateColumns(records, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пустые строки и итоговые строки без даты не являются операциями
+	var entries [][]string
+	var entryLines []int
+	for i := start; i < len(records); i++ {
+		if columns.cell(records[i], fieldDate) != "" {
+			entries = append(entries, records[i])
+			entryLines = append(entryLines, lines[i])
+		}
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+
+	if m.DateFormat == "" {
+		values := make([]string, len(entries))
+		for i, record := range entries {
+			values[i] = columns.cell(record, fieldDate)
+		}
+		if m.DateFormat = detectDateFormat(values); m.DateFormat == "" {
+			return nil, errors.New("не удалось определить формат даты; задайте его в сопоставлении")
+		}
+	}
+	if m.DecimalSeparator == "" {
+		var values []string
+		for _, record := range entries {
+			for _, f := range []field{fieldAmount, fieldDebit, fieldCredit} {
+				if v := columns.cell(record, f); v != "" {
+					values = append(values, v)
+				}
+			}
+		}
+		m.DecimalSeparator = detectDecimalSeparator(values)
+	}
+
+	statement := &Statement{Mapping: m, Rows: make([]Row, 0, len(entries))}
+	for i, record := range entries {
+		row := Row{
+			Line:     entryLines[i],
+			Payee:    columns.cell(record, fieldPayee),
+			Note:     columns.cell(record, fieldNote),
+			Category: columns.cell(record, fieldCategory),
+		}
+		if err := row.parse(record, columns, &m, currency); err != nil {
+			row.Error = err.Error()
+		}
+		statement.Rows = append(statement.Rows, row)
+	}
+	return statement, nil
+}
+
+// columnSet позиции столбцов полей в записи; -1 — столбца нет
+type columnSet [fieldCount]int
+
+// cell возвращает значение поля f из записи или пустую строку, если столбца нет
+func (c *columnSet) cell(record []string, f field) string {
+	i := c[f]
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parse разбирает дату и сумму записи с параметрами сопоставления m
+func (row *Row) parse(record []string, columns columnSet, m *models.StatementMapping, currency string) error {
+	date, err := parseDate(dateLayout(m.DateFormat), columns.cell(record, fieldDate))
+	if err != nil {
+		return err
+	}
+	row.Date = date
+	amount := func(f field) (int64, error) {
+		return parseAmount(columns.cell(record, f), m.DecimalSeparator, currency)
+	}
+	if columns[fieldAmount] >= 0 {
+		if row.Amount, err = amount(fieldAmount); err != nil {
+			return err
+		}
+	} else {
+		debit, err := amount(fieldDebit)
+		if err != nil {
+			return err
+		}
+		credit, err := amount(fieldCredit)
+		if err != nil {
+			return err
+		}
+		// Списание в отдельном столбце бывает записано как со знаком минус, так и без него
+		row.Amount = abs(credit) - abs(debit)
+	}
+	if row.Amount == 0 {
+		return errors.New("нулевая сумма операции")
+	}
+	return nil
+}
+
+// decode переводит содержимое файла в UTF-8
+// Если кодировка не задана, файл считается UTF-8, когда он им является, иначе windows-1251
+func decode(data []byte, encoding *string) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if *encoding == "" {
+		*encoding = EncodingWindows1251
+		if utf8.Valid(data) {
+			*encoding = EncodingUTF8
+		}
+	}
+	switch *encoding {
+	case EncodingUTF8:
+		if !utf8.Valid(data) {
+			return "", errors.New("файл не в кодировке UTF-8")
+		}
+		return string(data), nil
+	case EncodingWindows1251:
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("не удалось перекодировать файл из windows-1251: %w", err)
+		}
+		return string(decoded), nil
+	}
+	return "", fmt.Errorf("неподдерживаемая кодировка %q", *encoding)
+}
+
+// newReader создает читатель CSV с разделителем delimiter
+func newReader(text, delimiter string) *csv.Reader {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma, _ = utf8.DecodeRuneInString(delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+// detectDelimiter выбирает разделитель, при котором больше всего первых записей
+// имеют одинаковое число столбцов (больше одного); при равенстве — тот, что даёт больше
+// столбцов: запятые в суммах «-100,50» делят строки поровну и при другом разделителе
+func detectDelimiter(text string) string {
+	best, bestScore, bestColumns := delimiters[0], 0, 0
+	for _, d := range delimiters {
+		reader := newReader(text, d)
+		counts := make(map[int]int)
+		score, columns := 0, 0
+		for i := 0; i < sniffRecords; i++ {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if len(record) > 1 {
+				counts[len(record)]++
+				if n := counts[len(record)]; n > score || n == score && len(record) > columns {
+					score, columns = n, len(record)
+				}
+			}
+		}
+		if score > bestScore || score == bestScore && columns > bestColumns {
+			best, bestScore, bestColumns = d, score, columns
+		}
+	}
+	return best
+}
+
+// readRecords читает все записи CSV и номера строк, с которых они начинаются
+func readRecords(text, delimiter string) ([][]string, []int, error) {
+	reader := newReader(text, delimiter)
+	var (
+		records [][]string
+		lines   []int
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("некорректный CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+// locateColumns определяет позиции столбцов и индекс первой записи с операциями
+// Имена найденных столбцов записываются в m
+func locateColumns(records [][]string, m *models.StatementMapping) (columnSet, int, error) {
+	var columns columnSet
+	for f := range columns {
+		columns[f] = -1
+	}
+
+	// Столбцы, заданные номерами, не требуют заголовка
+	for f := field(0); f < fieldCount; f++ {
+		spec := *column(m, f)
+		if spec == "" {
+			continue
+		}
+		n, err := strconv.Atoi(spec)
+		if err != nil {
+			continue
+		}
+		if n < 1 {
+			return columns, 0, fmt.Errorf("некорректный номер столбца %q", spec)
+		}
+		columns[f] = n - 1
+	}
+	if columns[fieldDate] >= 0 && (columns[fieldAmount] >= 0 || columns[fieldDebit] >= 0 || columns[fieldCredit] >= 0) {
+		for f := field(0); f < fieldCount; f++ {
+			if spec := *column(m, f); spec != "" && columns[f] < 0 {
+				return columns, 0, fmt.Errorf("столбец %q: в выписке без заголовка столбцы задаются номерами", spec)
+			}
+		}
+		// Строки над операциями (реквизиты счёта, заголовок) пропускаются до первой даты
+		for i, record := range records {
+			if len(record) > columns[fieldDate] && looksLikeDate(strings.TrimSpace(record[columns[fieldDate]])) {
+				return columns, i, nil
+			}
+		}
+		return columns, 0, ErrEmpty
+	}
+
+	for i := 0; i < len(records) && i < headerScanRecords; i++ {
+		if found, ok := matchHeader(records[i], m); ok {
+			for f := field(0); f < fieldCount; f++ {
+				if found[f] >= 0 && found[f] < len(records[i]) {
+					*column(m, f) = strings.TrimSpace(records[i][found[f]])
+				}
+			}
+			return found, i + 1, nil
+		}
+	}
+	return columns, 0, ErrNoHeader
+}
+
+// matchHeader проверяет, является ли запись строкой заголовка: в ней есть столбцы, заданные
+// в сопоставлении, а также столбец даты и суммы (со знаком или списания и зачисления)
+func matchHeader(record []string, m *models.StatementMapping) (columnSet, bool) {
+	var columns columnSet
+	names := make([]string, len(record))
+	for i, name := range record {
+		names[i] = normalizeName(name)
+	}
+	used := make([]bool, len(record))
+	find := func(name string) int {
+		for i, n := range names {
+			if !used[i] && n == name {
+				return i
+			}
+		}
+		return -1
+	}
+	for f := field(0); f < fieldCount; f++ {
+		columns[f] = -1
+		spec := *column(m, f)
+		if spec == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(spec); err == nil {
+			columns[f] = n - 1
+			if columns[f] < len(used) {
+				used[columns[f]] = true
+			}
+			continue
+		}
+		if columns[f] = find(normalizeName(spec)); columns[f] < 0 {
+			return columns, false
+		}
+		used[columns[f]] = true
+	}
+	// Сумма со знаком предпочтительнее пары «списание — зачисление», если задана или найдена
+	explicitPair := m.DebitColumn != "" || m.CreditColumn != ""
+	for f := field(0); f < fieldCount; f++ {
+		if columns[f] >= 0 || *column(m, f) != "" || f == fieldAmount && explicitPair {
+			continue
+		}
+		if (f == fieldDebit || f == fieldCredit) && columns[fieldAmount] >= 0 {
+			continue
+		}
+		for _, name := range synonyms[f] {
+			if i := find(name); i >= 0 {
+				columns[f], used[i] = i, true
+				break
+			}
+		}
+	}
+	if columns[fieldDate] < 0 {
+		return columns, false
+	}
+	return columns, columns[fieldAmount] >= 0 || columns[fieldDebit] >= 0 || columns[fieldCredit] >= 0
+}
+
+// normalizeName приводит название столбца к виду для сравнения: нижний регистр,
+// «ё» как «е», без кавычек и лишних пробелов
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "\"'"))
+	name = strings.ReplaceAll(name, "ё", "е")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// dateOnly отбрасывает время после даты: «31.01.2025 14:05:00» → «31.01.2025»
+func dateOnly(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexAny(value, " T"); i > 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+// parseDate разбирает дату по раскладке layout
+func parseDate(layout, value string) (time.Time, error) {
+	date, err := time.Parse(layout, dateOnly(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректная дата %q", value)
+	}
+	return date, nil
+}
+
+// looksLikeDate сообщает, разбирается ли значение хотя бы в одном из допустимых форматов
+func looksLikeDate(value string) bool {
+	for _, f := range dateFormats {
+		if _, err := time.Parse(f.layout, dateOnly(value)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDateFormat выбирает формат, в котором разбирается больше всего дат;
+// при равенстве — первый по порядку dateFormats. Пустая строка — ни одна дата не разобрана
+func detectDateFormat(values []string) string {
+	best, bestCount := "", 0
+	for _, f := range dateFormats {
+		count := 0
+		for _, v := range values {
+			if _, err := time.Parse(f.layout, dateOnly(v)); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = f.format, count
+		}
+	}
+	return best
+}
+
+// detectDecimalSeparator определяет десятичный разделитель по суммам
+// Последний разделитель в числе десятичный, если перед ним есть разделитель другого вида
+// или после него не три цифры; «1,500» и «1.500» неоднозначны и учитываются, только если
+// других подсказок нет — тогда разделитель считается разделителем разрядов
+func detectDecimalSeparator(values []string) string {
+	votes := map[string]int{}
+	ambiguous := map[string]int{}
+	for _, v := range values {
+		i := strings.LastIndexAny(v, ",.")
+		if i < 0 {
+			continue
+		}
+		sep := v[i : i+1]
+		digits := 0
+		for _, r := range v[i+1:] {
+			if r < '0' || r > '9' {
+				break
+			}
+			digits++
+		}
+		switch {
+		case strings.ContainsAny(v[:i], otherSeparator(sep)):
+			votes[sep]++
+		case strings.Count(v, sep) > 1:
+			votes[otherSeparator(sep)]++
+		case digits != 3:
+			votes[sep]++
+		default:
+			ambiguous[sep]++
+		}
+	}
+	switch {
+	case votes[","] > votes["."]:
+		return ","
+	case votes["."] > votes[","]:
+		return "."
+	case ambiguous[","] > ambiguous["."]:
+		return "."
+	}
+	return ","
+}
+
+// otherSeparator возвращает разделитель другого вида: «.» для «,» и наоборот
+func otherSeparator(sep string) string {
+	if sep == "," {
+		return "."
+	}
+	return ","
+}
+
+// parseAmount разбирает сумму с десятичным разделителем decimal в минимальные единицы валюты
+// Разделители разрядов, пробелы и обозначения валюты отбрасываются; сумма в скобках отрицательна
+// Пустое значение считается нулём
+func parseAmount(value, decimal, currency string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '+':
+			b.WriteRune(r)
+		case r == '−':
+			b.WriteRune('-')
+		case string(r) == decimal:
+			b.WriteRune('.')
+		}
+	}
+	amount, err := money.Parse(b.String(), currency)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная сумма %q", value)
+	}
+	if negative {
+		amount = -abs(amount)
+	}
+	return amount, nil
+}
+
+// abs возвращает модуль числа
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,248 @@
+package statement
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"family_finance_back/internal/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseDetectsMapping(t *testing.T) {
+	windows1251 := func(s string) string {
+		encoded, err := charmap.Windows1251.NewEncoder().String(s)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		return encoded
+	}
+	tests := []struct {
+		name    string
+		data    string
+		mapping models.StatementMapping
+		want    models.StatementMapping
+		rows    []Row
+	}{
+		{
+			name: "UTF-8 с BOM, точка с запятой, русские столбцы",
+			data: "\xef\xbb\xbfДата операции;Сумма;Описание\n" +
+				"31.01.2025 14:05:00;-1 234,56;Магазин\n" +
+				"01.02.2025;50 000,00;Зарплата\n",
+			want: models.StatementMapping{Encoding: EncodingUTF8, Delimiter: ";", DateFormat: "DD.MM.YYYY",
+				DecimalSeparator: ",", DateColumn: "Дата операции", AmountColumn: "Сумма", PayeeColumn: "Описание"},
+			rows: []Row{
+				{Line: 2, Date: day(2025, 1, 31), Amount: -123456, Payee: "Магазин"},
+				{Line: 3, Date: day(2025, 2, 1), Amount: 5000000, Payee: "Зарплата"},
+			},
+		},
+		{
+			name: "windows-1251 и реквизиты над заголовком",
+			data: windows1251("Выписка по счёту 40817810000000000001\n\n" +
+				"Дата;Списание;Зачисление;Назначение платежа\n" +
+				"05.03.2025;150,00;;Кофе\n" +
+				"06.03.2025;;1000,00;Перевод\n" +
+				";150,00;1000,00;Итого\n"),
+			want: models.StatementMapping{Encoding: EncodingWindows1251, Delimiter: ";", DateFormat: "DD.MM.YYYY",
+				DecimalSeparator: ",", DateColumn: "Дата", DebitColumn: "Списание", CreditColumn: "Зачисление",
+				NoteColumn: "Назначение платежа"},
+			rows: []Row{
+				{Line: 4, Date: day(2025, 3, 5), Amount: -15000, Note: "Кофе"},
+				{Line: 5, Date: day(2025, 3, 6), Amount: 100000, Note: "Перевод"},
+			},
+		},
+		{
+			name: "запятая, ISO-даты и разделитель разрядов",
+			data: "Date,Amount,Payee,Category\n" +
+				"2025-04-01,\"-1,500.25\",Grocery,Food\n" +
+				"2025-04-02,12.5,Refund,\n",
+			want: models.StatementMapping{Encoding: EncodingUTF8, Delimiter: ",", DateFormat: "YYYY-MM-DD",
+				DecimalSeparator: ".", DateColumn: "Date", AmountColumn: "Amount", PayeeColumn: "Payee",
+				CategoryColumn: "Category"},
+			rows: []Row{
+				{Line: 2, Date: day(2025, 4, 1), Amount: -150025, Payee: "Grocery", Category: "Food"},
+				{Line: 3, Date: day(2025, 4, 2), Amount: 1250, Payee: "Refund"},
+			},
+		},
+		{
+			name: "табуляция и месяц перед днём",
+			data: "Transaction Date\tDebit\tCredit\n" +
+				"01/31/2025\t20.00\t\n" +
+				"02/13/2025\t\t(5.00)\n",
+			want: models.StatementMapping{Encoding: EncodingUTF8, Delimiter: "\t", DateFormat: "MM/DD/YYYY",
+				DecimalSeparator: ".", DateColumn: "Transaction Date", DebitColumn: "Debit", CreditColumn: "Credit"},
+			rows: []Row{
+				{Line: 2, Date: day(2025, 1, 31), Amount: -2000},
+				{Line: 3, Date: day(2025, 2, 13), Amount: 500},
+			},
+		},
+		{
+			name:    "без заголовка, столбцы заданы номерами",
+			data:    "10.05.2025|−300,00|Такси\n11.05.2025|+20,50|Кешбэк\n",
+			mapping: models.StatementMapping{DateColumn: "1", AmountColumn: "2", PayeeColumn: "3"},
+			want: models.StatementMapping{Encoding: EncodingUTF8, Delimiter: "|", DateFormat: "DD.MM.YYYY",
+				DecimalSeparator: ",", DateColumn: "1", AmountColumn: "2", PayeeColumn: "3"},
+			rows: []Row{
+				{Line: 1, Date: day(2025, 5, 10), Amount: -30000, Payee: "Такси"},
+				{Line: 2, Date: day(2025, 5, 11), Amount: 2050, Payee: "Кешбэк"},
+			},
+		},
+		{
+			name: "ошибки строк не прерывают разбор",
+			data: "Дата;Сумма\n01.06.2025;abc\n32.06.2025;10,00\n03.06.2025;0,00\n04.06.2025;1,005\n",
+			want: models.StatementMapping{Encoding: EncodingUTF8, Delimiter: ";", DateFormat: "DD.MM.YYYY",
+				DecimalSeparator: ",", DateColumn: "Дата", AmountColumn: "Сумма"},
+			rows: []Row{
+				{Line: 2, Date: day(2025, 6, 1), Error: `некорректная сумма "abc"`},
+				{Line: 3, Error: `некорректная дата "32.06.2025"`},
+				{Line: 4, Date: day(2025, 6, 3), Error: "нулевая сумма операции"},
+				{Line: 5, Date: day(2025, 6, 4), Error: `некорректная сумма "1,005"`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data), "RUB", tt.mapping)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got.Mapping != tt.want {
+				t.Errorf("mapping = %+v, want %+v", got.Mapping, tt.want)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.rows) {
+				t.Errorf("rows = %+v, want %+v", got.Rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		mapping models.StatementMapping
+		want    string
+	}{
+		{name: "нет заголовка", data: "a;b\n1;2\n", want: ErrNoHeader.Error()},
+		{name: "нет операций", data: "Дата;Сумма\n;Итого\n", want: ErrEmpty.Error()},
+		{name: "не UTF-8", data: "\xff\xfe", mapping: models.StatementMapping{Encoding: EncodingUTF8}, want: "файл не в кодировке UTF-8"},
+		{name: "формат даты не определён", data: "Дата;Сумма\nвчера;10\n", want: "не удалось определить формат даты"},
+		{name: "имя столбца без заголовка", data: "01.01.2025;10\n",
+			mapping: models.StatementMapping{DateColumn: "1", AmountColumn: "2", PayeeColumn: "Описание"}, want: "задаются номерами"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), "RUB", tt.mapping)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "точка с запятой", text: "a;b;c\n1;2,5;3\n", want: ";"},
+		{name: "запятая с кавычками", text: "a,b\n\"1;2\",3\n", want: ","},
+		{name: "табуляция", text: "a\tb\n1\t2\n", want: "\t"},
+		{name: "вертикальная черта", text: "a|b|c\n1|2|3\n", want: "|"},
+		{name: "табуляция и запятые в суммах", text: "01.01.2025\t-100,50\tКофе\n02.01.2025\t20,00\tКешбэк\n", want: "\t"},
+		{name: "точка с запятой и запятые в суммах", text: "01.01.2025;-100,50\n02.01.2025;20,00\n", want: ";"},
+		{name: "один столбец", text: "a\nb\n", want: ";"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDelimiter(tt.text); got != tt.want {
+				t.Errorf("detectDelimiter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDateFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{name: "день и месяц неоднозначны — ДД/ММ", values: []string{"01/02/2025", "03/04/2025"}, want: "DD/MM/YYYY"},
+		{name: "месяц больше двенадцати — ММ/ДД", values: []string{"01/02/2025", "12/31/2025"}, want: "MM/DD/YYYY"},
+		{name: "дата со временем", values: []string{"2025-01-31T10:00:00"}, want: "YYYY-MM-DD"},
+		{name: "двузначный год", values: []string{"31.01.25"}, want: "DD.MM.YY"},
+		{name: "не дата", values: []string{"вчера"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDateFormat(tt.values); got != tt.want {
+				t.Errorf("detectDateFormat(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{name: "запятая", values: []string{"100,50"}, want: ","},
+		{name: "точка", values: []string{"100.5"}, want: "."},
+		{name: "точка после запятой разрядов", values: []string{"1,234.56"}, want: "."},
+		{name: "запятая после точки разрядов", values: []string{"1.234,56"}, want: ","},
+		{name: "несколько запятых — разряды", values: []string{"1,234,567"}, want: "."},
+		{name: "неоднозначная запятая — разряды", values: []string{"1,500"}, want: "."},
+		{name: "неоднозначное уступает однозначному", values: []string{"1,500", "20,5"}, want: ","},
+		{name: "без разделителей", values: []string{"100"}, want: ","},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDecimalSeparator(tt.values); got != tt.want {
+				t.Errorf("detectDecimalSeparator(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		decimal  string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{name: "пусто — ноль", value: "", decimal: ",", currency: "RUB", want: 0},
+		{name: "пробелы и знак рубля", value: "-1 234,56 ₽", decimal: ",", currency: "RUB", want: -123456},
+		{name: "точка разрядов", value: "1.234,56", decimal: ",", currency: "RUB", want: 123456},
+		{name: "запятая разрядов", value: "$1,234.56", decimal: ".", currency: "USD", want: 123456},
+		{name: "скобки — отрицательная", value: "(500.00)", decimal: ".", currency: "USD", want: -50000},
+		{name: "типографский минус", value: "−12,5", decimal: ",", currency: "RUB", want: -1250},
+		{name: "иена без дробной части", value: "1,500", decimal: ".", currency: "JPY", want: 1500},
+		{name: "лишние знаки после запятой", value: "1,005", decimal: ",", currency: "RUB", wantErr: true},
+		{name: "не число", value: "abc", decimal: ",", currency: "RUB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAmount(tt.value, tt.decimal, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAmount(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAmount(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// day возвращает дату без времени
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -6,9 +6,12 @@ import (
 	"time"
 )
 
-// GenerateCode генерирует случайный 6-значный код подтверждения
+// CodeLength количество цифр в коде подтверждения
+const CodeLength = 5
+
+// GenerateCode генерирует случайный код подтверждения длиной CodeLength цифр
 // Использует криптографически безопасный генератор случайных чисел
 func GenerateCode() string {
 	rand.Seed(time.Now().UnixNano())
-	return fmt.Sprintf("%05d", rand.Intn(100000))
+	return fmt.Sprintf("%0*d", CodeLength, rand.Intn(100000))
 }
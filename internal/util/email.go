@@ -0,0 +1,98 @@
+package util
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidEmail возвращается, если адрес невозможно привести к каноническому виду
+var ErrInvalidEmail = errors.New("некорректный адрес электронной почты")
+
+// CanonicalEmail приводит адрес к базовому каноническому виду:
+// обрезает пробелы, приводит к нижнему регистру и переводит
+// интернационализированный домен (IDN) в punycode
+// В этом виде адрес хранится и используется для доставки писем
+func CanonicalEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "", ErrInvalidEmail
+	}
+	local, domain := email[:at], strings.TrimSuffix(email[at+1:], ".")
+
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", ErrInvalidEmail
+	}
+	return local + "@" + asciiDomain, nil
+}
+
+// EmailNormalizer вычисляет ключ адреса электронной почты, по которому пользователи
+// ищутся и проверяются на уникальность (users.email_key)
+// Ключ может отличаться от адреса, который указал пользователь, поэтому письма
+// отправляются не на ключ, а на адрес в виде CanonicalEmail
+type EmailNormalizer struct {
+	// ProviderRules включает правила конкретных почтовых провайдеров
+	// (например, игнорирование точек и "+меток" в Gmail)
+	ProviderRules bool
+}
+
+// NewEmailNormalizer создает новый экземпляр EmailNormalizer
+func NewEmailNormalizer(providerRules bool) EmailNormalizer {
+	return EmailNormalizer{ProviderRules: providerRules}
+}
+
+// providerAliases сопоставляет домены-синонимы основному домену провайдера
+var providerAliases = map[string]string{
+	"googlemail.com": "gmail.com",
+	"ya.ru":          "yandex.ru",
+	"yandex.com":     "yandex.ru",
+	"yandex.by":      "yandex.ru",
+	"yandex.kz":      "yandex.ru",
+	"yandex.ua":      "yandex.ru",
+}
+
+// Normalize возвращает ключ адреса: CanonicalEmail и, если включены, правила провайдеров
+// Возвращает ErrInvalidEmail, если адрес некорректен
+func (n EmailNormalizer) Normalize(email string) (string, error) {
+	canonical, err := CanonicalEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if !n.ProviderRules {
+		return canonical, nil
+	}
+
+	at := strings.LastIndex(canonical, "@")
+	local, domain := canonical[:at], canonical[at+1:]
+	if alias, ok := providerAliases[domain]; ok {
+		domain = alias
+	}
+
+	switch domain {
+	case "gmail.com":
+		// Gmail игнорирует точки и всё, что после "+"
+		local = stripPlusTag(local)
+		local = strings.ReplaceAll(local, ".", "")
+	case "yandex.ru":
+		// Яндекс считает точку и дефис одним символом и поддерживает "+метки"
+		local = stripPlusTag(local)
+		local = strings.ReplaceAll(local, ".", "-")
+	case "mail.ru", "outlook.com", "hotmail.com", "icloud.com":
+		local = stripPlusTag(local)
+	}
+	if local == "" {
+		return "", ErrInvalidEmail
+	}
+	return local + "@" + domain, nil
+}
+
+// stripPlusTag отбрасывает "+метку" из локальной части адреса
+func stripPlusTag(local string) string {
+	if i := strings.Index(local, "+"); i > 0 {
+		return local[:i]
+	}
+	return local
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// derivedKeySize длина ключа, который DeriveKey получает из общего секрета
+const derivedKeySize = 32
+
+// ErrInvalidSignedToken возвращается, если подпись токена не совпадает или токен повреждён
+var ErrInvalidSignedToken = errors.New("недействительный токен")
+
+// SignToken формирует токен из частей данных и HMAC-SHA256 подписи:
+// base64url(часть1:часть2:...).base64url(подпись)
+// Части не должны содержать символ ":"
+func SignToken(secret string, parts ...string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, ":")))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(sign(secret, payload))
+}
+
+// VerifyToken проверяет подпись токена и возвращает части данных
+func VerifyToken(secret, token string) ([]string, error) {
+	payload, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidSignedToken
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(gotMAC, sign(secret, payload)) {
+		return nil, ErrInvalidSignedToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidSignedToken
+	}
+	return strings.Split(string(data), ":"), nil
+}
+
+// DeriveKey получает из общего секрета отдельный ключ для назначения purpose (HKDF-SHA256)
+// Ключи разных назначений независимы: подпись, сделанная одним ключом, не подходит к другому,
+// а по производному ключу нельзя восстановить секрет
+func DeriveKey(secret, purpose string) string {
+	key := make([]byte, derivedKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(purpose)), key); err != nil {
+		// HKDF-SHA256 отдаёт до 255*32 байт, поэтому 32 байта прочитаются всегда
+		panic(err)
+	}
+	return string(key)
+}
+
+// HashToken возвращает SHA-256 хеш токена в шестнадцатеричном виде
+// В базе хранится только хеш, чтобы утечка таблицы не раскрывала действующие токены
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomHex возвращает n случайных байт в шестнадцатеричном виде
+func RandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func sign(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
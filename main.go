@@ -1,24 +1,51 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"family_finance_back/config"
 	"family_finance_back/internal/db"
 	"family_finance_back/internal/handlers"
+	"family_finance_back/internal/mail"
 	"family_finance_back/internal/middleware"
 	"family_finance_back/internal/repository"
 	"family_finance_back/internal/service"
+	"family_finance_back/internal/util"
 
 	"github.com/go-redis/redis/v8"
 )
 
 func main() {
-	// Загружаем конфигурацию из .env
-	cfg := config.LoadConfig()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "rates":
+			runRates(os.Args[2:])
+			return
+		}
+	}
 
-	// Инициализируем PostgreSQL
+	// Загружаем конфигурацию: значения по умолчанию, файл, окружение и флаги
+	cfg, err := config.Load("family_finance_back", os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Инициализируем PostgreSQL (сервер не запустится, если схема устарела)
 	postgresDB := db.InitPostgres(cfg)
 
 	// Инициализируем Redis клиент
@@ -29,19 +56,88 @@ func main() {
 	})
 
 	// Инициализируем репозитории
-	userRepo := repository.NewUserRepository(postgresDB)
+	repos := repository.NewRepositories(postgresDB)
+	transactor := repository.NewTransactor(postgresDB)
 
 	// Инициализируем сервисы
-	emailSvc := service.NewEmailService(cfg)
-	authSvc := service.NewAuthService(userRepo, emailSvc, redisClient, cfg.JWTSecret)
-	userSvc := service.NewUserService(userRepo, cfg.JWTSecret)
+	emailNormalizer := util.NewEmailNormalizer(cfg.EmailProviderRules)
+	mailTransport, err := mail.NewTransport(cfg)
+	if err != nil {
+		log.Fatalf("error initializing mail transport: %v", err)
+	}
+	mailRenderer, err := mail.NewRenderer()
+	if err != nil {
+		log.Fatalf("error loading email templates: %v", err)
+	}
+	outboxSvc := service.NewOutboxService(repos.Outbox, mailTransport, service.OutboxOptions{
+		Workers:      cfg.OutboxWorkers,
+		BatchSize:    cfg.OutboxBatchSize,
+		PollInterval: cfg.OutboxPollInterval,
+		Lease:        5 * time.Minute,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+		BaseBackoff:  cfg.OutboxBaseBackoff,
+		MaxBackoff:   cfg.OutboxMaxBackoff,
+	})
+	emailSvc := service.NewEmailService(outboxSvc, mailRenderer, cfg.Sender(), cfg.EmailLocale)
+	inviteSvc := service.NewInviteService(repos.Invites, repos.Families, repos.Users, emailSvc, emailNormalizer, service.InviteOptions{
+		Secret: cfg.JWTSecret,
+		TTL:    cfg.InviteTTL,
+		AppURL: cfg.AppURL,
+	})
+	authSvc := service.NewAuthService(repos.Users, emailSvc, inviteSvc, redisClient, cfg.JWTSecret, emailNormalizer)
+	userSvc := service.NewUserService(repos.Users, cfg.JWTSecret, emailNormalizer)
+	familySvc := service.NewFamilyService(transactor, repos.Families)
+	rateSvc := service.NewExchangeRateService(repos.Rates, cfg.RatesPivotCurrency, cfg.RatesMaxAgeDays)
+	accountSvc := service.NewAccountService(repos.Accounts, repos.Transactions, repos.Families, rateSvc)
+	ledgerSvc := service.NewLedgerService(repos.Ledger)
+	notificationSvc := service.NewNotificationService(repos.Notifications)
+	budgetAlertSvc := service.NewBudgetAlertService(repos.Budgets, repos.Categories, repos.Transactions, repos.Families, notificationSvc, emailSvc, rateSvc)
+	splitSvc := service.NewSplitService(repos.Splits, repos.Transactions, repos.Families)
+	debtSvc := service.NewDebtService(transactor, repos.Debts, repos.Splits, repos.Families, notificationSvc, emailSvc)
+	goalSvc := service.NewGoalService(transactor, repos.Goals, repos.Accounts, repos.Families)
+	approvalSvc := service.NewApprovalService(transactor, repos.Approvals, repos.Transactions, repos.Accounts, repos.Categories,
+		repos.Families, rateSvc, budgetAlertSvc, notificationSvc, emailSvc)
+	limitSvc := service.NewSpendingLimitService(repos.Limits, repos.Transactions, repos.Accounts, repos.Families, rateSvc)
+	transactionSvc := service.NewTransactionService(transactor, repos.Transactions, repos.Accounts, repos.Categories, repos.Families,
+		budgetAlertSvc, approvalSvc, limitSvc)
+	categorySvc := service.NewCategoryService(transactor, repos.Categories, repos.Families)
+	budgetSvc := service.NewBudgetService(transactor, repos.Budgets, repos.Categories, repos.Transactions, repos.Families, rateSvc)
+	recurringSvc := service.NewRecurringService(transactor, repos.Recurring, repos.Accounts, repos.Categories, repos.Families,
+		budgetAlertSvc, approvalSvc, limitSvc, cfg.RecurringPollInterval)
+	billSvc := service.NewBillService(transactor, repos.Bills, repos.Accounts, repos.Categories, repos.Transactions,
+		repos.Families, notificationSvc, emailSvc, budgetAlertSvc, cfg.BillReminderInterval)
+	allowanceSvc := service.NewAllowanceService(transactor, repos.Allowances, repos.Accounts, repos.Families, cfg.RecurringPollInterval)
+	spendingSvc := service.NewSpendingRequestService(transactor, repos.Spending, repos.Accounts, repos.Categories, repos.Families, budgetAlertSvc)
+	importSvc := service.NewStatementImportService(transactor, repos.Statements, repos.Accounts, repos.Categories, repos.Families,
+		budgetAlertSvc, approvalSvc, limitSvc, cfg.ImportPollInterval)
 
 	// Инициализируем обработчики
 	authHandler := handlers.NewAuthHandler(authSvc)
 	userHandler := handlers.NewUserHandler(userSvc)
+	adminHandler := handlers.NewAdminHandler(outboxSvc, ledgerSvc)
+	familyHandler := handlers.NewFamilyHandler(familySvc)
+	inviteHandler := handlers.NewInviteHandler(inviteSvc)
+	accountHandler := handlers.NewAccountHandler(accountSvc)
+	transactionHandler := handlers.NewTransactionHandler(transactionSvc)
+	categoryHandler := handlers.NewCategoryHandler(categorySvc)
+	budgetHandler := handlers.NewBudgetHandler(budgetSvc)
+	notificationHandler := handlers.NewNotificationHandler(notificationSvc)
+	recurringHandler := handlers.NewRecurringHandler(recurringSvc)
+	billHandler := handlers.NewBillHandler(billSvc)
+	rateHandler := handlers.NewExchangeRateHandler(rateSvc)
+	splitHandler := handlers.NewSplitHandler(splitSvc)
+	debtHandler := handlers.NewDebtHandler(debtSvc)
+	goalHandler := handlers.NewGoalHandler(goalSvc)
+	allowanceHandler := handlers.NewAllowanceHandler(allowanceSvc)
+	spendingHandler := handlers.NewSpendingRequestHandler(spendingSvc)
+	approvalHandler := handlers.NewApprovalHandler(approvalSvc)
+	limitHandler := handlers.NewSpendingLimitHandler(limitSvc)
+	importHandler := handlers.NewStatementImportHandler(importSvc)
 
-	// Создаем middleware для проверки JWT токена
-	jwtMiddleware := middleware.JWTAuthMiddleware(redisClient)
+	// Создаем middleware для проверки JWT токена и прав администратора
+	jwtMiddleware := middleware.JWTAuthMiddleware(redisClient, userSvc)
+	adminMiddleware := middleware.AdminMiddleware(cfg.AdminEmails)
+	adminOnly := func(next http.HandlerFunc) http.HandlerFunc { return jwtMiddleware(adminMiddleware(next)) }
 
 	// Группируем эндпоинты, связанные с авторизацией, под префиксом /auth
 	http.HandleFunc("/auth/login", authHandler.RequestLoginCodeHandler)
@@ -55,6 +151,208 @@ func main() {
 	http.HandleFunc("/user/update", jwtMiddleware(userHandler.UpdateUserHandler))
 	http.HandleFunc("/user/search", jwtMiddleware(userHandler.SearchUserByEmailHandler))
 
-	log.Println("Сервер запущен на порту 8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	// Эндпоинты для работы с семьями (защищенные JWT)
+	http.HandleFunc("/families", jwtMiddleware(familyHandler.ListFamiliesHandler))
+	http.HandleFunc("/families/create", jwtMiddleware(familyHandler.CreateFamilyHandler))
+	http.HandleFunc("/families/rename", jwtMiddleware(familyHandler.RenameFamilyHandler))
+	http.HandleFunc("/families/currency", jwtMiddleware(familyHandler.SetCurrencyHandler))
+	http.HandleFunc("/families/leave", jwtMiddleware(familyHandler.LeaveFamilyHandler))
+	http.HandleFunc("/families/members", jwtMiddleware(familyHandler.ListMembersHandler))
+	http.HandleFunc("/families/members/role", jwtMiddleware(familyHandler.ChangeRoleHandler))
+
+	// Эндпоинты приглашений в семью (защищенные JWT)
+	http.HandleFunc("/families/invites", jwtMiddleware(inviteHandler.ListFamilyInvitesHandler))
+	http.HandleFunc("/families/invites/create", jwtMiddleware(inviteHandler.CreateInviteHandler))
+	http.HandleFunc("/families/invites/revoke", jwtMiddleware(inviteHandler.RevokeInviteHandler))
+	http.HandleFunc("/invites", jwtMiddleware(inviteHandler.ListMyInvitesHandler))
+	http.HandleFunc("/invites/accept", jwtMiddleware(inviteHandler.AcceptInviteHandler))
+	http.HandleFunc("/invites/decline", jwtMiddleware(inviteHandler.DeclineInviteHandler))
+
+	// Эндпоинты для работы со счетами (защищенные JWT)
+	http.HandleFunc("/accounts", jwtMiddleware(accountHandler.ListAccountsHandler))
+	http.HandleFunc("/accounts/get", jwtMiddleware(accountHandler.GetAccountHandler))
+	http.HandleFunc("/accounts/totals", jwtMiddleware(accountHandler.AccountTotalsHandler))
+	http.HandleFunc("/accounts/create", jwtMiddleware(accountHandler.CreateAccountHandler))
+	http.HandleFunc("/accounts/update", jwtMiddleware(accountHandler.UpdateAccountHandler))
+	http.HandleFunc("/accounts/delete", jwtMiddleware(accountHandler.DeleteAccountHandler))
+	http.HandleFunc("/accounts/reorder", jwtMiddleware(accountHandler.ReorderAccountsHandler))
+
+	// Эндпоинты для работы с операциями (защищенные JWT)
+	http.HandleFunc("/transactions", jwtMiddleware(transactionHandler.ListTransactionsHandler))
+	http.HandleFunc("/transactions/get", jwtMiddleware(transactionHandler.GetTransactionHandler))
+	http.HandleFunc("/transactions/create", jwtMiddleware(transactionHandler.CreateTransactionHandler))
+	http.HandleFunc("/transactions/update", jwtMiddleware(transactionHandler.UpdateTransactionHandler))
+	http.HandleFunc("/transactions/delete", jwtMiddleware(transactionHandler.DeleteTransactionHandler))
+	http.HandleFunc("/transfers/create", jwtMiddleware(transactionHandler.CreateTransferHandler))
+
+	// Эндпоинты разделения расходов между участниками семьи (защищенные JWT)
+	http.HandleFunc("/transactions/split", jwtMiddleware(splitHandler.GetSplitHandler))
+	http.HandleFunc("/transactions/split/history", jwtMiddleware(splitHandler.SplitHistoryHandler))
+	http.HandleFunc("/transactions/split/set", jwtMiddleware(splitHandler.SetSplitHandler))
+	http.HandleFunc("/transactions/split/cancel", jwtMiddleware(splitHandler.CancelSplitHandler))
+
+	// Эндпоинты долгов между участниками семьи (защищенные JWT)
+	http.HandleFunc("/debts", jwtMiddleware(debtHandler.DebtSummaryHandler))
+	http.HandleFunc("/debts/list", jwtMiddleware(debtHandler.ListDebtsHandler))
+	http.HandleFunc("/debts/create", jwtMiddleware(debtHandler.CreateDebtHandler))
+	http.HandleFunc("/debts/settle", jwtMiddleware(debtHandler.SettleDebtHandler))
+	http.HandleFunc("/debts/delete", jwtMiddleware(debtHandler.DeleteDebtHandler))
+
+	// Эндпоинты целей накоплений (защищенные JWT)
+	http.HandleFunc("/goals", jwtMiddleware(goalHandler.ListGoalsHandler))
+	http.HandleFunc("/goals/get", jwtMiddleware(goalHandler.GetGoalHandler))
+	http.HandleFunc("/goals/contributions", jwtMiddleware(goalHandler.ListContributionsHandler))
+	http.HandleFunc("/goals/create", jwtMiddleware(goalHandler.CreateGoalHandler))
+	http.HandleFunc("/goals/update", jwtMiddleware(goalHandler.UpdateGoalHandler))
+	http.HandleFunc("/goals/delete", jwtMiddleware(goalHandler.DeleteGoalHandler))
+	http.HandleFunc("/goals/contribute", jwtMiddleware(goalHandler.ContributeGoalHandler))
+
+	// Эндпоинты карманных денег детей и запросов на расход (защищенные JWT)
+	http.HandleFunc("/allowances", jwtMiddleware(allowanceHandler.ListAllowancesHandler))
+	http.HandleFunc("/allowances/get", jwtMiddleware(allowanceHandler.GetAllowanceHandler))
+	http.HandleFunc("/allowances/payments", jwtMiddleware(allowanceHandler.ListPaymentsHandler))
+	http.HandleFunc("/allowances/create", jwtMiddleware(allowanceHandler.CreateAllowanceHandler))
+	http.HandleFunc("/allowances/update", jwtMiddleware(allowanceHandler.UpdateAllowanceHandler))
+	http.HandleFunc("/allowances/pause", jwtMiddleware(allowanceHandler.PauseAllowanceHandler))
+	http.HandleFunc("/allowances/delete", jwtMiddleware(allowanceHandler.DeleteAllowanceHandler))
+	http.HandleFunc("/allowances/bonuses", jwtMiddleware(allowanceHandler.ListBonusesHandler))
+	http.HandleFunc("/allowances/bonuses/create", jwtMiddleware(allowanceHandler.CreateBonusHandler))
+	http.HandleFunc("/allowances/bonuses/delete", jwtMiddleware(allowanceHandler.DeleteBonusHandler))
+	http.HandleFunc("/spending-requests", jwtMiddleware(spendingHandler.ListSpendingRequestsHandler))
+	http.HandleFunc("/spending-requests/create", jwtMiddleware(spendingHandler.CreateSpendingRequestHandler))
+	http.HandleFunc("/spending-requests/approve", jwtMiddleware(spendingHandler.ApproveSpendingRequestHandler))
+	http.HandleFunc("/spending-requests/reject", jwtMiddleware(spendingHandler.RejectSpendingRequestHandler))
+	http.HandleFunc("/spending-requests/cancel", jwtMiddleware(spendingHandler.CancelSpendingRequestHandler))
+
+	// Эндпоинты одобрения расходов и правил одобрения (защищенные JWT)
+	http.HandleFunc("/approvals", jwtMiddleware(approvalHandler.ListApprovalsHandler))
+	http.HandleFunc("/approvals/approve", jwtMiddleware(approvalHandler.ApproveHandler))
+	http.HandleFunc("/approvals/reject", jwtMiddleware(approvalHandler.RejectHandler))
+	http.HandleFunc("/approvals/rules", jwtMiddleware(approvalHandler.ListRulesHandler))
+	http.HandleFunc("/approvals/rules/create", jwtMiddleware(approvalHandler.CreateRuleHandler))
+	http.HandleFunc("/approvals/rules/update", jwtMiddleware(approvalHandler.UpdateRuleHandler))
+	http.HandleFunc("/approvals/rules/delete", jwtMiddleware(approvalHandler.DeleteRuleHandler))
+
+	// Эндпоинты лимитов расходов участников (защищенные JWT)
+	http.HandleFunc("/spending-limits", jwtMiddleware(limitHandler.ListLimitsHandler))
+	http.HandleFunc("/spending-limits/remaining", jwtMiddleware(limitHandler.RemainingHandler))
+	http.HandleFunc("/spending-limits/create", jwtMiddleware(limitHandler.CreateLimitHandler))
+	http.HandleFunc("/spending-limits/update", jwtMiddleware(limitHandler.UpdateLimitHandler))
+	http.HandleFunc("/spending-limits/delete", jwtMiddleware(limitHandler.DeleteLimitHandler))
+
+	// Эндпоинты импорта банковских выписок и профилей сопоставления столбцов (защищенные JWT)
+	http.HandleFunc("/imports", jwtMiddleware(importHandler.ListImportsHandler))
+	http.HandleFunc("/imports/get", jwtMiddleware(importHandler.GetImportHandler))
+	http.HandleFunc("/imports/upload", jwtMiddleware(importHandler.UploadHandler))
+	http.HandleFunc("/imports/remap", jwtMiddleware(importHandler.RemapHandler))
+	http.HandleFunc("/imports/rows/update", jwtMiddleware(importHandler.UpdateRowHandler))
+	http.HandleFunc("/imports/commit", jwtMiddleware(importHandler.CommitHandler))
+	http.HandleFunc("/imports/delete", jwtMiddleware(importHandler.DeleteImportHandler))
+	http.HandleFunc("/imports/profiles", jwtMiddleware(importHandler.ListProfilesHandler))
+	http.HandleFunc("/imports/profiles/create", jwtMiddleware(importHandler.CreateProfileHandler))
+	http.HandleFunc("/imports/profiles/update", jwtMiddleware(importHandler.UpdateProfileHandler))
+	http.HandleFunc("/imports/profiles/delete", jwtMiddleware(importHandler.DeleteProfileHandler))
+
+	// Эндпоинты для работы с категориями (защищенные JWT)
+	http.HandleFunc("/categories", jwtMiddleware(categoryHandler.ListCategoriesHandler))
+	http.HandleFunc("/categories/create", jwtMiddleware(categoryHandler.CreateCategoryHandler))
+	http.HandleFunc("/categories/update", jwtMiddleware(categoryHandler.UpdateCategoryHandler))
+	http.HandleFunc("/categories/archive", jwtMiddleware(categoryHandler.ArchiveCategoryHandler))
+	http.HandleFunc("/categories/reorder", jwtMiddleware(categoryHandler.ReorderCategoriesHandler))
+	http.HandleFunc("/categories/merge", jwtMiddleware(categoryHandler.MergeCategoriesHandler))
+
+	// Эндпоинты для работы с бюджетами (защищенные JWT)
+	http.HandleFunc("/budgets", jwtMiddleware(budgetHandler.ListBudgetsHandler))
+	http.HandleFunc("/budgets/get", jwtMiddleware(budgetHandler.GetBudgetHandler))
+	http.HandleFunc("/budgets/progress", jwtMiddleware(budgetHandler.BudgetProgressHandler))
+	http.HandleFunc("/budgets/create", jwtMiddleware(budgetHandler.CreateBudgetHandler))
+	http.HandleFunc("/budgets/update", jwtMiddleware(budgetHandler.UpdateBudgetHandler))
+	http.HandleFunc("/budgets/delete", jwtMiddleware(budgetHandler.DeleteBudgetHandler))
+	http.HandleFunc("/budgets/copy", jwtMiddleware(budgetHandler.CopyBudgetHandler))
+
+	// Эндпоинты для работы с регулярными операциями (защищенные JWT)
+	http.HandleFunc("/recurring", jwtMiddleware(recurringHandler.ListRecurringHandler))
+	http.HandleFunc("/recurring/get", jwtMiddleware(recurringHandler.GetRecurringHandler))
+	http.HandleFunc("/recurring/occurrences", jwtMiddleware(recurringHandler.ListOccurrencesHandler))
+	http.HandleFunc("/recurring/create", jwtMiddleware(recurringHandler.CreateRecurringHandler))
+	http.HandleFunc("/recurring/update", jwtMiddleware(recurringHandler.UpdateRecurringHandler))
+	http.HandleFunc("/recurring/skip", jwtMiddleware(recurringHandler.SkipOccurrenceHandler))
+	http.HandleFunc("/recurring/pause", jwtMiddleware(recurringHandler.PauseRecurringHandler))
+	http.HandleFunc("/recurring/delete", jwtMiddleware(recurringHandler.DeleteRecurringHandler))
+
+	// Эндпоинты счетов к оплате и напоминаний о сроках (защищенные JWT)
+	http.HandleFunc("/bills", jwtMiddleware(billHandler.ListBillsHandler))
+	http.HandleFunc("/bills/get", jwtMiddleware(billHandler.GetBillHandler))
+	http.HandleFunc("/bills/upcoming", jwtMiddleware(billHandler.UpcomingBillsHandler))
+	http.HandleFunc("/bills/create", jwtMiddleware(billHandler.CreateBillHandler))
+	http.HandleFunc("/bills/update", jwtMiddleware(billHandler.UpdateBillHandler))
+	http.HandleFunc("/bills/delete", jwtMiddleware(billHandler.DeleteBillHandler))
+	http.HandleFunc("/bills/pay", jwtMiddleware(billHandler.PayBillHandler))
+	http.HandleFunc("/bills/unpay", jwtMiddleware(billHandler.UnpayBillHandler))
+
+	// Справочник валют и курсы (защищенные JWT)
+	http.HandleFunc("/currencies", jwtMiddleware(rateHandler.ListCurrenciesHandler))
+	http.HandleFunc("/rates", jwtMiddleware(rateHandler.ListRatesHandler))
+
+	// Эндпоинты ленты уведомлений и настроек их доставки (защищенные JWT)
+	http.HandleFunc("/notifications", jwtMiddleware(notificationHandler.ListNotificationsHandler))
+	http.HandleFunc("/notifications/read", jwtMiddleware(notificationHandler.MarkReadHandler))
+	http.HandleFunc("/notifications/preferences", jwtMiddleware(notificationHandler.ListPreferencesHandler))
+	http.HandleFunc("/notifications/preferences/update", jwtMiddleware(notificationHandler.UpdatePreferenceHandler))
+
+	// Административные эндпоинты (только для ADMIN_EMAILS)
+	http.HandleFunc("/admin/outbox", adminOnly(adminHandler.ListOutboxHandler))
+	http.HandleFunc("/admin/outbox/replay", adminOnly(adminHandler.ReplayOutboxHandler))
+	http.HandleFunc("/admin/ledger/check", adminOnly(adminHandler.CheckLedgerHandler))
+
+	// Отладочные эндпоинты (только для локальной разработки и интеграционных тестов)
+	if cfg.DevEndpoints {
+		capture, _ := mailTransport.(*mail.CaptureTransport)
+		devHandler := handlers.NewDevHandler(capture, mailRenderer)
+		http.HandleFunc("/dev/emails", devHandler.ListEmailsHandler)
+		http.HandleFunc("/dev/emails/preview", devHandler.PreviewEmailHandler)
+		log.Println("Внимание: включены отладочные эндпоинты /dev/*")
+	}
+
+	// Фоновые задачи останавливаются по SIGINT/SIGTERM вместе с сервером
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var background sync.WaitGroup
+	background.Add(5)
+	go func() {
+		defer background.Done()
+		outboxSvc.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		recurringSvc.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		billSvc.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		allowanceSvc.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		importSvc.Run(ctx)
+	}()
+
+	server := &http.Server{Addr: cfg.HTTPAddr}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Сервер запущен на %s", cfg.HTTPAddr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	background.Wait()
+	log.Println("Сервер остановлен")
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"family_finance_back/config"
+	"family_finance_back/internal/db"
+	"family_finance_back/internal/util"
+)
+
+// migrationsDir каталог с SQL-миграциями относительно корня репозитория
+const migrationsDir = "internal/db/migrations"
+
+// runMigrate выполняет подкоманду migrate: up, down [N|all], status, create <name>
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := fs.String("dir", migrationsDir, "каталог для новых миграций (для create)")
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: family_finance_back migrate [флаги] up | down [N|all] | status | create <имя>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// create не требует подключения к базе данных
+	if fs.Arg(0) == "create" {
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		upPath, downPath, err := db.CreateMigration(*dir, fs.Arg(1))
+		if err != nil {
+			log.Fatalf("Не удалось создать миграцию: %v", err)
+		}
+		fmt.Println("Создано:", upPath)
+		fmt.Println("Создано:", downPath)
+		return
+	}
+
+	// Миграциям нужна только база данных: Redis, почта и JWT_SECRET не требуются
+	cfg, err := loader.LoadDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	conn, err := db.Connect(cfg)
+	if err != nil {
+		log.Fatalf("error connecting to database: %v", err)
+	}
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		log.Fatalf("error loading migrations: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "up":
+		done, err := migrator.Up()
+		for _, m := range done {
+			fmt.Printf("Применена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Схема актуальна, новых миграций нет")
+		}
+		// Правила провайдеров нельзя выразить в SQL, поэтому ключи адресов вычисляем здесь
+		if err := db.NormalizeEmailKeys(conn, util.NewEmailNormalizer(cfg.EmailProviderRules)); err != nil {
+			log.Fatalf("Ошибка нормализации email: %v", err)
+		}
+
+	case "down":
+		steps := 1
+		if fs.NArg() > 1 {
+			if fs.Arg(1) == "all" {
+				steps = -1
+			} else if steps, err = strconv.Atoi(fs.Arg(1)); err != nil || steps < 1 {
+				log.Fatalf("Некорректное количество шагов: %s", fs.Arg(1))
+			}
+		}
+		done, err := migrator.Down(steps)
+		for _, m := range done {
+			fmt.Printf("Откачена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка отката: %v", err)
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Не удалось получить состояние миграций: %v", err)
+		}
+		for _, st := range statuses {
+			state := "ожидает"
+			if st.AppliedAt != nil {
+				state = "применена " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", st.Version, st.Name, state)
+		}
+
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
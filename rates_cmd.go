@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"family_finance_back/config"
+	"family_finance_back/internal/db"
+	"family_finance_back/internal/rates"
+	"family_finance_back/internal/repository"
+	"family_finance_back/internal/service"
+)
+
+// runRates выполняет подкоманду rates: import [файл|-]
+func runRates(args []string) {
+	fs := flag.NewFlagSet("rates", flag.ExitOnError)
+	format := fs.String("format", string(rates.FormatAuto), "формат файла: auto, cbr (XML Банка России) или csv")
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: family_finance_back rates import [флаги] [файл|-]")
+		fmt.Fprintln(os.Stderr, "Без файла используется RATES_FILE; \"-\" читает курсы из стандартного ввода")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "import" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+	if fs.NArg() > 1 || !rates.Format(*format).Valid() {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	path := cfg.RatesFile
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		log.Fatal("Не указан файл курсов: передайте путь аргументом или задайте RATES_FILE")
+	}
+
+	var input io.Reader = os.Stdin
+	source := "stdin"
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Не удалось открыть файл курсов: %v", err)
+		}
+		defer file.Close()
+		input, source = file, filepath.Base(path)
+	}
+	list, err := rates.Parse(input, rates.Format(*format))
+	if err != nil {
+		log.Fatalf("Не удалось разобрать файл курсов: %v", err)
+	}
+
+	conn := db.InitPostgres(cfg)
+	rateSvc := service.NewExchangeRateService(repository.NewExchangeRateRepository(conn), cfg.RatesPivotCurrency, cfg.RatesMaxAgeDays)
+	result, err := rateSvc.Import(list, source)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки курсов: %v", err)
+	}
+	fmt.Printf("Загружено курсов: %d\n", result.Imported)
+	if result.From != nil {
+		fmt.Printf("Даты: %s — %s\n", result.From.Format(time.DateOnly), result.To.Format(time.DateOnly))
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Пропущены валюты не из справочника: %v\n", result.Skipped)
+	}
+}